@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/services"
+	"github.com/go-web-dev/event-bus/storage"
+)
+
+const adminCommand = "admin"
+
+var adminSubcommands = map[string]func(args []string, out io.Writer) error{
+	"streams": adminStreams,
+	"dump":    adminDump,
+	"verify":  adminVerify,
+	"gc":      adminGC,
+	"purge":   adminPurge,
+}
+
+type adminStreamsResult struct {
+	Streams []models.StoredStream `json:"streams"`
+}
+
+type gcResult struct {
+	Rewritten int `json:"rewritten"`
+}
+
+// admin inspects and repairs the storage of a stopped server, the subcommand coming first
+func admin(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("admin subcommand is required: streams, dump, verify, gc or purge")
+	}
+	run, ok := adminSubcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown admin subcommand '%s': streams, dump, verify, gc or purge", args[0])
+	}
+	return run(args[1:], out)
+}
+
+// adminStreams lists the stored streams along with their events counted by status
+func adminStreams(args []string, out io.Writer) error {
+	fs, configPath := newFlagSet(adminCommand+" streams", out)
+	asJSON := fs.Bool("json", false, "print the output as JSON instead of text")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openAdminStorage(*configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	streams, err := services.NewInspector(db).Streams(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not list streams: %s", err)
+	}
+	if *asJSON {
+		return printJSON(out, adminStreamsResult{Streams: streams})
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tUNPROCESSED\tPROCESSED\tRETRY")
+	for _, s := range streams {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\n", s.Stream.Name, s.Stream.ID, s.Unprocessed, s.Processed, s.Retry)
+	}
+	return tw.Flush()
+}
+
+// adminDump prints the stored keys starting with a prefix, one per line, along with their values if asked to
+func adminDump(args []string, out io.Writer) error {
+	fs, configPath := newFlagSet(adminCommand+" dump", out)
+	prefix := fs.String("prefix", "", "prefix of the keys to dump, e.g. stream: or event:<stream id>:")
+	values := fs.Bool("values", false, "print the values along with the keys")
+	limit := fs.Int("limit", 0, "maximum number of keys to dump, zero for all of them")
+	asJSON := fs.Bool("json", false, "print every key as a JSON object instead of text")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openAdminStorage(*configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = services.NewInspector(db).Dump(context.Background(), *prefix, *values, *limit, func(key models.StoredKey) error {
+		if *asJSON {
+			return printJSON(out, key)
+		}
+		if *values {
+			_, err := fmt.Fprintf(out, "%s %s\n", key.Key, key.Value)
+			return err
+		}
+		_, err := fmt.Fprintln(out, key.Key)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not dump keys: %s", err)
+	}
+	return nil
+}
+
+// adminVerify checks the integrity of the stored data, failing when any issue is found
+func adminVerify(args []string, out io.Writer) error {
+	fs, configPath := newFlagSet(adminCommand+" verify", out)
+	asJSON := fs.Bool("json", false, "print the output as JSON instead of text")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openAdminStorage(*configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, err := services.NewInspector(db).Verify(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not verify storage: %s", err)
+	}
+	if *asJSON {
+		err = printJSON(out, report)
+		if err != nil {
+			return err
+		}
+	} else {
+		printIssues(out, report)
+		fmt.Fprintf(out, "checked %d keys, %d issues found\n", report.Keys, report.Issues())
+	}
+	if report.Issues() > 0 {
+		return fmt.Errorf("storage has %d integrity issues, admin purge removes them", report.Issues())
+	}
+	return nil
+}
+
+// adminGC rewrites the value log files holding enough discardable data to reclaim disk space
+func adminGC(args []string, out io.Writer) error {
+	fs, configPath := newFlagSet(adminCommand+" gc", out)
+	discardRatio := fs.Float64("discard-ratio", 0.5, "minimum ratio of discardable data for a value log file to be rewritten")
+	asJSON := fs.Bool("json", false, "print the output as JSON instead of text")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	settings, err := adminSettings(*configPath)
+	if err != nil {
+		return err
+	}
+
+	rewritten, err := storage.CollectGarbage(settings, *discardRatio)
+	if err != nil {
+		return fmt.Errorf("could not collect garbage: %s", err)
+	}
+	if *asJSON {
+		return printJSON(out, gcResult{Rewritten: rewritten})
+	}
+	fmt.Fprintf(out, "value log garbage collected, %d files rewritten\n", rewritten)
+	return nil
+}
+
+// adminPurge deletes the orphaned events and, if asked to, the values which cannot be decoded
+func adminPurge(args []string, out io.Writer) error {
+	fs, configPath := newFlagSet(adminCommand+" purge", out)
+	unparseable := fs.Bool("unparseable", false, "also delete the values which cannot be decoded, audit log entries excepted")
+	asJSON := fs.Bool("json", false, "print the output as JSON instead of text")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openAdminStorage(*configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, err := services.NewInspector(db).Purge(context.Background(), *unparseable)
+	if err != nil {
+		return fmt.Errorf("could not purge storage: %s", err)
+	}
+	if *asJSON {
+		return printJSON(out, report)
+	}
+	printIssues(out, report)
+	fmt.Fprintf(out, "purged %d orphaned and %d unparseable keys\n", len(report.Orphaned), len(report.Unparseable))
+	return nil
+}
+
+func printIssues(out io.Writer, report models.IntegrityReport) {
+	for _, issue := range report.Orphaned {
+		fmt.Fprintf(out, "orphaned %s: %s\n", issue.Key, issue.Reason)
+	}
+	for _, issue := range report.Unparseable {
+		fmt.Fprintf(out, "unparseable %s: %s\n", issue.Key, issue.Reason)
+	}
+}
+
+// adminSettings loads the storage settings, the admin commands working on the data directory only
+func adminSettings(configPath string) (storage.Settings, error) {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return storage.Settings{}, err
+	}
+	settings, err := storage.NewSettings(cfg)
+	if err != nil {
+		return storage.Settings{}, err
+	}
+	if settings.InMemory {
+		return storage.Settings{}, errors.New("admin commands cannot work on an in-memory storage")
+	}
+	return settings, nil
+}
+
+func openAdminStorage(configPath string) (*badger.DB, error) {
+	initLogger()
+	settings, err := adminSettings(configPath)
+	if err != nil {
+		return nil, err
+	}
+	db, err := storage.Open(settings)
+	if err != nil {
+		return nil, fmt.Errorf("could not open storage: %s", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-web-dev/event-bus/storage"
+)
+
+const (
+	backupCommand  = "backup"
+	restoreCommand = "restore"
+)
+
+type backupRequest struct {
+	Path  string `json:"path"`
+	Since uint64 `json:"since,omitempty"`
+}
+
+type backupResponse struct {
+	Path    string `json:"path"`
+	Version uint64 `json:"version"`
+}
+
+type restoreRequest struct {
+	Path        string `json:"path"`
+	Incremental bool   `json:"incremental,omitempty"`
+}
+
+// backup writes a backup of the storage into a file, either a full one or an incremental one
+// made of the entries stored since the version printed by the previous backup.
+// The running server writes the backup into its backup directory, unless -offline is given,
+// in which case the storage of the stopped server is backed up into a local file
+func backup(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(backupCommand, out)
+	path := fs.String("path", "", "path of the backup file to write, relative to the server backup directory unless -offline")
+	since := fs.Uint64("since", 0, "version to back up since, zero for a full backup")
+	offline := fs.Bool("offline", false, "back up the storage of a stopped server, read from the config, into a local file")
+	configPath := fs.String("config", defaultConfigPath, "config file path, with -offline")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("backup file path is required")
+	}
+	if *offline {
+		return backupOffline(*configPath, *path, *since, out)
+	}
+
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var res backupResponse
+	err = c.do("backup", backupRequest{Path: *path, Since: *since}, &res)
+	if err != nil {
+		return fmt.Errorf("could not back up storage: %s", err)
+	}
+	if *flags.json {
+		return printJSON(out, res)
+	}
+	fmt.Fprintf(out, "storage backed up successfully into %s, next incremental backup since version %d\n", res.Path, res.Version)
+	return nil
+}
+
+func backupOffline(configPath, path string, since uint64, out io.Writer) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	settings, err := storage.NewSettings(cfg)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create backup file: %s", err)
+	}
+	version, err := storage.Backup(settings, f, since)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("could not back up storage: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write backup file: %s", err)
+	}
+	fmt.Fprintf(out, "storage backed up successfully into %s, next incremental backup since version %d\n", path, version)
+	return nil
+}
+
+// restore loads a backup file into the storage, replacing the stored data unless the restore is incremental.
+// The running server restores a file of its backup directory and reloads its streams, unless -offline is given,
+// in which case a local file is restored into the storage of the stopped server
+func restore(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(restoreCommand, out)
+	path := fs.String("path", "", "path of the backup file to restore, relative to the server backup directory unless -offline")
+	incremental := fs.Bool("incremental", false, "apply the backup on top of the stored data instead of replacing it")
+	offline := fs.Bool("offline", false, "restore a local file into the storage of a stopped server, read from the config")
+	configPath := fs.String("config", defaultConfigPath, "config file path, with -offline")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("backup file path is required")
+	}
+	if *offline {
+		return restoreOffline(*configPath, *path, *incremental, out)
+	}
+
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	req := restoreRequest{Path: *path, Incremental: *incremental}
+	err = c.do("restore", req, nil)
+	if err != nil {
+		return fmt.Errorf("could not restore storage: %s", err)
+	}
+	if *flags.json {
+		return printJSON(out, req)
+	}
+	fmt.Fprintln(out, "storage restored successfully")
+	return nil
+}
+
+func restoreOffline(configPath, path string, incremental bool, out io.Writer) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	settings, err := storage.NewSettings(cfg)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open backup file: %s", err)
+	}
+	defer f.Close()
+	err = storage.Restore(settings, f, incremental)
+	if err != nil {
+		return fmt.Errorf("could not restore storage: %s", err)
+	}
+	fmt.Fprintln(out, "storage restored successfully")
+	return nil
+}
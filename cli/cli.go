@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/config"
+	"github.com/go-web-dev/event-bus/logging"
+)
+
+const defaultConfigPath = "config/config.yaml"
+
+type command struct {
+	description string
+	run         func(args []string, out io.Writer) error
+}
+
+var commands = map[string]command{
+	adminCommand: {
+		description: "inspect and repair the storage: streams, dump, verify, gc or purge (server must be stopped)",
+		run:         admin,
+	},
+	backupCommand: {
+		description: "back up the storage into a file of the server backup directory, -offline when the server is stopped",
+		run:         backup,
+	},
+	consumeCommand: {
+		description: "print the events waiting in a stream, -follow to keep polling (server must be running)",
+		run:         consume,
+	},
+	exportCommand: {
+		description: "export a stream as JSON Lines into a file (server must be stopped)",
+		run:         exportStream,
+	},
+	importCommand: {
+		description: "import a JSON Lines stream export into a stream (server must be stopped)",
+		run:         importStream,
+	},
+	markCommand: {
+		description: "mark an event as processed or with another status (server must be running)",
+		run:         mark,
+	},
+	publishCommand: {
+		description: "publish events to a stream (server must be running)",
+		run:         publish,
+	},
+	restoreCommand: {
+		description: "restore the storage from a file of the server backup directory, -offline when the server is stopped",
+		run:         restore,
+	},
+	rotateKeyCommand: {
+		description: "rotate the storage encryption key (server must be stopped)",
+		run:         rotateKey,
+	},
+	streamsCommand: {
+		description: "list, create, delete or describe the streams (server must be running)",
+		run:         streams,
+	},
+}
+
+// IsCommand tells whether the given program argument is an event-bus subcommand
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run runs the event-bus subcommand found in the given program arguments
+func Run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("command is required\n%s", usage())
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command '%s'\n%s", args[0], usage())
+	}
+	return cmd.run(args[1:], out)
+}
+
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("available commands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-20s %s\n", name, commands[name].description)
+	}
+	return b.String()
+}
+
+func newFlagSet(name string, out io.Writer) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("config", defaultConfigPath, "config file path")
+	return fs, configPath
+}
+
+// initLogger silences the logs of the services the commands run, unless a logger is already set
+func initLogger() {
+	if logging.Logger == nil {
+		logging.Logger = zap.NewNop()
+	}
+}
+
+func loadConfig(path string) (*config.Manager, error) {
+	cfg, err := config.NewManager(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create config manager: %s", err)
+	}
+	return cfg, nil
+}
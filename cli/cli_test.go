@@ -0,0 +1,542 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/storage"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+const (
+	testKey1 = "0123456789abcdef"
+	testKey2 = "fedcba9876543210fedcba9876543210"
+)
+
+type cliSuite struct {
+	testutils.Suite
+	dir string
+	out *bytes.Buffer
+}
+
+func (s *cliSuite) SetupSuite() {
+	logging.Logger = testutils.Logger(s.T(), nil)
+}
+
+func (s *cliSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "event-bus-cli")
+	s.Require().NoError(err)
+	s.dir = dir
+	s.out = new(bytes.Buffer)
+}
+
+func (s *cliSuite) TearDownTest() {
+	s.Require().NoError(os.RemoveAll(s.dir))
+}
+
+func (s *cliSuite) Test_IsCommand() {
+	s.True(IsCommand("rotate-key"))
+	s.False(IsCommand("-config"))
+}
+
+func (s *cliSuite) Test_Run_NoCommandError() {
+	err := Run(nil, s.out)
+
+	s.EqualError(err, "command is required\n"+usage())
+}
+
+func (s *cliSuite) Test_Run_UnknownCommandError() {
+	err := Run([]string{"abc"}, s.out)
+
+	s.EqualError(err, "unknown command 'abc'\n"+usage())
+}
+
+func (s *cliSuite) Test_RotateKey_Success() {
+	cfgPath := s.writeConfig(testKey1)
+	newKeyPath := filepath.Join(s.dir, "new.key")
+	s.Require().NoError(ioutil.WriteFile(newKeyPath, []byte(testKey2), 0600))
+	s.openStorage(testKey1)
+
+	err := Run([]string{"rotate-key", "-config", cfgPath, "-new-key-path", newKeyPath}, s.out)
+
+	s.Require().NoError(err)
+	s.Equal("encryption key rotated successfully\n", s.out.String())
+	s.openStorage(testKey2)
+}
+
+func (s *cliSuite) Test_RotateKey_FromEnv() {
+	cfgPath := s.writeConfig(testKey1)
+	s.Require().NoError(os.Setenv(newKeyEnv, testKey2))
+	defer os.Unsetenv(newKeyEnv)
+	s.openStorage(testKey1)
+
+	err := Run([]string{"rotate-key", "-config", cfgPath}, s.out)
+
+	s.Require().NoError(err)
+	s.openStorage(testKey2)
+}
+
+func (s *cliSuite) Test_RotateKey_KeyMismatchError() {
+	cfgPath := s.writeConfig(testKey2)
+	s.openStorage(testKey1)
+
+	err := Run([]string{"rotate-key", "-config", cfgPath, "-decrypt"}, s.out)
+
+	s.EqualError(err, "could not rotate encryption key: "+storage.ErrEncryptionKeyMismatch.Error())
+}
+
+func (s *cliSuite) Test_RotateKey_Decrypt() {
+	cfgPath := s.writeConfig(testKey1)
+	s.openStorage(testKey1)
+
+	err := Run([]string{"rotate-key", "-config", cfgPath, "-decrypt"}, s.out)
+
+	s.Require().NoError(err)
+	s.Equal("encryption turned off successfully\n", s.out.String())
+	s.openStorage("")
+}
+
+func (s *cliSuite) Test_RotateKey_EmptyKeyError() {
+	cfgPath := s.writeConfig(testKey1)
+	s.openStorage(testKey1)
+
+	err := Run([]string{"rotate-key", "-config", cfgPath}, s.out)
+
+	s.EqualError(err, "could not rotate encryption key: "+storage.ErrEmptyEncryptionKey.Error())
+	s.openStorage(testKey1)
+}
+
+func (s *cliSuite) Test_RotateKey_ConfigError() {
+	err := Run([]string{"rotate-key", "-config", "does_not_exist"}, s.out)
+
+	s.EqualError(err, `could not create config manager: Unsupported Config Type ""`)
+}
+
+func (s *cliSuite) Test_Backup_Success() {
+	addr, requests := s.serve(`{"operation":"backup","status":true,"body":{"path":"event-bus-1.bak","version":1042}}`)
+
+	err := Run(s.clientArgs(addr, "backup", "-path", "event-bus-1.bak", "-since", "1000"), s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation":"backup","body":{"path":"event-bus-1.bak","since":1000},"auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.Equal("storage backed up successfully into event-bus-1.bak, next incremental backup since version 1042\n", s.out.String())
+}
+
+func (s *cliSuite) Test_Restore_JSON() {
+	addr, requests := s.serve(`{"operation":"restore","status":true}`)
+
+	err := Run(s.clientArgs(addr, "restore", "-json", "-path", "event-bus-1.bak", "-incremental"), s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation":"restore","body":{"path":"event-bus-1.bak","incremental":true},"auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.JSONEq(`{"path":"event-bus-1.bak","incremental":true}`, s.out.String())
+}
+
+func (s *cliSuite) Test_Restore_RequestError() {
+	addr, _ := s.serve(`{"operation":"restore","status":false,"reason":"invalid backup: unexpected EOF"}`)
+
+	err := Run(s.clientArgs(addr, "restore", "-path", "event-bus.bak"), s.out)
+
+	s.EqualError(err, "could not restore storage: invalid backup: unexpected EOF")
+	s.Empty(s.out.String())
+}
+
+func (s *cliSuite) Test_BackupRestore_Offline_Success() {
+	cfgPath := s.writeConfig(testKey1)
+	s.openStorage(testKey1)
+	backupPath := filepath.Join(s.dir, "event-bus.bak")
+
+	err := Run([]string{"backup", "-offline", "-config", cfgPath, "-path", backupPath}, s.out)
+
+	s.Require().NoError(err)
+	s.Regexp("^storage backed up successfully into .+, next incremental backup since version \\d+\n$", s.out.String())
+	s.FileExists(backupPath)
+	s.out.Reset()
+	err = Run([]string{"restore", "-offline", "-config", cfgPath, "-path", backupPath}, s.out)
+	s.Require().NoError(err)
+	s.Equal("storage restored successfully\n", s.out.String())
+	s.openStorage(testKey1)
+}
+
+func (s *cliSuite) Test_Backup_PathRequiredError() {
+	err := Run([]string{"backup"}, s.out)
+
+	s.EqualError(err, "backup file path is required")
+}
+
+func (s *cliSuite) Test_Restore_Offline_InputError() {
+	cfgPath := s.writeConfig(testKey1)
+
+	err := Run([]string{"restore", "-offline", "-config", cfgPath, "-path", filepath.Join(s.dir, "missing.bak")}, s.out)
+
+	s.Require().Error(err)
+	s.Contains(err.Error(), "could not open backup file")
+}
+
+func (s *cliSuite) Test_Restore_Offline_KeyMismatchError() {
+	cfgPath := s.writeConfig(testKey2)
+	s.openStorage(testKey1)
+	backupPath := filepath.Join(s.dir, "event-bus.bak")
+	s.Require().NoError(ioutil.WriteFile(backupPath, nil, 0600))
+
+	err := Run([]string{"restore", "-offline", "-config", cfgPath, "-path", backupPath}, s.out)
+
+	s.EqualError(err, "could not restore storage: "+storage.ErrEncryptionKeyMismatch.Error())
+}
+
+func (s *cliSuite) Test_ExportImport_Success() {
+	cfgPath := s.writeConfig(testKey1)
+	exportPath := filepath.Join(s.dir, "source.jsonl")
+	createdAt := time.Now().UTC().Format(time.RFC3339)
+	export := `{"id":"source-id","name":"source"}
+{"id":"evt1-id","stream_id":"source-id","created_at":"` + createdAt + `","body":{"k":"v"}}
+`
+	s.Require().NoError(ioutil.WriteFile(exportPath, []byte(export), 0600))
+
+	err := Run([]string{"import", "-config", cfgPath, "-input", exportPath, "-stream", "copy"}, s.out)
+
+	s.Require().NoError(err)
+	s.Equal("stream 'copy' imported successfully: 1 imported, 0 skipped, 0 renamed\n", s.out.String())
+	s.out.Reset()
+	copyPath := filepath.Join(s.dir, "copy.jsonl")
+	err = Run([]string{"export", "-config", cfgPath, "-stream", "copy", "-output", copyPath}, s.out)
+	s.Require().NoError(err)
+	s.Equal("stream exported successfully with 1 events\n", s.out.String())
+	bs, err := ioutil.ReadFile(copyPath)
+	s.Require().NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(bs)), "\n")
+	s.Require().Len(lines, 2)
+	s.Contains(lines[0], `"name":"copy"`)
+	s.Contains(lines[1], `"id":"evt1-id"`)
+}
+
+func (s *cliSuite) Test_Export_StreamNotFoundError() {
+	cfgPath := s.writeConfig(testKey1)
+	exportPath := filepath.Join(s.dir, "source.jsonl")
+
+	err := Run([]string{"export", "-config", cfgPath, "-stream", "source", "-output", exportPath}, s.out)
+
+	s.EqualError(err, "could not export stream: stream 'source' not found")
+	s.NoFileExists(exportPath)
+}
+
+func (s *cliSuite) Test_Export_RequiredFlagsError() {
+	err := Run([]string{"export", "-stream", "source"}, s.out)
+
+	s.EqualError(err, "stream name and export file path are required")
+}
+
+func (s *cliSuite) Test_Import_InvalidImportError() {
+	cfgPath := s.writeConfig(testKey1)
+	exportPath := filepath.Join(s.dir, "source.jsonl")
+	s.Require().NoError(ioutil.WriteFile(exportPath, []byte("{\"name\":\"source\"}\n{"), 0600))
+
+	err := Run([]string{"import", "-config", cfgPath, "-input", exportPath}, s.out)
+
+	s.EqualError(err, "could not import stream: invalid import at line 2: unexpected EOF")
+}
+
+func (s *cliSuite) Test_Streams_List() {
+	addr, requests := s.serve(`{"operation":"list_streams","status":true,"body":{"streams":[{"id":"s1-id","name":"s1","created_at":"2020-12-15T00:00:00Z","usage":{"events":2,"bytes":20}}]}}`)
+
+	err := Run(s.clientArgs(addr, "streams", "list"), s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation":"list_streams","auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.Equal("NAME  ID     EVENTS  BYTES  CREATED AT\ns1    s1-id  2       20     2020-12-15T00:00:00Z\n", s.out.String())
+}
+
+func (s *cliSuite) Test_Streams_Create_JSON() {
+	res := `{"stream":{"id":"s1-id","name":"s1","created_at":"2020-12-15T00:00:00Z","compression":"zstd"}}`
+	addr, requests := s.serve(`{"operation":"create_stream","status":true,"body":` + res + `}`)
+
+	err := Run(s.clientArgs(addr, "streams", "create", "-json", "-stream", "s1", "-compression", "zstd", "-max-events", "10"), s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation":"create_stream","body":{"stream_name":"s1","compression":"zstd","max_events":10},"auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.JSONEq(res, s.out.String())
+}
+
+func (s *cliSuite) Test_Streams_Info() {
+	addr, _ := s.serve(`{"operation":"get_stream_info","status":true,"body":{"stream":{"id":"s1-id","name":"s1","created_at":"2020-12-15T00:00:00Z","max_bytes":100,"usage":{"events":2,"bytes":20}}}}`)
+
+	err := Run(s.clientArgs(addr, "streams", "info", "-stream", "s1"), s.out)
+
+	s.Require().NoError(err)
+	s.Equal("name:       s1\nid:         s1-id\ncreated at: 2020-12-15T00:00:00Z\nmax bytes:  100\nevents:     2\nbytes:      20\n", s.out.String())
+}
+
+func (s *cliSuite) Test_Streams_Delete_RequestError() {
+	addr, _ := s.serve(`{"operation":"delete_stream","status":false,"reason":"stream 's1' not found"}`)
+
+	err := Run(s.clientArgs(addr, "streams", "delete", "-stream", "s1"), s.out)
+
+	s.EqualError(err, "stream 's1' not found")
+	s.Empty(s.out.String())
+}
+
+func (s *cliSuite) Test_Streams_SubcommandError() {
+	s.EqualError(Run([]string{"streams"}, s.out), "streams subcommand is required: list, create, delete or info")
+	s.EqualError(Run([]string{"streams", "abc"}, s.out), "unknown streams subcommand 'abc': list, create, delete or info")
+}
+
+func (s *cliSuite) Test_Streams_CredentialsRequiredError() {
+	s.Require().NoError(os.Unsetenv(clientIDEnv))
+
+	err := Run([]string{"streams", "list", "-client-secret", "secret"}, s.out)
+
+	s.EqualError(err, "client id and secret are required")
+}
+
+func (s *cliSuite) Test_Publish_Stdin() {
+	stdin = strings.NewReader("{\"k\": 1}\n\n{\"k\": 2}\n")
+	defer func() { stdin = os.Stdin }()
+	ok := `{"operation":"write_event","status":true}`
+	addr, requests := s.serve(ok, ok)
+
+	err := Run(s.clientArgs(addr, "publish", "-stream", "s1", "-event-type", "user_created"), s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation":"write_event","body":{"stream_name":"s1","event":{"k":1},"event_type":"user_created"},"auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.JSONEq(`{"operation":"write_event","body":{"stream_name":"s1","event":{"k":2},"event_type":"user_created"},"auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.Equal("2 events published to stream 's1'\n", s.out.String())
+}
+
+func (s *cliSuite) Test_Publish_Data() {
+	addr, requests := s.serve(`{"operation":"write_event","status":true}`)
+
+	err := Run(append(s.clientArgs(addr, "publish", "-json", "-stream", "s1", "-content-type", "text/plain"), "hello"), s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation":"write_event","body":{"stream_name":"s1","content_type":"text/plain","data":"aGVsbG8="},"auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.JSONEq(`{"stream_name":"s1","published":1}`, s.out.String())
+}
+
+func (s *cliSuite) Test_Publish_InvalidJSONError() {
+	err := Run([]string{"publish", "-stream", "s1", "{}", "{"}, s.out)
+
+	s.EqualError(err, "event 2 is not valid JSON")
+}
+
+func (s *cliSuite) Test_Publish_RequestError() {
+	addr, _ := s.serve(`{"operation":"write_event","status":false,"reason":"event does not match the stream schema","context":{"violations":[{"path":"name","message":"name is required"}]}}`)
+
+	err := Run(append(s.clientArgs(addr, "publish", "-stream", "s1"), "{}"), s.out)
+
+	s.EqualError(err, `could not publish event 1: event does not match the stream schema: {"violations":[{"path":"name","message":"name is required"}]}`)
+}
+
+func (s *cliSuite) Test_Consume_Retry() {
+	addr, requests := s.serve(`{"operation":"retry_events","status":true,"body":{"events":[` +
+		`{"id":"e1","stream_id":"s1-id","status":2,"created_at":"2020-12-15T00:00:00Z","body":{"k":"v"}},` +
+		`{"id":"e2","stream_id":"s1-id","status":2,"created_at":"2020-12-15T00:00:00Z","content_type":"text/plain","data":"aGVsbG8="}]}}`)
+
+	err := Run(s.clientArgs(addr, "consume", "-stream", "s1", "-retry"), s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation":"retry_events","body":{"stream_name":"s1"},"auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.Equal("2020-12-15T00:00:00Z e1 retry {\"k\":\"v\"}\n2020-12-15T00:00:00Z e2 retry text/plain aGVsbG8=\n", s.out.String())
+}
+
+func (s *cliSuite) Test_Consume_Follow() {
+	e1 := `{"id":"e1","stream_id":"s1-id","status":0,"created_at":"2020-12-15T00:00:00Z","body":{"k":1}}`
+	e2 := `{"id":"e2","stream_id":"s1-id","status":0,"created_at":"2020-12-15T00:00:00Z","body":{"k":2}}`
+	addr, _ := s.serve(
+		`{"operation":"process_events","status":true,"body":{"events":[`+e1+`]}}`,
+		`{"operation":"process_events","status":true,"body":{"events":[`+e1+`,`+e2+`]}}`,
+		`{"operation":"shutdown","status":true}`,
+	)
+
+	err := Run(s.clientArgs(addr, "consume", "-json", "-stream", "s1", "-follow", "-interval", "1ms"), s.out)
+
+	s.EqualError(err, "connection closed by server: shutdown")
+	lines := strings.Split(strings.TrimSpace(s.out.String()), "\n")
+	s.Require().Len(lines, 2)
+	s.JSONEq(e1, lines[0])
+	s.JSONEq(e2, lines[1])
+}
+
+func (s *cliSuite) Test_Mark() {
+	addr, requests := s.serve(`{"operation":"mark_event","status":true}`)
+
+	err := Run(s.clientArgs(addr, "mark", "-event", "e1", "-status", "retry"), s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation":"mark_event","body":{"event_id":"e1","status":2},"auth":{"client_id":"id","client_secret":"secret"}}`, <-requests)
+	s.Equal("event 'e1' marked as retry\n", s.out.String())
+}
+
+func (s *cliSuite) Test_Mark_StatusError() {
+	err := Run([]string{"mark", "-event", "e1", "-status", "3"}, s.out)
+
+	s.EqualError(err, "unknown event status '3': unprocessed, processed or retry")
+}
+
+func (s *cliSuite) Test_Admin_Streams() {
+	cfgPath := s.writeConfig(testKey1)
+	s.writeRaw(map[string]string{
+		"stream:s1-id": `{"id":"s1-id","name":"s1","created_at":"2020-12-15T00:00:00Z"}`,
+		"event:s1-id:0:2020-12-15T00:00:00Z:e1-id": `{"id":"e1-id","stream_id":"s1-id","body":{}}`,
+		"event:s1-id:2:2020-12-15T00:00:00Z:e2-id": `{"id":"e2-id","stream_id":"s1-id","status":2,"body":{}}`,
+	})
+
+	err := Run([]string{"admin", "streams", "-config", cfgPath}, s.out)
+
+	s.Require().NoError(err)
+	s.Equal("NAME  ID     UNPROCESSED  PROCESSED  RETRY\ns1    s1-id  1            0          1\n", s.out.String())
+}
+
+func (s *cliSuite) Test_Admin_Dump() {
+	cfgPath := s.writeConfig(testKey1)
+	s.writeRaw(map[string]string{
+		"stream:s1-id": `{"id":"s1-id"}`,
+		"stream:s2-id": "{",
+		"stream:s3-id": `{"id":"s3-id"}`,
+	})
+
+	err := Run([]string{"admin", "dump", "-config", cfgPath, "-prefix", "stream:", "-values", "-limit", "2"}, s.out)
+
+	s.Require().NoError(err)
+	s.Equal("stream:s1-id {\"id\":\"s1-id\"}\nstream:s2-id \"{\"\n", s.out.String())
+}
+
+func (s *cliSuite) Test_Admin_VerifyPurge() {
+	cfgPath := s.writeConfig(testKey1)
+	s.writeRaw(map[string]string{
+		"stream:s1-id": `{"id":"s1-id","name":"s1"}`,
+		"event:s1-id:0:2020-12-15T00:00:00Z:e1-id": `{"id":"e1-id","stream_id":"s1-id","body":{}}`,
+		"event:s2-id:0:2020-12-15T00:00:00Z:e2-id": `{"id":"e2-id","stream_id":"s2-id","body":{}}`,
+		"event:s1-id:0:2020-12-15T00:00:00Z:e3-id": "{",
+	})
+
+	err := Run([]string{"admin", "verify", "-config", cfgPath}, s.out)
+
+	s.EqualError(err, "storage has 2 integrity issues, admin purge removes them")
+	s.Equal(
+		"orphaned event:s2-id:0:2020-12-15T00:00:00Z:e2-id: stream id 's2-id' not found\n"+
+			"unparseable event:s1-id:0:2020-12-15T00:00:00Z:e3-id: unexpected end of JSON input\n"+
+			"checked 4 keys, 2 issues found\n",
+		s.out.String(),
+	)
+	s.out.Reset()
+	s.Require().NoError(Run([]string{"admin", "purge", "-config", cfgPath, "-unparseable"}, s.out))
+	s.Contains(s.out.String(), "purged 1 orphaned and 1 unparseable keys\n")
+	s.out.Reset()
+	s.Require().NoError(Run([]string{"admin", "verify", "-config", cfgPath, "-json"}, s.out))
+	s.JSONEq(`{"keys":2,"orphaned":[],"unparseable":[]}`, s.out.String())
+}
+
+func (s *cliSuite) Test_Admin_GC() {
+	cfgPath := s.writeConfig(testKey1)
+	s.openStorage(testKey1)
+
+	err := Run([]string{"admin", "gc", "-config", cfgPath, "-json"}, s.out)
+
+	s.Require().NoError(err)
+	s.JSONEq(`{"rewritten":0}`, s.out.String())
+}
+
+func (s *cliSuite) Test_Admin_SubcommandError() {
+	s.EqualError(Run([]string{"admin"}, s.out), "admin subcommand is required: streams, dump, verify, gc or purge")
+	s.EqualError(Run([]string{"admin", "abc"}, s.out), "unknown admin subcommand 'abc': streams, dump, verify, gc or purge")
+}
+
+func (s *cliSuite) Test_Admin_InMemoryError() {
+	path := filepath.Join(s.dir, "config.yaml")
+	s.Require().NoError(ioutil.WriteFile(path, []byte("auth:\n  client:\n    client_id: id\n    client_secret: secret\nstorage:\n  in_memory: true\n"), 0600))
+
+	err := Run([]string{"admin", "verify", "-config", path}, s.out)
+
+	s.EqualError(err, "admin commands cannot work on an in-memory storage")
+}
+
+func (s *cliSuite) writeConfig(key string) string {
+	cfg := "auth:\n  client:\n    client_id: id\n    client_secret: secret\n" +
+		"storage:\n  dir: " + filepath.Join(s.dir, "badger") + "\n  encryption_key: " + key + "\n"
+	path := filepath.Join(s.dir, "config.yaml")
+	s.Require().NoError(ioutil.WriteFile(path, []byte(cfg), 0600))
+	return path
+}
+
+func (s *cliSuite) openStorage(key string) {
+	db, err := storage.Open(storage.Settings{
+		Dir:              filepath.Join(s.dir, "badger"),
+		ValueLogFileSize: 1 << 20,
+		Compression:      "none",
+		EncryptionKey:    []byte(key),
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(db.Close())
+}
+
+// writeRaw writes the entries into the storage as they are, encrypted with the first test key
+func (s *cliSuite) writeRaw(entries map[string]string) {
+	db, err := storage.Open(storage.Settings{
+		Dir:              filepath.Join(s.dir, "badger"),
+		ValueLogFileSize: 1 << 20,
+		Compression:      "none",
+		EncryptionKey:    []byte(testKey1),
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(db.Update(func(txn *badger.Txn) error {
+		for key, value := range entries {
+			err := txn.Set([]byte(key), []byte(value))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	s.Require().NoError(db.Close())
+}
+
+func (s *cliSuite) clientArgs(addr string, args ...string) []string {
+	return append(args, "-addr", addr, "-client-id", "id", "-client-secret", "secret")
+}
+
+// serve answers the requests of a single connection with the given responses, in order,
+// closing the connection once answered them all
+func (s *cliSuite) serve(responses ...string) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() {
+		l.Close()
+	})
+	requests := make(chan string, len(responses))
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for _, res := range responses {
+			req, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			requests <- req
+			_, err = io.WriteString(conn, res+"\n")
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return l.Addr().String(), requests
+}
+
+func Test_CliSuite(t *testing.T) {
+	suite.Run(t, new(cliSuite))
+}
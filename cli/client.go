@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// Environment variables the client commands take their defaults from, the server address one
+// being the one overriding server.addr in the server config
+const (
+	addrEnv         = "EVENT_BUS_SERVER_ADDR"
+	clientIDEnv     = "EVENT_BUS_CLIENT_ID"
+	clientSecretEnv = "EVENT_BUS_CLIENT_SECRET"
+	defaultAddr     = "localhost:8080"
+	dialTimeout     = 5 * time.Second
+)
+
+// clientFlags are the flags shared by the commands talking to a running server
+type clientFlags struct {
+	addr         *string
+	clientID     *string
+	clientSecret *string
+	json         *bool
+}
+
+type clientAuth struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+type clientRequest struct {
+	Operation string      `json:"operation"`
+	Body      interface{} `json:"body,omitempty"`
+	Auth      clientAuth  `json:"auth"`
+}
+
+type clientResponse struct {
+	Operation string          `json:"operation"`
+	Status    bool            `json:"status"`
+	Body      json.RawMessage `json:"body"`
+	Context   json.RawMessage `json:"context"`
+	Reason    string          `json:"reason"`
+}
+
+// client talks newline delimited JSON to the server, one request at a time
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+	auth clientAuth
+}
+
+func newClientFlagSet(name string, out io.Writer) (*flag.FlagSet, clientFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(out)
+	flags := clientFlags{
+		addr:         fs.String("addr", envOr(addrEnv, defaultAddr), "server address, defaults to "+addrEnv),
+		clientID:     fs.String("client-id", os.Getenv(clientIDEnv), "client ID, defaults to "+clientIDEnv),
+		clientSecret: fs.String("client-secret", os.Getenv(clientSecretEnv), "client secret, defaults to "+clientSecretEnv),
+		json:         fs.Bool("json", false, "print the output as JSON instead of text"),
+	}
+	return fs, flags
+}
+
+func envOr(key, value string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return value
+}
+
+func dial(flags clientFlags) (*client, error) {
+	if *flags.clientID == "" || *flags.clientSecret == "" {
+		return nil, errors.New("client id and secret are required")
+	}
+	conn, err := net.DialTimeout("tcp", *flags.addr, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to server: %s", err)
+	}
+	c := &client{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+		auth: clientAuth{
+			ClientID:     *flags.clientID,
+			ClientSecret: *flags.clientSecret,
+		},
+	}
+	return c, nil
+}
+
+// do makes a request and decodes the response body into v, if any.
+// Failed requests return the response reason as error, along with its context when given
+func (c *client) do(operation string, body interface{}, v interface{}) error {
+	req := clientRequest{
+		Operation: operation,
+		Body:      body,
+		Auth:      c.auth,
+	}
+	err := json.NewEncoder(c.conn).Encode(req)
+	if err != nil {
+		return fmt.Errorf("could not send request: %s", err)
+	}
+	line, err := c.r.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("could not read response: %s", err)
+	}
+	var res clientResponse
+	err = json.Unmarshal(line, &res)
+	if err != nil {
+		return fmt.Errorf("could not decode response: %s", err)
+	}
+	// the server notifies the connections it closes, on shutdown or when disconnecting a client
+	if res.Operation != operation {
+		return fmt.Errorf("connection closed by server: %s", res.Operation)
+	}
+	if !res.Status {
+		if len(res.Context) > 0 {
+			return fmt.Errorf("%s: %s", res.Reason, res.Context)
+		}
+		return errors.New(res.Reason)
+	}
+	if v == nil || len(res.Body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(res.Body, v)
+}
+
+func (c *client) Close() error {
+	return c.conn.Close()
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	return json.NewEncoder(out).Encode(v)
+}
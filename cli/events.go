@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+const (
+	publishCommand = "publish"
+	consumeCommand = "consume"
+	markCommand    = "mark"
+)
+
+// stdin is where publish reads the events from when none is given as argument
+var stdin io.Reader = os.Stdin
+
+var statusNames = map[uint8]string{
+	models.EventUnprocessedStatus: "unprocessed",
+	models.EventProcessedStatus:   "processed",
+	models.EventRetryStatus:       "retry",
+}
+
+type writeEventRequest struct {
+	StreamName       string          `json:"stream_name"`
+	Event            json.RawMessage `json:"event,omitempty"`
+	ContentType      string          `json:"content_type,omitempty"`
+	Data             []byte          `json:"data,omitempty"`
+	EventType        string          `json:"event_type,omitempty"`
+	EventTypeVersion int             `json:"event_type_version,omitempty"`
+}
+
+type publishResult struct {
+	StreamName string `json:"stream_name"`
+	Published  int    `json:"published"`
+}
+
+type eventsResponse struct {
+	Events []models.Event `json:"events"`
+}
+
+type markEventRequest struct {
+	EventID string `json:"event_id"`
+	Status  uint8  `json:"status"`
+}
+
+// publish writes the events given as arguments into a stream, or the ones read from stdin, one JSON event per line.
+// Events of any other content type are written as they are, a single one out of the whole stdin
+func publish(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(publishCommand, out)
+	streamName := fs.String("stream", "", "name of the stream to publish to")
+	contentType := fs.String("content-type", "", "content type of the events, JSON by default")
+	eventType := fs.String("event-type", "", "event type the JSON events are validated against")
+	eventTypeVersion := fs.Int("event-type-version", 0, "version of the event type, the latest one by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *streamName == "" {
+		return errors.New("stream name is required")
+	}
+	isJSON := models.IsJSONContentType(*contentType)
+	events, err := readEvents(fs.Args(), isJSON)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return errors.New("no event to publish")
+	}
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	res := publishResult{StreamName: *streamName}
+	for _, evt := range events {
+		req := writeEventRequest{
+			StreamName:       *streamName,
+			EventType:        *eventType,
+			EventTypeVersion: *eventTypeVersion,
+		}
+		if isJSON {
+			req.Event = json.RawMessage(evt)
+		} else {
+			req.ContentType = *contentType
+			req.Data = evt
+		}
+		err = c.do("write_event", req, nil)
+		if err != nil {
+			return fmt.Errorf("could not publish event %d: %s", res.Published+1, err)
+		}
+		res.Published++
+	}
+	if *flags.json {
+		return printJSON(out, res)
+	}
+	fmt.Fprintf(out, "%d events published to stream '%s'\n", res.Published, res.StreamName)
+	return nil
+}
+
+func readEvents(args []string, isJSON bool) ([][]byte, error) {
+	var events [][]byte
+	if len(args) > 0 {
+		for _, arg := range args {
+			events = append(events, []byte(arg))
+		}
+	} else if isJSON {
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			if line := scanner.Bytes(); len(line) > 0 {
+				events = append(events, append([]byte{}, line...))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("could not read events: %s", err)
+		}
+	} else {
+		data, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("could not read event: %s", err)
+		}
+		events = append(events, data)
+	}
+	if isJSON {
+		for i, evt := range events {
+			if !json.Valid(evt) {
+				return nil, fmt.Errorf("event %d is not valid JSON", i+1)
+			}
+		}
+	}
+	return events, nil
+}
+
+// consume prints the unprocessed events of a stream, or the ones to retry.
+// Following the stream polls it until interrupted, printing the events once, as long as they are not marked
+func consume(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(consumeCommand, out)
+	streamName := fs.String("stream", "", "name of the stream to consume")
+	retry := fs.Bool("retry", false, "consume the events to retry instead of the unprocessed ones")
+	follow := fs.Bool("follow", false, "keep polling the stream for new events until interrupted")
+	interval := fs.Duration("interval", time.Second, "interval between two polls when following the stream")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *streamName == "" {
+		return errors.New("stream name is required")
+	}
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	operation := "process_events"
+	if *retry {
+		operation = "retry_events"
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	printed := map[string]bool{}
+	for {
+		var res eventsResponse
+		err = c.do(operation, streamRequest{StreamName: *streamName}, &res)
+		if err != nil {
+			return err
+		}
+		// only the events still waiting are kept, the marked ones are never returned again
+		polled := make(map[string]bool, len(res.Events))
+		for _, evt := range res.Events {
+			polled[evt.ID] = true
+			if printed[evt.ID] {
+				continue
+			}
+			err = printEvent(out, evt, *flags.json)
+			if err != nil {
+				return err
+			}
+		}
+		printed = polled
+		if !*follow {
+			return nil
+		}
+		select {
+		case <-signals:
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// printEvent prints an event on a single line, binary data being base64 encoded
+func printEvent(out io.Writer, evt models.Event, asJSON bool) error {
+	if asJSON {
+		return printJSON(out, evt)
+	}
+	payload := string(evt.Body)
+	if !evt.IsJSON() {
+		payload = evt.ContentType + " " + base64.StdEncoding.EncodeToString(evt.Data)
+	}
+	_, err := fmt.Fprintf(out, "%s %s %s %s\n", evt.CreatedAt.Format(time.RFC3339), evt.ID, statusNames[evt.Status], payload)
+	return err
+}
+
+// mark marks an event as processed, or with any other status given by name or number
+func mark(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(markCommand, out)
+	eventID := fs.String("event", "", "ID of the event to mark")
+	status := fs.String("status", statusNames[models.EventProcessedStatus], "status to mark the event with: unprocessed, processed or retry")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *eventID == "" {
+		return errors.New("event id is required")
+	}
+	req := markEventRequest{EventID: *eventID}
+	var err error
+	req.Status, err = parseStatus(*status)
+	if err != nil {
+		return err
+	}
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	err = c.do("mark_event", req, nil)
+	if err != nil {
+		return err
+	}
+	if *flags.json {
+		return printJSON(out, req)
+	}
+	fmt.Fprintf(out, "event '%s' marked as %s\n", req.EventID, statusNames[req.Status])
+	return nil
+}
+
+func parseStatus(status string) (uint8, error) {
+	for value, name := range statusNames {
+		if name == status {
+			return value, nil
+		}
+	}
+	value, err := strconv.ParseUint(status, 10, 8)
+	if err == nil {
+		if _, ok := statusNames[uint8(value)]; ok {
+			return uint8(value), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown event status '%s': unprocessed, processed or retry", status)
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-web-dev/event-bus/storage"
+)
+
+const (
+	rotateKeyCommand = "rotate-key"
+	newKeyEnv        = "EVENT_BUS_NEW_ENCRYPTION_KEY"
+)
+
+// rotateKey re-encrypts the storage key registry: the current key comes from the config,
+// the new one either from a key file or from the EVENT_BUS_NEW_ENCRYPTION_KEY environment variable.
+// Turning encryption off takes -decrypt instead of a new key, so that a missing key is never taken for it
+func rotateKey(args []string, out io.Writer) error {
+	fs, configPath := newFlagSet(rotateKeyCommand, out)
+	newKeyPath := fs.String("new-key-path", "", "path of the file holding the new encryption key")
+	decrypt := fs.Bool("decrypt", false, "turn encryption off for newly written data instead of rotating to a new key")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	settings, err := storage.NewSettings(cfg)
+	if err != nil {
+		return err
+	}
+	newKey, err := storage.LoadEncryptionKey(os.Getenv(newKeyEnv), *newKeyPath)
+	if err != nil {
+		return err
+	}
+
+	err = storage.RotateKey(settings, newKey, *decrypt)
+	if err != nil {
+		return fmt.Errorf("could not rotate encryption key: %s", err)
+	}
+	if *decrypt {
+		fmt.Fprintln(out, "encryption turned off successfully")
+		return nil
+	}
+	fmt.Fprintln(out, "encryption key rotated successfully")
+	return nil
+}
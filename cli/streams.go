@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+const streamsCommand = "streams"
+
+var streamsSubcommands = map[string]func(args []string, out io.Writer) error{
+	"list":   listStreams,
+	"create": createStream,
+	"delete": deleteStream,
+	"info":   streamInfo,
+}
+
+type streamRequest struct {
+	StreamName   string          `json:"stream_name"`
+	Compression  string          `json:"compression,omitempty"`
+	Schema       json.RawMessage `json:"schema,omitempty"`
+	MaxEventSize int64           `json:"max_event_size,omitempty"`
+	MaxEvents    int64           `json:"max_events,omitempty"`
+	MaxBytes     int64           `json:"max_bytes,omitempty"`
+}
+
+type streamResponse struct {
+	Stream models.Stream `json:"stream"`
+}
+
+type listStreamsResponse struct {
+	Streams []models.Stream `json:"streams"`
+}
+
+// streams manages the streams of a running server, the subcommand coming first
+func streams(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("streams subcommand is required: list, create, delete or info")
+	}
+	run, ok := streamsSubcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown streams subcommand '%s': list, create, delete or info", args[0])
+	}
+	return run(args[1:], out)
+}
+
+func listStreams(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(streamsCommand+" list", out)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var res listStreamsResponse
+	err = c.do("list_streams", nil, &res)
+	if err != nil {
+		return err
+	}
+	if *flags.json {
+		return printJSON(out, res)
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tEVENTS\tBYTES\tCREATED AT")
+	for _, stream := range res.Streams {
+		var usage models.Usage
+		if stream.Usage != nil {
+			usage = *stream.Usage
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", stream.Name, stream.ID, usage.Events, usage.Bytes, stream.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func createStream(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(streamsCommand+" create", out)
+	streamName := fs.String("stream", "", "name of the stream to create")
+	compression := fs.String("compression", "", "compression of the stream events, snappy or zstd")
+	schema := fs.String("schema", "", "JSON Schema the stream events must match")
+	maxEventSize := fs.Int64("max-event-size", 0, "maximum size in bytes of a single event")
+	maxEvents := fs.Int64("max-events", 0, "maximum number of stored events")
+	maxBytes := fs.Int64("max-bytes", 0, "maximum total size in bytes of the stored events")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *streamName == "" {
+		return errors.New("stream name is required")
+	}
+	req := streamRequest{
+		StreamName:   *streamName,
+		Compression:  *compression,
+		MaxEventSize: *maxEventSize,
+		MaxEvents:    *maxEvents,
+		MaxBytes:     *maxBytes,
+	}
+	if *schema != "" {
+		if !json.Valid([]byte(*schema)) {
+			return errors.New("schema must be valid JSON")
+		}
+		req.Schema = json.RawMessage(*schema)
+	}
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var res streamResponse
+	err = c.do("create_stream", req, &res)
+	if err != nil {
+		return err
+	}
+	if *flags.json {
+		return printJSON(out, res)
+	}
+	fmt.Fprintf(out, "stream '%s' created with id %s\n", res.Stream.Name, res.Stream.ID)
+	return nil
+}
+
+func deleteStream(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(streamsCommand+" delete", out)
+	streamName := fs.String("stream", "", "name of the stream to delete")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *streamName == "" {
+		return errors.New("stream name is required")
+	}
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	err = c.do("delete_stream", streamRequest{StreamName: *streamName}, nil)
+	if err != nil {
+		return err
+	}
+	if *flags.json {
+		return printJSON(out, streamRequest{StreamName: *streamName})
+	}
+	fmt.Fprintf(out, "stream '%s' deleted\n", *streamName)
+	return nil
+}
+
+func streamInfo(args []string, out io.Writer) error {
+	fs, flags := newClientFlagSet(streamsCommand+" info", out)
+	streamName := fs.String("stream", "", "name of the stream to describe")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *streamName == "" {
+		return errors.New("stream name is required")
+	}
+	c, err := dial(flags)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var res streamResponse
+	err = c.do("get_stream_info", streamRequest{StreamName: *streamName}, &res)
+	if err != nil {
+		return err
+	}
+	if *flags.json {
+		return printJSON(out, res)
+	}
+	printStream(out, res.Stream)
+	return nil
+}
+
+// printStream prints the stream fields one per line, leaving out the unset optional ones
+func printStream(out io.Writer, stream models.Stream) {
+	tw := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "name:\t%s\n", stream.Name)
+	fmt.Fprintf(tw, "id:\t%s\n", stream.ID)
+	fmt.Fprintf(tw, "created at:\t%s\n", stream.CreatedAt.Format(time.RFC3339))
+	if stream.Compression != "" {
+		fmt.Fprintf(tw, "compression:\t%s\n", stream.Compression)
+	}
+	if len(stream.Schema) > 0 {
+		fmt.Fprintf(tw, "schema:\t%s\n", stream.Schema)
+	}
+	if stream.MaxEventSize > 0 {
+		fmt.Fprintf(tw, "max event size:\t%d\n", stream.MaxEventSize)
+	}
+	if stream.MaxEvents > 0 {
+		fmt.Fprintf(tw, "max events:\t%d\n", stream.MaxEvents)
+	}
+	if stream.MaxBytes > 0 {
+		fmt.Fprintf(tw, "max bytes:\t%d\n", stream.MaxBytes)
+	}
+	if stream.Usage != nil {
+		fmt.Fprintf(tw, "events:\t%d\n", stream.Usage.Events)
+		fmt.Fprintf(tw, "bytes:\t%d\n", stream.Usage.Bytes)
+	}
+	tw.Flush()
+}
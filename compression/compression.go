@@ -0,0 +1,106 @@
+// Package compression compresses the stored events and the responses of the connections asking for it
+package compression
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms
+const (
+	None   = ""
+	Snappy = "snappy"
+	Zstd   = "zstd"
+)
+
+// ErrTooLarge is returned when the decompressed data exceeds the given max size
+var ErrTooLarge = errors.New("decompressed data is too large")
+
+// the zstd encoder and decoder are safe for concurrent use and are only created when first needed
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func initZstd() {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil)
+	})
+}
+
+// Supported tells whether the compression algorithm is known, no compression included
+func Supported(algorithm string) bool {
+	switch algorithm {
+	case None, Snappy, Zstd:
+		return true
+	}
+	return false
+}
+
+// Algorithms lists all the supported compression algorithms
+func Algorithms() []string {
+	return []string{Snappy, Zstd}
+}
+
+// Compress compresses the data with the given algorithm
+func Compress(algorithm string, data []byte) ([]byte, error) {
+	switch algorithm {
+	case None:
+		return data, nil
+	case Snappy:
+		return snappy.Encode(nil, data), nil
+	case Zstd:
+		initZstd()
+		return zstdEncoder.EncodeAll(data, nil), nil
+	}
+	return nil, errors.New("unknown compression algorithm " + algorithm)
+}
+
+// Decompress decompresses the data with the given algorithm.
+// A positive max size limits the size of the decompressed data
+func Decompress(algorithm string, data []byte, maxSize int) ([]byte, error) {
+	switch algorithm {
+	case None:
+		return data, nil
+	case Snappy:
+		size, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if maxSize > 0 && size > maxSize {
+			return nil, ErrTooLarge
+		}
+		return snappy.Decode(nil, data)
+	case Zstd:
+		return decompressZstd(data, maxSize)
+	}
+	return nil, errors.New("unknown compression algorithm " + algorithm)
+}
+
+func decompressZstd(data []byte, maxSize int) ([]byte, error) {
+	if maxSize <= 0 {
+		initZstd()
+		return zstdDecoder.DecodeAll(data, nil)
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(maxSize)), zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+	res, err := dec.DecodeAll(data, nil)
+	if err != nil {
+		if err == zstd.ErrDecoderSizeExceeded || err == zstd.ErrWindowSizeExceeded {
+			return nil, ErrTooLarge
+		}
+		return nil, err
+	}
+	if len(res) > maxSize {
+		return nil, ErrTooLarge
+	}
+	return res, nil
+}
@@ -0,0 +1,82 @@
+package compression
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+type compressionSuite struct {
+	testutils.Suite
+	data []byte
+}
+
+func (s *compressionSuite) SetupTest() {
+	s.data = bytes.Repeat([]byte(`{"field": "value"}`), 100)
+}
+
+func (s *compressionSuite) Test_Supported() {
+	s.True(Supported(""))
+	s.True(Supported("snappy"))
+	s.True(Supported("zstd"))
+	s.False(Supported("gzip"))
+}
+
+func (s *compressionSuite) Test_Algorithms() {
+	s.Equal([]string{"snappy", "zstd"}, Algorithms())
+}
+
+func (s *compressionSuite) Test_RoundTrip() {
+	for _, algorithm := range []string{None, Snappy, Zstd} {
+		s.Run(algorithm, func() {
+			compressed, err := Compress(algorithm, s.data)
+			s.Require().NoError(err)
+			if algorithm != None {
+				s.Less(len(compressed), len(s.data))
+			}
+
+			decompressed, err := Decompress(algorithm, compressed, 0)
+
+			s.Require().NoError(err)
+			s.Equal(s.data, decompressed)
+		})
+	}
+}
+
+func (s *compressionSuite) Test_Decompress_TooLargeError() {
+	for _, algorithm := range []string{Snappy, Zstd} {
+		s.Run(algorithm, func() {
+			compressed, err := Compress(algorithm, s.data)
+			s.Require().NoError(err)
+
+			_, err = Decompress(algorithm, compressed, 100)
+
+			s.Equal(ErrTooLarge, err)
+		})
+	}
+}
+
+func (s *compressionSuite) Test_Decompress_CorruptError() {
+	for _, algorithm := range []string{Snappy, Zstd} {
+		s.Run(algorithm, func() {
+			_, err := Decompress(algorithm, []byte("corrupt"), 0)
+
+			s.Error(err)
+		})
+	}
+}
+
+func (s *compressionSuite) Test_UnknownAlgorithmError() {
+	_, err := Compress("gzip", s.data)
+	s.EqualError(err, "unknown compression algorithm gzip")
+
+	_, err = Decompress("gzip", s.data, 0)
+	s.EqualError(err, "unknown compression algorithm gzip")
+}
+
+func Test_CompressionSuite(t *testing.T) {
+	suite.Run(t, new(compressionSuite))
+}
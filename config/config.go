@@ -2,42 +2,137 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Configuration fields
 const (
-	auth         = "auth"
-	loggerLevel  = "logger.level"
-	loggerOutput = "logger.output"
+	auth                     = "auth"
+	loggerLevel              = "logger.level"
+	loggerOutput             = "logger.output"
+	loggerAccessLog          = "logger.access_log"
+	loggerAccessOutput       = "logger.access_output"
+	serverAddr               = "server.addr"
+	serverDeadline           = "server.deadline"
+	serverShutdownTimeout    = "server.shutdown_timeout"
+	serverIdleTimeout        = "server.idle_timeout"
+	serverReadTimeout        = "server.read_timeout"
+	serverWriteTimeout       = "server.write_timeout"
+	serverMaxRequestSize     = "server.max_request_size"
+	serverMaxPipelined       = "server.max_pipelined_requests"
+	serverCompressThreshold  = "server.compression_threshold"
+	httpAddr                 = "http.addr"
+	httpAllowedOrigins       = "http.allowed_origins"
+	grpcAddr                 = "grpc.addr"
+	metricsAddr              = "metrics.addr"
+	tracingExporter          = "tracing.exporter"
+	tracingEndpoint          = "tracing.endpoint"
+	tracingInsecure          = "tracing.insecure"
+	tracingSampleRatio       = "tracing.sample_ratio"
+	tracingServiceName       = "tracing.service_name"
+	healthTimeout            = "health.timeout"
+	healthMinFreeDisk        = "health.min_free_disk"
+	healthMaxGoroutines      = "health.max_goroutines"
+	limitsMaxConnections     = "limits.max_connections"
+	limitsMaxClientConns     = "limits.max_client_connections"
+	limitsRequestsPerSecond  = "limits.requests_per_second"
+	limitsRequestBurst       = "limits.request_burst"
+	limitsBytesPerSecond     = "limits.bytes_per_second"
+	limitsBytesBurst         = "limits.bytes_burst"
+	limitsMaxEventSize       = "limits.max_event_size"
+	storageDir               = "storage.dir"
+	storageInMemory          = "storage.in_memory"
+	storageSyncWrites        = "storage.sync_writes"
+	storageValueLogFileSize  = "storage.value_log_file_size"
+	storageCompression       = "storage.compression"
+	storageEncryptionKeyPath = "storage.encryption_key_path"
+	storageEncryptionKey     = "storage.encryption_key"
+	storageKeyRotation       = "storage.encryption_key_rotation"
+	backupDir                = "backup.dir"
 )
 
-// ClientCredentials represents the credentials every client has to make a request with
+const (
+	envPrefix                 = "event_bus"
+	minValueLogFileSize int64 = 1 << 20
+	maxValueLogFileSize int64 = 2 << 30
+)
+
+// Storage compression types
+const (
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+	CompressionZSTD   = "zstd"
+)
+
+var compressionTypes = []string{CompressionNone, CompressionSnappy, CompressionZSTD}
+
+// Tracing exporters
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+	TracingMemory = "memory"
+)
+
+var tracingExporters = []string{TracingNone, TracingStdout, TracingOTLP, TracingMemory}
+
+// ClientCredentials represents the credentials every client has to make a request with,
+// along with the quota of the events the client writes. Zero quotas mean unlimited
 type ClientCredentials struct {
 	ClientID     string `mapstructure:"client_id"`
 	ClientSecret string `mapstructure:"client_secret"`
+	MaxEventSize int64  `mapstructure:"max_event_size"`
+	MaxEvents    int64  `mapstructure:"max_events"`
+	MaxBytes     int64  `mapstructure:"max_bytes"`
+	// Admin allows the client to make the admin operations, such as managing the server connections
+	Admin bool `mapstructure:"admin"`
 }
 
 // ClientAuth represents all allowed client to make requests to Event Bus
 type ClientAuth map[string]ClientCredentials
 
-// NewManager creates a new configuration manager
+// Authenticate looks up the client the credentials belong to, giving back its name along with its credentials
+func (a ClientAuth) Authenticate(clientID, clientSecret string) (string, ClientCredentials, bool) {
+	for client, c := range a {
+		if c.ClientID == clientID && c.ClientSecret == clientSecret {
+			return client, c, true
+		}
+	}
+	return "", ClientCredentials{}, false
+}
+
+// NewManager creates a new configuration manager.
+// Every configuration field can be overridden with an environment variable
+// prefixed with EVENT_BUS_, e.g. EVENT_BUS_SERVER_ADDR overrides server.addr
 func NewManager(filename string) (*Manager, error) {
 	m := viper.New()
 	m.SetConfigFile(filename)
+	m.SetEnvPrefix(envPrefix)
+	m.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	m.AutomaticEnv()
 	err := m.ReadInConfig()
 	if err != nil {
 		return nil, err
 	}
 	manager := &Manager{
-		viper: m,
+		viper:    m,
+		filename: filename,
 	}
 	err = manager.loadClientAuth()
 	if err != nil {
 		return nil, err
 	}
 	manager.setDefaults()
+	err = manager.validate()
+	if err != nil {
+		return nil, err
+	}
 
 	return manager, nil
 }
@@ -45,9 +140,25 @@ func NewManager(filename string) (*Manager, error) {
 // Manager represents the configuration manager
 type Manager struct {
 	viper      *viper.Viper
+	filename   string
+	mu         sync.RWMutex
 	clientAuth ClientAuth
 }
 
+// ReloadAuth reads the configuration file again and replaces the clients allowed to make requests
+// along with their quotas. The whole configuration has to be valid, every other field keeps
+// the value it had on startup
+func (m *Manager) ReloadAuth() error {
+	reloaded, err := NewManager(m.filename)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clientAuth = reloaded.clientAuth
+	return nil
+}
+
 func (m *Manager) loadClientAuth() error {
 	err := m.viper.UnmarshalKey(auth, &m.clientAuth)
 	if err != nil {
@@ -56,12 +167,191 @@ func (m *Manager) loadClientAuth() error {
 	if len(m.clientAuth) == 0 {
 		return errors.New("'auth' field is required")
 	}
+	for client, c := range m.clientAuth {
+		quotas := map[string]int64{
+			"max_event_size": c.MaxEventSize,
+			"max_events":     c.MaxEvents,
+			"max_bytes":      c.MaxBytes,
+		}
+		for field, quota := range quotas {
+			if quota < 0 {
+				return fmt.Errorf("'%s.%s.%s' field must not be negative", auth, client, field)
+			}
+		}
+	}
 	return nil
 }
 
 func (m *Manager) setDefaults() {
 	m.viper.SetDefault(loggerLevel, "debug")
 	m.viper.SetDefault(loggerOutput, "stdout")
+	m.viper.SetDefault(loggerAccessLog, true)
+	m.viper.SetDefault(loggerAccessOutput, []string{})
+	m.viper.SetDefault(serverAddr, "localhost:8080")
+	m.viper.SetDefault(serverDeadline, 500*time.Millisecond)
+	m.viper.SetDefault(serverShutdownTimeout, 10*time.Second)
+	m.viper.SetDefault(serverIdleTimeout, 5*time.Minute)
+	m.viper.SetDefault(serverReadTimeout, 30*time.Second)
+	m.viper.SetDefault(serverWriteTimeout, 30*time.Second)
+	m.viper.SetDefault(serverMaxRequestSize, 1<<20)
+	m.viper.SetDefault(serverMaxPipelined, 16)
+	m.viper.SetDefault(serverCompressThreshold, 1024)
+	m.viper.SetDefault(httpAddr, "")
+	m.viper.SetDefault(httpAllowedOrigins, []string{})
+	m.viper.SetDefault(grpcAddr, "")
+	m.viper.SetDefault(metricsAddr, "")
+	m.viper.SetDefault(tracingExporter, TracingNone)
+	m.viper.SetDefault(tracingEndpoint, "localhost:4317")
+	m.viper.SetDefault(tracingInsecure, false)
+	m.viper.SetDefault(tracingSampleRatio, 1)
+	m.viper.SetDefault(tracingServiceName, "event-bus")
+	m.viper.SetDefault(healthTimeout, 2*time.Second)
+	m.viper.SetDefault(healthMinFreeDisk, 100<<20)
+	m.viper.SetDefault(healthMaxGoroutines, 0)
+	m.viper.SetDefault(limitsMaxConnections, 0)
+	m.viper.SetDefault(limitsMaxClientConns, 0)
+	m.viper.SetDefault(limitsRequestsPerSecond, 0)
+	m.viper.SetDefault(limitsRequestBurst, 50)
+	m.viper.SetDefault(limitsBytesPerSecond, 0)
+	m.viper.SetDefault(limitsBytesBurst, 1<<20)
+	m.viper.SetDefault(limitsMaxEventSize, 0)
+	m.viper.SetDefault(storageDir, "badger")
+	m.viper.SetDefault(storageInMemory, false)
+	m.viper.SetDefault(storageSyncWrites, true)
+	m.viper.SetDefault(storageValueLogFileSize, 1<<30-1)
+	m.viper.SetDefault(storageCompression, CompressionNone)
+	m.viper.SetDefault(storageEncryptionKeyPath, "")
+	m.viper.SetDefault(storageEncryptionKey, "")
+	m.viper.SetDefault(storageKeyRotation, 240*time.Hour)
+	m.viper.SetDefault(backupDir, "backups")
+}
+
+func (m *Manager) validate() error {
+	if m.GetServerAddr() == "" {
+		return fmt.Errorf("'%s' field is required", serverAddr)
+	}
+	if m.GetServerDeadline() <= 0 {
+		return fmt.Errorf("'%s' field must be a positive duration", serverDeadline)
+	}
+	if m.GetServerShutdownTimeout() <= 0 {
+		return fmt.Errorf("'%s' field must be a positive duration", serverShutdownTimeout)
+	}
+	timeouts := map[string]time.Duration{
+		serverIdleTimeout:  m.GetServerIdleTimeout(),
+		serverReadTimeout:  m.GetServerReadTimeout(),
+		serverWriteTimeout: m.GetServerWriteTimeout(),
+	}
+	for field, timeout := range timeouts {
+		if timeout < 0 {
+			return fmt.Errorf("'%s' field must not be negative", field)
+		}
+	}
+	if m.GetServerMaxRequestSize() <= 0 {
+		return fmt.Errorf("'%s' field must be positive", serverMaxRequestSize)
+	}
+	if m.GetServerMaxPipelinedRequests() <= 0 {
+		return fmt.Errorf("'%s' field must be positive", serverMaxPipelined)
+	}
+	if m.GetServerCompressionThreshold() < 0 {
+		return fmt.Errorf("'%s' field must not be negative", serverCompressThreshold)
+	}
+	err := m.validateLimits()
+	if err != nil {
+		return err
+	}
+	err = m.validateHealth()
+	if err != nil {
+		return err
+	}
+	err = m.validateTracing()
+	if err != nil {
+		return err
+	}
+	if !m.GetStorageInMemory() && m.GetStorageDir() == "" {
+		return fmt.Errorf("'%s' field is required unless '%s' is enabled", storageDir, storageInMemory)
+	}
+	size := m.GetStorageValueLogFileSize()
+	if size < minValueLogFileSize || size >= maxValueLogFileSize {
+		return fmt.Errorf(
+			"'%s' field must be in range [%d, %d)",
+			storageValueLogFileSize,
+			minValueLogFileSize,
+			maxValueLogFileSize,
+		)
+	}
+	if !contains(compressionTypes, m.GetStorageCompression()) {
+		return fmt.Errorf(
+			"'%s' field must be one of: '%s'",
+			storageCompression,
+			strings.Join(compressionTypes, "', '"),
+		)
+	}
+	keyPath := m.GetStorageEncryptionKeyPath()
+	if keyPath != "" {
+		if _, err := os.Stat(keyPath); err != nil {
+			return fmt.Errorf("'%s' field is invalid: %s", storageEncryptionKeyPath, err)
+		}
+	}
+	if keyPath != "" && m.GetStorageEncryptionKey() != "" {
+		return fmt.Errorf("only one of '%s' and '%s' fields can be set", storageEncryptionKeyPath, storageEncryptionKey)
+	}
+	if m.GetStorageEncryptionKeyRotation() <= 0 {
+		return fmt.Errorf("'%s' field must be a positive duration", storageKeyRotation)
+	}
+	return nil
+}
+
+func (m *Manager) validateTracing() error {
+	if !contains(tracingExporters, m.GetTracingExporter()) {
+		return fmt.Errorf(
+			"'%s' field must be one of: '%s'",
+			tracingExporter,
+			strings.Join(tracingExporters, "', '"),
+		)
+	}
+	if m.GetTracingExporter() == TracingOTLP && m.GetTracingEndpoint() == "" {
+		return fmt.Errorf("'%s' field is required when '%s' is '%s'", tracingEndpoint, tracingExporter, TracingOTLP)
+	}
+	ratio := m.GetTracingSampleRatio()
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("'%s' field must be in range [0, 1]", tracingSampleRatio)
+	}
+	return nil
+}
+
+func (m *Manager) validateHealth() error {
+	if m.GetHealthTimeout() <= 0 {
+		return fmt.Errorf("'%s' field must be a positive duration", healthTimeout)
+	}
+	if m.GetHealthMinFreeDisk() < 0 {
+		return fmt.Errorf("'%s' field must not be negative", healthMinFreeDisk)
+	}
+	if m.GetHealthMaxGoroutines() < 0 {
+		return fmt.Errorf("'%s' field must not be negative", healthMaxGoroutines)
+	}
+	return nil
+}
+
+func (m *Manager) validateLimits() error {
+	limits := map[string]float64{
+		limitsMaxConnections:    float64(m.GetLimitsMaxConnections()),
+		limitsMaxClientConns:    float64(m.GetLimitsMaxClientConnections()),
+		limitsRequestsPerSecond: m.GetLimitsRequestsPerSecond(),
+		limitsBytesPerSecond:    m.GetLimitsBytesPerSecond(),
+		limitsMaxEventSize:      float64(m.GetLimitsMaxEventSize()),
+	}
+	for field, limit := range limits {
+		if limit < 0 {
+			return fmt.Errorf("'%s' field must not be negative", field)
+		}
+	}
+	if m.GetLimitsRequestsPerSecond() > 0 && m.GetLimitsRequestBurst() <= 0 {
+		return fmt.Errorf("'%s' field must be positive", limitsRequestBurst)
+	}
+	if m.GetLimitsBytesPerSecond() > 0 && m.GetLimitsBytesBurst() < m.GetServerMaxRequestSize() {
+		return fmt.Errorf("'%s' field must be at least '%s'", limitsBytesBurst, serverMaxRequestSize)
+	}
+	return nil
 }
 
 // GetLoggerLevel gets logger atomic level level
@@ -74,7 +364,216 @@ func (m *Manager) GetLoggerOutput() []string {
 	return m.viper.GetStringSlice(loggerOutput)
 }
 
+// GetLoggerAccessLog tells whether every request is logged to the access log
+func (m *Manager) GetLoggerAccessLog() bool {
+	return m.viper.GetBool(loggerAccessLog)
+}
+
+// GetLoggerAccessOutput gets the access log output, empty means the logger output
+func (m *Manager) GetLoggerAccessOutput() []string {
+	return m.viper.GetStringSlice(loggerAccessOutput)
+}
+
 // GetAuth gets all allowed clients authentication details from config file
 func (m *Manager) GetAuth() ClientAuth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.clientAuth
 }
+
+// GetServerAddr gets the TCP address the Event Bus server listens on
+func (m *Manager) GetServerAddr() string {
+	return m.viper.GetString(serverAddr)
+}
+
+// GetServerDeadline gets the server listener accept deadline
+func (m *Manager) GetServerDeadline() time.Duration {
+	return m.viper.GetDuration(serverDeadline)
+}
+
+// GetServerShutdownTimeout gets how long the server waits for in-flight requests when shutting down
+func (m *Manager) GetServerShutdownTimeout() time.Duration {
+	return m.viper.GetDuration(serverShutdownTimeout)
+}
+
+// GetServerIdleTimeout gets how long a connection may stay idle between requests, zero means forever
+func (m *Manager) GetServerIdleTimeout() time.Duration {
+	return m.viper.GetDuration(serverIdleTimeout)
+}
+
+// GetServerReadTimeout gets how long the server waits for a started request to be fully read, zero means forever
+func (m *Manager) GetServerReadTimeout() time.Duration {
+	return m.viper.GetDuration(serverReadTimeout)
+}
+
+// GetServerWriteTimeout gets how long the server waits for a response to be written, zero means forever
+func (m *Manager) GetServerWriteTimeout() time.Duration {
+	return m.viper.GetDuration(serverWriteTimeout)
+}
+
+// GetServerMaxRequestSize gets the maximum size in bytes of a single request
+func (m *Manager) GetServerMaxRequestSize() int {
+	return m.viper.GetInt(serverMaxRequestSize)
+}
+
+// GetServerMaxPipelinedRequests gets the maximum number of requests processed concurrently per connection
+func (m *Manager) GetServerMaxPipelinedRequests() int {
+	return m.viper.GetInt(serverMaxPipelined)
+}
+
+// GetServerCompressionThreshold gets the size starting from which the responses are compressed
+// on the connections which negotiated a compression algorithm
+func (m *Manager) GetServerCompressionThreshold() int {
+	return m.viper.GetInt(serverCompressThreshold)
+}
+
+// GetHTTPAddr gets the address of the HTTP gateway, empty means the gateway is disabled
+func (m *Manager) GetHTTPAddr() string {
+	return m.viper.GetString(httpAddr)
+}
+
+// GetHTTPAllowedOrigins gets the origins allowed to open WebSocket connections besides the gateway own origin
+func (m *Manager) GetHTTPAllowedOrigins() []string {
+	return m.viper.GetStringSlice(httpAllowedOrigins)
+}
+
+// GetGRPCAddr gets the address of the gRPC server, empty means the gRPC server is disabled
+func (m *Manager) GetGRPCAddr() string {
+	return m.viper.GetString(grpcAddr)
+}
+
+// GetMetricsAddr gets the address of the Prometheus metrics endpoint, empty means the endpoint is disabled
+func (m *Manager) GetMetricsAddr() string {
+	return m.viper.GetString(metricsAddr)
+}
+
+// GetTracingExporter gets the exporter the trace spans are sent with, none means spans are not recorded
+func (m *Manager) GetTracingExporter() string {
+	return m.viper.GetString(tracingExporter)
+}
+
+// GetTracingEndpoint gets the address of the OTLP collector the spans are sent to
+func (m *Manager) GetTracingEndpoint() string {
+	return m.viper.GetString(tracingEndpoint)
+}
+
+// GetTracingInsecure tells whether TLS is disabled towards the OTLP collector
+func (m *Manager) GetTracingInsecure() bool {
+	return m.viper.GetBool(tracingInsecure)
+}
+
+// GetTracingSampleRatio gets the ratio of the traces started by the Event Bus which are sampled
+func (m *Manager) GetTracingSampleRatio() float64 {
+	return m.viper.GetFloat64(tracingSampleRatio)
+}
+
+// GetTracingServiceName gets the service name the spans are reported under
+func (m *Manager) GetTracingServiceName() string {
+	return m.viper.GetString(tracingServiceName)
+}
+
+// GetHealthTimeout gets how long every deep health check is given before being reported down
+func (m *Manager) GetHealthTimeout() time.Duration {
+	return m.viper.GetDuration(healthTimeout)
+}
+
+// GetHealthMinFreeDisk gets the free bytes the storage disk must have left to be healthy, zero means any
+func (m *Manager) GetHealthMinFreeDisk() int64 {
+	return m.viper.GetInt64(healthMinFreeDisk)
+}
+
+// GetHealthMaxGoroutines gets the number of goroutines above which the Event Bus is unhealthy, zero means unlimited
+func (m *Manager) GetHealthMaxGoroutines() int {
+	return m.viper.GetInt(healthMaxGoroutines)
+}
+
+// GetLimitsMaxConnections gets the maximum number of connections the server accepts, zero means unlimited
+func (m *Manager) GetLimitsMaxConnections() int {
+	return m.viper.GetInt(limitsMaxConnections)
+}
+
+// GetLimitsMaxClientConnections gets the maximum number of connections per authenticated client, zero means unlimited
+func (m *Manager) GetLimitsMaxClientConnections() int {
+	return m.viper.GetInt(limitsMaxClientConns)
+}
+
+// GetLimitsRequestsPerSecond gets the per client request rate limit, zero means unlimited
+func (m *Manager) GetLimitsRequestsPerSecond() float64 {
+	return m.viper.GetFloat64(limitsRequestsPerSecond)
+}
+
+// GetLimitsRequestBurst gets how many requests a client can make at once above its request rate
+func (m *Manager) GetLimitsRequestBurst() int {
+	return m.viper.GetInt(limitsRequestBurst)
+}
+
+// GetLimitsBytesPerSecond gets the per client request bytes rate limit, zero means unlimited
+func (m *Manager) GetLimitsBytesPerSecond() float64 {
+	return m.viper.GetFloat64(limitsBytesPerSecond)
+}
+
+// GetLimitsBytesBurst gets how many request bytes a client can send at once above its bytes rate
+func (m *Manager) GetLimitsBytesBurst() int {
+	return m.viper.GetInt(limitsBytesBurst)
+}
+
+// GetLimitsMaxEventSize gets the maximum size in bytes of any event payload, zero means unlimited
+func (m *Manager) GetLimitsMaxEventSize() int64 {
+	return m.viper.GetInt64(limitsMaxEventSize)
+}
+
+// GetStorageDir gets the Badger data directory
+func (m *Manager) GetStorageDir() string {
+	return m.viper.GetString(storageDir)
+}
+
+// GetStorageInMemory tells whether Badger should run entirely in memory
+func (m *Manager) GetStorageInMemory() bool {
+	return m.viper.GetBool(storageInMemory)
+}
+
+// GetStorageSyncWrites tells whether Badger should sync every write to disk
+func (m *Manager) GetStorageSyncWrites() bool {
+	return m.viper.GetBool(storageSyncWrites)
+}
+
+// GetStorageValueLogFileSize gets the maximum size in bytes of a single Badger value log file
+func (m *Manager) GetStorageValueLogFileSize() int64 {
+	return m.viper.GetInt64(storageValueLogFileSize)
+}
+
+// GetStorageCompression gets the Badger block compression type
+func (m *Manager) GetStorageCompression() string {
+	return m.viper.GetString(storageCompression)
+}
+
+// GetStorageEncryptionKeyPath gets the path of the file holding the Badger encryption key
+func (m *Manager) GetStorageEncryptionKeyPath() string {
+	return m.viper.GetString(storageEncryptionKeyPath)
+}
+
+// GetStorageEncryptionKey gets the Badger encryption key.
+// Meant to be provided through the EVENT_BUS_STORAGE_ENCRYPTION_KEY environment variable
+func (m *Manager) GetStorageEncryptionKey() string {
+	return m.viper.GetString(storageEncryptionKey)
+}
+
+// GetStorageEncryptionKeyRotation gets the duration after which Badger rotates its data keys
+func (m *Manager) GetStorageEncryptionKeyRotation() time.Duration {
+	return m.viper.GetDuration(storageKeyRotation)
+}
+
+// GetBackupDir gets the directory the backup and export files are written to and read from on the server,
+// empty disables them
+func (m *Manager) GetBackupDir() string {
+	return m.viper.GetString(backupDir)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
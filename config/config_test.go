@@ -1,8 +1,10 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/suite"
@@ -70,6 +72,52 @@ func (s *configSuite) Test_NewManager_LoadAuth_EmptyError() {
 	s.Require().NoError(os.Remove(tmpFileName))
 }
 
+func (s *configSuite) Test_NewManager_LoadAuth_NegativeQuotaError() {
+	tmpFileName := "tmp_cfg.yaml"
+	file, err := os.Create(tmpFileName)
+	s.Require().NoError(err)
+	_, err = file.Write([]byte("auth:\n  client1:\n    client_id: \"id\"\n    max_bytes: -1\n"))
+	s.Require().NoError(err)
+
+	m, err := NewManager(tmpFileName)
+
+	s.EqualError(err, "'auth.client1.max_bytes' field must not be negative")
+	s.Nil(m)
+	s.Require().NoError(os.Remove(tmpFileName))
+}
+
+func (s *configSuite) Test_Manager_ReloadAuth() {
+	tmpFileName := "tmp_cfg.yaml"
+	defer func() {
+		s.Require().NoError(os.Remove(tmpFileName))
+	}()
+	s.Require().NoError(ioutil.WriteFile(tmpFileName, []byte("auth:\n  client1:\n    client_id: \"id1\"\n"), 0644))
+	m, err := NewManager(tmpFileName)
+	s.Require().NoError(err)
+	s.Require().NoError(ioutil.WriteFile(tmpFileName, []byte("auth:\n  client2:\n    client_id: \"id2\"\n    max_events: 10\n"), 0644))
+
+	err = m.ReloadAuth()
+
+	s.Require().NoError(err)
+	s.Equal(ClientAuth{"client2": {ClientID: "id2", MaxEvents: 10}}, m.GetAuth())
+}
+
+func (s *configSuite) Test_Manager_ReloadAuth_Error() {
+	tmpFileName := "tmp_cfg.yaml"
+	defer func() {
+		s.Require().NoError(os.Remove(tmpFileName))
+	}()
+	s.Require().NoError(ioutil.WriteFile(tmpFileName, []byte("auth:\n  client1:\n    client_id: \"id1\"\n"), 0644))
+	m, err := NewManager(tmpFileName)
+	s.Require().NoError(err)
+	s.Require().NoError(ioutil.WriteFile(tmpFileName, []byte("auth:\n"), 0644))
+
+	err = m.ReloadAuth()
+
+	s.EqualError(err, "'auth' field is required")
+	s.Equal(ClientAuth{"client1": {ClientID: "id1"}}, m.GetAuth())
+}
+
 func (s *configSuite) Test_Manager_setDefaults() {
 	m := &Manager{viper: viper.New()}
 	m.setDefaults()
@@ -80,6 +128,45 @@ func (s *configSuite) Test_Manager_setDefaults() {
 	}{
 		{name: "GetLoggerLevel", actual: m.GetLoggerLevel(), expected: "debug"},
 		{name: "GetLoggerOutput", actual: m.GetLoggerOutput(), expected: []string{"stdout"}},
+		{name: "GetLoggerAccessLog", actual: m.GetLoggerAccessLog(), expected: true},
+		{name: "GetLoggerAccessOutput", actual: m.GetLoggerAccessOutput(), expected: []string{}},
+		{name: "GetServerAddr", actual: m.GetServerAddr(), expected: "localhost:8080"},
+		{name: "GetServerDeadline", actual: m.GetServerDeadline(), expected: 500 * time.Millisecond},
+		{name: "GetServerShutdownTimeout", actual: m.GetServerShutdownTimeout(), expected: 10 * time.Second},
+		{name: "GetServerIdleTimeout", actual: m.GetServerIdleTimeout(), expected: 5 * time.Minute},
+		{name: "GetServerReadTimeout", actual: m.GetServerReadTimeout(), expected: 30 * time.Second},
+		{name: "GetServerWriteTimeout", actual: m.GetServerWriteTimeout(), expected: 30 * time.Second},
+		{name: "GetServerMaxRequestSize", actual: m.GetServerMaxRequestSize(), expected: 1 << 20},
+		{name: "GetServerMaxPipelinedRequests", actual: m.GetServerMaxPipelinedRequests(), expected: 16},
+		{name: "GetServerCompressionThreshold", actual: m.GetServerCompressionThreshold(), expected: 1024},
+		{name: "GetHTTPAddr", actual: m.GetHTTPAddr(), expected: ""},
+		{name: "GetHTTPAllowedOrigins", actual: m.GetHTTPAllowedOrigins(), expected: []string{}},
+		{name: "GetGRPCAddr", actual: m.GetGRPCAddr(), expected: ""},
+		{name: "GetMetricsAddr", actual: m.GetMetricsAddr(), expected: ""},
+		{name: "GetTracingExporter", actual: m.GetTracingExporter(), expected: "none"},
+		{name: "GetTracingEndpoint", actual: m.GetTracingEndpoint(), expected: "localhost:4317"},
+		{name: "GetTracingInsecure", actual: m.GetTracingInsecure(), expected: false},
+		{name: "GetTracingSampleRatio", actual: m.GetTracingSampleRatio(), expected: float64(1)},
+		{name: "GetTracingServiceName", actual: m.GetTracingServiceName(), expected: "event-bus"},
+		{name: "GetHealthTimeout", actual: m.GetHealthTimeout(), expected: 2 * time.Second},
+		{name: "GetHealthMinFreeDisk", actual: m.GetHealthMinFreeDisk(), expected: int64(100 << 20)},
+		{name: "GetHealthMaxGoroutines", actual: m.GetHealthMaxGoroutines(), expected: 0},
+		{name: "GetLimitsMaxConnections", actual: m.GetLimitsMaxConnections(), expected: 0},
+		{name: "GetLimitsMaxClientConnections", actual: m.GetLimitsMaxClientConnections(), expected: 0},
+		{name: "GetLimitsRequestsPerSecond", actual: m.GetLimitsRequestsPerSecond(), expected: float64(0)},
+		{name: "GetLimitsRequestBurst", actual: m.GetLimitsRequestBurst(), expected: 50},
+		{name: "GetLimitsBytesPerSecond", actual: m.GetLimitsBytesPerSecond(), expected: float64(0)},
+		{name: "GetLimitsBytesBurst", actual: m.GetLimitsBytesBurst(), expected: 1 << 20},
+		{name: "GetLimitsMaxEventSize", actual: m.GetLimitsMaxEventSize(), expected: int64(0)},
+		{name: "GetStorageDir", actual: m.GetStorageDir(), expected: "badger"},
+		{name: "GetStorageInMemory", actual: m.GetStorageInMemory(), expected: false},
+		{name: "GetStorageSyncWrites", actual: m.GetStorageSyncWrites(), expected: true},
+		{name: "GetStorageValueLogFileSize", actual: m.GetStorageValueLogFileSize(), expected: int64(1<<30 - 1)},
+		{name: "GetStorageCompression", actual: m.GetStorageCompression(), expected: "none"},
+		{name: "GetStorageEncryptionKeyPath", actual: m.GetStorageEncryptionKeyPath(), expected: ""},
+		{name: "GetStorageEncryptionKey", actual: m.GetStorageEncryptionKey(), expected: ""},
+		{name: "GetStorageEncryptionKeyRotation", actual: m.GetStorageEncryptionKeyRotation(), expected: 240 * time.Hour},
+		{name: "GetBackupDir", actual: m.GetBackupDir(), expected: "backups"},
 	}
 
 	for _, testCase := range testCases {
@@ -101,15 +188,244 @@ func (s *configSuite) Test_GetLoggerOutput() {
 	s.Equal([]string{"stdout", "app.log"}, out)
 }
 
+func (s *configSuite) Test_GetLoggerAccessLog() {
+	s.False(s.manager.GetLoggerAccessLog())
+	s.Equal([]string{"access.log"}, s.manager.GetLoggerAccessOutput())
+}
+
+func (s *configSuite) Test_NewManager_ValidationError() {
+	testCases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "EmptyServerAddr",
+			config: "server:\n  addr: \"\"\n",
+			err:    "'server.addr' field is required",
+		},
+		{
+			name:   "NegativeServerDeadline",
+			config: "server:\n  deadline: -1s\n",
+			err:    "'server.deadline' field must be a positive duration",
+		},
+		{
+			name:   "NegativeServerShutdownTimeout",
+			config: "server:\n  shutdown_timeout: -5s\n",
+			err:    "'server.shutdown_timeout' field must be a positive duration",
+		},
+		{
+			name:   "NegativeServerIdleTimeout",
+			config: "server:\n  idle_timeout: -1m\n",
+			err:    "'server.idle_timeout' field must not be negative",
+		},
+		{
+			name:   "NegativeServerWriteTimeout",
+			config: "server:\n  write_timeout: -1s\n",
+			err:    "'server.write_timeout' field must not be negative",
+		},
+		{
+			name:   "ZeroServerMaxRequestSize",
+			config: "server:\n  max_request_size: 0\n",
+			err:    "'server.max_request_size' field must be positive",
+		},
+		{
+			name:   "ZeroServerMaxPipelinedRequests",
+			config: "server:\n  max_pipelined_requests: 0\n",
+			err:    "'server.max_pipelined_requests' field must be positive",
+		},
+		{
+			name:   "NegativeServerCompressionThreshold",
+			config: "server:\n  compression_threshold: -1\n",
+			err:    "'server.compression_threshold' field must not be negative",
+		},
+		{
+			name:   "ZeroHealthTimeout",
+			config: "health:\n  timeout: 0s\n",
+			err:    "'health.timeout' field must be a positive duration",
+		},
+		{
+			name:   "NegativeHealthMinFreeDisk",
+			config: "health:\n  min_free_disk: -1\n",
+			err:    "'health.min_free_disk' field must not be negative",
+		},
+		{
+			name:   "NegativeHealthMaxGoroutines",
+			config: "health:\n  max_goroutines: -1\n",
+			err:    "'health.max_goroutines' field must not be negative",
+		},
+		{
+			name:   "NegativeMaxConnections",
+			config: "limits:\n  max_connections: -1\n",
+			err:    "'limits.max_connections' field must not be negative",
+		},
+		{
+			name:   "NegativeMaxEventSize",
+			config: "limits:\n  max_event_size: -1\n",
+			err:    "'limits.max_event_size' field must not be negative",
+		},
+		{
+			name:   "ZeroRequestBurst",
+			config: "limits:\n  requests_per_second: 10\n  request_burst: 0\n",
+			err:    "'limits.request_burst' field must be positive",
+		},
+		{
+			name:   "BytesBurstBelowMaxRequestSize",
+			config: "limits:\n  bytes_per_second: 1024\n  bytes_burst: 1024\n",
+			err:    "'limits.bytes_burst' field must be at least 'server.max_request_size'",
+		},
+		{
+			name:   "UnknownTracingExporter",
+			config: "tracing:\n  exporter: jaeger\n",
+			err:    "'tracing.exporter' field must be one of: 'none', 'stdout', 'otlp', 'memory'",
+		},
+		{
+			name:   "EmptyTracingEndpoint",
+			config: "tracing:\n  exporter: otlp\n  endpoint: \"\"\n",
+			err:    "'tracing.endpoint' field is required when 'tracing.exporter' is 'otlp'",
+		},
+		{
+			name:   "TracingSampleRatioOutOfRange",
+			config: "tracing:\n  sample_ratio: 1.5\n",
+			err:    "'tracing.sample_ratio' field must be in range [0, 1]",
+		},
+		{
+			name:   "EmptyStorageDir",
+			config: "storage:\n  dir: \"\"\n",
+			err:    "'storage.dir' field is required unless 'storage.in_memory' is enabled",
+		},
+		{
+			name:   "ValueLogFileSizeTooSmall",
+			config: "storage:\n  value_log_file_size: 1024\n",
+			err:    "'storage.value_log_file_size' field must be in range [1048576, 2147483648)",
+		},
+		{
+			name:   "UnknownCompression",
+			config: "storage:\n  compression: lz4\n",
+			err:    "'storage.compression' field must be one of: 'none', 'snappy', 'zstd'",
+		},
+		{
+			name:   "EncryptionKeyNotFound",
+			config: "storage:\n  encryption_key_path: does_not_exist.key\n",
+			err:    "'storage.encryption_key_path' field is invalid: stat does_not_exist.key: no such file or directory",
+		},
+		{
+			name:   "EncryptionKeyAndPath",
+			config: "storage:\n  encryption_key_path: config_test.yaml\n  encryption_key: some-key\n",
+			err:    "only one of 'storage.encryption_key_path' and 'storage.encryption_key' fields can be set",
+		},
+		{
+			name:   "NegativeEncryptionKeyRotation",
+			config: "storage:\n  encryption_key_rotation: 0s\n",
+			err:    "'storage.encryption_key_rotation' field must be a positive duration",
+		},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			tmpFileName := s.writeTmpConfig(testCase.config)
+			defer os.Remove(tmpFileName)
+
+			m, err := NewManager(tmpFileName)
+
+			s.EqualError(err, testCase.err)
+			s.Nil(m)
+		})
+	}
+}
+
+func (s *configSuite) Test_NewManager_EnvOverrides() {
+	s.Require().NoError(os.Setenv("EVENT_BUS_SERVER_ADDR", "0.0.0.0:7000"))
+	s.Require().NoError(os.Setenv("EVENT_BUS_STORAGE_COMPRESSION", "snappy"))
+	s.Require().NoError(os.Setenv("EVENT_BUS_STORAGE_ENCRYPTION_KEY", "0123456789abcdef"))
+	defer os.Unsetenv("EVENT_BUS_SERVER_ADDR")
+	defer os.Unsetenv("EVENT_BUS_STORAGE_COMPRESSION")
+	defer os.Unsetenv("EVENT_BUS_STORAGE_ENCRYPTION_KEY")
+
+	m, err := NewManager(testCfgFile)
+
+	s.Require().NoError(err)
+	s.Equal("0.0.0.0:7000", m.GetServerAddr())
+	s.Equal("snappy", m.GetStorageCompression())
+	s.Equal("0123456789abcdef", m.GetStorageEncryptionKey())
+}
+
+func (s *configSuite) Test_GetServer() {
+	s.Equal("localhost:9999", s.manager.GetServerAddr())
+	s.Equal(200*time.Millisecond, s.manager.GetServerDeadline())
+	s.Equal(3*time.Second, s.manager.GetServerShutdownTimeout())
+	s.Equal(time.Minute, s.manager.GetServerIdleTimeout())
+	s.Equal(5*time.Second, s.manager.GetServerReadTimeout())
+	s.Equal(time.Duration(0), s.manager.GetServerWriteTimeout())
+	s.Equal(4096, s.manager.GetServerMaxRequestSize())
+	s.Equal(4, s.manager.GetServerMaxPipelinedRequests())
+	s.Equal(512, s.manager.GetServerCompressionThreshold())
+}
+
+func (s *configSuite) Test_GetHTTP() {
+	s.Equal("localhost:9998", s.manager.GetHTTPAddr())
+	s.Equal([]string{"http://localhost:3000"}, s.manager.GetHTTPAllowedOrigins())
+}
+
+func (s *configSuite) Test_GetGRPCAddr() {
+	s.Equal("localhost:9997", s.manager.GetGRPCAddr())
+}
+
+func (s *configSuite) Test_GetMetricsAddr() {
+	s.Equal("localhost:9996", s.manager.GetMetricsAddr())
+}
+
+func (s *configSuite) Test_GetTracing() {
+	s.Equal("otlp", s.manager.GetTracingExporter())
+	s.Equal("localhost:4318", s.manager.GetTracingEndpoint())
+	s.True(s.manager.GetTracingInsecure())
+	s.Equal(0.5, s.manager.GetTracingSampleRatio())
+	s.Equal("test-event-bus", s.manager.GetTracingServiceName())
+}
+
+func (s *configSuite) Test_GetHealth() {
+	s.Equal(time.Second, s.manager.GetHealthTimeout())
+	s.Equal(int64(1024), s.manager.GetHealthMinFreeDisk())
+	s.Equal(500, s.manager.GetHealthMaxGoroutines())
+}
+
+func (s *configSuite) Test_GetLimits() {
+	s.Equal(100, s.manager.GetLimitsMaxConnections())
+	s.Equal(10, s.manager.GetLimitsMaxClientConnections())
+	s.Equal(20.5, s.manager.GetLimitsRequestsPerSecond())
+	s.Equal(40, s.manager.GetLimitsRequestBurst())
+	s.Equal(float64(8192), s.manager.GetLimitsBytesPerSecond())
+	s.Equal(8192, s.manager.GetLimitsBytesBurst())
+	s.Equal(int64(65536), s.manager.GetLimitsMaxEventSize())
+}
+
+func (s *configSuite) Test_GetStorage() {
+	s.Equal("test_badger", s.manager.GetStorageDir())
+	s.True(s.manager.GetStorageInMemory())
+	s.False(s.manager.GetStorageSyncWrites())
+	s.Equal(int64(1048576), s.manager.GetStorageValueLogFileSize())
+	s.Equal("zstd", s.manager.GetStorageCompression())
+	s.Empty(s.manager.GetStorageEncryptionKeyPath())
+	s.Empty(s.manager.GetStorageEncryptionKey())
+	s.Equal(240*time.Hour, s.manager.GetStorageEncryptionKeyRotation())
+}
+
+func (s *configSuite) Test_GetBackupDir() {
+	s.Equal("test_backups", s.manager.GetBackupDir())
+}
+
 func (s *configSuite) Test_GetAuth() {
 	expected := ClientAuth{
 		"client1": ClientCredentials{
 			ClientID:     "client1_id",
 			ClientSecret: "client1_secret",
+			Admin:        true,
 		},
 		"client2": ClientCredentials{
 			ClientID:     "client2_id",
 			ClientSecret: "client2_secret",
+			MaxEvents:    1000,
+			MaxBytes:     1048576,
 		},
 	}
 
@@ -118,6 +434,30 @@ func (s *configSuite) Test_GetAuth() {
 	s.Equal(expected, clientAuth)
 }
 
+func (s *configSuite) Test_Authenticate() {
+	clientAuth := s.manager.GetAuth()
+
+	client, credentials, ok := clientAuth.Authenticate("client2_id", "client2_secret")
+	s.True(ok)
+	s.Equal("client2", client)
+	s.Equal(int64(1000), credentials.MaxEvents)
+
+	client, _, ok = clientAuth.Authenticate("client2_id", "client1_secret")
+	s.False(ok)
+	s.Empty(client)
+}
+
+func (s *configSuite) writeTmpConfig(content string) string {
+	tmpFileName := "tmp_cfg.yaml"
+	auth := "auth:\n  client1:\n    client_id: \"client1_id\"\n    client_secret: \"client1_secret\"\n"
+	file, err := os.Create(tmpFileName)
+	s.Require().NoError(err)
+	_, err = file.Write([]byte(auth + content))
+	s.Require().NoError(err)
+	s.Require().NoError(file.Close())
+	return tmpFileName
+}
+
 func Test_ConfigSuite(t *testing.T) {
 	suite.Run(t, new(configSuite))
 }
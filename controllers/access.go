@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/metrics"
+)
+
+// peerConn represents the connection a request came in on
+type peerConn interface {
+	ID() int
+	RemoteAddr() net.Addr
+}
+
+// accessEntry collects what the access log records about a single request.
+// Credentials are never part of it
+type accessEntry struct {
+	conn      peerConn
+	operation string
+	requestID string
+	client    string
+	stream    string
+	events    int
+	hasEvents bool
+	bytes     int64
+}
+
+func newAccessEntry(w io.Writer) *accessEntry {
+	entry := &accessEntry{operation: decodeOperation}
+	if conn, ok := w.(peerConn); ok {
+		entry.conn = conn
+	}
+	return entry
+}
+
+// setRequest records the decoded request, the stream being the one named in the request body, if any
+func (e *accessEntry) setRequest(req request) {
+	e.requestID = req.RequestID
+	var body struct {
+		StreamName string `json:"stream_name"`
+	}
+	if req.bodyCodec().Unmarshal(req.Body, &body) == nil {
+		e.stream = body.StreamName
+	}
+}
+
+// setEvents records the number of events written or returned
+func (e *accessEntry) setEvents(count int) {
+	if e == nil {
+		return
+	}
+	e.events = count
+	e.hasEvents = true
+}
+
+// log writes the entry to the access log and observes the request metrics
+func (e *accessEntry) log(err error, duration time.Duration) {
+	metrics.ObserveRequest(e.operation, err, duration)
+
+	fields := []zap.Field{
+		zap.String("operation", e.operation),
+		zap.Int64("bytes", e.bytes),
+		zap.Duration("duration", duration),
+	}
+	if e.conn != nil {
+		fields = append(fields, zap.Int("conn_id", e.conn.ID()))
+		if addr := e.conn.RemoteAddr(); addr != nil {
+			fields = append(fields, zap.String("remote_addr", addr.String()))
+		}
+	}
+	if e.requestID != "" {
+		fields = append(fields, zap.String("request_id", e.requestID))
+	}
+	if e.client != "" {
+		fields = append(fields, zap.String("client", e.client))
+	}
+	if e.stream != "" {
+		fields = append(fields, zap.String("stream", e.stream))
+	}
+	if e.hasEvents {
+		fields = append(fields, zap.Int("events", e.events))
+	}
+	if err != nil {
+		fields = append(fields, zap.String("result", metrics.StatusError), zap.String("error", err.Error()))
+	} else {
+		fields = append(fields, zap.String("result", metrics.StatusSuccess))
+	}
+	logging.AccessLogger.Info("request", fields...)
+}
+
+// countingReader counts the bytes read from the request
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(bs []byte) (int, error) {
+	n, err := r.Reader.Read(bs)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"net"
+
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/go-web-dev/event-bus/config"
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *controllersSuite) observeAccessLog() *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logging.AccessLogger = zap.New(core)
+	s.T().Cleanup(func() {
+		logging.AccessLogger = zap.NewNop()
+	})
+	return logs
+}
+
+func (s *controllersSuite) Test_Switch_AccessLog() {
+	logs := s.observeAccessLog()
+	conn := peerConnMock{
+		ReadWriter: s.rw,
+		id:         7,
+		addr:       &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4242},
+	}
+	req := `{"operation": "process_events", "request_id": "req-1", "body": {"stream_name": "s1"}, ` +
+		`"auth": {"client_id": "root_client_id", "client_secret": "root_client_secret"}}`
+	_, err := s.rw.Write([]byte(req))
+	s.Require().NoError(err)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ProcessEvents", mock.Anything, "s1", false).
+		Return([]models.Event{{ID: "evt1-id"}, {ID: "evt2-id"}}, nil).
+		Once()
+
+	_, err = s.router.Switch(conn, conn)
+
+	s.Require().NoError(err)
+	entries := logs.AllUntimed()
+	s.Require().Len(entries, 1)
+	s.Equal("request", entries[0].Message)
+	fields := entries[0].ContextMap()
+	s.Require().Contains(fields, "duration")
+	delete(fields, "duration")
+	s.Equal(map[string]interface{}{
+		"conn_id":     int64(7),
+		"remote_addr": "127.0.0.1:4242",
+		"operation":   "process_events",
+		"request_id":  "req-1",
+		"client":      "root",
+		"stream":      "s1",
+		"events":      int64(2),
+		"bytes":       int64(len(req)),
+		"result":      "success",
+	}, fields)
+	s.NotContains(s.JSONMarshal(fields), "root_client_secret")
+}
+
+func (s *controllersSuite) Test_Switch_AccessLog_Error() {
+	logs := s.observeAccessLog()
+	s.write("write_event", `{"stream_name": "s1", "event": {}}`)
+	s.cfg.
+		On("GetAuth").
+		Return(config.ClientAuth{}).
+		Once()
+
+	_, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.AuthError{}, err)
+	entries := logs.AllUntimed()
+	s.Require().Len(entries, 1)
+	fields := entries[0].ContextMap()
+	s.Equal("write_event", fields["operation"])
+	s.Equal("s1", fields["stream"])
+	s.Equal("error", fields["result"])
+	s.Equal(models.AuthError{}.Error(), fields["error"])
+	s.NotContains(fields, "client")
+	s.NotContains(fields, "events")
+	s.NotContains(fields, "conn_id")
+}
+
+func (s *controllersSuite) Test_Switch_AccessLog_UnknownOperation() {
+	logs := s.observeAccessLog()
+	s.write("abc", "")
+
+	_, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.OperationNotFoundError{}, err)
+	entries := logs.AllUntimed()
+	s.Require().Len(entries, 1)
+	s.Equal("decode_request", entries[0].ContextMap()["operation"])
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type backuper interface {
+	auditor
+	BackupFile(ctx context.Context, path string, since uint64) (uint64, error)
+	RestoreFile(ctx context.Context, path string, incremental bool) error
+}
+
+type backupRequest struct {
+	Path  string `json:"path" type:"string"`
+	Since uint64 `json:"since,omitempty" type:"int"`
+}
+
+type backupResponse struct {
+	Path    string `json:"path"`
+	Version uint64 `json:"version"`
+}
+
+type restoreRequest struct {
+	Path        string `json:"path" type:"string"`
+	Incremental bool   `json:"incremental,omitempty" type:"bool"`
+}
+
+// backup writes a backup of the store into a file on the server,
+// the response version being the one to back up since in the next incremental backup
+func (router Router) backup(bus backuper) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body backupRequest
+		err := parseReq(r, &body)
+		if err != nil {
+			transport.SendError(w, backupOperation, err)
+			return err
+		}
+		if body.Path == "" {
+			e := models.OperationRequestError{Body: transport.DecodeFields(body)}
+			transport.SendError(w, backupOperation, e)
+			return e
+		}
+
+		version, err := bus.BackupFile(r.ctx, body.Path, body.Since)
+		if err != nil {
+			transport.SendError(w, backupOperation, err)
+			return err
+		}
+		err = bus.Audit(r.ctx, models.NewBackupEntry(r.client, body.Path, body.Since, version))
+		if err != nil {
+			transport.SendError(w, backupOperation, err)
+			return err
+		}
+
+		res := backupResponse{
+			Path:    body.Path,
+			Version: version,
+		}
+		transport.SendJSON(w, backupOperation, res)
+		return nil
+	}
+}
+
+// restore loads a backup file on the server into the store, the audit entry being written once restored
+func (router Router) restore(bus backuper) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body restoreRequest
+		err := parseReq(r, &body)
+		if err != nil {
+			transport.SendError(w, restoreOperation, err)
+			return err
+		}
+		if body.Path == "" {
+			e := models.OperationRequestError{Body: transport.DecodeFields(body)}
+			transport.SendError(w, restoreOperation, e)
+			return e
+		}
+
+		err = bus.RestoreFile(r.ctx, body.Path, body.Incremental)
+		if err != nil {
+			transport.SendError(w, restoreOperation, err)
+			return err
+		}
+		err = bus.Audit(r.ctx, models.NewRestoreEntry(r.client, body.Path, body.Incremental))
+		if err != nil {
+			transport.SendError(w, restoreOperation, err)
+			return err
+		}
+
+		transport.SendJSON(w, restoreOperation, nil)
+		return nil
+	}
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_Backup_Success() {
+	s.admin()
+	expectedRes := transport.Response{
+		Operation: "backup",
+		Status:    true,
+		Body:      JSON{"path": "/backups/bus.bak", "version": float64(12)},
+	}
+	s.write("backup", `{"path": "/backups/bus.bak", "since": 5}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("BackupFile", mock.Anything, "/backups/bus.bak", uint64(5)).
+		Return(uint64(12), nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewBackupEntry("root", "/backups/bus.bak", 5, 12)).
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Backup_Error() {
+	s.admin()
+	s.write("backup", `{"path": "/backups/bus.bak"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("BackupFile", mock.Anything, "/backups/bus.bak", uint64(0)).
+		Return(uint64(0), models.Error{Message: "could not create backup file"}).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.Error{Message: "could not create backup file"}, err)
+	s.False(exited)
+	s.Equal("could not create backup file", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_Backup_MissingPathError() {
+	s.admin()
+	s.write("backup", `{"since": 3}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.IsType(models.OperationRequestError{}, err)
+	s.False(exited)
+	s.Equal("missing required fields", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_Backup_ForbiddenError() {
+	s.write("backup", `{"path": "/backups/bus.bak"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.ForbiddenError{}, err)
+	s.False(exited)
+	s.Equal("forbidden to make request", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_Backup_ParseReqError() {
+	s.admin()
+	s.testParseRequest("backup", `{"path": 1}`)
+}
+
+func (s *controllersSuite) Test_Restore_Success() {
+	s.admin()
+	expectedRes := transport.Response{
+		Operation: "restore",
+		Status:    true,
+	}
+	s.write("restore", `{"path": "/backups/bus.bak", "incremental": true}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("RestoreFile", mock.Anything, "/backups/bus.bak", true).
+		Return(nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewRestoreEntry("root", "/backups/bus.bak", true)).
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Restore_Error() {
+	s.admin()
+	s.write("restore", `{"path": "/backups/bus.bak"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("RestoreFile", mock.Anything, "/backups/bus.bak", false).
+		Return(models.Error{Message: "could not open backup file"}).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.Error{Message: "could not open backup file"}, err)
+	s.False(exited)
+	s.Equal("could not open backup file", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_Restore_MissingPathError() {
+	s.admin()
+	s.write("restore", `{}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.IsType(models.OperationRequestError{}, err)
+	s.False(exited)
+	s.Equal("missing required fields", s.read().Reason)
+}
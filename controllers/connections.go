@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+// ConnectionManager represents the registry of the connections admin clients list and disconnect
+type ConnectionManager interface {
+	List() []models.Connection
+	Disconnect(connID int) error
+	DisconnectClient(client string) []int
+}
+
+type auditor interface {
+	Audit(ctx context.Context, entry models.AuditEntry) error
+}
+
+type listConnectionsResponse struct {
+	Connections []models.Connection `json:"connections"`
+}
+
+type disconnectRequest struct {
+	ConnectionID *int   `json:"connection_id,omitempty" type:"int"`
+	Client       string `json:"client,omitempty" type:"string"`
+}
+
+type disconnectResponse struct {
+	Disconnected []int `json:"disconnected"`
+}
+
+// errNoConnections is returned by the connection operations of a router with no connections registry
+var errNoConnections = models.Error{Message: "connections are not managed by this server"}
+
+func (router Router) listConnections(conns ConnectionManager) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		if conns == nil {
+			transport.SendError(w, listConnectionsOperation, errNoConnections)
+			return errNoConnections
+		}
+
+		res := listConnectionsResponse{
+			Connections: conns.List(),
+		}
+		transport.SendJSON(w, listConnectionsOperation, res)
+		return nil
+	}
+}
+
+// disconnect closes either a single connection or every connection of a client,
+// the connections being notified with a disconnect notice first
+func (router Router) disconnect(bus auditor, conns ConnectionManager) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body disconnectRequest
+		err := parseReq(r, &body)
+		if err != nil {
+			transport.SendError(w, disconnectOperation, err)
+			return err
+		}
+		if body.ConnectionID == nil && body.Client == "" {
+			e := models.OperationRequestError{Body: transport.DecodeFields(body)}
+			transport.SendError(w, disconnectOperation, e)
+			return e
+		}
+		if body.ConnectionID != nil && body.Client != "" {
+			e := models.Error{Message: "only one of connection_id and client can be provided"}
+			transport.SendError(w, disconnectOperation, e)
+			return e
+		}
+		if conns == nil {
+			transport.SendError(w, disconnectOperation, errNoConnections)
+			return errNoConnections
+		}
+
+		var ids []int
+		if body.ConnectionID != nil {
+			err = conns.Disconnect(*body.ConnectionID)
+			if err != nil {
+				transport.SendError(w, disconnectOperation, err)
+				return err
+			}
+			ids = []int{*body.ConnectionID}
+		} else {
+			ids = conns.DisconnectClient(body.Client)
+		}
+
+		err = bus.Audit(r.ctx, models.NewDisconnectEntry(r.client, body.Client, ids))
+		if err != nil {
+			transport.SendError(w, disconnectOperation, err)
+			return err
+		}
+
+		res := disconnectResponse{
+			Disconnected: ids,
+		}
+		transport.SendJSON(w, disconnectOperation, res)
+		return nil
+	}
+}
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/config"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_ListConnections_Success() {
+	s.admin()
+	conn := models.Connection{
+		ID:            2,
+		Transport:     models.GRPCTransport,
+		RemoteAddr:    "127.0.0.1:54321",
+		Client:        "root",
+		ConnectedAt:   testTime,
+		Requests:      7,
+		Subscriptions: 1,
+	}
+	expectedRes := transport.Response{
+		Operation: "list_connections",
+		Status:    true,
+		Body: JSON{
+			"connections": []interface{}{
+				JSON{
+					"id":            float64(2),
+					"transport":     "grpc",
+					"remote_addr":   "127.0.0.1:54321",
+					"client":        "root",
+					"connected_at":  testTimeStr,
+					"requests":      float64(7),
+					"subscriptions": float64(1),
+				},
+			},
+		},
+	}
+	s.write("list_connections", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.conns.
+		On("List").
+		Return([]models.Connection{conn}).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_ListConnections_ForbiddenError() {
+	expectedRes := transport.Response{
+		Operation: "list_connections",
+		Status:    false,
+		Reason:    "forbidden to make request",
+	}
+	s.write("list_connections", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.ForbiddenError{}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_ListConnections_NoConnectionsError() {
+	s.admin()
+	router := NewRouter(s.bus, s.cfg, s.checker, nil)
+	s.write("list_connections", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := router.Switch(s.rw, s.rw)
+
+	s.Equal(errNoConnections, err)
+	s.False(exited)
+	s.Equal("connections are not managed by this server", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_Disconnect_Connection() {
+	s.admin()
+	expectedRes := transport.Response{
+		Operation: "disconnect",
+		Status:    true,
+		Body:      JSON{"disconnected": []interface{}{float64(3)}},
+	}
+	entry := models.AuditEntry{
+		Action:  models.AuditDisconnect,
+		Client:  "root",
+		Details: map[string]string{"connections": "3"},
+	}
+	s.write("disconnect", `{"connection_id": 3}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.conns.
+		On("Disconnect", 3).
+		Return(nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, entry).
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Disconnect_Client() {
+	s.admin()
+	entry := models.AuditEntry{
+		Action:  models.AuditDisconnect,
+		Client:  "root",
+		Details: map[string]string{"connections": "1,4", "client": "go-client"},
+	}
+	s.write("disconnect", `{"client": "go-client"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.conns.
+		On("DisconnectClient", "go-client").
+		Return([]int{1, 4}).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, entry).
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(JSON{"disconnected": []interface{}{float64(1), float64(4)}}, s.read().Body)
+}
+
+func (s *controllersSuite) Test_Disconnect_ConnectionNotFoundError() {
+	s.admin()
+	s.write("disconnect", `{"connection_id": 3}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.conns.
+		On("Disconnect", 3).
+		Return(models.ConnectionNotFoundError{ID: 3}).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.ConnectionNotFoundError{ID: 3}, err)
+	s.False(exited)
+	s.Equal("connection '3' not found", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_Disconnect_MissingFieldsError() {
+	s.admin()
+	s.write("disconnect", `{}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.IsType(models.OperationRequestError{}, err)
+	s.False(exited)
+	s.Equal("missing required fields", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_Disconnect_BothFieldsError() {
+	s.admin()
+	s.write("disconnect", `{"connection_id": 3, "client": "go-client"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Error(err)
+	s.False(exited)
+	s.Equal("only one of connection_id and client can be provided", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_Disconnect_ParseReqError() {
+	s.admin()
+	s.testParseRequest("disconnect", `{"connection_id": "3"}`)
+}
+
+func (s *controllersSuite) Test_Disconnect_AuditError() {
+	s.admin()
+	s.write("disconnect", `{"connection_id": 3}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.conns.
+		On("Disconnect", 3).
+		Return(nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, mock.Anything).
+		Return(errTest).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(errTest, err)
+	s.False(exited)
+	s.Equal(errTest.Error(), s.read().Reason)
+}
+
+// admin makes the root client an admin one
+func (s *controllersSuite) admin() {
+	s.auth = config.ClientAuth{
+		"root": config.ClientCredentials{
+			ClientID:     "root_client_id",
+			ClientSecret: "root_client_secret",
+			Admin:        true,
+		},
+	}
+}
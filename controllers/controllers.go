@@ -2,52 +2,101 @@ package controllers
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"go.uber.org/zap"
 	"io"
 	"reflect"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/go-web-dev/event-bus/config"
 	"github.com/go-web-dev/event-bus/logging"
 	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/tracing"
 	"github.com/go-web-dev/event-bus/transport"
 )
 
 // Operations
 const (
-	healthOperation          = "health"
-	createStreamOperation    = "create_stream"
-	deleteStreamOperation    = "delete_stream"
-	getStreamInfoOperation   = "get_stream_info"
-	getStreamEventsOperation = "get_stream_events"
-	writeEventOperation      = "write_event"
-	processEventsOperation   = "process_events"
-	retryEventsOperation     = "retry_events"
-	markEventOperation       = "mark_event"
-	exitOperation            = "exit"
-	decodeOperation          = "decode_request"
+	healthOperation            = "health"
+	helloOperation             = "hello"
+	createStreamOperation      = "create_stream"
+	deleteStreamOperation      = "delete_stream"
+	updateStreamOperation      = "update_stream"
+	getStreamInfoOperation     = "get_stream_info"
+	listStreamsOperation       = "list_streams"
+	getStreamEventsOperation   = "get_stream_events"
+	writeEventOperation        = "write_event"
+	processEventsOperation     = "process_events"
+	retryEventsOperation       = "retry_events"
+	markEventOperation         = "mark_event"
+	registerEventTypeOperation = "register_event_type"
+	listEventTypesOperation    = "list_event_types"
+	getEventTypeOperation      = "get_event_type"
+	getAuditLogOperation       = "get_audit_log"
+	listConnectionsOperation   = "list_connections"
+	disconnectOperation        = "disconnect"
+	backupOperation            = "backup"
+	restoreOperation           = "restore"
+	exportStreamOperation      = "export_stream"
+	importStreamOperation      = "import_stream"
+	subscribeOperation         = "subscribe"
+	exitOperation              = "exit"
+	decodeOperation            = "decode_request"
 )
 
+// adminOperations are the operations only the admin clients are allowed to make
+var adminOperations = map[string]bool{
+	listConnectionsOperation: true,
+	disconnectOperation:      true,
+	backupOperation:          true,
+	restoreOperation:         true,
+	exportStreamOperation:    true,
+	importStreamOperation:    true,
+	getAuditLogOperation:     true,
+}
+
 type auth struct {
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
 }
 
 type request struct {
-	Operation string          `json:"operation"`
-	Body      json.RawMessage `json:"body"`
-	Auth      auth            `json:"auth"`
+	Operation string               `json:"operation"`
+	Body      transport.RawMessage `json:"body"`
+	Auth      auth                 `json:"auth"`
+	RequestID string               `json:"request_id,omitempty"`
+	// TraceContext is the W3C trace context of the caller, the operation span continues its trace
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	// client is the name of the authenticated client making the request
+	client string
+	// ctx carries the operation span down to the Event Bus service
+	ctx context.Context
+	// access is the access log entry of the request
+	access *accessEntry
+	// codec is the wire encoding the request was read with, the body is decoded with it
+	codec transport.Codec
 }
 
 // EventBus represents the Event Bus operations
 type EventBus interface {
 	streamCreator
 	streamDeleter
+	streamUpdater
 	streamInfoGetter
+	streamsLister
 	streamEventsGetter
 	eventWriter
 	eventMarker
 	eventProcessor
+	eventTypeRegistrar
+	eventTypesLister
+	eventTypeGetter
+	auditLogGetter
+	backuper
+	streamExporter
 }
 
 // ConfigManager represents the application configuration manager
@@ -57,6 +106,11 @@ type ConfigManager interface {
 	GetLoggerOutput() []string
 }
 
+// clientConn represents a connection which enforces the limits of the client authenticated on it
+type clientConn interface {
+	Authorize(client string) error
+}
+
 type operator func(io.Writer, request) error
 
 // Router represents the Event Bus operation router switch
@@ -65,24 +119,36 @@ type Router struct {
 	cfg        ConfigManager
 }
 
-// NewRouter creates a new instance of Router switch operation
-func NewRouter(b EventBus, cfg ConfigManager) Router {
+// NewRouter creates a new instance of Router switch operation, deep health checks report no components without a checker.
+// The connection operations fail without a connections registry
+func NewRouter(b EventBus, cfg ConfigManager, checker HealthChecker, conns ConnectionManager) Router {
 	router := Router{
 		cfg: cfg,
 	}
 	router.operations = map[string]operator{
-		createStreamOperation:    router.createStream(b),
-		deleteStreamOperation:    router.deleteStream(b),
-		getStreamInfoOperation:   router.getStreamInfo(b),
-		getStreamEventsOperation: router.getStreamEvents(b),
-		writeEventOperation:      router.writeEvent(b),
-		markEventOperation:       router.markEvent(b),
-		processEventsOperation:   router.processEvents(b),
-		retryEventsOperation:     router.retryEvents(b),
-		healthOperation: func(w io.Writer, _ request) error {
-			transport.SendJSON(w, healthOperation, nil)
-			return nil
-		},
+		createStreamOperation:      router.createStream(b),
+		deleteStreamOperation:      router.deleteStream(b),
+		updateStreamOperation:      router.updateStream(b),
+		getStreamInfoOperation:     router.getStreamInfo(b),
+		listStreamsOperation:       router.listStreams(b),
+		getStreamEventsOperation:   router.getStreamEvents(b),
+		writeEventOperation:        router.writeEvent(b),
+		markEventOperation:         router.markEvent(b),
+		processEventsOperation:     router.processEvents(b),
+		retryEventsOperation:       router.retryEvents(b),
+		registerEventTypeOperation: router.registerEventType(b),
+		listEventTypesOperation:    router.listEventTypes(b),
+		getEventTypeOperation:      router.getEventType(b),
+		getAuditLogOperation:       router.getAuditLog(b),
+		listConnectionsOperation:   router.listConnections(conns),
+		disconnectOperation:        router.disconnect(b, conns),
+		backupOperation:            router.backup(b),
+		restoreOperation:           router.restore(b),
+		exportStreamOperation:      router.exportStream(b),
+		importStreamOperation:      router.importStream(b),
+		subscribeOperation:         router.subscribe,
+		helloOperation:             router.hello,
+		healthOperation:            router.health(checker),
 		exitOperation: func(w io.Writer, _ request) error {
 			transport.SendJSON(w, exitOperation, nil)
 			return nil
@@ -92,45 +158,88 @@ func NewRouter(b EventBus, cfg ConfigManager) Router {
 }
 
 // Switch represents the switch between Event Bus operations
-func (router Router) Switch(w io.Writer, r io.Reader) (bool, error) {
+func (router Router) Switch(w io.Writer, r io.Reader) (exited bool, err error) {
+	// unknown operations are logged and observed as decode errors to keep the operation label bounded
+	entry := newAccessEntry(w)
+	defer func(start time.Time) {
+		entry.log(err, time.Since(start))
+	}(time.Now())
+
 	var req request
-	err := transport.Decode(r, &req)
+	codec := transport.CodecOf(w)
+	body := &countingReader{Reader: r}
+	err = transport.Decode(body, codec, &req)
+	entry.bytes = body.n
 	if err != nil {
-		transport.SendError(w, decodeOperation, models.InvalidJSONError{})
+		transport.SendError(w, decodeOperation, decodeError(codec))
 		return false, models.Error{Message: err.Error()}
 	}
+	req.codec = codec
 
+	// every response echoes the request ID, the original writer is kept for the connection capabilities
+	rw := transport.WithRequestID(w, req.RequestID)
 	notFoundErr := models.OperationNotFoundError{}
 	operation, ok := router.operations[req.Operation]
 	if !ok {
-		transport.SendError(w, decodeOperation, notFoundErr)
+		transport.SendError(rw, decodeOperation, notFoundErr)
 		return false, notFoundErr
 	}
+	entry.operation = req.Operation
+	entry.setRequest(req)
+	req.access = entry
+
+	var span trace.Span
+	req.ctx, span = tracing.Start(
+		tracing.Extract(context.Background(), req.TraceContext),
+		req.Operation,
+		trace.SpanKindServer,
+		attribute.String("eventbus.operation", req.Operation),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+	if req.RequestID != "" {
+		span.SetAttributes(attribute.String("eventbus.request_id", req.RequestID))
+	}
 
-	if req.Operation == healthOperation {
-		return false, operation(w, req)
+	if req.Operation == healthOperation || req.Operation == helloOperation {
+		return false, operation(rw, req)
 	}
 	if req.Operation == exitOperation {
-		return true, operation(w, req)
+		return true, operation(rw, req)
 	}
 
-	if err := router.auth(req); err != nil {
-		transport.SendError(w, req.Operation, err)
+	client, credentials, err := router.auth(req)
+	if err != nil {
+		transport.SendError(rw, req.Operation, err)
+		return false, err
+	}
+	if adminOperations[req.Operation] && !credentials.Admin {
+		err = models.ForbiddenError{}
+		transport.SendError(rw, req.Operation, err)
 		return false, err
 	}
+	if conn, ok := w.(clientConn); ok {
+		err := conn.Authorize(client)
+		if err != nil {
+			transport.SendError(rw, req.Operation, err)
+			return false, err
+		}
+	}
 
-	return false, operation(w, req)
+	req.client = client
+	entry.client = client
+	span.SetAttributes(attribute.String("eventbus.client", client))
+	return false, operation(rw, req)
 }
 
-func (router Router) auth(r request) error {
-	logger := logging.Logger
-	for client, c := range router.cfg.GetAuth() {
-		if c.ClientID == r.Auth.ClientID && c.ClientSecret == r.Auth.ClientSecret {
-			logger.Debug("authorized client", zap.String("client", client))
-			return nil
-		}
+func (router Router) auth(r request) (string, config.ClientCredentials, error) {
+	client, c, ok := router.cfg.GetAuth().Authenticate(r.Auth.ClientID, r.Auth.ClientSecret)
+	if !ok {
+		return "", config.ClientCredentials{}, models.AuthError{}
 	}
-	return models.AuthError{}
+	logging.Logger.Debug("authorized client", zap.String("client", client))
+	return client, c, nil
 }
 
 func parseReq(r request, body interface{}) error {
@@ -141,10 +250,28 @@ func parseReq(r request, body interface{}) error {
 		}
 		return e
 	}
-	err := transport.Decode(bytes.NewReader(r.Body), body)
+	codec := r.bodyCodec()
+	err := transport.Decode(bytes.NewReader(r.Body), codec, body)
 	if err != nil {
 		logging.Logger.Debug("could not decode request body", zap.Error(err))
-		return models.InvalidJSONError{}
+		return decodeError(codec)
 	}
 	return nil
 }
+
+// bodyCodec gets the codec the request body is decoded with, JSON unless the request was read with another one
+func (r request) bodyCodec() transport.Codec {
+	if r.codec != nil {
+		return r.codec
+	}
+	codec, _ := transport.GetCodec(transport.JSONEncoding)
+	return codec
+}
+
+// decodeError is the error sent when a request cannot be decoded with the wire encoding it was read with
+func decodeError(codec transport.Codec) error {
+	if codec.Name() == transport.JSONEncoding {
+		return models.InvalidJSONError{}
+	}
+	return models.InvalidEncodingError{Encoding: codec.Name()}
+}
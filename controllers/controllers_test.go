@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,12 +9,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/go-web-dev/event-bus/config"
 	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/metrics"
 	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/testutils"
+	"github.com/go-web-dev/event-bus/tracing"
 	"github.com/go-web-dev/event-bus/transport"
 )
 
@@ -30,18 +37,22 @@ type JSON = map[string]interface{}
 
 type controllersSuite struct {
 	testutils.Suite
-	bus    *busMock
-	cfg    *cfgMock
-	rw     *testutils.ReadWriter
-	auth   config.ClientAuth
-	router Router
+	bus     *busMock
+	cfg     *cfgMock
+	checker *checkerMock
+	conns   *connsMock
+	rw      *testutils.ReadWriter
+	auth    config.ClientAuth
+	router  Router
 }
 
 func (s *controllersSuite) SetupSuite() {
 	logging.Logger = testutils.Logger(s.T(), nil)
 	s.bus = new(busMock)
 	s.cfg = new(cfgMock)
-	s.router = NewRouter(s.bus, s.cfg)
+	s.checker = new(checkerMock)
+	s.conns = new(connsMock)
+	s.router = NewRouter(s.bus, s.cfg, s.checker, s.conns)
 }
 
 func (s *controllersSuite) SetupTest() {
@@ -54,18 +65,22 @@ func (s *controllersSuite) SetupTest() {
 	}
 	s.bus.Test(s.T())
 	s.cfg.Test(s.T())
+	s.checker.Test(s.T())
+	s.conns.Test(s.T())
 }
 
 func (s *controllersSuite) TearDownTest() {
 	s.bus.AssertExpectations(s.T())
 	s.cfg.AssertExpectations(s.T())
+	s.checker.AssertExpectations(s.T())
+	s.conns.AssertExpectations(s.T())
 }
 
 func (s *controllersSuite) Test_NewRouter() {
-	router := NewRouter(s.bus, s.cfg)
+	router := NewRouter(s.bus, s.cfg, s.checker, s.conns)
 
 	s.Equal(s.cfg, router.cfg)
-	s.Len(router.operations, 10)
+	s.Len(router.operations, 24)
 }
 
 func (s *controllersSuite) Test_Switch_Health() {
@@ -96,6 +111,27 @@ func (s *controllersSuite) Test_Switch_Exit() {
 	s.Equal(expectedRes, s.read())
 }
 
+func (s *controllersSuite) Test_Switch_RequestID() {
+	expectedRes := transport.Response{
+		Operation: "get_stream_info",
+		Status:    false,
+		Reason:    "unauthorized to make request",
+		RequestID: "req-1",
+	}
+	_, err := s.rw.Write([]byte(`{"operation": "get_stream_info", "request_id": "req-1"}`))
+	s.Require().NoError(err)
+	s.cfg.
+		On("GetAuth").
+		Return(config.ClientAuth{}).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.AuthError{}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
 func (s *controllersSuite) Test_Switch_WrongCommandError() {
 	s.write("abc", "")
 
@@ -105,6 +141,59 @@ func (s *controllersSuite) Test_Switch_WrongCommandError() {
 	s.False(exited)
 }
 
+func (s *controllersSuite) Test_Switch_Metrics() {
+	healthCount := s.requestsCount("health", "success")
+	unknownCount := s.requestsCount("decode_request", "error")
+	s.write("health", "")
+	_, err := s.router.Switch(s.rw, s.rw)
+	s.Require().NoError(err)
+	s.read()
+	s.write("abc", "")
+	_, err = s.router.Switch(s.rw, s.rw)
+	s.Require().Error(err)
+
+	s.Equal(healthCount+1, s.requestsCount("health", "success"))
+	s.Equal(unknownCount+1, s.requestsCount("decode_request", "error"))
+}
+
+func (s *controllersSuite) Test_Switch_TraceContext() {
+	memory := tracetest.NewInMemoryExporter()
+	shutdown, err := tracing.Init(tracing.Settings{Exporter: tracing.MemoryExporter, SampleRatio: 1, Memory: memory})
+	s.Require().NoError(err)
+	defer func() {
+		s.NoError(shutdown(context.Background()))
+	}()
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	_, err = s.rw.Write([]byte(`{
+		"operation": "delete_stream",
+		"body": {"stream_name": "some-stream-name"},
+		"auth": {"client_id": "root_client_id", "client_secret": "root_client_secret"},
+		"trace_context": {"traceparent": "00-` + traceID + `-00f067aa0ba902b7-01"}
+	}`))
+	s.Require().NoError(err)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	inTrace := mock.MatchedBy(func(ctx context.Context) bool {
+		return trace.SpanContextFromContext(ctx).TraceID().String() == traceID
+	})
+	s.bus.
+		On("DeleteStream", inTrace, "root", "some-stream-name").
+		Return(nil).
+		Once()
+
+	_, err = s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	spans := memory.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal("delete_stream", spans[0].Name)
+	s.Equal(traceID, spans[0].SpanContext.TraceID().String())
+	s.Equal("00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	s.Contains(spans[0].Attributes, attribute.String("eventbus.client", "root"))
+}
+
 func (s *controllersSuite) Test_Switch_AuthError() {
 	expectedRes := transport.Response{
 		Operation: "create_stream",
@@ -124,6 +213,59 @@ func (s *controllersSuite) Test_Switch_AuthError() {
 	s.Equal(expectedRes, s.read())
 }
 
+func (s *controllersSuite) Test_Switch_ClientConn_Authorized() {
+	conn := &connMock{ReadWriter: s.rw}
+	conn.Test(s.T())
+	s.write("create_stream", `{"stream_name": "some-stream"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	conn.
+		On("Authorize", "root").
+		Return(nil).
+		Once()
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "some-stream", "", json.RawMessage(nil), models.Quota{}).
+		Return(models.Stream{}, nil).
+		Once()
+
+	exited, err := s.router.Switch(conn, conn)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.True(s.read().Status)
+	conn.AssertExpectations(s.T())
+}
+
+func (s *controllersSuite) Test_Switch_ClientConn_RateLimitError() {
+	expectedRes := transport.Response{
+		Operation: "create_stream",
+		Status:    false,
+		Reason:    "rate_limited",
+		Context:   JSON{"retry_after_ms": float64(100)},
+	}
+	rateLimitErr := models.RateLimitError{RetryAfterMS: 100}
+	conn := &connMock{ReadWriter: s.rw}
+	conn.Test(s.T())
+	s.write("create_stream", `{"stream_name": "some-stream"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	conn.
+		On("Authorize", "root").
+		Return(rateLimitErr).
+		Once()
+
+	exited, err := s.router.Switch(conn, conn)
+
+	s.Equal(rateLimitErr, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+	conn.AssertExpectations(s.T())
+}
+
 func (s *controllersSuite) Test_Switch_DecodeError() {
 	expectedRes := transport.Response{
 		Operation: "decode_request",
@@ -142,6 +284,52 @@ func (s *controllersSuite) Test_Switch_DecodeError() {
 	s.Equal(expectedRes, s.read())
 }
 
+func (s *controllersSuite) Test_Switch_MsgPack() {
+	codec, _ := transport.GetCodec(transport.MsgPackEncoding)
+	conn := codecConnMock{ReadWriter: s.rw, codec: codec}
+	req, err := codec.Marshal(map[string]interface{}{
+		"operation": "create_stream",
+		"body": map[string]interface{}{
+			"stream_name": "some-stream",
+			"schema":      map[string]interface{}{"type": "object"},
+		},
+		"auth": map[string]interface{}{"client_id": "root_client_id", "client_secret": "root_client_secret"},
+	})
+	s.Require().NoError(err)
+	_, err = s.rw.Write(req)
+	s.Require().NoError(err)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "some-stream", "", json.RawMessage(`{"type":"object"}`), models.Quota{}).
+		Return(models.Stream{}, nil).
+		Once()
+
+	exited, err := s.router.Switch(conn, conn)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.True(s.read().Status)
+}
+
+func (s *controllersSuite) Test_Switch_MsgPack_DecodeError() {
+	codec, _ := transport.GetCodec(transport.MsgPackEncoding)
+	conn := codecConnMock{ReadWriter: s.rw, codec: codec}
+	s.write("create_stream", "{")
+
+	exited, err := s.router.Switch(conn, conn)
+
+	s.Error(err)
+	s.False(exited)
+	s.Equal(transport.Response{
+		Operation: "decode_request",
+		Status:    false,
+		Reason:    "invalid msgpack provided",
+	}, s.read())
+}
+
 func (s *controllersSuite) write(operation, body string) {
 	auth := `{"client_id": "root_client_id", "client_secret": "root_client_secret"}`
 	req := fmt.Sprintf(`{"operation": "%s"`, operation)
@@ -165,6 +353,26 @@ func (s *controllersSuite) read() transport.Response {
 	return res
 }
 
+func (s *controllersSuite) requestsCount(operation, status string) float64 {
+	families, err := metrics.Registry.Gather()
+	s.Require().NoError(err)
+	for _, family := range families {
+		if family.GetName() != "eventbus_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["operation"] == operation && labels["status"] == status {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
 func (s *controllersSuite) testNilRequest(operation string, context JSON, fields []models.RequiredField) {
 	expectedRes := transport.Response{
 		Operation: operation,
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
 	"io"
 
 	"github.com/go-web-dev/event-bus/models"
@@ -8,11 +10,16 @@ import (
 )
 
 type streamCreator interface {
-	CreateStream(streamName string) (models.Stream, error)
+	CreateStream(ctx context.Context, client, streamName, compression string, schema json.RawMessage, quota models.Quota) (models.Stream, error)
 }
 
 type createStreamRequest struct {
-	StreamName string `json:"stream_name" type:"string"`
+	StreamName   string          `json:"stream_name" type:"string"`
+	Compression  string          `json:"compression,omitempty" type:"string"`
+	Schema       json.RawMessage `json:"schema,omitempty" type:"[]byte"`
+	MaxEventSize int64           `json:"max_event_size,omitempty" type:"int64"`
+	MaxEvents    int64           `json:"max_events,omitempty" type:"int64"`
+	MaxBytes     int64           `json:"max_bytes,omitempty" type:"int64"`
 }
 
 type createStreamResponse struct {
@@ -28,7 +35,12 @@ func (router Router) createStream(bus streamCreator) func(io.Writer, request) er
 			return err
 		}
 
-		s, err := bus.CreateStream(body.StreamName)
+		quota := models.Quota{
+			MaxEventSize: body.MaxEventSize,
+			MaxEvents:    body.MaxEvents,
+			MaxBytes:     body.MaxBytes,
+		}
+		s, err := bus.CreateStream(r.ctx, r.client, body.StreamName, body.Compression, body.Schema, quota)
 		if err != nil {
 			transport.SendError(w, createStreamOperation, err)
 			return err
@@ -1,6 +1,10 @@
 package controllers
 
 import (
+	"encoding/json"
+
+	"github.com/stretchr/testify/mock"
+
 	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/transport"
 )
@@ -28,7 +32,7 @@ func (s *controllersSuite) Test_CreateStream_Success() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("CreateStream", "some-stream-name").
+		On("CreateStream", mock.Anything, "root", "some-stream-name", "", json.RawMessage(nil), models.Quota{}).
 		Return(expectedStream, nil).
 		Once()
 
@@ -39,6 +43,86 @@ func (s *controllersSuite) Test_CreateStream_Success() {
 	s.Equal(expectedRes, s.read())
 }
 
+func (s *controllersSuite) Test_CreateStream_Compression() {
+	expectedStream := models.Stream{
+		ID:          "stream-id",
+		Name:        "some-stream-name",
+		CreatedAt:   testTime,
+		Compression: "zstd",
+	}
+	s.write("create_stream", `{"stream_name": "some-stream-name", "compression": "zstd"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "some-stream-name", "zstd", json.RawMessage(nil), models.Quota{}).
+		Return(expectedStream, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	res := s.read()
+	s.True(res.Status)
+	s.Equal("zstd", res.Body.(JSON)["stream"].(JSON)["compression"])
+}
+
+func (s *controllersSuite) Test_CreateStream_Quota() {
+	quota := models.Quota{MaxEventSize: 1024, MaxEvents: 100, MaxBytes: 10240}
+	expectedStream := models.Stream{
+		ID:        "stream-id",
+		Name:      "some-stream-name",
+		CreatedAt: testTime,
+		Quota:     quota,
+	}
+	s.write("create_stream", `{"stream_name": "some-stream-name", "max_event_size": 1024, "max_events": 100, "max_bytes": 10240}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "some-stream-name", "", json.RawMessage(nil), quota).
+		Return(expectedStream, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	res := s.read()
+	s.True(res.Status)
+	s.Equal(float64(100), res.Body.(JSON)["stream"].(JSON)["max_events"])
+}
+
+func (s *controllersSuite) Test_CreateStream_Schema() {
+	schema := `{"type": "object", "required": ["k"]}`
+	expectedStream := models.Stream{
+		ID:        "stream-id",
+		Name:      "some-stream-name",
+		CreatedAt: testTime,
+		Schema:    json.RawMessage(schema),
+	}
+	s.write("create_stream", `{"stream_name": "some-stream-name", "schema": `+schema+`}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "some-stream-name", "", json.RawMessage(schema), models.Quota{}).
+		Return(expectedStream, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	res := s.read()
+	s.True(res.Status)
+	s.Equal(JSON{"type": "object", "required": []interface{}{"k"}}, res.Body.(JSON)["stream"].(JSON)["schema"])
+}
+
 func (s *controllersSuite) Test_CreateStream_NilReqError() {
 	ctx := JSON{
 		"body": []interface{}{
@@ -47,6 +131,31 @@ func (s *controllersSuite) Test_CreateStream_NilReqError() {
 				"type":     "string",
 				"required": true,
 			},
+			JSON{
+				"name":     "compression",
+				"type":     "string",
+				"required": false,
+			},
+			JSON{
+				"name":     "schema",
+				"type":     "[]byte",
+				"required": false,
+			},
+			JSON{
+				"name":     "max_event_size",
+				"type":     "int64",
+				"required": false,
+			},
+			JSON{
+				"name":     "max_events",
+				"type":     "int64",
+				"required": false,
+			},
+			JSON{
+				"name":     "max_bytes",
+				"type":     "int64",
+				"required": false,
+			},
 		},
 	}
 	fields := []models.RequiredField{
@@ -55,6 +164,31 @@ func (s *controllersSuite) Test_CreateStream_NilReqError() {
 			Type:     "string",
 			Required: true,
 		},
+		{
+			Name:     "compression",
+			Type:     "string",
+			Required: false,
+		},
+		{
+			Name:     "schema",
+			Type:     "[]byte",
+			Required: false,
+		},
+		{
+			Name:     "max_event_size",
+			Type:     "int64",
+			Required: false,
+		},
+		{
+			Name:     "max_events",
+			Type:     "int64",
+			Required: false,
+		},
+		{
+			Name:     "max_bytes",
+			Type:     "int64",
+			Required: false,
+		},
 	}
 	s.testNilRequest("create_stream", ctx, fields)
 }
@@ -75,7 +209,7 @@ func (s *controllersSuite) Test_CreateStream_ServiceError() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("CreateStream", "some-stream-name").
+		On("CreateStream", mock.Anything, "root", "some-stream-name", "", json.RawMessage(nil), models.Quota{}).
 		Return(models.Stream{}, errTest).
 		Once()
 
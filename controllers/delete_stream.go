@@ -1,13 +1,14 @@
 package controllers
 
 import (
+	"context"
 	"io"
 
 	"github.com/go-web-dev/event-bus/transport"
 )
 
 type streamDeleter interface {
-	DeleteStream(streamName string) error
+	DeleteStream(ctx context.Context, client, streamName string) error
 }
 
 type deleteStreamRequest struct {
@@ -23,7 +24,7 @@ func (router Router) deleteStream(bus streamDeleter) func(io.Writer, request) er
 			return err
 		}
 
-		err = bus.DeleteStream(body.StreamName)
+		err = bus.DeleteStream(r.ctx, r.client, body.StreamName)
 		if err != nil {
 			transport.SendError(w, deleteStreamOperation, err)
 			return err
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"github.com/stretchr/testify/mock"
+
 	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/transport"
 )
@@ -16,7 +18,7 @@ func (s *controllersSuite) Test_DeleteStream_Success() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("DeleteStream", "some-stream-name").
+		On("DeleteStream", mock.Anything, "root", "some-stream-name").
 		Return(nil).
 		Once()
 
@@ -63,7 +65,7 @@ func (s *controllersSuite) Test_DeleteStream_ServiceError() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("DeleteStream", "some-stream-name").
+		On("DeleteStream", mock.Anything, "root", "some-stream-name").
 		Return(errTest).
 		Once()
 
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type streamExporter interface {
+	auditor
+	ExportStreamFile(ctx context.Context, streamName, path string) (int, error)
+	ImportStreamFile(ctx context.Context, streamName, path string) (models.ImportResult, error)
+}
+
+type exportStreamRequest struct {
+	StreamName string `json:"stream_name" type:"string"`
+	Path       string `json:"path" type:"string"`
+}
+
+type exportStreamResponse struct {
+	Path   string `json:"path"`
+	Events int    `json:"events"`
+}
+
+type importStreamRequest struct {
+	Path string `json:"path" type:"string"`
+	// StreamName is the stream to import into, the exported one when empty
+	StreamName string `json:"stream_name,omitempty" type:"string"`
+}
+
+// exportStream exports a stream as JSON Lines into a file on the server
+func (router Router) exportStream(bus streamExporter) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body exportStreamRequest
+		err := parseReq(r, &body)
+		if err != nil {
+			transport.SendError(w, exportStreamOperation, err)
+			return err
+		}
+		if body.StreamName == "" || body.Path == "" {
+			e := models.OperationRequestError{Body: transport.DecodeFields(body)}
+			transport.SendError(w, exportStreamOperation, e)
+			return e
+		}
+
+		n, err := bus.ExportStreamFile(r.ctx, body.StreamName, body.Path)
+		if err != nil {
+			transport.SendError(w, exportStreamOperation, err)
+			return err
+		}
+		err = bus.Audit(r.ctx, models.NewExportEntry(r.client, body.StreamName, body.Path, n))
+		if err != nil {
+			transport.SendError(w, exportStreamOperation, err)
+			return err
+		}
+
+		res := exportStreamResponse{
+			Path:   body.Path,
+			Events: n,
+		}
+		transport.SendJSON(w, exportStreamOperation, res)
+		return nil
+	}
+}
+
+// importStream imports a stream export file on the server into a new or an existing stream
+func (router Router) importStream(bus streamExporter) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body importStreamRequest
+		err := parseReq(r, &body)
+		if err != nil {
+			transport.SendError(w, importStreamOperation, err)
+			return err
+		}
+		if body.Path == "" {
+			e := models.OperationRequestError{Body: transport.DecodeFields(body)}
+			transport.SendError(w, importStreamOperation, e)
+			return e
+		}
+
+		res, err := bus.ImportStreamFile(r.ctx, body.StreamName, body.Path)
+		if err != nil {
+			transport.SendError(w, importStreamOperation, err)
+			return err
+		}
+		err = bus.Audit(r.ctx, models.NewImportEntry(r.client, body.Path, res))
+		if err != nil {
+			transport.SendError(w, importStreamOperation, err)
+			return err
+		}
+
+		transport.SendJSON(w, importStreamOperation, res)
+		return nil
+	}
+}
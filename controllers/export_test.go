@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_ExportStream_Success() {
+	s.admin()
+	expectedRes := transport.Response{
+		Operation: "export_stream",
+		Status:    true,
+		Body:      JSON{"path": "/exports/s1.jsonl", "events": float64(42)},
+	}
+	s.write("export_stream", `{"stream_name": "s1", "path": "/exports/s1.jsonl"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ExportStreamFile", mock.Anything, "s1", "/exports/s1.jsonl").
+		Return(42, nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewExportEntry("root", "s1", "/exports/s1.jsonl", 42)).
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_ExportStream_StreamNotFoundError() {
+	s.admin()
+	s.write("export_stream", `{"stream_name": "s1", "path": "/exports/s1.jsonl"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ExportStreamFile", mock.Anything, "s1", "/exports/s1.jsonl").
+		Return(0, models.StreamNotFoundError{Name: "s1"}).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.StreamNotFoundError{Name: "s1"}, err)
+	s.False(exited)
+	s.Equal("stream 's1' not found", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_ExportStream_MissingFieldsError() {
+	s.admin()
+	s.write("export_stream", `{"stream_name": "s1"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.IsType(models.OperationRequestError{}, err)
+	s.False(exited)
+	s.Equal("missing required fields", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_ExportStream_ForbiddenError() {
+	s.write("export_stream", `{"stream_name": "s1", "path": "/exports/s1.jsonl"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.ForbiddenError{}, err)
+	s.False(exited)
+	s.Equal("forbidden to make request", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_ImportStream_Success() {
+	s.admin()
+	result := models.ImportResult{
+		Stream:   models.Stream{ID: "s2-id", Name: "s2", CreatedAt: testTime},
+		Imported: 40,
+		Skipped:  2,
+	}
+	expectedRes := transport.Response{
+		Operation: "import_stream",
+		Status:    true,
+		Body: JSON{
+			"stream":   JSON{"id": "s2-id", "name": "s2", "created_at": testTimeStr},
+			"imported": float64(40),
+			"skipped":  float64(2),
+			"renamed":  float64(0),
+		},
+	}
+	s.write("import_stream", `{"path": "/exports/s1.jsonl", "stream_name": "s2"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ImportStreamFile", mock.Anything, "s2", "/exports/s1.jsonl").
+		Return(result, nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewImportEntry("root", "/exports/s1.jsonl", result)).
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_ImportStream_InvalidImportError() {
+	s.admin()
+	s.write("import_stream", `{"path": "/exports/s1.jsonl"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ImportStreamFile", mock.Anything, "", "/exports/s1.jsonl").
+		Return(models.ImportResult{}, models.InvalidImportError{Line: 3, Reason: "unexpected EOF"}).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.InvalidImportError{Line: 3, Reason: "unexpected EOF"}, err)
+	s.False(exited)
+	s.Equal("invalid import at line 3: unexpected EOF", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_ImportStream_MissingPathError() {
+	s.admin()
+	s.write("import_stream", `{"stream_name": "s2"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.IsType(models.OperationRequestError{}, err)
+	s.False(exited)
+	s.Equal("missing required fields", s.read().Reason)
+}
+
+func (s *controllersSuite) Test_ImportStream_ParseReqError() {
+	s.admin()
+	s.testParseRequest("import_stream", `{"path": 1}`)
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type auditLogGetter interface {
+	GetAuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+type getAuditLogRequest struct {
+	Action     string    `json:"action,omitempty" type:"string"`
+	Client     string    `json:"client,omitempty" type:"string"`
+	StreamName string    `json:"stream_name,omitempty" type:"string"`
+	Since      time.Time `json:"since,omitempty" type:"time"`
+	Until      time.Time `json:"until,omitempty" type:"time"`
+	Limit      int       `json:"limit,omitempty" type:"int"`
+}
+
+type getAuditLogResponse struct {
+	Entries []models.AuditEntry `json:"entries"`
+}
+
+func (router Router) getAuditLog(bus auditLogGetter) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body getAuditLogRequest
+		// getting the whole audit log needs no body
+		if r.Body != nil {
+			err := parseReq(r, &body)
+			if err != nil {
+				transport.SendError(w, getAuditLogOperation, err)
+				return err
+			}
+		}
+
+		filter := models.AuditFilter{
+			Action: body.Action,
+			Client: body.Client,
+			Stream: body.StreamName,
+			Since:  body.Since,
+			Until:  body.Until,
+			Limit:  body.Limit,
+		}
+		entries, err := bus.GetAuditLog(r.ctx, filter)
+		if err != nil {
+			transport.SendError(w, getAuditLogOperation, err)
+			return err
+		}
+
+		res := getAuditLogResponse{
+			Entries: entries,
+		}
+		transport.SendJSON(w, getAuditLogOperation, res)
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_GetAuditLog_Success() {
+	s.admin()
+	entry := models.AuditEntry{
+		ID:        "audit-id",
+		Action:    models.AuditDeleteStream,
+		Client:    "root",
+		Stream:    "some-stream-name",
+		CreatedAt: testTime,
+	}
+	expectedRes := transport.Response{
+		Operation: "get_audit_log",
+		Status:    true,
+		Body: JSON{
+			"entries": []interface{}{
+				JSON{
+					"id":         "audit-id",
+					"action":     "delete_stream",
+					"client":     "root",
+					"stream":     "some-stream-name",
+					"created_at": testTimeStr,
+				},
+			},
+		},
+	}
+	s.write("get_audit_log", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("GetAuditLog", mock.Anything, models.AuditFilter{}).
+		Return([]models.AuditEntry{entry}, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_GetAuditLog_Filter() {
+	s.admin()
+	filter := models.AuditFilter{
+		Action: models.AuditDeleteStream,
+		Client: "root",
+		Stream: "some-stream-name",
+		Since:  testTime,
+		Limit:  10,
+	}
+	body := `{"action": "delete_stream", "client": "root", "stream_name": "some-stream-name", "since": "` +
+		testTimeStr + `", "limit": 10}`
+	s.write("get_audit_log", body)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("GetAuditLog", mock.Anything, filter).
+		Return([]models.AuditEntry{}, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(JSON{"entries": []interface{}{}}, s.read().Body)
+}
+
+func (s *controllersSuite) Test_GetAuditLog_ParseReqError() {
+	s.admin()
+	s.testParseRequest("get_audit_log", `{"since": "yesterday"}`)
+}
+
+func (s *controllersSuite) Test_GetAuditLog_ServiceError() {
+	s.admin()
+	expectedRes := transport.Response{
+		Operation: "get_audit_log",
+		Status:    false,
+		Reason:    errTest.Error(),
+	}
+	s.write("get_audit_log", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("GetAuditLog", mock.Anything, models.AuditFilter{}).
+		Return([]models.AuditEntry{}, errTest).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(errTest, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_GetAuditLog_ForbiddenError() {
+	s.write("get_audit_log", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.ForbiddenError{}, err)
+	s.False(exited)
+	s.Equal("forbidden to make request", s.read().Reason)
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type eventTypeGetter interface {
+	GetEventType(name string, version int) (models.EventType, error)
+	GetEventTypeBySchemaID(schemaID string) (models.EventType, error)
+}
+
+type getEventTypeRequest struct {
+	Name     string `json:"name,omitempty" type:"string"`
+	Version  int    `json:"version,omitempty" type:"int"`
+	SchemaID string `json:"schema_id,omitempty" type:"string"`
+}
+
+func (router Router) getEventType(bus eventTypeGetter) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body getEventTypeRequest
+		err := parseReq(r, &body)
+		if err != nil {
+			transport.SendError(w, getEventTypeOperation, err)
+			return err
+		}
+
+		var eventType models.EventType
+		// the schema ID events are tagged with takes precedence over the name and version
+		if body.SchemaID != "" {
+			eventType, err = bus.GetEventTypeBySchemaID(body.SchemaID)
+		} else {
+			eventType, err = bus.GetEventType(body.Name, body.Version)
+		}
+		if err != nil {
+			transport.SendError(w, getEventTypeOperation, err)
+			return err
+		}
+
+		res := eventTypeResponse{
+			EventType: eventType,
+		}
+		transport.SendJSON(w, getEventTypeOperation, res)
+		return nil
+	}
+}
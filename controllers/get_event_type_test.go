@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_GetEventType_Success() {
+	expectedRes := transport.Response{
+		Operation: "get_event_type",
+		Status:    true,
+		Body: JSON{
+			"event_type": testEventTypeJSON,
+		},
+	}
+	s.write("get_event_type", `{"name": "user_created", "version": 1}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("GetEventType", "user_created", 1).
+		Return(testEventType, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_GetEventType_SchemaID() {
+	s.write("get_event_type", `{"schema_id": "schema-id"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("GetEventTypeBySchemaID", "schema-id").
+		Return(testEventType, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(testEventTypeJSON, s.read().Body.(JSON)["event_type"])
+}
+
+func (s *controllersSuite) Test_GetEventType_NilReqError() {
+	ctx := JSON{
+		"body": []interface{}{
+			JSON{
+				"name":     "name",
+				"type":     "string",
+				"required": false,
+			},
+			JSON{
+				"name":     "version",
+				"type":     "int",
+				"required": false,
+			},
+			JSON{
+				"name":     "schema_id",
+				"type":     "string",
+				"required": false,
+			},
+		},
+	}
+	fields := []models.RequiredField{
+		{
+			Name:     "name",
+			Type:     "string",
+			Required: false,
+		},
+		{
+			Name:     "version",
+			Type:     "int",
+			Required: false,
+		},
+		{
+			Name:     "schema_id",
+			Type:     "string",
+			Required: false,
+		},
+	}
+	s.testNilRequest("get_event_type", ctx, fields)
+}
+
+func (s *controllersSuite) Test_GetEventType_ParseReqError() {
+	s.testParseRequest("get_event_type", `{"version": "1"}`)
+}
+
+func (s *controllersSuite) Test_GetEventType_ServiceError() {
+	notFoundErr := models.EventTypeNotFoundError{Name: "user_created"}
+	expectedRes := transport.Response{
+		Operation: "get_event_type",
+		Status:    false,
+		Reason:    "event type 'user_created' not found",
+	}
+	s.write("get_event_type", `{"name": "user_created"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("GetEventType", "user_created", 0).
+		Return(models.EventType{}, notFoundErr).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(notFoundErr, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"io"
 
 	"github.com/go-web-dev/event-bus/models"
@@ -8,7 +9,7 @@ import (
 )
 
 type streamEventsGetter interface {
-	GetStreamEvents(streamName string) ([]models.Event, error)
+	GetStreamEvents(ctx context.Context, streamName string) ([]models.Event, error)
 }
 
 type getStreamEventsRequest struct {
@@ -28,12 +29,13 @@ func (router Router) getStreamEvents(bus streamEventsGetter) func(io.Writer, req
 			return err
 		}
 
-		events, err := bus.GetStreamEvents(body.StreamName)
+		events, err := bus.GetStreamEvents(r.ctx, body.StreamName)
 		if err != nil {
 			transport.SendError(w, getStreamEventsOperation, err)
 			return err
 		}
 
+		r.access.setEvents(len(events))
 		res := getStreamEventsResponse{
 			Events: events,
 		}
@@ -3,6 +3,8 @@ package controllers
 import (
 	"encoding/json"
 
+	"github.com/stretchr/testify/mock"
+
 	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/transport"
 )
@@ -67,7 +69,7 @@ func (s *controllersSuite) Test_GetStreamEvents_Success() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("GetStreamEvents", "some-stream-name").
+		On("GetStreamEvents", mock.Anything, "some-stream-name").
 		Return(events, nil).
 		Once()
 
@@ -92,7 +94,7 @@ func (s *controllersSuite) Test_GetStreamEvents_EmptyResult() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("GetStreamEvents", "some-stream-name").
+		On("GetStreamEvents", mock.Anything, "some-stream-name").
 		Return([]models.Event{}, nil).
 		Once()
 
@@ -139,7 +141,7 @@ func (s *controllersSuite) Test_GetStreamEvents_ServiceError() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("GetStreamEvents", "some-stream-name").
+		On("GetStreamEvents", mock.Anything, "some-stream-name").
 		Return([]models.Event{}, errTest).
 		Once()
 
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+// HealthChecker represents the deep health checks of the Event Bus components
+type HealthChecker interface {
+	Check(ctx context.Context) models.Health
+}
+
+type healthRequest struct {
+	Deep bool `json:"deep,omitempty" type:"bool"`
+}
+
+// health tells whether the Event Bus is alive, when asked to go deep it checks every component
+// and fails unless they are all up, telling whether the Event Bus is ready to serve requests.
+// Only the authenticated clients get the components, the others get the status alone
+func (router Router) health(checker HealthChecker) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body healthRequest
+		// liveness needs no body
+		if r.Body != nil {
+			err := parseReq(r, &body)
+			if err != nil {
+				transport.SendError(w, healthOperation, err)
+				return err
+			}
+		}
+		if !body.Deep {
+			transport.SendJSON(w, healthOperation, nil)
+			return nil
+		}
+
+		res := models.Health{Status: models.HealthUp}
+		if checker != nil {
+			res = checker.Check(r.ctx)
+		}
+		if !router.authenticated(r) {
+			res = models.Health{Status: res.Status}
+		}
+		if res.Status != models.HealthUp {
+			e := models.UnhealthyError{Health: res}
+			transport.SendError(w, healthOperation, e)
+			return e
+		}
+		transport.SendJSON(w, healthOperation, res)
+		return nil
+	}
+}
+
+// authenticated tells whether the request, which needs no credentials, comes with valid ones
+func (router Router) authenticated(r request) bool {
+	if r.Auth == (auth{}) {
+		return false
+	}
+	_, _, err := router.auth(r)
+	return err == nil
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/config"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_Health_Deep_Success() {
+	health := models.Health{
+		Status: models.HealthUp,
+		Components: map[string]models.ComponentHealth{
+			"storage": {Status: models.HealthUp},
+		},
+	}
+	expectedRes := transport.Response{
+		Operation: "health",
+		Status:    true,
+		Body: JSON{
+			"status": "up",
+			"components": JSON{
+				"storage": JSON{"status": "up"},
+			},
+		},
+	}
+	s.write("health", `{"deep": true}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.checker.
+		On("Check", mock.Anything).
+		Return(health).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Health_Deep_NoChecker() {
+	router := NewRouter(s.bus, s.cfg, nil, s.conns)
+	s.write("health", `{"deep": true}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(JSON{"status": "up"}, s.read().Body)
+}
+
+func (s *controllersSuite) Test_Health_Deep_Unauthenticated() {
+	health := models.Health{
+		Status: models.HealthDown,
+		Components: map[string]models.ComponentHealth{
+			"storage": {Status: models.HealthDown, Error: "DB Closed"},
+		},
+	}
+	s.auth = nil
+	s.write("health", `{"deep": true}`)
+	s.checker.
+		On("Check", mock.Anything).
+		Return(health).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.UnhealthyError{Health: models.Health{Status: models.HealthDown}}, err)
+	s.False(exited)
+	s.Equal(JSON{"status": "down"}, s.read().Context)
+}
+
+func (s *controllersSuite) Test_Health_Deep_InvalidAuth() {
+	health := models.Health{
+		Status: models.HealthUp,
+		Components: map[string]models.ComponentHealth{
+			"storage": {Status: models.HealthUp},
+		},
+	}
+	s.write("health", `{"deep": true}`)
+	s.cfg.
+		On("GetAuth").
+		Return(config.ClientAuth{}).
+		Once()
+	s.checker.
+		On("Check", mock.Anything).
+		Return(health).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(JSON{"status": "up"}, s.read().Body)
+}
+
+func (s *controllersSuite) Test_Health_NotDeep() {
+	expectedRes := transport.Response{
+		Operation: "health",
+		Status:    true,
+	}
+	s.write("health", `{"deep": false}`)
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Health_Deep_UnhealthyError() {
+	health := models.Health{
+		Status: models.HealthDown,
+		Components: map[string]models.ComponentHealth{
+			"storage": {Status: models.HealthDown, Error: "DB Closed"},
+		},
+	}
+	expectedRes := transport.Response{
+		Operation: "health",
+		Status:    false,
+		Reason:    "service is unhealthy",
+		Context: JSON{
+			"status": "down",
+			"components": JSON{
+				"storage": JSON{"status": "down", "error": "DB Closed"},
+			},
+		},
+	}
+	s.write("health", `{"deep": true}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.checker.
+		On("Check", mock.Anything).
+		Return(health).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.UnhealthyError{Health: health}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Health_ParseReqError() {
+	s.write("health", `{"deep": "yes"}`)
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.InvalidJSONError{}, err)
+	s.False(exited)
+	s.Equal("invalid json provided", s.read().Reason)
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"io"
+
+	"github.com/go-web-dev/event-bus/compression"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+// encodingConn represents a connection which can switch its wire encoding and compression
+type encodingConn interface {
+	SetEncoding(encoding string) error
+	SetCompression(algorithm string) error
+}
+
+type helloRequest struct {
+	Encoding    string `json:"encoding" type:"string"`
+	Compression string `json:"compression,omitempty" type:"string"`
+}
+
+type helloResponse struct {
+	Encoding    string `json:"encoding"`
+	Compression string `json:"compression,omitempty"`
+}
+
+// hello negotiates the wire encoding and the response compression of the connection.
+// The response is sent with the current wire format, the following ones with the negotiated one
+func (router Router) hello(w io.Writer, r request) error {
+	var body helloRequest
+	err := parseReq(r, &body)
+	if err != nil {
+		transport.SendError(w, helloOperation, err)
+		return err
+	}
+	if _, ok := transport.GetCodec(body.Encoding); !ok {
+		e := models.UnsupportedEncodingError{}
+		transport.SendError(w, helloOperation, e)
+		return e
+	}
+	if !compression.Supported(body.Compression) {
+		e := models.UnsupportedCompressionError{}
+		transport.SendError(w, helloOperation, e)
+		return e
+	}
+
+	conn, ok := transport.Unwrap(w).(encodingConn)
+	if !ok && body.Encoding != transport.JSONEncoding {
+		e := models.Error{Message: "encoding cannot be changed on this connection"}
+		transport.SendError(w, helloOperation, e)
+		return e
+	}
+	if !ok && body.Compression != compression.None {
+		e := models.Error{Message: "compression cannot be changed on this connection"}
+		transport.SendError(w, helloOperation, e)
+		return e
+	}
+
+	res := helloResponse{
+		Encoding:    body.Encoding,
+		Compression: body.Compression,
+	}
+	transport.SendJSON(w, helloOperation, res)
+	if !ok {
+		return nil
+	}
+	err = conn.SetEncoding(body.Encoding)
+	if err != nil {
+		return err
+	}
+	return conn.SetCompression(body.Compression)
+}
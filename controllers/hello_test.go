@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_Hello_Success() {
+	expectedRes := transport.Response{
+		Operation: "hello",
+		Status:    true,
+		Body:      JSON{"encoding": "msgpack"},
+	}
+	conn := &connMock{ReadWriter: s.rw}
+	conn.Test(s.T())
+	s.write("hello", `{"encoding": "msgpack"}`)
+	conn.
+		On("SetEncoding", "msgpack").
+		Return(nil).
+		Once()
+	conn.
+		On("SetCompression", "").
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(conn, conn)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+	conn.AssertExpectations(s.T())
+}
+
+func (s *controllersSuite) Test_Hello_Compression() {
+	expectedRes := transport.Response{
+		Operation: "hello",
+		Status:    true,
+		Body:      JSON{"encoding": "json", "compression": "zstd"},
+	}
+	conn := &connMock{ReadWriter: s.rw}
+	conn.Test(s.T())
+	s.write("hello", `{"encoding": "json", "compression": "zstd"}`)
+	conn.
+		On("SetEncoding", "json").
+		Return(nil).
+		Once()
+	conn.
+		On("SetCompression", "zstd").
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(conn, conn)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+	conn.AssertExpectations(s.T())
+}
+
+func (s *controllersSuite) Test_Hello_CompressionConnError() {
+	expectedRes := transport.Response{
+		Operation: "hello",
+		Status:    false,
+		Reason:    "compression cannot be changed on this connection",
+	}
+	s.write("hello", `{"encoding": "json", "compression": "zstd"}`)
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.EqualError(err, "compression cannot be changed on this connection")
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Hello_UnsupportedCompressionError() {
+	expectedRes := transport.Response{
+		Operation: "hello",
+		Status:    false,
+		Reason:    "compression must be one of: 'snappy', 'zstd'",
+	}
+	s.write("hello", `{"encoding": "json", "compression": "gzip"}`)
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.UnsupportedCompressionError{}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Hello_JSONWithoutEncodingConn() {
+	expectedRes := transport.Response{
+		Operation: "hello",
+		Status:    true,
+		Body:      JSON{"encoding": "json"},
+	}
+	s.write("hello", `{"encoding": "json"}`)
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Hello_EncodingConnError() {
+	expectedRes := transport.Response{
+		Operation: "hello",
+		Status:    false,
+		Reason:    "encoding cannot be changed on this connection",
+	}
+	s.write("hello", `{"encoding": "msgpack"}`)
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.EqualError(err, "encoding cannot be changed on this connection")
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Hello_UnsupportedEncodingError() {
+	expectedRes := transport.Response{
+		Operation: "hello",
+		Status:    false,
+		Reason:    "encoding must be one of: 'json', 'msgpack'",
+	}
+	s.write("hello", `{"encoding": "xml"}`)
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.UnsupportedEncodingError{}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Hello_NilReqError() {
+	expectedErr := models.OperationRequestError{
+		Body: []models.RequiredField{
+			{
+				Name:     "encoding",
+				Type:     "string",
+				Required: true,
+			},
+			{
+				Name:     "compression",
+				Type:     "string",
+				Required: false,
+			},
+		},
+	}
+	s.write("hello", "")
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(expectedErr, err)
+	s.False(exited)
+	s.False(s.read().Status)
+}
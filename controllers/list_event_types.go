@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type eventTypesLister interface {
+	ListEventTypes(name string) ([]models.EventType, error)
+}
+
+type listEventTypesRequest struct {
+	Name string `json:"name,omitempty" type:"string"`
+}
+
+type listEventTypesResponse struct {
+	EventTypes []models.EventType `json:"event_types"`
+}
+
+func (router Router) listEventTypes(bus eventTypesLister) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body listEventTypesRequest
+		// listing every event type needs no body
+		if r.Body != nil {
+			err := parseReq(r, &body)
+			if err != nil {
+				transport.SendError(w, listEventTypesOperation, err)
+				return err
+			}
+		}
+
+		eventTypes, err := bus.ListEventTypes(body.Name)
+		if err != nil {
+			transport.SendError(w, listEventTypesOperation, err)
+			return err
+		}
+
+		res := listEventTypesResponse{
+			EventTypes: eventTypes,
+		}
+		transport.SendJSON(w, listEventTypesOperation, res)
+		return nil
+	}
+}
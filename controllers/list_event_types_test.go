@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_ListEventTypes_Success() {
+	expectedRes := transport.Response{
+		Operation: "list_event_types",
+		Status:    true,
+		Body: JSON{
+			"event_types": []interface{}{testEventTypeJSON},
+		},
+	}
+	s.write("list_event_types", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ListEventTypes", "").
+		Return([]models.EventType{testEventType}, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_ListEventTypes_Name() {
+	s.write("list_event_types", `{"name": "user_created"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ListEventTypes", "user_created").
+		Return([]models.EventType{testEventType}, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.True(s.read().Status)
+}
+
+func (s *controllersSuite) Test_ListEventTypes_ParseReqError() {
+	s.testParseRequest("list_event_types", `{"name": 1}`)
+}
+
+func (s *controllersSuite) Test_ListEventTypes_ServiceError() {
+	expectedRes := transport.Response{
+		Operation: "list_event_types",
+		Status:    false,
+		Reason:    errTest.Error(),
+	}
+	s.write("list_event_types", `{"name": "user_created"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ListEventTypes", "user_created").
+		Return([]models.EventType{}, errTest).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(errTest, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type streamsLister interface {
+	ListStreams() []models.Stream
+}
+
+type listStreamsResponse struct {
+	Streams []models.Stream `json:"streams"`
+}
+
+func (router Router) listStreams(bus streamsLister) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		res := listStreamsResponse{
+			Streams: bus.ListStreams(),
+		}
+		transport.SendJSON(w, listStreamsOperation, res)
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_ListStreams_Success() {
+	streams := []models.Stream{
+		{
+			ID:        "some-stream-id",
+			Name:      "some-stream-name",
+			CreatedAt: testTime,
+			Usage:     &models.Usage{Events: 2, Bytes: 20},
+		},
+	}
+	expectedRes := transport.Response{
+		Operation: "list_streams",
+		Status:    true,
+		Body: JSON{
+			"streams": []interface{}{
+				JSON{
+					"id":         "some-stream-id",
+					"name":       "some-stream-name",
+					"created_at": testTimeStr,
+					"usage":      JSON{"events": 2.0, "bytes": 20.0},
+				},
+			},
+		},
+	}
+	s.write("list_streams", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ListStreams").
+		Return(streams).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_ListStreams_NoStreams() {
+	s.write("list_streams", "")
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("ListStreams").
+		Return([]models.Stream{}).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(JSON{"streams": []interface{}{}}, s.read().Body)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"io"
 
 	"github.com/go-web-dev/event-bus/models"
@@ -8,7 +9,7 @@ import (
 )
 
 type eventMarker interface {
-	MarkEvent(eventID string, status uint8) error
+	MarkEvent(ctx context.Context, client, eventID string, status uint8) error
 }
 
 type markEventRequest struct {
@@ -30,7 +31,7 @@ func (router Router) markEvent(bus eventMarker) func(io.Writer, request) error {
 			return e
 		}
 
-		err = bus.MarkEvent(body.EventID, body.Status)
+		err = bus.MarkEvent(r.ctx, r.client, body.EventID, body.Status)
 		if err != nil {
 			transport.SendError(w, markEventOperation, err)
 			return err
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"github.com/stretchr/testify/mock"
+
 	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/transport"
 )
@@ -16,7 +18,7 @@ func (s *controllersSuite) Test_MarkEvent_Success() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("MarkEvent", "some-event-id", uint8(2)).
+		On("MarkEvent", mock.Anything, "root", "some-event-id", uint8(2)).
 		Return(nil).
 		Once()
 
@@ -93,7 +95,7 @@ func (s *controllersSuite) Test_MarkEvent_ServiceError() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("MarkEvent", "some-event-id", uint8(2)).
+		On("MarkEvent", mock.Anything, "root", "some-event-id", uint8(2)).
 		Return(errTest).
 		Once()
 
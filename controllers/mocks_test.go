@@ -1,25 +1,63 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"net"
 
 	"github.com/stretchr/testify/mock"
 
 	"github.com/go-web-dev/event-bus/config"
 	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/testutils"
+	"github.com/go-web-dev/event-bus/transport"
 )
 
+type checkerMock struct {
+	mock.Mock
+}
+
+func (m *checkerMock) Check(ctx context.Context) models.Health {
+	args := m.Called(ctx)
+	return args.Get(0).(models.Health)
+}
+
+type connsMock struct {
+	mock.Mock
+}
+
+func (m *connsMock) List() []models.Connection {
+	args := m.Called()
+	return args.Get(0).([]models.Connection)
+}
+
+func (m *connsMock) Disconnect(connID int) error {
+	args := m.Called(connID)
+	return args.Error(0)
+}
+
+func (m *connsMock) DisconnectClient(client string) []int {
+	args := m.Called(client)
+	return args.Get(0).([]int)
+}
+
 type busMock struct {
 	mock.Mock
 }
 
-func (m *busMock) CreateStream(streamName string) (models.Stream, error) {
-	args := m.Called(streamName)
+func (m *busMock) CreateStream(ctx context.Context, client, streamName, compression string, schema json.RawMessage, quota models.Quota) (models.Stream, error) {
+	args := m.Called(ctx, client, streamName, compression, schema, quota)
 	return args.Get(0).(models.Stream), args.Error(1)
 }
 
-func (m *busMock) DeleteStream(streamName string) error {
-	args := m.Called(streamName)
+func (m *busMock) UpdateStream(ctx context.Context, client, streamName string, schema json.RawMessage, quota models.Quota) (models.Stream, error) {
+	args := m.Called(ctx, client, streamName, schema, quota)
+	return args.Get(0).(models.Stream), args.Error(1)
+}
+
+func (m *busMock) DeleteStream(ctx context.Context, client, streamName string) error {
+	args := m.Called(ctx, client, streamName)
 	return args.Error(0)
 }
 
@@ -28,23 +66,88 @@ func (m *busMock) GetStreamInfo(streamName string) (models.Stream, error) {
 	return args.Get(0).(models.Stream), args.Error(1)
 }
 
-func (m *busMock) GetStreamEvents(streamName string) ([]models.Event, error) {
-	args := m.Called(streamName)
+func (m *busMock) ListStreams() []models.Stream {
+	args := m.Called()
+	return args.Get(0).([]models.Stream)
+}
+
+func (m *busMock) GetStreamEvents(ctx context.Context, streamName string) ([]models.Event, error) {
+	args := m.Called(ctx, streamName)
 	return args.Get(0).([]models.Event), args.Error(1)
 }
 
-func (m *busMock) WriteEvent(streamName string, event json.RawMessage) error {
-	args := m.Called(streamName, event)
+func (m *busMock) WriteEvent(ctx context.Context, client, streamName string, event json.RawMessage) error {
+	args := m.Called(ctx, client, streamName, event)
+	return args.Error(0)
+}
+
+func (m *busMock) WriteData(ctx context.Context, client, streamName, contentType string, data []byte) error {
+	args := m.Called(ctx, client, streamName, contentType, data)
+	return args.Error(0)
+}
+
+func (m *busMock) WriteTypedEvent(ctx context.Context, client, streamName, eventType string, version int, event json.RawMessage) error {
+	args := m.Called(ctx, client, streamName, eventType, version, event)
+	return args.Error(0)
+}
+
+func (m *busMock) RegisterEventType(ctx context.Context, name, compatibility string, schema json.RawMessage) (models.EventType, error) {
+	args := m.Called(ctx, name, compatibility, schema)
+	return args.Get(0).(models.EventType), args.Error(1)
+}
+
+func (m *busMock) ListEventTypes(name string) ([]models.EventType, error) {
+	args := m.Called(name)
+	return args.Get(0).([]models.EventType), args.Error(1)
+}
+
+func (m *busMock) GetEventType(name string, version int) (models.EventType, error) {
+	args := m.Called(name, version)
+	return args.Get(0).(models.EventType), args.Error(1)
+}
+
+func (m *busMock) GetEventTypeBySchemaID(schemaID string) (models.EventType, error) {
+	args := m.Called(schemaID)
+	return args.Get(0).(models.EventType), args.Error(1)
+}
+
+func (m *busMock) MarkEvent(ctx context.Context, client, eventID string, status uint8) error {
+	args := m.Called(ctx, client, eventID, status)
+	return args.Error(0)
+}
+
+func (m *busMock) Audit(ctx context.Context, entry models.AuditEntry) error {
+	args := m.Called(ctx, entry)
 	return args.Error(0)
 }
 
-func (m *busMock) MarkEvent(streamName string, status uint8) error {
-	args := m.Called(streamName, status)
+func (m *busMock) BackupFile(ctx context.Context, path string, since uint64) (uint64, error) {
+	args := m.Called(ctx, path, since)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *busMock) RestoreFile(ctx context.Context, path string, incremental bool) error {
+	args := m.Called(ctx, path, incremental)
 	return args.Error(0)
 }
 
-func (m *busMock) ProcessEvents(streamName string, retry bool) ([]models.Event, error) {
-	args := m.Called(streamName, retry)
+func (m *busMock) ExportStreamFile(ctx context.Context, streamName, path string) (int, error) {
+	args := m.Called(ctx, streamName, path)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *busMock) ImportStreamFile(ctx context.Context, streamName, path string) (models.ImportResult, error) {
+	args := m.Called(ctx, streamName, path)
+	return args.Get(0).(models.ImportResult), args.Error(1)
+}
+
+func (m *busMock) GetAuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.AuditEntry), args.Error(1)
+}
+
+func (m *busMock) ProcessEvents(ctx context.Context, streamName string, retry bool) ([]models.Event, error) {
+	args := m.Called(ctx, streamName, retry)
 	return args.Get(0).([]models.Event), args.Error(1)
 }
 
@@ -66,3 +169,56 @@ func (m *cfgMock) GetAuth() config.ClientAuth {
 	args := m.Called()
 	return args.Get(0).(config.ClientAuth)
 }
+
+type connMock struct {
+	mock.Mock
+	*testutils.ReadWriter
+}
+
+func (m *connMock) Authorize(client string) error {
+	return m.Called(client).Error(0)
+}
+
+func (m *connMock) SetEncoding(encoding string) error {
+	return m.Called(encoding).Error(0)
+}
+
+func (m *connMock) SetCompression(algorithm string) error {
+	return m.Called(algorithm).Error(0)
+}
+
+type peerConnMock struct {
+	*testutils.ReadWriter
+	id   int
+	addr net.Addr
+}
+
+func (m peerConnMock) ID() int {
+	return m.id
+}
+
+func (m peerConnMock) RemoteAddr() net.Addr {
+	return m.addr
+}
+
+type codecConnMock struct {
+	*testutils.ReadWriter
+	codec transport.Codec
+}
+
+func (m codecConnMock) Codec() transport.Codec {
+	return m.codec
+}
+
+type subscriptionConnMock struct {
+	mock.Mock
+	*testutils.ReadWriter
+}
+
+func (m *subscriptionConnMock) Subscribe(streamName string) (func(io.Writer), error) {
+	args := m.Called(streamName)
+	if push, ok := args.Get(0).(func(io.Writer)); ok {
+		return push, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"io"
 
 	"github.com/go-web-dev/event-bus/models"
@@ -8,7 +9,7 @@ import (
 )
 
 type eventProcessor interface {
-	ProcessEvents(streamName string, retry bool) ([]models.Event, error)
+	ProcessEvents(ctx context.Context, streamName string, retry bool) ([]models.Event, error)
 }
 
 type processEventsRequest struct {
@@ -28,12 +29,13 @@ func (router Router) processEvents(bus eventProcessor) func(io.Writer, request)
 			return err
 		}
 
-		events, err := bus.ProcessEvents(body.StreamName, false)
+		events, err := bus.ProcessEvents(r.ctx, body.StreamName, false)
 		if err != nil {
 			transport.SendError(w, processEventsOperation, err)
 			return err
 		}
 
+		r.access.setEvents(len(events))
 		res := processEventsResponse{
 			Events: events,
 		}
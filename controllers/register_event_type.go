@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type eventTypeRegistrar interface {
+	RegisterEventType(ctx context.Context, name, compatibility string, schema json.RawMessage) (models.EventType, error)
+}
+
+type registerEventTypeRequest struct {
+	Name          string          `json:"name" type:"string"`
+	Schema        json.RawMessage `json:"schema" type:"[]byte"`
+	Compatibility string          `json:"compatibility,omitempty" type:"string"`
+}
+
+type eventTypeResponse struct {
+	EventType models.EventType `json:"event_type"`
+}
+
+func (router Router) registerEventType(bus eventTypeRegistrar) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body registerEventTypeRequest
+		err := parseReq(r, &body)
+		if err != nil {
+			transport.SendError(w, registerEventTypeOperation, err)
+			return err
+		}
+
+		eventType, err := bus.RegisterEventType(r.ctx, body.Name, body.Compatibility, body.Schema)
+		if err != nil {
+			transport.SendError(w, registerEventTypeOperation, err)
+			return err
+		}
+
+		res := eventTypeResponse{
+			EventType: eventType,
+		}
+		transport.SendJSON(w, registerEventTypeOperation, res)
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+var testEventType = models.EventType{
+	ID:            "schema-id",
+	Name:          "user_created",
+	Version:       1,
+	Compatibility: "backward",
+	Schema:        json.RawMessage(`{"type":"object"}`),
+	CreatedAt:     testTime,
+}
+
+var testEventTypeJSON = JSON{
+	"id":            "schema-id",
+	"name":          "user_created",
+	"version":       float64(1),
+	"compatibility": "backward",
+	"schema":        JSON{"type": "object"},
+	"created_at":    testTimeStr,
+}
+
+func (s *controllersSuite) Test_RegisterEventType_Success() {
+	expectedRes := transport.Response{
+		Operation: "register_event_type",
+		Status:    true,
+		Body: JSON{
+			"event_type": testEventTypeJSON,
+		},
+	}
+	s.write("register_event_type", `{"name": "user_created", "schema": {"type":"object"}, "compatibility": "backward"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("RegisterEventType", mock.Anything, "user_created", "backward", json.RawMessage(`{"type":"object"}`)).
+		Return(testEventType, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_RegisterEventType_NilReqError() {
+	ctx := JSON{
+		"body": []interface{}{
+			JSON{
+				"name":     "name",
+				"type":     "string",
+				"required": true,
+			},
+			JSON{
+				"name":     "schema",
+				"type":     "[]byte",
+				"required": true,
+			},
+			JSON{
+				"name":     "compatibility",
+				"type":     "string",
+				"required": false,
+			},
+		},
+	}
+	fields := []models.RequiredField{
+		{
+			Name:     "name",
+			Type:     "string",
+			Required: true,
+		},
+		{
+			Name:     "schema",
+			Type:     "[]byte",
+			Required: true,
+		},
+		{
+			Name:     "compatibility",
+			Type:     "string",
+			Required: false,
+		},
+	}
+	s.testNilRequest("register_event_type", ctx, fields)
+}
+
+func (s *controllersSuite) Test_RegisterEventType_ParseReqError() {
+	s.testParseRequest("register_event_type", `{"name": 1}`)
+}
+
+func (s *controllersSuite) Test_RegisterEventType_IncompatibleSchemaError() {
+	incompatibleErr := models.IncompatibleSchemaError{
+		Compatibility: "backward",
+		Version:       1,
+		Violations:    []schema.Violation{{Path: "age", Message: "the new version requires the property"}},
+	}
+	expectedRes := transport.Response{
+		Operation: "register_event_type",
+		Status:    false,
+		Reason:    "schema is not backward compatible with version 1",
+		Context: JSON{
+			"compatibility": "backward",
+			"version":       float64(1),
+			"violations": []interface{}{
+				JSON{"path": "age", "message": "the new version requires the property"},
+			},
+		},
+	}
+	s.write("register_event_type", `{"name": "user_created", "schema": {"required":["age"]}}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("RegisterEventType", mock.Anything, "user_created", "", json.RawMessage(`{"required":["age"]}`)).
+		Return(models.EventType{}, incompatibleErr).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(incompatibleErr, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
@@ -24,12 +24,13 @@ func (router Router) retryEvents(bus eventProcessor) func(io.Writer, request) er
 			return err
 		}
 
-		events, err := bus.ProcessEvents(body.StreamName, true)
+		events, err := bus.ProcessEvents(r.ctx, body.StreamName, true)
 		if err != nil {
 			transport.SendError(w, retryEventsOperation, err)
 			return err
 		}
 
+		r.access.setEvents(len(events))
 		res := retryEventsResponse{
 			Events: events,
 		}
@@ -3,6 +3,8 @@ package controllers
 import (
 	"encoding/json"
 
+	"github.com/stretchr/testify/mock"
+
 	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/transport"
 )
@@ -53,7 +55,7 @@ func (s *controllersSuite) Test_RetryEvents_Success() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("ProcessEvents", "some-stream-name", true).
+		On("ProcessEvents", mock.Anything, "some-stream-name", true).
 		Return(events, nil).
 		Once()
 
@@ -78,7 +80,7 @@ func (s *controllersSuite) Test_RetryEvents_EmptyResult() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("ProcessEvents", "some-stream-name", true).
+		On("ProcessEvents", mock.Anything, "some-stream-name", true).
 		Return([]models.Event{}, nil).
 		Once()
 
@@ -125,7 +127,7 @@ func (s *controllersSuite) Test_RetryEvents_ServiceError() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("ProcessEvents", "some-stream-name", true).
+		On("ProcessEvents", mock.Anything, "some-stream-name", true).
 		Return([]models.Event{}, errTest).
 		Once()
 
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+// subscriptionConn represents a connection the events written to a stream can be pushed on.
+// The events are pushed with the push function once the subscription is acknowledged
+type subscriptionConn interface {
+	Subscribe(streamName string) (push func(w io.Writer), err error)
+}
+
+type subscribeRequest struct {
+	StreamName string `json:"stream_name" type:"string"`
+}
+
+type subscribeResponse struct {
+	StreamName string `json:"stream_name"`
+}
+
+// subscribe subscribes the connection to every event written to the stream from now on,
+// the events are pushed as responses of the subscribe operation until the subscription ends
+func (router Router) subscribe(w io.Writer, r request) error {
+	var body subscribeRequest
+	err := parseReq(r, &body)
+	if err != nil {
+		transport.SendError(w, subscribeOperation, err)
+		return err
+	}
+
+	conn, ok := transport.Unwrap(w).(subscriptionConn)
+	if !ok {
+		e := models.Error{Message: "subscriptions are not supported on this connection"}
+		transport.SendError(w, subscribeOperation, e)
+		return e
+	}
+	push, err := conn.Subscribe(body.StreamName)
+	if err != nil {
+		transport.SendError(w, subscribeOperation, err)
+		return err
+	}
+
+	res := subscribeResponse{
+		StreamName: body.StreamName,
+	}
+	transport.SendJSON(w, subscribeOperation, res)
+	push(w)
+	return nil
+}
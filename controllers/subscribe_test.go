@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_Subscribe_Success() {
+	expectedRes := transport.Response{
+		Operation: "subscribe",
+		Status:    true,
+		Body:      JSON{"stream_name": "s1"},
+	}
+	conn := &subscriptionConnMock{ReadWriter: s.rw}
+	conn.Test(s.T())
+	var pushed bool
+	push := func(w io.Writer) {
+		// the subscription is acknowledged before any event is pushed
+		s.Equal(expectedRes, s.read())
+		pushed = true
+	}
+	s.write("subscribe", `{"stream_name": "s1"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	conn.
+		On("Subscribe", "s1").
+		Return(push, nil).
+		Once()
+
+	exited, err := s.router.Switch(conn, conn)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.True(pushed)
+	conn.AssertExpectations(s.T())
+}
+
+func (s *controllersSuite) Test_Subscribe_StreamNotFoundError() {
+	expectedRes := transport.Response{
+		Operation: "subscribe",
+		Status:    false,
+		Reason:    "stream 's1' not found",
+	}
+	conn := &subscriptionConnMock{ReadWriter: s.rw}
+	conn.Test(s.T())
+	s.write("subscribe", `{"stream_name": "s1"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	conn.
+		On("Subscribe", "s1").
+		Return(nil, models.StreamNotFoundError{Name: "s1"}).
+		Once()
+
+	exited, err := s.router.Switch(conn, conn)
+
+	s.Equal(models.StreamNotFoundError{Name: "s1"}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+	conn.AssertExpectations(s.T())
+}
+
+func (s *controllersSuite) Test_Subscribe_ConnError() {
+	expectedRes := transport.Response{
+		Operation: "subscribe",
+		Status:    false,
+		Reason:    "subscriptions are not supported on this connection",
+	}
+	s.write("subscribe", `{"stream_name": "s1"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.EqualError(err, "subscriptions are not supported on this connection")
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_Subscribe_InvalidJSONError() {
+	expectedRes := transport.Response{
+		Operation: "subscribe",
+		Status:    false,
+		Reason:    "invalid json provided",
+	}
+	s.write("subscribe", `{"stream_name": 1}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.InvalidJSONError{}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type streamUpdater interface {
+	UpdateStream(ctx context.Context, client, streamName string, schema json.RawMessage, quota models.Quota) (models.Stream, error)
+}
+
+type updateStreamRequest struct {
+	StreamName   string          `json:"stream_name" type:"string"`
+	Schema       json.RawMessage `json:"schema,omitempty" type:"[]byte"`
+	MaxEventSize int64           `json:"max_event_size,omitempty" type:"int64"`
+	MaxEvents    int64           `json:"max_events,omitempty" type:"int64"`
+	MaxBytes     int64           `json:"max_bytes,omitempty" type:"int64"`
+}
+
+type updateStreamResponse struct {
+	Stream models.Stream `json:"stream"`
+}
+
+func (router Router) updateStream(bus streamUpdater) func(io.Writer, request) error {
+	return func(w io.Writer, r request) error {
+		var body updateStreamRequest
+		err := parseReq(r, &body)
+		if err != nil {
+			transport.SendError(w, updateStreamOperation, err)
+			return err
+		}
+
+		quota := models.Quota{
+			MaxEventSize: body.MaxEventSize,
+			MaxEvents:    body.MaxEvents,
+			MaxBytes:     body.MaxBytes,
+		}
+		s, err := bus.UpdateStream(r.ctx, r.client, body.StreamName, body.Schema, quota)
+		if err != nil {
+			transport.SendError(w, updateStreamOperation, err)
+			return err
+		}
+
+		res := updateStreamResponse{
+			Stream: s,
+		}
+		transport.SendJSON(w, updateStreamOperation, res)
+		return nil
+	}
+}
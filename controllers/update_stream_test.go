@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+func (s *controllersSuite) Test_UpdateStream_Success() {
+	schema := `{"type": "object"}`
+	expectedStream := models.Stream{
+		ID:        "stream-id",
+		Name:      "some-stream-name",
+		CreatedAt: testTime,
+		Schema:    json.RawMessage(schema),
+	}
+	expectedRes := transport.Response{
+		Operation: "update_stream",
+		Status:    true,
+		Body: JSON{
+			"stream": JSON{
+				"id":         expectedStream.ID,
+				"name":       expectedStream.Name,
+				"created_at": testTimeStr,
+				"schema":     JSON{"type": "object"},
+			},
+		},
+	}
+	s.write("update_stream", `{"stream_name": "some-stream-name", "schema": `+schema+`}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("UpdateStream", mock.Anything, "root", "some-stream-name", json.RawMessage(schema), models.Quota{}).
+		Return(expectedStream, nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_UpdateStream_NilReqError() {
+	ctx := JSON{
+		"body": []interface{}{
+			JSON{
+				"name":     "stream_name",
+				"type":     "string",
+				"required": true,
+			},
+			JSON{
+				"name":     "schema",
+				"type":     "[]byte",
+				"required": false,
+			},
+			JSON{
+				"name":     "max_event_size",
+				"type":     "int64",
+				"required": false,
+			},
+			JSON{
+				"name":     "max_events",
+				"type":     "int64",
+				"required": false,
+			},
+			JSON{
+				"name":     "max_bytes",
+				"type":     "int64",
+				"required": false,
+			},
+		},
+	}
+	fields := []models.RequiredField{
+		{
+			Name:     "stream_name",
+			Type:     "string",
+			Required: true,
+		},
+		{
+			Name:     "schema",
+			Type:     "[]byte",
+			Required: false,
+		},
+		{
+			Name:     "max_event_size",
+			Type:     "int64",
+			Required: false,
+		},
+		{
+			Name:     "max_events",
+			Type:     "int64",
+			Required: false,
+		},
+		{
+			Name:     "max_bytes",
+			Type:     "int64",
+			Required: false,
+		},
+	}
+	s.testNilRequest("update_stream", ctx, fields)
+}
+
+func (s *controllersSuite) Test_UpdateStream_ParseReqError() {
+	s.testParseRequest("update_stream", `{"stream_name": 1}`)
+}
+
+func (s *controllersSuite) Test_UpdateStream_ServiceError() {
+	expectedRes := transport.Response{
+		Operation: "update_stream",
+		Status:    false,
+		Reason:    errTest.Error(),
+	}
+	s.write("update_stream", `{"stream_name": "some-stream-name"}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("UpdateStream", mock.Anything, "root", "some-stream-name", json.RawMessage(nil), models.Quota{}).
+		Return(models.Stream{}, errTest).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(errTest, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
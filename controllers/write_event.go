@@ -1,19 +1,27 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 
+	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/transport"
 )
 
 type eventWriter interface {
-	WriteEvent(streamName string, event json.RawMessage) error
+	WriteEvent(ctx context.Context, client, streamName string, event json.RawMessage) error
+	WriteData(ctx context.Context, client, streamName, contentType string, data []byte) error
+	WriteTypedEvent(ctx context.Context, client, streamName, eventType string, version int, event json.RawMessage) error
 }
 
 type writeEventRequest struct {
-	StreamName string          `json:"stream_name" type:"string"`
-	Event      json.RawMessage `json:"event" type:"[]byte"`
+	StreamName       string          `json:"stream_name" type:"string"`
+	Event            json.RawMessage `json:"event,omitempty" type:"[]byte"`
+	ContentType      string          `json:"content_type,omitempty" type:"string"`
+	Data             []byte          `json:"data,omitempty" type:"base64"`
+	EventType        string          `json:"event_type,omitempty" type:"string"`
+	EventTypeVersion int             `json:"event_type_version,omitempty" type:"int"`
 }
 
 func (router Router) writeEvent(bus eventWriter) func(io.Writer, request) error {
@@ -25,13 +33,31 @@ func (router Router) writeEvent(bus eventWriter) func(io.Writer, request) error
 			return err
 		}
 
-		err = bus.WriteEvent(body.StreamName, body.Event)
+		switch {
+		case body.Data != nil:
+			err = writeData(r.ctx, bus, r.client, body)
+		case body.EventType != "":
+			err = bus.WriteTypedEvent(r.ctx, r.client, body.StreamName, body.EventType, body.EventTypeVersion, body.Event)
+		default:
+			err = bus.WriteEvent(r.ctx, r.client, body.StreamName, body.Event)
+		}
 		if err != nil {
 			transport.SendError(w, writeEventOperation, err)
 			return err
 		}
 
+		r.access.setEvents(1)
 		transport.SendJSON(w, writeEventOperation, nil)
 		return nil
 	}
 }
+
+func writeData(ctx context.Context, bus eventWriter, client string, body writeEventRequest) error {
+	if body.Event != nil {
+		return models.EventPayloadError{}
+	}
+	if body.EventType != "" {
+		return models.EventTypePayloadError{}
+	}
+	return bus.WriteData(ctx, client, body.StreamName, body.ContentType, body.Data)
+}
@@ -3,6 +3,8 @@ package controllers
 import (
 	"encoding/json"
 
+	"github.com/stretchr/testify/mock"
+
 	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/transport"
 )
@@ -19,7 +21,71 @@ func (s *controllersSuite) Test_WriteEvent_Success() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("WriteEvent", "some-stream-name", evtBody).
+		On("WriteEvent", mock.Anything, "root", "some-stream-name", evtBody).
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_WriteEvent_Data() {
+	expectedRes := transport.Response{
+		Operation: "write_event",
+		Status:    true,
+	}
+	s.write("write_event", `{"stream_name": "some-stream-name", "content_type": "application/x-protobuf", "data": "AAEC/w=="}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("WriteData", mock.Anything, "root", "some-stream-name", "application/x-protobuf", []byte{0, 1, 2, 255}).
+		Return(nil).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Require().NoError(err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_WriteEvent_EventPayloadError() {
+	expectedRes := transport.Response{
+		Operation: "write_event",
+		Status:    false,
+		Reason:    "event and data cannot be both provided",
+	}
+	s.write("write_event", `{"stream_name": "some-stream-name", "event": {"k": "v"}, "data": "AAEC/w=="}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.EventPayloadError{}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
+func (s *controllersSuite) Test_WriteEvent_EventType() {
+	expectedRes := transport.Response{
+		Operation: "write_event",
+		Status:    true,
+	}
+	evtBody := json.RawMessage(`{"k": "v"}`)
+	s.write("write_event", `{"stream_name": "some-stream-name", "event": {"k": "v"}, "event_type": "user_created", "event_type_version": 2}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+	s.bus.
+		On("WriteTypedEvent", mock.Anything, "root", "some-stream-name", "user_created", 2, evtBody).
 		Return(nil).
 		Once()
 
@@ -30,6 +96,25 @@ func (s *controllersSuite) Test_WriteEvent_Success() {
 	s.Equal(expectedRes, s.read())
 }
 
+func (s *controllersSuite) Test_WriteEvent_EventTypePayloadError() {
+	expectedRes := transport.Response{
+		Operation: "write_event",
+		Status:    false,
+		Reason:    "event_type can only be provided for json events",
+	}
+	s.write("write_event", `{"stream_name": "some-stream-name", "event_type": "user_created", "data": "AAEC/w=="}`)
+	s.cfg.
+		On("GetAuth").
+		Return(s.auth).
+		Once()
+
+	exited, err := s.router.Switch(s.rw, s.rw)
+
+	s.Equal(models.EventTypePayloadError{}, err)
+	s.False(exited)
+	s.Equal(expectedRes, s.read())
+}
+
 func (s *controllersSuite) Test_WriteEvent_NilReqError() {
 	ctx := JSON{
 		"body": []interface{}{
@@ -41,7 +126,27 @@ func (s *controllersSuite) Test_WriteEvent_NilReqError() {
 			JSON{
 				"name":     "event",
 				"type":     "[]byte",
-				"required": true,
+				"required": false,
+			},
+			JSON{
+				"name":     "content_type",
+				"type":     "string",
+				"required": false,
+			},
+			JSON{
+				"name":     "data",
+				"type":     "base64",
+				"required": false,
+			},
+			JSON{
+				"name":     "event_type",
+				"type":     "string",
+				"required": false,
+			},
+			JSON{
+				"name":     "event_type_version",
+				"type":     "int",
+				"required": false,
 			},
 		},
 	}
@@ -54,7 +159,27 @@ func (s *controllersSuite) Test_WriteEvent_NilReqError() {
 		{
 			Name:     "event",
 			Type:     "[]byte",
-			Required: true,
+			Required: false,
+		},
+		{
+			Name:     "content_type",
+			Type:     "string",
+			Required: false,
+		},
+		{
+			Name:     "data",
+			Type:     "base64",
+			Required: false,
+		},
+		{
+			Name:     "event_type",
+			Type:     "string",
+			Required: false,
+		},
+		{
+			Name:     "event_type_version",
+			Type:     "int",
+			Required: false,
 		},
 	}
 	s.testNilRequest("write_event", ctx, fields)
@@ -76,7 +201,7 @@ func (s *controllersSuite) Test_WriteEvent_ServiceError() {
 		Return(s.auth).
 		Once()
 	s.bus.
-		On("WriteEvent", "some-stream-name", json.RawMessage(nil)).
+		On("WriteEvent", mock.Anything, "root", "some-stream-name", json.RawMessage(nil)).
 		Return(errTest).
 		Once()
 
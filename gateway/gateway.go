@@ -0,0 +1,310 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/services"
+	"github.com/go-web-dev/event-bus/tracing"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type router interface {
+	Switch(io.Writer, io.Reader) (bool, error)
+}
+
+type auth struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+type request struct {
+	Operation string          `json:"operation"`
+	Body      json.RawMessage `json:"body,omitempty"`
+	Auth      auth            `json:"auth"`
+	// TraceContext is the W3C trace context of the traceparent and tracestate headers
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+// ConnectionManager represents the registry of the connections sharing the client limits with the TCP server
+type ConnectionManager interface {
+	Open() (int, error)
+	Connect(transport, remoteAddr string, disconnect func()) (int, error)
+	Authorize(connID int, client string, size int) error
+	AddSubscriptions(connID int, delta int)
+	Release(connID int)
+}
+
+// Subscriber represents the Event Bus subscriptions pushed over the WebSocket connections
+type Subscriber interface {
+	Subscribe(streamName string) (*services.Subscription, error)
+	Unsubscribe(sub *services.Subscription)
+}
+
+// Settings represents the HTTP gateway settings
+type Settings struct {
+	Addr           string
+	Router         router
+	MaxRequestSize int64
+	AllowedOrigins []string
+	// Connections enforces the client limits of the TCP server on the gateway requests, no limits are enforced when nil
+	Connections ConnectionManager
+	// Bus serves the subscriptions of the WebSocket connections, subscribing is not supported when nil
+	Bus Subscriber
+}
+
+// limitedResponse buffers the response of a request,
+// taking the request out of the limits of the client authenticated on it
+type limitedResponse struct {
+	*bytes.Buffer
+	conns  ConnectionManager
+	connID int
+	size   int
+}
+
+// Authorize binds the request to the client authenticated on it and takes it out of the client's limits
+func (r limitedResponse) Authorize(client string) error {
+	return r.conns.Authorize(r.connID, client, r.size)
+}
+
+// Gateway represents the HTTP/JSON gateway exposing every Event Bus operation as a REST endpoint
+// and over a WebSocket connection.
+// Requests are translated into operation requests handled by the same router as the TCP server
+type Gateway struct {
+	router         router
+	maxRequestSize int64
+	conns          ConnectionManager
+	bus            Subscriber
+	server         *http.Server
+	listener       net.Listener
+	upgrader       websocket.Upgrader
+
+	mu         sync.Mutex
+	stopping   bool
+	serveErr   error
+	websockets map[*wsConn]struct{}
+	handlers   sync.WaitGroup
+}
+
+// New creates a new HTTP gateway handler
+func New(settings Settings) *Gateway {
+	g := &Gateway{
+		router:         settings.Router,
+		maxRequestSize: settings.MaxRequestSize,
+		conns:          settings.Connections,
+		bus:            settings.Bus,
+		websockets:     map[*wsConn]struct{}{},
+	}
+	g.upgrader = g.newUpgrader(settings.AllowedOrigins)
+	g.server = &http.Server{Handler: g}
+	return g
+}
+
+// ListenAndServe spins up the HTTP gateway
+func ListenAndServe(settings Settings) (*Gateway, error) {
+	li, err := net.Listen("tcp", settings.Addr)
+	if err != nil {
+		return nil, err
+	}
+	g := New(settings)
+	g.listener = li
+	go func() {
+		logging.Logger.Info("http gateway is up and running on address", zap.String("addr", li.Addr().String()))
+		err := g.server.Serve(li)
+		if err != nil && err != http.ErrServerClosed {
+			logging.Logger.Error("http gateway stopped unexpectedly", zap.Error(err))
+			g.mu.Lock()
+			g.serveErr = err
+			g.mu.Unlock()
+		}
+	}()
+	return g, nil
+}
+
+// Err returns the error the HTTP gateway stopped serving with, nil while serving
+func (g *Gateway) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.serveErr
+}
+
+// Stop gracefully shuts down the HTTP gateway waiting for in-flight requests up to the given timeout.
+// WebSocket connections are closed once their in-flight request is done, or when the timeout is exceeded
+func (g *Gateway) Stop(timeout time.Duration) error {
+	logging.Logger.Info("shutting down the http gateway")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := g.server.Shutdown(ctx)
+	g.closeWebSockets(false)
+
+	done := make(chan struct{})
+	go func() {
+		g.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		logging.Logger.Info("http gateway shutdown timeout exceeded, closing all websocket connections")
+		g.closeWebSockets(true)
+		return ctx.Err()
+	}
+}
+
+// ServeHTTP translates a REST request into an Event Bus operation or upgrades it to a WebSocket connection
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == websocketPath {
+		g.serveWebSocket(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	path := segments(r.URL.Path)
+	pathFound := false
+	for _, rt := range routes {
+		params, ok := rt.match(path)
+		if !ok {
+			continue
+		}
+		pathFound = true
+		if rt.method != r.Method {
+			continue
+		}
+		g.serveOperation(w, r, rt, params)
+		return
+	}
+
+	if pathFound {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		transport.SendError(w, decodeOperation, models.Error{Message: "method not allowed"})
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+	transport.SendError(w, decodeOperation, models.OperationNotFoundError{})
+}
+
+func (g *Gateway) serveOperation(w http.ResponseWriter, r *http.Request, rt route, params map[string]string) {
+	req := request{
+		Operation:    rt.operation,
+		TraceContext: tracing.FromHeaders(r.Header),
+	}
+	req.Auth.ClientID, req.Auth.ClientSecret, _ = r.BasicAuth()
+
+	if rt.body != nil {
+		reqBody, err := g.readBody(w, r)
+		if err != nil {
+			w.WriteHeader(statusCode(err))
+			transport.SendError(w, rt.operation, err)
+			return
+		}
+		body, err := rt.body(params, r.Header.Get("Content-Type"), reqBody)
+		if err != nil {
+			logging.Logger.Debug("could not decode http request body", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			transport.SendError(w, rt.operation, models.InvalidJSONError{})
+			return
+		}
+		if r.Method == http.MethodGet && r.URL.RawQuery != "" {
+			body, err = queryBody(body, r.URL.Query())
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				transport.SendError(w, rt.operation, err)
+				return
+			}
+		}
+		req.Body = body
+	}
+
+	bs, err := json.Marshal(req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		transport.SendError(w, rt.operation, err)
+		return
+	}
+	var res bytes.Buffer
+	var rw io.Writer = &res
+	if g.conns != nil {
+		// every request counts as a client connection while it is served
+		connID, err := g.conns.Open()
+		if err != nil {
+			w.WriteHeader(statusCode(err))
+			transport.SendError(w, rt.operation, err)
+			return
+		}
+		defer g.conns.Release(connID)
+		rw = limitedResponse{Buffer: &res, conns: g.conns, connID: connID, size: len(bs)}
+	}
+	_, err = g.router.Switch(rw, bytes.NewReader(bs))
+	if err != nil {
+		logging.Logger.Debug("http gateway operation error", zap.String("operation", rt.operation), zap.Error(err))
+	}
+	if _, ok := err.(models.AuthError); ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="event-bus"`)
+	}
+	w.WriteHeader(statusCode(err))
+	_, err = res.WriteTo(w)
+	if err != nil {
+		logging.Logger.Error("could not write http response", zap.Error(err))
+	}
+}
+
+func (g *Gateway) readBody(w http.ResponseWriter, r *http.Request) (json.RawMessage, error) {
+	body := r.Body
+	if g.maxRequestSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, g.maxRequestSize)
+	}
+	bs, err := ioutil.ReadAll(body)
+	if err != nil {
+		if g.maxRequestSize > 0 && int64(len(bs)) >= g.maxRequestSize {
+			return nil, models.RequestTooLargeError{MaxSize: int(g.maxRequestSize)}
+		}
+		return nil, err
+	}
+	return bs, nil
+}
+
+// statusCode maps the operation outcome onto the HTTP status code
+func statusCode(err error) int {
+	switch err.(type) {
+	case nil:
+		return http.StatusOK
+	case models.AuthError:
+		return http.StatusUnauthorized
+	case models.ForbiddenError:
+		return http.StatusForbidden
+	case models.StreamNotFoundError, models.EventNotFoundError, models.EventTypeNotFoundError, models.OperationNotFoundError,
+		models.ConnectionNotFoundError:
+		return http.StatusNotFound
+	case models.StreamExistsError, models.IncompatibleSchemaError:
+		return http.StatusConflict
+	case models.RequestTooLargeError, models.EventTooLargeError:
+		return http.StatusRequestEntityTooLarge
+	case models.RateLimitError, models.TooManyConnectionsError:
+		return http.StatusTooManyRequests
+	case models.OperationRequestError, models.InvalidJSONError, models.InvalidEventStatusError, models.EventPayloadError, models.Error:
+		return http.StatusBadRequest
+	case models.QuotaExceededError:
+		return http.StatusInsufficientStorage
+	case models.UnsupportedCompressionError, models.InvalidSchemaError, models.UnsupportedCompatibilityError, models.EventTypePayloadError, models.InvalidQuotaError,
+		models.InvalidImportError, models.InvalidBackupError, models.InvalidPathError:
+		return http.StatusBadRequest
+	case models.EventValidationError:
+		return http.StatusUnprocessableEntity
+	case models.UnhealthyError:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
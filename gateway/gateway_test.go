@@ -0,0 +1,470 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+const (
+	okRes    = `{"operation":"op","status":true}` + "\n"
+	authJSON = `"auth":{"client_id":"id","client_secret":"secret"}`
+)
+
+var (
+	errTest = errors.New("some test error")
+)
+
+type gatewaySuite struct {
+	testutils.Suite
+	router  *routerMock
+	gateway *Gateway
+}
+
+func (s *gatewaySuite) SetupSuite() {
+	logging.Logger = testutils.Logger(s.T(), nil)
+}
+
+func (s *gatewaySuite) SetupTest() {
+	s.router = new(routerMock)
+	s.router.Test(s.T())
+	s.gateway = New(Settings{
+		Router:         s.router,
+		MaxRequestSize: 64,
+	})
+}
+
+func (s *gatewaySuite) TearDownTest() {
+	s.router.AssertExpectations(s.T())
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_Routes() {
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		request     string
+	}{
+		{
+			name:    "Health",
+			method:  http.MethodGet,
+			path:    "/health",
+			request: `{"operation":"health",` + authJSON + `}`,
+		},
+		{
+			name:    "Liveness",
+			method:  http.MethodGet,
+			path:    "/health/live",
+			request: `{"operation":"health",` + authJSON + `}`,
+		},
+		{
+			name:    "Readiness",
+			method:  http.MethodGet,
+			path:    "/health/ready",
+			request: `{"operation":"health","body":{"deep":true},` + authJSON + `}`,
+		},
+		{
+			name:    "CreateStream",
+			method:  http.MethodPost,
+			path:    "/streams",
+			body:    `{"stream_name": "s1"}`,
+			request: `{"operation":"create_stream","body":{"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "ListStreams",
+			method:  http.MethodGet,
+			path:    "/streams",
+			request: `{"operation":"list_streams",` + authJSON + `}`,
+		},
+		{
+			name:    "GetStreamInfo",
+			method:  http.MethodGet,
+			path:    "/streams/s1",
+			request: `{"operation":"get_stream_info","body":{"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "DeleteStream",
+			method:  http.MethodDelete,
+			path:    "/streams/s1/",
+			request: `{"operation":"delete_stream","body":{"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "UpdateStream",
+			method:  http.MethodPatch,
+			path:    "/streams/s1",
+			body:    `{"schema": {"type": "object"}}`,
+			request: `{"operation":"update_stream","body":{"schema":{"type":"object"},"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "GetStreamEvents",
+			method:  http.MethodGet,
+			path:    "/streams/s1/events",
+			request: `{"operation":"get_stream_events","body":{"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "GetStreamEvents_Query",
+			method:  http.MethodGet,
+			path:    "/streams/s1/events?stream_name=s2",
+			request: `{"operation":"get_stream_events","body":{"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "WriteEvent",
+			method:  http.MethodPost,
+			path:    "/streams/s1/events",
+			body:    `{"k": "v"}`,
+			request: `{"operation":"write_event","body":{"event":{"k":"v"},"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:        "WriteEvent_Form",
+			method:      http.MethodPost,
+			path:        "/streams/s1/events",
+			contentType: "application/x-www-form-urlencoded",
+			body:        `{"k": "v"}`,
+			request:     `{"operation":"write_event","body":{"event":{"k":"v"},"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:        "WriteEvent_Data",
+			method:      http.MethodPost,
+			path:        "/streams/s1/events",
+			contentType: "application/x-protobuf; messageType=Test",
+			body:        "\x00\x01\x02",
+			request:     `{"operation":"write_event","body":{"content_type":"application/x-protobuf","data":"AAEC","stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "ProcessEvents",
+			method:  http.MethodPost,
+			path:    "/streams/s1/events/process",
+			request: `{"operation":"process_events","body":{"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "RetryEvents",
+			method:  http.MethodPost,
+			path:    "/streams/s1/events/retry",
+			request: `{"operation":"retry_events","body":{"stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "MarkEvent",
+			method:  http.MethodPost,
+			path:    "/events/e1/mark",
+			body:    `{"status": 1}`,
+			request: `{"operation":"mark_event","body":{"event_id":"e1","status":1},` + authJSON + `}`,
+		},
+		{
+			name:    "RegisterEventType",
+			method:  http.MethodPost,
+			path:    "/event-types",
+			body:    `{"name": "user_created", "schema": {"type": "object"}}`,
+			request: `{"operation":"register_event_type","body":{"name":"user_created","schema":{"type":"object"}},` + authJSON + `}`,
+		},
+		{
+			name:    "ListEventTypes",
+			method:  http.MethodGet,
+			path:    "/event-types",
+			request: `{"operation":"list_event_types","body":{},` + authJSON + `}`,
+		},
+		{
+			name:    "ListEventTypes_Name",
+			method:  http.MethodGet,
+			path:    "/event-types?name=user_created",
+			request: `{"operation":"list_event_types","body":{"name":"user_created"},` + authJSON + `}`,
+		},
+		{
+			name:    "GetEventType_Name",
+			method:  http.MethodGet,
+			path:    "/event-types/user_created",
+			request: `{"operation":"get_event_type","body":{"name":"user_created"},` + authJSON + `}`,
+		},
+		{
+			name:    "GetEventType_Version",
+			method:  http.MethodGet,
+			path:    "/event-types/user_created?version=2&name=other",
+			request: `{"operation":"get_event_type","body":{"name":"user_created","version":2},` + authJSON + `}`,
+		},
+		{
+			name:    "GetEventType",
+			method:  http.MethodGet,
+			path:    "/schemas/schema-id",
+			request: `{"operation":"get_event_type","body":{"schema_id":"schema-id"},` + authJSON + `}`,
+		},
+		{
+			name:    "GetAuditLog",
+			method:  http.MethodGet,
+			path:    "/audit",
+			body:    `{"action":"delete_stream"}`,
+			request: `{"operation":"get_audit_log","body":{"action":"delete_stream"},` + authJSON + `}`,
+		},
+		{
+			name:    "GetAuditLog_Query",
+			method:  http.MethodGet,
+			path:    "/audit?stream_name=s1&since=2021-03-01T00:00:00Z&limit=10",
+			request: `{"operation":"get_audit_log","body":{"limit":10,"since":"2021-03-01T00:00:00Z","stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "ListConnections",
+			method:  http.MethodGet,
+			path:    "/connections",
+			request: `{"operation":"list_connections","body":{},` + authJSON + `}`,
+		},
+		{
+			name:    "Disconnect",
+			method:  http.MethodDelete,
+			path:    "/connections/3",
+			request: `{"operation":"disconnect","body":{"connection_id":3},` + authJSON + `}`,
+		},
+		{
+			name:    "Disconnect_Client",
+			method:  http.MethodDelete,
+			path:    "/clients/go-client/connections",
+			request: `{"operation":"disconnect","body":{"client":"go-client"},` + authJSON + `}`,
+		},
+		{
+			name:    "Backup",
+			method:  http.MethodPost,
+			path:    "/backup",
+			body:    `{"path":"/backups/bus.bak","since":12}`,
+			request: `{"operation":"backup","body":{"path":"/backups/bus.bak","since":12},` + authJSON + `}`,
+		},
+		{
+			name:    "Restore",
+			method:  http.MethodPost,
+			path:    "/restore",
+			body:    `{"path":"/backups/bus.bak"}`,
+			request: `{"operation":"restore","body":{"path":"/backups/bus.bak"},` + authJSON + `}`,
+		},
+		{
+			name:    "ExportStream",
+			method:  http.MethodPost,
+			path:    "/streams/s1/export",
+			body:    `{"path":"/exports/s1.jsonl"}`,
+			request: `{"operation":"export_stream","body":{"path":"/exports/s1.jsonl","stream_name":"s1"},` + authJSON + `}`,
+		},
+		{
+			name:    "ImportStream",
+			method:  http.MethodPost,
+			path:    "/streams/s2/import",
+			body:    `{"path":"/exports/s1.jsonl"}`,
+			request: `{"operation":"import_stream","body":{"path":"/exports/s1.jsonl","stream_name":"s2"},` + authJSON + `}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			s.router.
+				On("Switch", testCase.request).
+				Return(okRes, nil).
+				Once()
+
+			res := s.serveContent(testCase.method, testCase.path, testCase.contentType, testCase.body)
+
+			s.Equal(http.StatusOK, res.Code)
+			s.Equal("application/json", res.Header().Get("Content-Type"))
+			s.Equal(okRes, res.Body.String())
+		})
+	}
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_StatusCodes() {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "AuthError", err: models.AuthError{}, status: http.StatusUnauthorized},
+		{name: "ForbiddenError", err: models.ForbiddenError{}, status: http.StatusForbidden},
+		{name: "ConnectionNotFoundError", err: models.ConnectionNotFoundError{ID: 3}, status: http.StatusNotFound},
+		{name: "StreamNotFoundError", err: models.StreamNotFoundError{Name: "s1"}, status: http.StatusNotFound},
+		{name: "EventNotFoundError", err: models.EventNotFoundError{ID: "e1"}, status: http.StatusNotFound},
+		{name: "StreamExistsError", err: models.StreamExistsError{Name: "s1"}, status: http.StatusConflict},
+		{name: "OperationRequestError", err: models.OperationRequestError{}, status: http.StatusBadRequest},
+		{name: "InvalidEventStatusError", err: models.InvalidEventStatusError{}, status: http.StatusBadRequest},
+		{name: "UnsupportedCompressionError", err: models.UnsupportedCompressionError{}, status: http.StatusBadRequest},
+		{name: "InvalidSchemaError", err: models.InvalidSchemaError{}, status: http.StatusBadRequest},
+		{name: "EventValidationError", err: models.EventValidationError{}, status: http.StatusUnprocessableEntity},
+		{name: "EventTypeNotFoundError", err: models.EventTypeNotFoundError{Name: "t1"}, status: http.StatusNotFound},
+		{name: "IncompatibleSchemaError", err: models.IncompatibleSchemaError{}, status: http.StatusConflict},
+		{name: "UnsupportedCompatibilityError", err: models.UnsupportedCompatibilityError{}, status: http.StatusBadRequest},
+		{name: "EventTooLargeError", err: models.EventTooLargeError{Size: 2, MaxSize: 1}, status: http.StatusRequestEntityTooLarge},
+		{name: "QuotaExceededError", err: models.QuotaExceededError{Stream: "s1"}, status: http.StatusInsufficientStorage},
+		{name: "InvalidQuotaError", err: models.InvalidQuotaError{}, status: http.StatusBadRequest},
+		{name: "InvalidImportError", err: models.InvalidImportError{Line: 2}, status: http.StatusBadRequest},
+		{name: "RateLimitError", err: models.RateLimitError{}, status: http.StatusTooManyRequests},
+		{name: "UnhealthyError", err: models.UnhealthyError{}, status: http.StatusServiceUnavailable},
+		{name: "UnknownError", err: errTest, status: http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			s.router.
+				On("Switch", mock.AnythingOfType("string")).
+				Return(okRes, testCase.err).
+				Once()
+
+			res := s.serve(http.MethodGet, "/streams/s1", "")
+
+			s.Equal(testCase.status, res.Code)
+		})
+	}
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_AuthErrorChallenge() {
+	s.router.
+		On("Switch", mock.AnythingOfType("string")).
+		Return(okRes, models.AuthError{}).
+		Once()
+
+	res := s.serve(http.MethodGet, "/streams/s1", "")
+
+	s.Equal(`Basic realm="event-bus"`, res.Header().Get("WWW-Authenticate"))
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_TraceContext() {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	s.router.
+		On("Switch", `{"operation":"get_stream_info","body":{"stream_name":"s1"},`+authJSON+`,"trace_context":{"traceparent":"`+traceparent+`"}}`).
+		Return(okRes, nil).
+		Once()
+	req := httptest.NewRequest(http.MethodGet, "/streams/s1", nil)
+	req.SetBasicAuth("id", "secret")
+	req.Header.Set("traceparent", traceparent)
+	res := httptest.NewRecorder()
+
+	s.gateway.ServeHTTP(res, req)
+
+	s.Equal(http.StatusOK, res.Code)
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_RateLimitError() {
+	conns := new(connectionsMock)
+	conns.Test(s.T())
+	defer conns.AssertExpectations(s.T())
+	conns.On("Open").Return(4, nil).Once()
+	conns.On("Authorize", 4, "client", mock.AnythingOfType("int")).Return(models.RateLimitError{RetryAfterMS: 250}).Once()
+	conns.On("Release", 4).Once()
+	s.gateway = New(Settings{Router: s.router, Connections: conns})
+	s.router.client = "client"
+
+	res := s.serve(http.MethodGet, "/streams/s1", "")
+
+	s.Equal(http.StatusTooManyRequests, res.Code)
+	s.JSONEq(
+		`{"operation": "op", "status": false, "reason": "rate_limited", "context": {"retry_after_ms": 250}}`,
+		res.Body.String(),
+	)
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_TooManyConnectionsError() {
+	conns := new(connectionsMock)
+	conns.Test(s.T())
+	defer conns.AssertExpectations(s.T())
+	conns.On("Open").Return(0, models.TooManyConnectionsError{}).Once()
+	s.gateway = New(Settings{Router: s.router, Connections: conns})
+
+	res := s.serve(http.MethodGet, "/streams/s1", "")
+
+	s.Equal(http.StatusTooManyRequests, res.Code)
+	s.JSONEq(`{"operation": "get_stream_info", "status": false, "reason": "too many connections"}`, res.Body.String())
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_NotFoundError() {
+	res := s.serve(http.MethodGet, "/does/not/exist", "")
+
+	s.Equal(http.StatusNotFound, res.Code)
+	s.JSONEq(
+		`{"operation": "decode_request", "status": false, "reason": "`+models.OperationNotFoundError{}.Error()+`"}`,
+		res.Body.String(),
+	)
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_MethodNotAllowedError() {
+	res := s.serve(http.MethodPut, "/streams/s1", "")
+
+	s.Equal(http.StatusMethodNotAllowed, res.Code)
+	s.JSONEq(`{"operation": "decode_request", "status": false, "reason": "method not allowed"}`, res.Body.String())
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_InvalidJSONError() {
+	res := s.serve(http.MethodPost, "/streams", "{")
+
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.JSONEq(`{"operation": "create_stream", "status": false, "reason": "invalid json provided"}`, res.Body.String())
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_InvalidQueryError() {
+	res := s.serve(http.MethodGet, "/audit?limit=ten", "")
+
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.JSONEq(`{"operation": "get_audit_log", "status": false, "reason": "invalid query parameter 'limit'"}`, res.Body.String())
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_InvalidConnectionIDError() {
+	res := s.serve(http.MethodDelete, "/connections/abc", "")
+
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.JSONEq(`{"operation": "disconnect", "status": false, "reason": "invalid json provided"}`, res.Body.String())
+}
+
+func (s *gatewaySuite) Test_ServeHTTP_RequestTooLargeError() {
+	res := s.serve(http.MethodPost, "/streams/s1/events", `{"k": "`+strings.Repeat("v", 100)+`"}`)
+
+	s.Equal(http.StatusRequestEntityTooLarge, res.Code)
+	s.JSONEq(
+		`{"operation": "write_event", "status": false, "reason": "request exceeds the maximum size of 64 bytes"}`,
+		res.Body.String(),
+	)
+}
+
+func (s *gatewaySuite) Test_ListenAndServe_Success() {
+	g, err := ListenAndServe(Settings{Addr: "localhost:9700", Router: s.router})
+	s.Require().NoError(err)
+	s.router.
+		On("Switch", `{"operation":"health","auth":{"client_id":"","client_secret":""}}`).
+		Return(okRes, nil).
+		Once()
+
+	res, err := http.Get("http://localhost:9700/health")
+
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, res.StatusCode)
+	s.Equal(okRes, s.ReadAll(res.Body))
+	s.Require().NoError(res.Body.Close())
+	s.NoError(g.Err())
+	s.Require().NoError(g.Stop(time.Second))
+}
+
+func (s *gatewaySuite) Test_ListenAndServe_Error() {
+	g, err := ListenAndServe(Settings{Addr: "9700", Router: s.router})
+
+	s.EqualError(err, "listen tcp: address 9700: missing port in address")
+	s.Nil(g)
+}
+
+func (s *gatewaySuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	return s.serveContent(method, path, "", body)
+}
+
+func (s *gatewaySuite) serveContent(method, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.SetBasicAuth("id", "secret")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res := httptest.NewRecorder()
+	s.gateway.ServeHTTP(res, req)
+	return res
+}
+
+func Test_GatewaySuite(t *testing.T) {
+	suite.Run(t, new(gatewaySuite))
+}
@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+type routerMock struct {
+	mock.Mock
+	exit bool
+	// client is authorized on the writer before the response is written, the way the router does once authenticated
+	client string
+	// stream is subscribed to on the writer, the events being pushed once the response is written
+	stream string
+}
+
+// Switch records the operation request as JSON string and writes back the mocked response
+func (m *routerMock) Switch(w io.Writer, r io.Reader) (bool, error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+	if conn, ok := w.(interface{ Authorize(string) error }); ok && m.client != "" {
+		err := conn.Authorize(m.client)
+		if err != nil {
+			transport.SendError(w, "op", err)
+			return false, err
+		}
+	}
+	var push func(io.Writer)
+	if conn, ok := w.(interface {
+		Subscribe(string) (func(io.Writer), error)
+	}); ok && m.stream != "" {
+		push, err = conn.Subscribe(m.stream)
+		if err != nil {
+			transport.SendError(w, "subscribe", err)
+			return false, err
+		}
+	}
+	args := m.Called(string(bs))
+	_, err = w.Write([]byte(args.String(0)))
+	if err != nil {
+		return m.exit, err
+	}
+	if push != nil {
+		push(w)
+	}
+	return m.exit, args.Error(1)
+}
+
+type connectionsMock struct {
+	mock.Mock
+}
+
+func (m *connectionsMock) Open() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *connectionsMock) Connect(transport, remoteAddr string, disconnect func()) (int, error) {
+	args := m.Called(transport, remoteAddr, disconnect)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *connectionsMock) Authorize(connID int, client string, size int) error {
+	args := m.Called(connID, client, size)
+	return args.Error(0)
+}
+
+func (m *connectionsMock) AddSubscriptions(connID int, delta int) {
+	m.Called(connID, delta)
+}
+
+func (m *connectionsMock) Release(connID int) {
+	m.Called(connID)
+}
@@ -0,0 +1,206 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+// Operations
+const (
+	healthOperation            = "health"
+	createStreamOperation      = "create_stream"
+	deleteStreamOperation      = "delete_stream"
+	updateStreamOperation      = "update_stream"
+	getStreamInfoOperation     = "get_stream_info"
+	listStreamsOperation       = "list_streams"
+	getStreamEventsOperation   = "get_stream_events"
+	writeEventOperation        = "write_event"
+	processEventsOperation     = "process_events"
+	retryEventsOperation       = "retry_events"
+	markEventOperation         = "mark_event"
+	registerEventTypeOperation = "register_event_type"
+	listEventTypesOperation    = "list_event_types"
+	getEventTypeOperation      = "get_event_type"
+	getAuditLogOperation       = "get_audit_log"
+	listConnectionsOperation   = "list_connections"
+	disconnectOperation        = "disconnect"
+	backupOperation            = "backup"
+	restoreOperation           = "restore"
+	exportStreamOperation      = "export_stream"
+	importStreamOperation      = "import_stream"
+	decodeOperation            = "decode_request"
+	connectOperation           = "connect"
+	shutdownOperation          = "shutdown"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// queryTypes lists the query parameters sent as other types than strings in the operation request body
+var queryTypes = map[string]string{
+	"limit":   "int",
+	"version": "int",
+	"deep":    "bool",
+}
+
+// bodyBuilder builds the operation request body out of the path parameters and the HTTP request body
+type bodyBuilder func(params map[string]string, contentType string, body json.RawMessage) (json.RawMessage, error)
+
+type route struct {
+	method    string
+	pattern   []string
+	operation string
+	body      bodyBuilder
+}
+
+var routes = []route{
+	newRoute(http.MethodGet, "/health", healthOperation, nil),
+	newRoute(http.MethodGet, "/health/live", healthOperation, nil),
+	newRoute(http.MethodGet, "/health/ready", healthOperation, readinessBody),
+	newRoute(http.MethodGet, "/streams", listStreamsOperation, nil),
+	newRoute(http.MethodPost, "/streams", createStreamOperation, paramsBody),
+	newRoute(http.MethodGet, "/streams/{stream_name}", getStreamInfoOperation, paramsBody),
+	newRoute(http.MethodDelete, "/streams/{stream_name}", deleteStreamOperation, paramsBody),
+	newRoute(http.MethodPatch, "/streams/{stream_name}", updateStreamOperation, paramsBody),
+	newRoute(http.MethodGet, "/streams/{stream_name}/events", getStreamEventsOperation, paramsBody),
+	newRoute(http.MethodPost, "/streams/{stream_name}/events", writeEventOperation, eventBody),
+	newRoute(http.MethodPost, "/streams/{stream_name}/events/process", processEventsOperation, paramsBody),
+	newRoute(http.MethodPost, "/streams/{stream_name}/events/retry", retryEventsOperation, paramsBody),
+	newRoute(http.MethodPost, "/streams/{stream_name}/export", exportStreamOperation, paramsBody),
+	newRoute(http.MethodPost, "/streams/{stream_name}/import", importStreamOperation, paramsBody),
+	newRoute(http.MethodPost, "/events/{event_id}/mark", markEventOperation, paramsBody),
+	newRoute(http.MethodPost, "/event-types", registerEventTypeOperation, paramsBody),
+	newRoute(http.MethodGet, "/event-types", listEventTypesOperation, paramsBody),
+	newRoute(http.MethodGet, "/event-types/{name}", getEventTypeOperation, paramsBody),
+	newRoute(http.MethodGet, "/schemas/{schema_id}", getEventTypeOperation, paramsBody),
+	newRoute(http.MethodGet, "/audit", getAuditLogOperation, paramsBody),
+	newRoute(http.MethodGet, "/connections", listConnectionsOperation, paramsBody),
+	newRoute(http.MethodDelete, "/connections/{connection_id}", disconnectOperation, connectionBody),
+	newRoute(http.MethodDelete, "/clients/{client}/connections", disconnectOperation, paramsBody),
+	newRoute(http.MethodPost, "/backup", backupOperation, paramsBody),
+	newRoute(http.MethodPost, "/restore", restoreOperation, paramsBody),
+}
+
+func newRoute(method, path, operation string, body bodyBuilder) route {
+	return route{
+		method:    method,
+		pattern:   segments(path),
+		operation: operation,
+		body:      body,
+	}
+}
+
+// match tells whether the path segments match the route pattern and extracts the path parameters
+func (r route) match(path []string) (map[string]string, bool) {
+	if len(path) != len(r.pattern) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, segment := range r.pattern {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[strings.Trim(segment, "{}")] = path[i]
+			continue
+		}
+		if segment != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func segments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// paramsBody merges the path parameters into the JSON object sent as HTTP request body
+func paramsBody(params map[string]string, _ string, body json.RawMessage) (json.RawMessage, error) {
+	fields := map[string]interface{}{}
+	if len(body) > 0 {
+		err := json.Unmarshal(body, &fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range params {
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// queryBody merges the URL query parameters of GET requests into the operation request body.
+// Path parameters and body fields take precedence over the query parameters
+func queryBody(body json.RawMessage, query url.Values) (json.RawMessage, error) {
+	fields := map[string]interface{}{}
+	if len(body) > 0 {
+		err := json.Unmarshal(body, &fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for name, values := range query {
+		if _, ok := fields[name]; ok || len(values) == 0 {
+			continue
+		}
+		value, err := queryValue(name, values[0])
+		if err != nil {
+			return nil, models.Error{Message: fmt.Sprintf("invalid query parameter '%s'", name)}
+		}
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+func queryValue(name, value string) (interface{}, error) {
+	switch queryTypes[name] {
+	case "int":
+		return strconv.Atoi(value)
+	case "bool":
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
+
+// connectionBody sends the connection ID path parameter as a number, connection IDs being numbers
+func connectionBody(params map[string]string, _ string, _ json.RawMessage) (json.RawMessage, error) {
+	id, err := strconv.Atoi(params["connection_id"])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]int{"connection_id": id})
+}
+
+// readinessBody asks for the deep health checks, the Event Bus is ready once all of its components are up
+func readinessBody(map[string]string, string, json.RawMessage) (json.RawMessage, error) {
+	return json.RawMessage(`{"deep":true}`), nil
+}
+
+// eventBody sends the whole HTTP request body as the event to be written.
+// Bodies of any other content type than JSON are written as binary data
+func eventBody(params map[string]string, contentType string, body json.RawMessage) (json.RawMessage, error) {
+	fields := map[string]interface{}{
+		"stream_name": params["stream_name"],
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	// form content type is what most HTTP clients default to, e.g. curl -d, so it is not taken for binary data
+	if contentType == formContentType {
+		contentType = ""
+	}
+	if !models.IsJSONContentType(contentType) {
+		fields["content_type"] = contentType
+		fields["data"] = []byte(body)
+		return json.Marshal(fields)
+	}
+	if len(body) > 0 {
+		fields["event"] = body
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,322 @@
+package gateway
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/services"
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+const (
+	websocketPath      = "/ws"
+	websocketCloseWait = time.Second
+	subscribeOperation = "subscribe"
+)
+
+// wsConn adapts a WebSocket connection to the router writer.
+// Every write is sent out as a single text message, so every response is a message on its own
+type wsConn struct {
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	conns   ConnectionManager
+	connID  int
+	stateMu sync.Mutex
+	busy    bool
+	closing bool
+
+	bus    Subscriber
+	subsMu sync.Mutex
+	subs   map[*services.Subscription]struct{}
+	pushes sync.WaitGroup
+}
+
+// subscriptionEvent is the body of the messages pushing the events of a subscription
+type subscriptionEvent struct {
+	StreamName string       `json:"stream_name"`
+	Event      models.Event `json:"event"`
+}
+
+// wsRequest is the writer a single WebSocket message is switched with,
+// it carries the message size to take it out of the client's limits
+type wsRequest struct {
+	*wsConn
+	size int
+}
+
+// Authorize binds the connection to the client authenticated on it and takes the request out of the client's limits
+func (r wsRequest) Authorize(client string) error {
+	return r.conns.Authorize(r.connID, client, r.size)
+}
+
+// begin marks the connection as serving a request, unless it is being closed
+func (c *wsConn) begin() bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if c.closing {
+		return false
+	}
+	c.busy = true
+	return true
+}
+
+// end marks the request as served, telling whether the connection is to be closed
+func (c *wsConn) end() bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	c.busy = false
+	return c.closing
+}
+
+// shutdown closes the connection right away unless it is serving a request,
+// in which case it gets closed once the request is served
+func (c *wsConn) shutdown() {
+	c.stateMu.Lock()
+	c.closing = true
+	busy := c.busy
+	c.stateMu.Unlock()
+	if !busy {
+		c.close(websocket.CloseGoingAway, shutdownOperation)
+	}
+}
+
+// disconnect notifies the connection it is being disconnected by an admin client and closes it
+func (c *wsConn) disconnect() {
+	transport.SendJSON(c, disconnectOperation, nil)
+	c.close(websocket.CloseNormalClosure, disconnectOperation)
+}
+
+// Write sends out p as a single text message
+func (c *wsConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.conn.WriteMessage(websocket.TextMessage, p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Subscribe subscribes the connection to the events written to the stream,
+// they are pushed on the writer given to push as messages on their own until the subscription ends
+func (c *wsConn) Subscribe(streamName string) (func(w io.Writer), error) {
+	if c.bus == nil {
+		return nil, models.Error{Message: "subscriptions are not supported on this connection"}
+	}
+	sub, err := c.bus.Subscribe(streamName)
+	if err != nil {
+		return nil, err
+	}
+	c.subsMu.Lock()
+	c.subs[sub] = struct{}{}
+	c.subsMu.Unlock()
+	if c.conns != nil {
+		c.conns.AddSubscriptions(c.connID, 1)
+	}
+
+	push := func(w io.Writer) {
+		c.pushes.Add(1)
+		go c.push(w, streamName, sub)
+	}
+	return push, nil
+}
+
+// push sends every event of the subscription as a message on its own.
+// Subscriptions ended by the Event Bus, e.g. lagging ones or the ones of deleted streams, send the reason why
+func (c *wsConn) push(w io.Writer, streamName string, sub *services.Subscription) {
+	defer c.pushes.Done()
+	for evt := range sub.Events() {
+		transport.SendJSON(w, subscribeOperation, subscriptionEvent{StreamName: streamName, Event: evt})
+	}
+
+	c.subsMu.Lock()
+	delete(c.subs, sub)
+	c.subsMu.Unlock()
+	if c.conns != nil {
+		c.conns.AddSubscriptions(c.connID, -1)
+	}
+	if err := sub.Err(); err != nil {
+		transport.SendError(w, subscribeOperation, err)
+	}
+}
+
+// unsubscribe ends the subscriptions of the connection and waits for their pushes to stop
+func (c *wsConn) unsubscribe() {
+	c.subsMu.Lock()
+	subs := make([]*services.Subscription, 0, len(c.subs))
+	for sub := range c.subs {
+		subs = append(subs, sub)
+	}
+	c.subsMu.Unlock()
+
+	for _, sub := range subs {
+		c.bus.Unsubscribe(sub)
+	}
+	c.pushes.Wait()
+}
+
+func (c *wsConn) close(code int, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msg := websocket.FormatCloseMessage(code, text)
+	err := c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(websocketCloseWait))
+	if err != nil && err != websocket.ErrCloseSent {
+		logging.Logger.Debug("could not send websocket close message", zap.Error(err))
+	}
+	err = c.conn.Close()
+	if err != nil {
+		logging.Logger.Debug("could not close websocket connection", zap.Error(err))
+	}
+}
+
+func (g *Gateway) newUpgrader(allowedOrigins []string) websocket.Upgrader {
+	return websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return checkOrigin(r, allowedOrigins)
+		},
+	}
+}
+
+// serveWebSocket handles the WebSocket connection, every message is an operation request
+// following the same JSON envelope as the TCP server
+func (g *Gateway) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger
+	conn, err := g.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Debug("could not upgrade websocket connection", zap.Error(err))
+		return
+	}
+	c := &wsConn{
+		conn:  conn,
+		conns: g.conns,
+		bus:   g.bus,
+		subs:  map[*services.Subscription]struct{}{},
+	}
+	if !g.addWebSocket(c) {
+		c.close(websocket.CloseGoingAway, shutdownOperation)
+		return
+	}
+	defer g.removeWebSocket(c)
+	if g.conns != nil {
+		connID, err := g.conns.Connect(models.WebSocketTransport, conn.RemoteAddr().String(), c.disconnect)
+		if err != nil {
+			transport.SendError(c, connectOperation, err)
+			c.close(websocket.CloseTryAgainLater, err.Error())
+			return
+		}
+		c.connID = connID
+		defer g.conns.Release(c.connID)
+	}
+	// subscriptions end along with the connection, before its slot in the client limits is given back
+	defer c.unsubscribe()
+	if g.maxRequestSize > 0 {
+		conn.SetReadLimit(g.maxRequestSize)
+	}
+
+	logger.Debug("websocket connection established", zap.String("addr", conn.RemoteAddr().String()))
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err == websocket.ErrReadLimit {
+			logger.Debug("request too large", zap.Int64("max_size", g.maxRequestSize))
+			c.close(websocket.CloseMessageTooBig, "")
+			return
+		}
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Debug("websocket connection closed unexpectedly", zap.Error(err))
+			}
+			c.close(websocket.CloseNormalClosure, "")
+			return
+		}
+
+		if !c.begin() {
+			c.close(websocket.CloseGoingAway, shutdownOperation)
+			return
+		}
+		var w io.Writer = c
+		if g.conns != nil {
+			w = wsRequest{wsConn: c, size: len(msg)}
+		}
+		exit, err := g.router.Switch(w, bytes.NewReader(msg))
+		if err != nil {
+			logger.Debug("websocket operation error", zap.Error(err))
+		}
+		if closing := c.end(); closing {
+			c.close(websocket.CloseGoingAway, shutdownOperation)
+			return
+		}
+		if exit {
+			c.close(websocket.CloseNormalClosure, "")
+			return
+		}
+	}
+}
+
+func (g *Gateway) addWebSocket(c *wsConn) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopping {
+		return false
+	}
+	g.websockets[c] = struct{}{}
+	g.handlers.Add(1)
+	return true
+}
+
+func (g *Gateway) removeWebSocket(c *wsConn) {
+	g.mu.Lock()
+	delete(g.websockets, c)
+	g.mu.Unlock()
+	g.handlers.Done()
+}
+
+// closeWebSockets closes all the WebSocket connections, hijacked connections are not closed by the HTTP server.
+// Connections serving a request are closed once it is served, unless force is set
+func (g *Gateway) closeWebSockets(force bool) {
+	g.mu.Lock()
+	g.stopping = true
+	conns := make([]*wsConn, 0, len(g.websockets))
+	for c := range g.websockets {
+		conns = append(conns, c)
+	}
+	g.mu.Unlock()
+
+	for _, c := range conns {
+		if force {
+			c.close(websocket.CloseGoingAway, shutdownOperation)
+			continue
+		}
+		c.shutdown()
+	}
+}
+
+// checkOrigin allows non browser clients, same origin requests and the configured origins
+func checkOrigin(r *http.Request, allowedOrigins []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
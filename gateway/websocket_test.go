@@ -0,0 +1,357 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/services"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+func (s *gatewaySuite) Test_WebSocket_Success() {
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	conn := s.dialWebSocket(srv, nil)
+	defer conn.Close()
+	req1 := `{"operation":"get_stream_info","body":{"stream_name":"s1"}}`
+	req2 := `{"operation":"get_stream_info","body":{"stream_name":"s2"}}`
+	s.router.
+		On("Switch", req1).
+		Return(okRes, nil).
+		Once()
+	s.router.
+		On("Switch", req2).
+		Return(`{"operation":"get_stream_info","status":false}`+"\n", models.StreamNotFoundError{Name: "s2"}).
+		Once()
+
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(req1)))
+	s.Equal(okRes, s.readWebSocket(conn))
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(req2)))
+	s.Equal(`{"operation":"get_stream_info","status":false}`+"\n", s.readWebSocket(conn))
+}
+
+func (s *gatewaySuite) Test_WebSocket_Exit() {
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	conn := s.dialWebSocket(srv, nil)
+	defer conn.Close()
+	req := `{"operation":"exit"}`
+	s.router.
+		On("Switch", req).
+		Return(`{"operation":"exit","status":true}`+"\n", nil).
+		Once()
+	s.router.exit = true
+
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(req)))
+
+	s.Equal(`{"operation":"exit","status":true}`+"\n", s.readWebSocket(conn))
+	_, _, err := conn.ReadMessage()
+	s.True(websocket.IsCloseError(err, websocket.CloseNormalClosure))
+}
+
+func (s *gatewaySuite) Test_WebSocket_RequestTooLargeError() {
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	conn := s.dialWebSocket(srv, nil)
+	defer conn.Close()
+
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("a", 100))))
+
+	_, _, err := conn.ReadMessage()
+	s.True(websocket.IsCloseError(err, websocket.CloseMessageTooBig))
+}
+
+func (s *gatewaySuite) Test_WebSocket_OriginError() {
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	header := http.Header{"Origin": []string{"http://evil.com"}}
+
+	conn, res, err := websocket.DefaultDialer.Dial(s.webSocketURL(srv), header)
+
+	s.Equal(websocket.ErrBadHandshake, err)
+	s.Nil(conn)
+	s.Equal(http.StatusForbidden, res.StatusCode)
+}
+
+func (s *gatewaySuite) Test_WebSocket_Stop() {
+	g, err := ListenAndServe(Settings{Addr: "localhost:9701", Router: s.router})
+	s.Require().NoError(err)
+	conn, _, err := websocket.DefaultDialer.Dial("ws://localhost:9701/ws", nil)
+	s.Require().NoError(err)
+	defer conn.Close()
+	s.Eventually(func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return len(g.websockets) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	s.Require().NoError(g.Stop(time.Second))
+
+	_, _, err = conn.ReadMessage()
+	s.True(websocket.IsCloseError(err, websocket.CloseGoingAway))
+}
+
+func (s *gatewaySuite) Test_WebSocket_Stop_DrainsRequest() {
+	g, err := ListenAndServe(Settings{Addr: "localhost:9702", Router: s.router})
+	s.Require().NoError(err)
+	conn, _, err := websocket.DefaultDialer.Dial("ws://localhost:9702/ws", nil)
+	s.Require().NoError(err)
+	defer conn.Close()
+	req := `{"operation":"get_stream_info","body":{"stream_name":"s1"}}`
+	s.router.
+		On("Switch", req).
+		After(200*time.Millisecond).
+		Return(okRes, nil).
+		Once()
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	s.Eventually(func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for c := range g.websockets {
+			c.stateMu.Lock()
+			defer c.stateMu.Unlock()
+			return c.busy
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	s.Require().NoError(g.Stop(time.Second))
+
+	s.Equal(okRes, s.readWebSocket(conn))
+	_, _, err = conn.ReadMessage()
+	s.True(websocket.IsCloseError(err, websocket.CloseGoingAway))
+}
+
+func (s *gatewaySuite) Test_WebSocket_RateLimitError() {
+	conns := new(connectionsMock)
+	conns.Test(s.T())
+	defer conns.AssertExpectations(s.T())
+	req := `{"operation":"get_stream_info","body":{"stream_name":"s1"}}`
+	conns.On("Connect", models.WebSocketTransport, mock.AnythingOfType("string"), mock.Anything).Return(2, nil).Once()
+	conns.On("Authorize", 2, "client", len(req)).Return(nil).Once()
+	conns.On("Authorize", 2, "client", len(req)).Return(models.RateLimitError{RetryAfterMS: 100}).Once()
+	released := make(chan struct{})
+	conns.On("Release", 2).Run(func(mock.Arguments) { close(released) }).Once()
+	s.gateway = New(Settings{Router: s.router, Connections: conns})
+	s.router.client = "client"
+	s.router.
+		On("Switch", req).
+		Return(okRes, nil).
+		Once()
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	conn := s.dialWebSocket(srv, nil)
+
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	s.Equal(okRes, s.readWebSocket(conn))
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	s.JSONEq(
+		`{"operation": "op", "status": false, "reason": "rate_limited", "context": {"retry_after_ms": 100}}`,
+		s.readWebSocket(conn),
+	)
+	s.Require().NoError(conn.Close())
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		s.Fail("websocket connection not released")
+	}
+}
+
+func (s *gatewaySuite) Test_WebSocket_TooManyConnectionsError() {
+	conns := new(connectionsMock)
+	conns.Test(s.T())
+	defer conns.AssertExpectations(s.T())
+	conns.On("Connect", models.WebSocketTransport, mock.AnythingOfType("string"), mock.Anything).Return(0, models.TooManyConnectionsError{}).Once()
+	s.gateway = New(Settings{Router: s.router, Connections: conns})
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+
+	conn := s.dialWebSocket(srv, nil)
+
+	s.JSONEq(`{"operation": "connect", "status": false, "reason": "too many connections"}`, s.readWebSocket(conn))
+	_, _, err := conn.ReadMessage()
+	s.True(websocket.IsCloseError(err, websocket.CloseTryAgainLater))
+}
+
+func (s *gatewaySuite) Test_WebSocket_Disconnect() {
+	conns := new(connectionsMock)
+	conns.Test(s.T())
+	defer conns.AssertExpectations(s.T())
+	connected := make(chan func(), 1)
+	conns.
+		On("Connect", models.WebSocketTransport, mock.AnythingOfType("string"), mock.Anything).
+		Run(func(args mock.Arguments) { connected <- args.Get(2).(func()) }).
+		Return(2, nil).
+		Once()
+	released := make(chan struct{})
+	conns.On("Release", 2).Run(func(mock.Arguments) { close(released) }).Once()
+	s.gateway = New(Settings{Router: s.router, Connections: conns})
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	conn := s.dialWebSocket(srv, nil)
+
+	disconnect := <-connected
+	disconnect()
+
+	s.JSONEq(`{"operation": "disconnect", "status": true}`, s.readWebSocket(conn))
+	_, _, err := conn.ReadMessage()
+	s.True(websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		s.Fail("websocket connection not released")
+	}
+}
+
+func (s *gatewaySuite) Test_WebSocket_Subscribe_Success() {
+	bus := services.NewBus(testutils.NewBadger(s.T()), services.Quotas{})
+	_, err := bus.CreateStream(context.Background(), "root", "s1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	conns := new(connectionsMock)
+	conns.Test(s.T())
+	defer conns.AssertExpectations(s.T())
+	conns.On("Connect", models.WebSocketTransport, mock.AnythingOfType("string"), mock.Anything).Return(2, nil).Once()
+	conns.On("AddSubscriptions", 2, 1).Once()
+	unsubscribed := make(chan struct{})
+	conns.On("AddSubscriptions", 2, -1).Run(func(mock.Arguments) { close(unsubscribed) }).Once()
+	conns.On("Release", 2).Maybe()
+	s.gateway = New(Settings{Router: s.router, Connections: conns, Bus: bus})
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	conn := s.dialWebSocket(srv, nil)
+	defer conn.Close()
+	req := `{"operation":"subscribe","body":{"stream_name":"s1"}}`
+	s.router.stream = "s1"
+	s.router.
+		On("Switch", req).
+		Return(`{"operation":"subscribe","status":true,"body":{"stream_name":"s1"}}`+"\n", nil).
+		Once()
+
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	s.JSONEq(`{"operation":"subscribe","status":true,"body":{"stream_name":"s1"}}`, s.readWebSocket(conn))
+	s.Require().NoError(bus.WriteEvent(context.Background(), "root", "s1", json.RawMessage(`{"k":"v"}`)))
+
+	var res struct {
+		Operation string            `json:"operation"`
+		Status    bool              `json:"status"`
+		Body      subscriptionEvent `json:"body"`
+	}
+	s.Require().NoError(json.Unmarshal([]byte(s.readWebSocket(conn)), &res))
+	s.Equal("subscribe", res.Operation)
+	s.True(res.Status)
+	s.Equal("s1", res.Body.StreamName)
+	s.JSONEq(`{"k":"v"}`, string(res.Body.Event.Body))
+
+	s.Require().NoError(bus.DeleteStream(context.Background(), "root", "s1"))
+
+	s.JSONEq(`{"operation":"subscribe","status":false,"reason":"stream 's1' not found"}`, s.readWebSocket(conn))
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		s.Fail("websocket subscription not ended")
+	}
+}
+
+func (s *gatewaySuite) Test_WebSocket_Subscribe_Close() {
+	bus := services.NewBus(testutils.NewBadger(s.T()), services.Quotas{})
+	_, err := bus.CreateStream(context.Background(), "root", "s1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	conns := new(connectionsMock)
+	conns.Test(s.T())
+	defer conns.AssertExpectations(s.T())
+	conns.On("Connect", models.WebSocketTransport, mock.AnythingOfType("string"), mock.Anything).Return(2, nil).Once()
+	conns.On("AddSubscriptions", 2, 1).Once()
+	conns.On("AddSubscriptions", 2, -1).Once()
+	released := make(chan struct{})
+	conns.On("Release", 2).Run(func(mock.Arguments) { close(released) }).Once()
+	s.gateway = New(Settings{Router: s.router, Connections: conns, Bus: bus})
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	conn := s.dialWebSocket(srv, nil)
+	req := `{"operation":"subscribe","body":{"stream_name":"s1"}}`
+	s.router.stream = "s1"
+	s.router.
+		On("Switch", req).
+		Return(okRes, nil).
+		Once()
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	s.Equal(okRes, s.readWebSocket(conn))
+
+	s.Require().NoError(conn.Close())
+
+	// the subscriptions end before the connection is released
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		s.Fail("websocket connection not released")
+	}
+}
+
+func (s *gatewaySuite) Test_WebSocket_Subscribe_NotSupportedError() {
+	srv := httptest.NewServer(s.gateway)
+	defer srv.Close()
+	conn := s.dialWebSocket(srv, nil)
+	defer conn.Close()
+	s.router.stream = "s1"
+
+	s.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(`{"operation":"subscribe","body":{"stream_name":"s1"}}`)))
+
+	s.JSONEq(
+		`{"operation":"subscribe","status":false,"reason":"subscriptions are not supported on this connection"}`,
+		s.readWebSocket(conn),
+	)
+}
+
+func (s *gatewaySuite) Test_checkOrigin() {
+	allowed := []string{"http://localhost:3000"}
+	testCases := []struct {
+		name     string
+		origin   string
+		origins  []string
+		expected bool
+	}{
+		{name: "no origin", origin: "", expected: true},
+		{name: "same origin", origin: "http://example.com", expected: true},
+		{name: "allowed origin", origin: "http://localhost:3000", origins: allowed, expected: true},
+		{name: "any origin", origin: "http://localhost:3000", origins: []string{"*"}, expected: true},
+		{name: "other origin", origin: "http://localhost:3001", origins: allowed, expected: false},
+		{name: "invalid origin", origin: "%", origins: allowed, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if testCase.origin != "" {
+				r.Header.Set("Origin", testCase.origin)
+			}
+
+			s.Equal(testCase.expected, checkOrigin(r, testCase.origins))
+		})
+	}
+}
+
+func (s *gatewaySuite) dialWebSocket(srv *httptest.Server, header http.Header) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(s.webSocketURL(srv), header)
+	s.Require().NoError(err)
+	return conn
+}
+
+func (s *gatewaySuite) webSocketURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + websocketPath
+}
+
+func (s *gatewaySuite) readWebSocket(conn *websocket.Conn) string {
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(time.Second)))
+	msgType, msg, err := conn.ReadMessage()
+	s.Require().NoError(err)
+	s.Equal(websocket.TextMessage, msgType)
+	return string(msg)
+}
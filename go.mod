@@ -4,8 +4,23 @@ go 1.15
 
 require (
 	github.com/dgraph-io/badger/v2 v2.2007.2
+	github.com/golang/snappy v0.0.1
 	github.com/google/uuid v1.1.2
+	github.com/gorilla/websocket v1.4.2
+	github.com/klauspost/compress v1.13.6
+	github.com/prometheus/client_golang v1.11.1
 	github.com/spf13/viper v1.3.2
-	github.com/stretchr/testify v1.6.1
+	github.com/stretchr/testify v1.7.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	github.com/xeipuuv/gojsonschema v1.2.0
+	go.opentelemetry.io/otel v1.2.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.2.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.2.0
+	go.opentelemetry.io/otel/sdk v1.2.0
+	go.opentelemetry.io/otel/trace v1.2.0
 	go.uber.org/zap v1.16.0
+	golang.org/x/time v0.0.0-20201208040808-7e3f01d25324
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
+	google.golang.org/grpc v1.42.0
+	google.golang.org/protobuf v1.27.1
 )
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+// probeKey is the key written and read back by the storage check, it expires on its own
+var probeKey = []byte("health:probe")
+
+const probeTTL = time.Minute
+
+// DB represents the Badger database probed by the storage check
+type DB interface {
+	Update(fn func(txn *badger.Txn) error) error
+	View(fn func(txn *badger.Txn) error) error
+}
+
+// Storage checks that Badger can be written to and read from, with a probe key
+func Storage(db DB) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		start := time.Now()
+		value := []byte(strconv.FormatInt(start.UnixNano(), 10))
+		err := db.Update(func(txn *badger.Txn) error {
+			return txn.SetEntry(badger.NewEntry(probeKey, value).WithTTL(probeTTL))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not write probe: %s", err)
+		}
+		var read []byte
+		err = db.View(func(txn *badger.Txn) error {
+			item, err := txn.Get(probeKey)
+			if err != nil {
+				return err
+			}
+			read, err = item.ValueCopy(nil)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not read probe: %s", err)
+		}
+		if !bytes.Equal(read, value) {
+			return nil, errors.New("probe read back does not match the written one")
+		}
+		return map[string]interface{}{"latency_ms": time.Since(start).Milliseconds()}, nil
+	}
+}
+
+// Disk checks that the disk holding the directory has at least the given free bytes left, zero meaning any
+func Disk(dir string, minFree uint64) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		free, total, err := diskSpace(dir)
+		if err != nil {
+			return nil, err
+		}
+		details := map[string]interface{}{
+			"free_bytes":  free,
+			"total_bytes": total,
+		}
+		if free < minFree {
+			return details, fmt.Errorf("%d free bytes left, below the minimum of %d", free, minFree)
+		}
+		return details, nil
+	}
+}
+
+// Goroutines checks that the number of goroutines stays below the given maximum, zero meaning unlimited
+func Goroutines(max int) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		count := runtime.NumGoroutine()
+		details := map[string]interface{}{"count": count}
+		if max > 0 && count > max {
+			return details, fmt.Errorf("%d goroutines running, above the maximum of %d", count, max)
+		}
+		return details, nil
+	}
+}
+
+// Job checks a background job, such as a server, reporting the error the job stopped with, if any
+func Job(jobErr func() error) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, jobErr()
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package health
+
+import (
+	"syscall"
+)
+
+// diskSpace gets the free and total bytes of the disk holding the directory
+func diskSpace(dir string) (uint64, uint64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(dir, &stat)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), uint64(stat.Blocks) * uint64(stat.Bsize), nil
+}
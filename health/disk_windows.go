@@ -0,0 +1,10 @@
+package health
+
+import (
+	"errors"
+)
+
+// diskSpace is not supported on Windows, the disk check reports the component down
+func diskSpace(string) (uint64, uint64, error) {
+	return 0, 0, errors.New("disk space check is not supported on windows")
+}
@@ -0,0 +1,129 @@
+// Package health runs the deep health checks of the Event Bus components
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+// Check checks a component, giving back the details worth reporting and an error when the component is down
+type Check func(ctx context.Context) (map[string]interface{}, error)
+
+// reportTTL is how long a health report is reused for, so that probes cannot run the checks any more often
+const reportTTL = time.Second
+
+// Checker runs the registered checks, every check being given up to the checker timeout
+type Checker struct {
+	timeout time.Duration
+	mu      sync.RWMutex
+	checks  map[string]Check
+	// runMu serializes the checks, the callers waiting on a running check getting its report
+	runMu     sync.Mutex
+	reportTTL time.Duration
+	checkedAt time.Time
+	report    models.Health
+}
+
+// New creates a new health checker with no checks registered
+func New(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout:   timeout,
+		checks:    map[string]Check{},
+		reportTTL: reportTTL,
+	}
+}
+
+// Register registers the check of a component, replacing any check registered under the same name
+func (c *Checker) Register(name string, check Check) {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+	c.checkedAt = time.Time{}
+}
+
+// Check gives back the health report of the last second, if any, running the checks otherwise
+func (c *Checker) Check(ctx context.Context) models.Health {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.reportTTL {
+		return c.report
+	}
+	c.report = c.check(ctx)
+	c.checkedAt = time.Now()
+	return c.report
+}
+
+// check runs every check concurrently. The Event Bus is up when every component is up,
+// checks not done within the timeout are reported down
+func (c *Checker) check(ctx context.Context) models.Health {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	health := models.Health{
+		Status:     models.HealthUp,
+		Components: make(map[string]models.ComponentHealth, len(checks)),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			component := run(ctx, check)
+			mu.Lock()
+			defer mu.Unlock()
+			health.Components[name] = component
+			if component.Status != models.HealthUp {
+				health.Status = models.HealthDown
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return health
+}
+
+// run runs a check, giving up on it once the context is done
+func run(ctx context.Context, check Check) models.ComponentHealth {
+	type result struct {
+		details map[string]interface{}
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		details, err := check(ctx)
+		done <- result{details: details, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		component := models.ComponentHealth{
+			Status:  models.HealthUp,
+			Details: res.details,
+		}
+		if res.err != nil {
+			component.Status = models.HealthDown
+			component.Error = res.err.Error()
+		}
+		return component
+	case <-ctx.Done():
+		return models.ComponentHealth{
+			Status: models.HealthDown,
+			Error:  ctx.Err().Error(),
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+type healthSuite struct {
+	testutils.Suite
+	checker *Checker
+}
+
+func (s *healthSuite) SetupTest() {
+	s.checker = New(100 * time.Millisecond)
+}
+
+func (s *healthSuite) Test_Check_Up() {
+	s.checker.Register("a", func(context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"k": "v"}, nil
+	})
+	s.checker.Register("b", func(context.Context) (map[string]interface{}, error) {
+		return nil, nil
+	})
+
+	health := s.checker.Check(context.Background())
+
+	s.Equal(models.Health{
+		Status: models.HealthUp,
+		Components: map[string]models.ComponentHealth{
+			"a": {Status: models.HealthUp, Details: map[string]interface{}{"k": "v"}},
+			"b": {Status: models.HealthUp},
+		},
+	}, health)
+}
+
+func (s *healthSuite) Test_Check_Down() {
+	s.checker.Register("a", func(context.Context) (map[string]interface{}, error) {
+		return nil, nil
+	})
+	s.checker.Register("b", func(context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"count": 3}, errors.New("some error")
+	})
+
+	health := s.checker.Check(context.Background())
+
+	s.Equal(models.HealthDown, health.Status)
+	s.Equal(models.HealthUp, health.Components["a"].Status)
+	s.Equal(models.ComponentHealth{
+		Status:  models.HealthDown,
+		Details: map[string]interface{}{"count": 3},
+		Error:   "some error",
+	}, health.Components["b"])
+}
+
+func (s *healthSuite) Test_Check_Timeout() {
+	release := make(chan struct{})
+	defer close(release)
+	s.checker.Register("slow", func(context.Context) (map[string]interface{}, error) {
+		<-release
+		return nil, nil
+	})
+
+	health := s.checker.Check(context.Background())
+
+	s.Equal(models.HealthDown, health.Status)
+	s.Equal(models.ComponentHealth{
+		Status: models.HealthDown,
+		Error:  context.DeadlineExceeded.Error(),
+	}, health.Components["slow"])
+}
+
+func (s *healthSuite) Test_Check_NoChecks() {
+	health := s.checker.Check(context.Background())
+
+	s.Equal(models.HealthUp, health.Status)
+	s.Empty(health.Components)
+}
+
+func (s *healthSuite) Test_Check_ReusesReport() {
+	runs := 0
+	s.checker.Register("a", func(context.Context) (map[string]interface{}, error) {
+		runs++
+		return nil, nil
+	})
+
+	s.checker.Check(context.Background())
+	health := s.checker.Check(context.Background())
+
+	s.Equal(1, runs)
+	s.Equal(models.HealthUp, health.Status)
+	s.checker.reportTTL = 0
+	s.checker.Check(context.Background())
+	s.Equal(2, runs)
+}
+
+func (s *healthSuite) Test_Check_Register_DropsReport() {
+	s.checker.Check(context.Background())
+
+	s.checker.Register("a", func(context.Context) (map[string]interface{}, error) {
+		return nil, errors.New("down")
+	})
+
+	s.Equal(models.HealthDown, s.checker.Check(context.Background()).Status)
+}
+
+func (s *healthSuite) Test_Storage() {
+	db := testutils.NewBadger(s.T())
+
+	details, err := Storage(db)(context.Background())
+
+	s.Require().NoError(err)
+	s.Contains(details, "latency_ms")
+}
+
+func (s *healthSuite) Test_Storage_ClosedError() {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	s.Require().NoError(err)
+	s.Require().NoError(db.Close())
+
+	details, err := Storage(db)(context.Background())
+
+	s.EqualError(err, "could not write probe: DB Closed")
+	s.Nil(details)
+}
+
+func (s *healthSuite) Test_Disk() {
+	details, err := Disk(os.TempDir(), 1)(context.Background())
+
+	s.Require().NoError(err)
+	s.Contains(details, "free_bytes")
+	s.Contains(details, "total_bytes")
+}
+
+func (s *healthSuite) Test_Disk_LowSpaceError() {
+	details, err := Disk(os.TempDir(), 1<<62)(context.Background())
+
+	s.Require().Error(err)
+	s.Contains(err.Error(), "below the minimum of 4611686018427387904")
+	s.Contains(details, "free_bytes")
+}
+
+func (s *healthSuite) Test_Disk_Error() {
+	_, err := Disk("does_not_exist", 0)(context.Background())
+
+	s.Error(err)
+}
+
+func (s *healthSuite) Test_Goroutines() {
+	details, err := Goroutines(0)(context.Background())
+
+	s.Require().NoError(err)
+	s.Contains(details, "count")
+
+	_, err = Goroutines(1)(context.Background())
+
+	s.Require().Error(err)
+	s.Contains(err.Error(), "above the maximum of 1")
+}
+
+func (s *healthSuite) Test_Job() {
+	_, err := Job(func() error { return nil })(context.Background())
+
+	s.NoError(err)
+}
+
+func (s *healthSuite) Test_Job_Error() {
+	_, err := Job(func() error { return errors.New("stopped") })(context.Background())
+
+	s.EqualError(err, "stopped")
+}
+
+func Test_HealthSuite(t *testing.T) {
+	suite.Run(t, new(healthSuite))
+}
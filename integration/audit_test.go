@@ -0,0 +1,41 @@
+//+build integration
+
+package integration
+
+import (
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *appSuite) Test_GetAuditLog_Success() {
+	conn := s.newConn()
+	s.write(conn, "create_stream", `{"stream_name": "audited-stream"}`)
+	var res response
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+	s.write(conn, "delete_stream", `{"stream_name": "audited-stream"}`)
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+
+	s.write(conn, "get_audit_log", `{"stream_name": "audited-stream"}`)
+	s.read(conn, &res)
+	s.False(res.Status)
+	s.Equal("forbidden to make request", res.Reason)
+	s.auth = `{"client_id": "admin_client_id", "client_secret": "admin_client_secret"}`
+	adminConn := s.newConn()
+
+	s.write(adminConn, "get_audit_log", `{"stream_name": "audited-stream"}`)
+
+	s.read(adminConn, &res)
+	s.Equal("get_audit_log", res.Operation)
+	s.True(res.Status)
+	var body struct {
+		Entries []models.AuditEntry `json:"entries"`
+	}
+	s.JSONUnmarshal(res.Body, &body)
+	s.Require().Len(body.Entries, 2)
+	s.Equal(models.AuditCreateStream, body.Entries[0].Action)
+	s.Equal("integration", body.Entries[0].Client)
+	s.Equal(models.AuditDeleteStream, body.Entries[1].Action)
+	s.Equal("integration", body.Entries[1].Client)
+	s.Equal(map[string]string{"events": "0"}, body.Entries[1].Details)
+}
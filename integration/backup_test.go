@@ -0,0 +1,112 @@
+//+build integration
+
+package integration
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+func (s *appSuite) Test_BackupRestore_RoundTrip() {
+	path := "roundtrip/event-bus.bak"
+	conn := s.newConn()
+	integrationAuth := s.auth
+	s.auth = `{"client_id": "admin_client_id", "client_secret": "admin_client_secret"}`
+	adminConn := s.newConn()
+
+	s.write(adminConn, "backup", fmt.Sprintf(`{"path": "%s"}`, path))
+
+	var res response
+	s.read(adminConn, &res)
+	s.Equal("backup", res.Operation)
+	s.Require().True(res.Status)
+	var body struct {
+		Path    string `json:"path"`
+		Version uint64 `json:"version"`
+	}
+	s.JSONUnmarshal(res.Body, &body)
+	s.Equal(path, body.Path)
+	s.NotZero(body.Version)
+	s.FileExists(filepath.Join(s.backupDir, path))
+
+	s.auth = integrationAuth
+	s.write(conn, "delete_stream", `{"stream_name": "s1-name"}`)
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+	s.write(conn, "create_stream", `{"stream_name": "after-backup"}`)
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+
+	s.auth = `{"client_id": "admin_client_id", "client_secret": "admin_client_secret"}`
+	s.write(adminConn, "restore", fmt.Sprintf(`{"path": "%s"}`, path))
+
+	s.read(adminConn, &res)
+	s.Equal("restore", res.Operation)
+	s.Require().True(res.Status)
+	s.write(adminConn, "get_audit_log", `{"action": "restore"}`)
+	s.read(adminConn, &res)
+	s.True(res.Status)
+	s.Contains(string(res.Body), `"client":"admin"`)
+
+	s.auth = integrationAuth
+	s.write(conn, "get_stream_events", `{"stream_name": "s1-name"}`)
+	s.assertGetStreamEventsRes(conn)
+	s.write(conn, "get_stream_info", `{"stream_name": "after-backup"}`)
+	s.read(conn, &res)
+	s.False(res.Status)
+	s.Equal("stream 'after-backup' not found", res.Reason)
+}
+
+func (s *appSuite) Test_BackupRestore_Incremental() {
+	fullPath := "incremental/full.bak"
+	incrementalPath := "incremental/incremental.bak"
+	conn := s.newConn()
+	integrationAuth := s.auth
+	s.auth = `{"client_id": "admin_client_id", "client_secret": "admin_client_secret"}`
+	adminConn := s.newConn()
+	var res response
+	var body struct {
+		Version uint64 `json:"version"`
+	}
+	s.write(adminConn, "backup", fmt.Sprintf(`{"path": "%s"}`, fullPath))
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	s.JSONUnmarshal(res.Body, &body)
+	s.auth = integrationAuth
+	s.write(conn, "write_event", `{"stream_name": "s2-name", "event": {"after": "full backup"}}`)
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+	s.auth = `{"client_id": "admin_client_id", "client_secret": "admin_client_secret"}`
+
+	s.write(adminConn, "backup", fmt.Sprintf(`{"path": "%s", "since": %d}`, incrementalPath, body.Version))
+
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	s.write(adminConn, "restore", fmt.Sprintf(`{"path": "%s"}`, fullPath))
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	s.write(adminConn, "get_stream_info", `{"stream_name": "s2-name"}`)
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	s.Contains(string(res.Body), `"events":1`)
+	s.write(adminConn, "restore", fmt.Sprintf(`{"path": "%s", "incremental": true}`, incrementalPath))
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	s.write(adminConn, "get_stream_info", `{"stream_name": "s2-name"}`)
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	s.Contains(string(res.Body), `"events":2`)
+	s.auth = integrationAuth
+}
+
+func (s *appSuite) Test_Backup_ForbiddenError() {
+	conn := s.newConn()
+
+	s.write(conn, "backup", `{"path": "event-bus.bak"}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("backup", res.Operation)
+	s.False(res.Status)
+	s.Equal("forbidden to make request", res.Reason)
+}
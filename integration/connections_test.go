@@ -0,0 +1,68 @@
+//+build integration
+
+package integration
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *appSuite) Test_Connections_ListAndDisconnect() {
+	conn := s.newConn()
+	s.write(conn, "list_streams", "")
+	var res response
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+	integrationAuth := s.auth
+	s.auth = `{"client_id": "admin_client_id", "client_secret": "admin_client_secret"}`
+	adminConn := s.newConn()
+
+	s.write(adminConn, "list_connections", "")
+
+	s.read(adminConn, &res)
+	s.Equal("list_connections", res.Operation)
+	s.True(res.Status)
+	var body struct {
+		Connections []models.Connection `json:"connections"`
+	}
+	s.JSONUnmarshal(res.Body, &body)
+	clients := map[string]int64{}
+	for _, c := range body.Connections {
+		clients[c.Client] = c.Requests
+	}
+	s.Equal(int64(1), clients["integration"])
+	s.Equal(int64(1), clients["admin"])
+
+	s.write(adminConn, "disconnect", `{"client": "integration"}`)
+
+	s.read(adminConn, &res)
+	s.Equal("disconnect", res.Operation)
+	s.True(res.Status)
+	reader := bufio.NewReader(conn)
+	notice, err := reader.ReadBytes('\n')
+	s.Require().NoError(err)
+	s.JSONEq(`{"operation": "disconnect", "status": true}`, string(notice))
+	_, err = reader.ReadByte()
+	s.Equal(io.EOF, err)
+
+	s.write(adminConn, "get_audit_log", `{"action": "disconnect"}`)
+
+	s.read(adminConn, &res)
+	s.True(res.Status)
+	s.Contains(string(res.Body), `"client":"integration"`)
+	s.auth = integrationAuth
+}
+
+func (s *appSuite) Test_Connections_ForbiddenError() {
+	conn := s.newConn()
+
+	s.write(conn, "list_connections", "")
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("list_connections", res.Operation)
+	s.False(res.Status)
+	s.Equal("forbidden to make request", res.Reason)
+}
@@ -23,6 +23,39 @@ func (s *appSuite) Test_CreateStream_Success() {
 	s.assertCreateStreamRes(conn, "test-stream")
 }
 
+func (s *appSuite) Test_CreateStream_Compression() {
+	conn := s.newConn()
+
+	s.write(conn, "create_stream", `{"stream_name": "compressed-stream", "compression": "snappy"}`)
+
+	s.assertCreateStreamRes(conn, "compressed-stream")
+	s.write(conn, "write_event", `{"stream_name": "compressed-stream", "event": {"e1-field": "e1-value"}}`)
+	s.assertWriteEventRes(conn)
+	s.write(conn, "get_stream_events", `{"stream_name": "compressed-stream"}`)
+	var res response
+	s.read(conn, &res)
+	s.True(res.Status)
+	var body getStreamEventsResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	s.Require().Len(body.Events, 1)
+	s.JSONEq(`{"e1-field": "e1-value"}`, string(body.Events[0].Body))
+	values := s.dbFetch("event:" + body.Events[0].StreamID)
+	s.Require().Len(values, 1)
+	// compressed events are prefixed with a compression marker and the algorithm ID
+	s.Equal([]byte{2, 1}, values[0][:2])
+}
+
+func (s *appSuite) Test_CreateStream_UnsupportedCompressionError() {
+	conn := s.newConn()
+
+	s.write(conn, "create_stream", `{"stream_name": "compressed-stream", "compression": "gzip"}`)
+
+	var res response
+	s.read(conn, &res)
+	s.False(res.Status)
+	s.Equal("compression must be one of: 'snappy', 'zstd'", res.Reason)
+}
+
 func (s *appSuite) Test_CreateStream_MissingBodyError() {
 	expectedErrCtx := JSON{
 		"body": []interface{}{
@@ -31,6 +64,31 @@ func (s *appSuite) Test_CreateStream_MissingBodyError() {
 				"required": true,
 				"type":     "string",
 			},
+			JSON{
+				"name":     "compression",
+				"required": false,
+				"type":     "string",
+			},
+			JSON{
+				"name":     "schema",
+				"required": false,
+				"type":     "[]byte",
+			},
+			JSON{
+				"name":     "max_event_size",
+				"required": false,
+				"type":     "int64",
+			},
+			JSON{
+				"name":     "max_events",
+				"required": false,
+				"type":     "int64",
+			},
+			JSON{
+				"name":     "max_bytes",
+				"required": false,
+				"type":     "int64",
+			},
 		},
 	}
 	conn := s.newConn()
@@ -0,0 +1,202 @@
+//+build integration
+
+package integration
+
+import (
+	"net"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+const (
+	testSchemaV1 = `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"], "additionalProperties": false}`
+	testSchemaV2 = `{"type": "object", "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}, "required": ["name"]}`
+)
+
+type eventTypeResponseBody struct {
+	EventType models.EventType `json:"event_type"`
+}
+
+type listEventTypesResponseBody struct {
+	EventTypes []models.EventType `json:"event_types"`
+}
+
+func (s *appSuite) Test_RegisterEventType_Success() {
+	conn := s.newConn()
+
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV1+`}`)
+	v1 := s.assertRegisterEventTypeRes(conn, "user_created", 1)
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV2+`}`)
+	v2 := s.assertRegisterEventTypeRes(conn, "user_created", 2)
+
+	s.Equal("backward", v1.Compatibility)
+	s.Equal("backward", v2.Compatibility)
+	s.NotEqual(v1.ID, v2.ID)
+	s.JSONEq(testSchemaV2, string(v2.Schema))
+}
+
+func (s *appSuite) Test_RegisterEventType_SameSchema() {
+	conn := s.newConn()
+
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV1+`}`)
+	v1 := s.assertRegisterEventTypeRes(conn, "user_created", 1)
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV1+`}`)
+	same := s.assertRegisterEventTypeRes(conn, "user_created", 1)
+
+	s.Equal(v1.ID, same.ID)
+}
+
+func (s *appSuite) Test_RegisterEventType_IncompatibleSchemaError() {
+	conn := s.newConn()
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV1+`}`)
+	s.assertRegisterEventTypeRes(conn, "user_created", 1)
+
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": {"type": "object", "required": ["name", "age"]}}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("register_event_type", res.Operation)
+	s.False(res.Status)
+	s.Equal("schema is not backward compatible with version 1", res.Reason)
+	s.Equal(JSON{
+		"compatibility": "backward",
+		"version":       float64(1),
+		"violations": []interface{}{
+			JSON{"path": "age", "message": "the new version requires the property"},
+		},
+	}, res.Context)
+	s.Empty(res.Body)
+}
+
+func (s *appSuite) Test_RegisterEventType_UnsupportedCompatibilityError() {
+	conn := s.newConn()
+
+	s.write(conn, "register_event_type", `{"name": "user_created", "compatibility": "sideways", "schema": `+testSchemaV1+`}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("register_event_type", res.Operation)
+	s.False(res.Status)
+	s.Equal("compatibility must be one of: 'backward', 'forward', 'full', 'none'", res.Reason)
+	s.Empty(res.Body)
+}
+
+func (s *appSuite) Test_ListEventTypes_Success() {
+	conn := s.newConn()
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV1+`}`)
+	s.assertRegisterEventTypeRes(conn, "user_created", 1)
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV2+`}`)
+	s.assertRegisterEventTypeRes(conn, "user_created", 2)
+	s.write(conn, "register_event_type", `{"name": "order_placed", "schema": {"type": "object"}}`)
+	s.assertRegisterEventTypeRes(conn, "order_placed", 1)
+
+	s.write(conn, "list_event_types", "")
+	latest := s.assertListEventTypesRes(conn)
+	s.write(conn, "list_event_types", `{"name": "user_created"}`)
+	versions := s.assertListEventTypesRes(conn)
+
+	s.Require().Len(latest, 2)
+	s.Equal("order_placed", latest[0].Name)
+	s.Equal("user_created", latest[1].Name)
+	s.Equal(2, latest[1].Version)
+	s.Require().Len(versions, 2)
+	s.Equal(1, versions[0].Version)
+	s.Equal(2, versions[1].Version)
+}
+
+func (s *appSuite) Test_GetEventType_Success() {
+	conn := s.newConn()
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV1+`}`)
+	v1 := s.assertRegisterEventTypeRes(conn, "user_created", 1)
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV2+`}`)
+	v2 := s.assertRegisterEventTypeRes(conn, "user_created", 2)
+
+	s.write(conn, "get_event_type", `{"name": "user_created"}`)
+	s.Equal(v2.ID, s.assertGetEventTypeRes(conn).ID)
+	s.write(conn, "get_event_type", `{"name": "user_created", "version": 1}`)
+	s.Equal(v1.ID, s.assertGetEventTypeRes(conn).ID)
+	s.write(conn, "get_event_type", `{"schema_id": "`+v1.ID+`"}`)
+	s.Equal(1, s.assertGetEventTypeRes(conn).Version)
+}
+
+func (s *appSuite) Test_GetEventType_EventTypeNotFoundError() {
+	conn := s.newConn()
+
+	s.write(conn, "get_event_type", `{"name": "user_created", "version": 3}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("get_event_type", res.Operation)
+	s.False(res.Status)
+	s.Equal("event type 'user_created' version 3 not found", res.Reason)
+	s.Empty(res.Body)
+}
+
+func (s *appSuite) Test_WriteEvent_EventType() {
+	conn := s.newConn()
+	s.write(conn, "register_event_type", `{"name": "user_created", "schema": `+testSchemaV1+`}`)
+	eventType := s.assertRegisterEventTypeRes(conn, "user_created", 1)
+
+	s.write(conn, "write_event", `{"stream_name": "s3-name", "event_type": "user_created", "event": {"name": 1}}`)
+	s.assertEventValidationErr(conn, JSON{
+		"path":    "name",
+		"message": "Invalid type. Expected: string, given: integer",
+	})
+	s.write(conn, "write_event", `{"stream_name": "s3-name", "event_type": "user_created", "event": {"name": "steve"}}`)
+	s.assertWriteEventRes(conn)
+
+	s.write(conn, "get_stream_events", `{"stream_name": "s3-name"}`)
+	var res response
+	s.read(conn, &res)
+	s.True(res.Status)
+	var body getStreamEventsResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	s.Require().Len(body.Events, 1)
+	s.Equal(eventType.ID, body.Events[0].SchemaID)
+}
+
+func (s *appSuite) Test_WriteEvent_EventTypeNotFoundError() {
+	conn := s.newConn()
+
+	s.write(conn, "write_event", `{"stream_name": "s3-name", "event_type": "user_created", "event": {}}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("write_event", res.Operation)
+	s.False(res.Status)
+	s.Equal("event type 'user_created' not found", res.Reason)
+	s.Empty(res.Body)
+}
+
+func (s *appSuite) assertRegisterEventTypeRes(conn net.Conn, name string, version int) models.EventType {
+	var res response
+	s.read(conn, &res)
+	s.Equal("register_event_type", res.Operation)
+	s.Require().True(res.Status, res.Reason)
+	var body eventTypeResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	s.Equal(name, body.EventType.Name)
+	s.Equal(version, body.EventType.Version)
+	s.NotEmpty(body.EventType.ID)
+	return body.EventType
+}
+
+func (s *appSuite) assertListEventTypesRes(conn net.Conn) []models.EventType {
+	var res response
+	s.read(conn, &res)
+	s.Equal("list_event_types", res.Operation)
+	s.Require().True(res.Status, res.Reason)
+	var body listEventTypesResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	return body.EventTypes
+}
+
+func (s *appSuite) assertGetEventTypeRes(conn net.Conn) models.EventType {
+	var res response
+	s.read(conn, &res)
+	s.Equal("get_event_type", res.Operation)
+	s.Require().True(res.Status, res.Reason)
+	var body eventTypeResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	return body.EventType
+}
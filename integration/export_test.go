@@ -0,0 +1,104 @@
+//+build integration
+
+package integration
+
+import (
+	"fmt"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *appSuite) Test_ExportImportStream_RoundTrip() {
+	path := "exported.jsonl"
+	conn := s.newConn()
+	var res response
+	s.write(conn, "create_stream", `{"stream_name": "exported", "compression": "snappy"}`)
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+	for i := 0; i < 3; i++ {
+		s.write(conn, "write_event", fmt.Sprintf(`{"stream_name": "exported", "event": {"i": %d}}`, i))
+		s.read(conn, &res)
+		s.Require().True(res.Status)
+	}
+	s.write(conn, "get_stream_events", `{"stream_name": "exported"}`)
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+	var written getStreamEventsResponseBody
+	s.JSONUnmarshal(res.Body, &written)
+	s.Require().Len(written.Events, 3)
+	s.write(conn, "mark_event", fmt.Sprintf(`{"event_id": "%s", "status": 1}`, written.Events[0].ID))
+	s.read(conn, &res)
+	integrationAuth := s.auth
+	s.auth = `{"client_id": "admin_client_id", "client_secret": "admin_client_secret"}`
+	adminConn := s.newConn()
+
+	s.write(adminConn, "export_stream", fmt.Sprintf(`{"stream_name": "exported", "path": "%s"}`, path))
+
+	s.read(adminConn, &res)
+	s.Equal("export_stream", res.Operation)
+	s.Require().True(res.Status)
+	s.JSONEq(fmt.Sprintf(`{"path": "%s", "events": 3}`, path), string(res.Body))
+	s.write(adminConn, "get_stream_events", `{"stream_name": "exported"}`)
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	var exported getStreamEventsResponseBody
+	s.JSONUnmarshal(res.Body, &exported)
+	s.write(adminConn, "delete_stream", `{"stream_name": "exported"}`)
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+
+	s.write(adminConn, "import_stream", fmt.Sprintf(`{"path": "%s"}`, path))
+
+	s.read(adminConn, &res)
+	s.Equal("import_stream", res.Operation)
+	s.Require().True(res.Status)
+	var result models.ImportResult
+	s.JSONUnmarshal(res.Body, &result)
+	s.Equal("exported", result.Stream.Name)
+	s.Equal("snappy", result.Stream.Compression)
+	s.Equal(3, result.Imported)
+	s.Zero(result.Renamed)
+	s.write(adminConn, "get_stream_events", `{"stream_name": "exported"}`)
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	var imported getStreamEventsResponseBody
+	s.JSONUnmarshal(res.Body, &imported)
+	s.Require().Len(imported.Events, 3)
+	byID := map[string]models.Event{}
+	for _, evt := range imported.Events {
+		byID[evt.ID] = evt
+	}
+	for _, evt := range exported.Events {
+		s.Require().Contains(byID, evt.ID)
+		s.Equal(evt.Status, byID[evt.ID].Status)
+		s.Equal(evt.CreatedAt, byID[evt.ID].CreatedAt)
+		s.JSONEq(string(evt.Body), string(byID[evt.ID].Body))
+		s.Equal(result.Stream.ID, byID[evt.ID].StreamID)
+	}
+
+	s.write(adminConn, "import_stream", fmt.Sprintf(`{"path": "%s", "stream_name": "copy"}`, path))
+
+	s.read(adminConn, &res)
+	s.Require().True(res.Status)
+	s.JSONUnmarshal(res.Body, &result)
+	s.Equal("copy", result.Stream.Name)
+	s.Equal(3, result.Imported)
+	s.Equal(3, result.Renamed)
+	s.write(adminConn, "get_audit_log", `{"action": "import_stream"}`)
+	s.read(adminConn, &res)
+	s.True(res.Status)
+	s.Contains(string(res.Body), `"stream":"copy"`)
+	s.auth = integrationAuth
+}
+
+func (s *appSuite) Test_ExportStream_ForbiddenError() {
+	conn := s.newConn()
+
+	s.write(conn, "export_stream", `{"stream_name": "s1-name", "path": "s1.jsonl"}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("export_stream", res.Operation)
+	s.False(res.Status)
+	s.Equal("forbidden to make request", res.Reason)
+}
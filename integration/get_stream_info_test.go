@@ -55,8 +55,14 @@ func (s *appSuite) Test_GetStreamInfo_Concurrent() {
 }
 
 func (s *appSuite) assertGetStreamInfoRes(conn net.Conn) {
+	stream := s1
+	stream.Usage = &models.Usage{}
+	for _, evt := range []models.Event{s1evt1, s1evt2, s1evt3, s1evt4} {
+		stream.Usage.Events++
+		stream.Usage.Bytes += int64(len(evt.Body))
+	}
 	expectedResBody := getStreamInfoResponseBody{
-		Stream: s1,
+		Stream: stream,
 	}
 	var res response
 	s.read(conn, &res)
@@ -0,0 +1,90 @@
+//+build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-web-dev/event-bus/controllers"
+	"github.com/go-web-dev/event-bus/gateway"
+)
+
+func (s *appSuite) Test_HTTPGateway_WriteEvent_Success() {
+	srv := s.newHTTPGateway()
+	defer srv.Close()
+
+	res := s.httpRequest(srv, http.MethodPost, "/streams/s1-name/events", `{"field": "value"}`)
+	s.Equal(http.StatusOK, res.StatusCode)
+	var writeRes response
+	s.decodeHTTPResponse(res, &writeRes)
+	s.Equal("write_event", writeRes.Operation)
+	s.True(writeRes.Status)
+
+	res = s.httpRequest(srv, http.MethodGet, "/streams/s1-name/events", "")
+	s.Equal(http.StatusOK, res.StatusCode)
+	var eventsRes response
+	s.decodeHTTPResponse(res, &eventsRes)
+	s.Equal("get_stream_events", eventsRes.Operation)
+	s.True(eventsRes.Status)
+	s.Contains(string(eventsRes.Body), `"field":"value"`)
+}
+
+func (s *appSuite) Test_HTTPGateway_StreamNotFoundError() {
+	srv := s.newHTTPGateway()
+	defer srv.Close()
+
+	res := s.httpRequest(srv, http.MethodGet, "/streams/does-not-exist", "")
+
+	s.Equal(http.StatusNotFound, res.StatusCode)
+	var infoRes response
+	s.decodeHTTPResponse(res, &infoRes)
+	s.Equal("get_stream_info", infoRes.Operation)
+	s.False(infoRes.Status)
+	s.Equal("stream 'does-not-exist' not found", infoRes.Reason)
+}
+
+func (s *appSuite) Test_HTTPGateway_AuthError() {
+	srv := s.newHTTPGateway()
+	defer srv.Close()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/streams/s1-name", nil)
+	s.Require().NoError(err)
+	req.SetBasicAuth("integration", "wrong")
+
+	res, err := http.DefaultClient.Do(req)
+
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, res.StatusCode)
+	s.Require().NoError(res.Body.Close())
+}
+
+func (s *appSuite) newHTTPGateway() *httptest.Server {
+	router := controllers.NewRouter(s.bus, s.cfg, nil, nil)
+	g := gateway.New(gateway.Settings{
+		Router:         router,
+		MaxRequestSize: maxRequestSize,
+	})
+	return httptest.NewServer(g)
+}
+
+func (s *appSuite) httpRequest(srv *httptest.Server, method, path, body string) *http.Response {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, srv.URL+path, r)
+	s.Require().NoError(err)
+	integrationClient := s.cfg.GetAuth()["integration"]
+	req.SetBasicAuth(integrationClient.ClientID, integrationClient.ClientSecret)
+	res, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	return res
+}
+
+func (s *appSuite) decodeHTTPResponse(res *http.Response, v interface{}) {
+	defer res.Body.Close()
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(v))
+}
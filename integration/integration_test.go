@@ -4,9 +4,13 @@ package integration
 
 import (
 	"bufio"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -14,19 +18,26 @@ import (
 	"github.com/dgraph-io/badger/v2"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/go-web-dev/event-bus/compression"
 	"github.com/go-web-dev/event-bus/config"
 	"github.com/go-web-dev/event-bus/controllers"
+	"github.com/go-web-dev/event-bus/health"
 	"github.com/go-web-dev/event-bus/logging"
 	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/server"
 	"github.com/go-web-dev/event-bus/services"
 	"github.com/go-web-dev/event-bus/testutils"
+	"github.com/go-web-dev/event-bus/transport"
 )
 
 const (
 	addr        = "localhost:8000"
 	testCfgFile = "./config_integration_test.yaml"
 	testTimeStr = "2020-12-15T05:28:31.490416Z"
+
+	maxRequestSize       = 1 << 20
+	maxPipelined         = 16
+	compressionThreshold = 256
 )
 
 var (
@@ -84,14 +95,26 @@ var (
 	operations = []string{
 		"create_stream",
 		"delete_stream",
+		"update_stream",
 		"get_stream_info",
+		"list_streams",
 		"get_stream_events",
 		"write_event",
 		"mark_event",
 		"process_events",
 		"retry_events",
+		"register_event_type",
+		"list_event_types",
+		"get_event_type",
+		"get_audit_log",
+		"list_connections",
+		"disconnect",
+		"backup",
+		"restore",
+		"export_stream",
+		"import_stream",
 	}
-	opNotFoundReason = "operation must be one of: 'health', 'create_stream', 'delete_stream', 'get_stream_info', 'get_stream_events', 'write_event', 'process_events', 'retry_events', 'retry_events', 'mark_event', 'exit'"
+	opNotFoundReason = "operation must be one of: 'health', 'hello', 'create_stream', 'delete_stream', 'update_stream', 'get_stream_info', 'list_streams', 'get_stream_events', 'write_event', 'process_events', 'retry_events', 'retry_events', 'mark_event', 'register_event_type', 'list_event_types', 'get_event_type', 'get_audit_log', 'list_connections', 'disconnect', 'backup', 'restore', 'export_stream', 'import_stream', 'exit'"
 )
 
 type JSON = map[string]interface{}
@@ -102,6 +125,7 @@ type response struct {
 	Reason    string          `json:"reason,omitempty"`
 	Body      json.RawMessage `json:"body,omitempty"`
 	Context   interface{}     `json:"context,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
 }
 
 type appSuite struct {
@@ -112,6 +136,8 @@ type appSuite struct {
 	db     *badger.DB
 	bus    *services.Bus
 	wg     sync.WaitGroup
+	// backupDir holds the backup and export files of the tests
+	backupDir string
 }
 
 func (s *appSuite) SetupSuite() {
@@ -120,17 +146,28 @@ func (s *appSuite) SetupSuite() {
 	s.Require().NoError(err)
 	s.cfg = cfg
 	s.db = testutils.NewBadger(s.T())
-	s.bus = services.NewBus(s.db)
-	router := controllers.NewRouter(s.bus, cfg)
+	s.bus = services.NewBus(s.db, services.Quotas{})
+	s.backupDir, err = ioutil.TempDir("", "event-bus-integration")
+	s.Require().NoError(err)
+	s.bus.SetBackupDir(s.backupDir)
+	checker := health.New(time.Second)
+	checker.Register("storage", health.Storage(s.db))
+	conns := server.NewConnections()
+	router := controllers.NewRouter(s.bus, cfg, checker, conns)
 	settings := server.Settings{
-		Addr:     addr,
-		Router:   router,
-		DB:       s.db,
-		Deadline: 500 * time.Millisecond,
+		Addr:           addr,
+		Router:         router,
+		DB:             s.db,
+		Deadline:       500 * time.Millisecond,
+		MaxRequestSize:       maxRequestSize,
+		MaxPipelined:         maxPipelined,
+		CompressionThreshold: compressionThreshold,
+		Connections:          conns,
 	}
 	srv, err := server.ListenAndServe(settings)
 	s.Require().NoError(err)
 	s.server = srv
+	checker.Register("server", srv.Check)
 	s.waitForServer()
 }
 
@@ -150,6 +187,7 @@ func (s *appSuite) SetupTest() {
 
 func (s *appSuite) TearDownSuite() {
 	s.Require().NoError(s.server.Stop())
+	s.Require().NoError(os.RemoveAll(s.backupDir))
 }
 
 func (s *appSuite) Test_Health() {
@@ -164,6 +202,23 @@ func (s *appSuite) Test_Health() {
 	s.Empty(res.Body)
 }
 
+func (s *appSuite) Test_Health_Deep() {
+	conn := s.newConn()
+
+	s.write(conn, "health", `{"deep": true}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("health", res.Operation)
+	s.True(res.Status)
+	var body models.Health
+	s.JSONUnmarshal(res.Body, &body)
+	s.Equal(models.HealthUp, body.Status)
+	s.Equal(models.HealthUp, body.Components["storage"].Status)
+	s.Equal(models.HealthUp, body.Components["server"].Status)
+	s.Contains(body.Components["server"].Details, "connections")
+}
+
 func (s *appSuite) Test_Exit() {
 	conn := s.newConn()
 
@@ -176,6 +231,97 @@ func (s *appSuite) Test_Exit() {
 	s.Empty(res.Body)
 }
 
+func (s *appSuite) Test_PipelinedRequests() {
+	conn := s.newConn()
+	reqCount := 10
+	for i := 0; i < reqCount; i++ {
+		req := fmt.Sprintf(
+			`{"operation": "write_event", "request_id": "req-%d", "auth": %s, "body": {"stream_name": "s1-name", "event": {"i": %d}}}`,
+			i,
+			s.auth,
+			i,
+		)
+		_, err := conn.Write([]byte(req + "\n"))
+		s.Require().NoError(err)
+	}
+
+	reader := bufio.NewReader(conn)
+	requestIDs := map[string]bool{}
+	for i := 0; i < reqCount; i++ {
+		bs, err := reader.ReadBytes('\n')
+		s.Require().NoError(err)
+		var res response
+		s.JSONUnmarshal(bs, &res)
+		s.Equal("write_event", res.Operation)
+		s.True(res.Status)
+		requestIDs[res.RequestID] = true
+	}
+	s.Len(requestIDs, reqCount)
+	for i := 0; i < reqCount; i++ {
+		s.True(requestIDs[fmt.Sprintf("req-%d", i)])
+	}
+}
+
+func (s *appSuite) Test_Hello_MsgPack() {
+	conn := s.newConn()
+	s.write(conn, "hello", `{"encoding": "msgpack"}`)
+	var helloRes response
+	s.read(conn, &helloRes)
+	s.Require().True(helloRes.Status)
+	s.JSONEq(`{"encoding": "msgpack"}`, string(helloRes.Body))
+
+	msgPackCodec, _ := transport.GetCodec(transport.MsgPackEncoding)
+	var auth map[string]interface{}
+	s.JSONUnmarshal([]byte(s.auth), &auth)
+	msg, err := msgPackCodec.Marshal(map[string]interface{}{
+		"operation": "get_stream_info",
+		"auth":      auth,
+		"body":      map[string]interface{}{"stream_name": "s1-name"},
+	})
+	s.Require().NoError(err)
+	s.writeFrame(conn, msg, false)
+
+	msg, _ = s.readFrame(bufio.NewReader(conn))
+	var res response
+	s.Require().NoError(msgPackCodec.Unmarshal(msg, &res))
+	s.Equal("get_stream_info", res.Operation)
+	s.True(res.Status)
+	var body getStreamInfoResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	s.Equal(s1.Name, body.Stream.Name)
+}
+
+func (s *appSuite) Test_Hello_Compression() {
+	conn := s.newConn()
+	s.write(conn, "hello", `{"encoding": "json", "compression": "zstd"}`)
+	var helloRes response
+	s.read(conn, &helloRes)
+	s.Require().True(helloRes.Status)
+	reader := bufio.NewReader(conn)
+
+	req := fmt.Sprintf(`{"operation": "get_stream_info", "auth": %s, "body": {"stream_name": "s1-name"}}`, s.auth)
+	compressed, err := compression.Compress("zstd", []byte(req))
+	s.Require().NoError(err)
+	s.writeFrame(conn, compressed, true)
+	msg, isCompressed := s.readFrame(reader)
+	s.False(isCompressed)
+	var infoRes response
+	s.JSONUnmarshal(msg, &infoRes)
+	s.True(infoRes.Status)
+
+	s.writeFrame(conn, []byte(fmt.Sprintf(`{"operation": "get_stream_events", "auth": %s, "body": {"stream_name": "s1-name"}}`, s.auth)), false)
+	msg, isCompressed = s.readFrame(reader)
+	s.True(isCompressed)
+	msg, err = compression.Decompress("zstd", msg, 0)
+	s.Require().NoError(err)
+	var eventsRes response
+	s.JSONUnmarshal(msg, &eventsRes)
+	s.True(eventsRes.Status)
+	var body getStreamEventsResponseBody
+	s.JSONUnmarshal(eventsRes.Body, &body)
+	s.Len(body.Events, 4)
+}
+
 func (s *appSuite) Test_Operations_DecodeRequestError() {
 	conn := s.newConn()
 	ops := append(operations, "health", "exit")
@@ -240,6 +386,31 @@ func (s *appSuite) Test_Operations_ParseRequestBody_MissingFieldsError() {
 						"type":     "string",
 						"required": true,
 					},
+					JSON{
+						"name":     "compression",
+						"type":     "string",
+						"required": false,
+					},
+					JSON{
+						"name":     "schema",
+						"type":     "[]byte",
+						"required": false,
+					},
+					JSON{
+						"name":     "max_event_size",
+						"type":     "int64",
+						"required": false,
+					},
+					JSON{
+						"name":     "max_events",
+						"type":     "int64",
+						"required": false,
+					},
+					JSON{
+						"name":     "max_bytes",
+						"type":     "int64",
+						"required": false,
+					},
 				},
 			},
 		},
@@ -255,6 +426,38 @@ func (s *appSuite) Test_Operations_ParseRequestBody_MissingFieldsError() {
 				},
 			},
 		},
+		{
+			operation: "update_stream",
+			context: JSON{
+				"body": []interface{}{
+					JSON{
+						"name":     "stream_name",
+						"type":     "string",
+						"required": true,
+					},
+					JSON{
+						"name":     "schema",
+						"type":     "[]byte",
+						"required": false,
+					},
+					JSON{
+						"name":     "max_event_size",
+						"type":     "int64",
+						"required": false,
+					},
+					JSON{
+						"name":     "max_events",
+						"type":     "int64",
+						"required": false,
+					},
+					JSON{
+						"name":     "max_bytes",
+						"type":     "int64",
+						"required": false,
+					},
+				},
+			},
+		},
 		{
 			operation: "get_stream_info",
 			context: JSON{
@@ -291,7 +494,27 @@ func (s *appSuite) Test_Operations_ParseRequestBody_MissingFieldsError() {
 					JSON{
 						"name":     "event",
 						"type":     "[]byte",
-						"required": true,
+						"required": false,
+					},
+					JSON{
+						"name":     "content_type",
+						"type":     "string",
+						"required": false,
+					},
+					JSON{
+						"name":     "data",
+						"type":     "base64",
+						"required": false,
+					},
+					JSON{
+						"name":     "event_type",
+						"type":     "string",
+						"required": false,
+					},
+					JSON{
+						"name":     "event_type_version",
+						"type":     "int",
+						"required": false,
 					},
 				},
 			},
@@ -337,6 +560,50 @@ func (s *appSuite) Test_Operations_ParseRequestBody_MissingFieldsError() {
 				},
 			},
 		},
+		{
+			operation: "register_event_type",
+			context: JSON{
+				"body": []interface{}{
+					JSON{
+						"name":     "name",
+						"type":     "string",
+						"required": true,
+					},
+					JSON{
+						"name":     "schema",
+						"type":     "[]byte",
+						"required": true,
+					},
+					JSON{
+						"name":     "compatibility",
+						"type":     "string",
+						"required": false,
+					},
+				},
+			},
+		},
+		{
+			operation: "get_event_type",
+			context: JSON{
+				"body": []interface{}{
+					JSON{
+						"name":     "name",
+						"type":     "string",
+						"required": false,
+					},
+					JSON{
+						"name":     "version",
+						"type":     "int",
+						"required": false,
+					},
+					JSON{
+						"name":     "schema_id",
+						"type":     "string",
+						"required": false,
+					},
+				},
+			},
+		},
 	}
 	conn := s.newConn()
 	for _, req := range requests {
@@ -409,6 +676,28 @@ func (s *appSuite) write(conn net.Conn, operation, body string) {
 	s.Require().NoError(err)
 }
 
+func (s *appSuite) writeFrame(conn net.Conn, msg []byte, compressed bool) {
+	length := uint32(len(msg))
+	if compressed {
+		length |= 1 << 31
+	}
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, length)
+	_, err := conn.Write(append(header, msg...))
+	s.Require().NoError(err)
+}
+
+func (s *appSuite) readFrame(reader *bufio.Reader) ([]byte, bool) {
+	header := make([]byte, 4)
+	_, err := io.ReadFull(reader, header)
+	s.Require().NoError(err)
+	length := binary.BigEndian.Uint32(header)
+	msg := make([]byte, length&^(1<<31))
+	_, err = io.ReadFull(reader, msg)
+	s.Require().NoError(err)
+	return msg, length&(1<<31) != 0
+}
+
 func (s *appSuite) read(conn net.Conn, res interface{}) {
 	bs, err := bufio.NewReader(conn).ReadBytes('\n')
 	s.Require().NoError(err)
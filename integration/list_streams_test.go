@@ -0,0 +1,32 @@
+//+build integration
+
+package integration
+
+import (
+	"github.com/go-web-dev/event-bus/models"
+)
+
+type listStreamsResponseBody struct {
+	Streams []models.Stream `json:"streams"`
+}
+
+func (s *appSuite) Test_ListStreams_Success() {
+	conn := s.newConn()
+
+	s.write(conn, "list_streams", "")
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("list_streams", res.Operation)
+	s.True(res.Status)
+	s.Empty(res.Reason)
+	var body listStreamsResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	s.Require().Len(body.Streams, 3)
+	s.Equal([]string{s1.Name, s2.Name, s3.Name}, []string{body.Streams[0].Name, body.Streams[1].Name, body.Streams[2].Name})
+	s.Equal(s1.ID, body.Streams[0].ID)
+	s.Require().NotNil(body.Streams[0].Usage)
+	s.Equal(int64(4), body.Streams[0].Usage.Events)
+	s.Require().NotNil(body.Streams[2].Usage)
+	s.Zero(body.Streams[2].Usage.Events)
+}
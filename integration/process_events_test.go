@@ -16,6 +16,26 @@ func (s *appSuite) Test_ProcessEvents_Success() {
 	s.assertProcessEventsRes(conn)
 }
 
+func (s *appSuite) Test_ProcessEvents_TraceContext() {
+	conn := s.newConn()
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := `{"operation": "write_event", "auth": ` + s.auth + `, "body": {"stream_name": "s3-name", "event": {}}, ` +
+		`"trace_context": {"traceparent": "` + traceparent + `"}}` + "\n"
+	_, err := conn.Write([]byte(req))
+	s.Require().NoError(err)
+	s.assertWriteEventRes(conn)
+
+	s.write(conn, "process_events", `{"stream_name": "s3-name"}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Require().True(res.Status, res.Reason)
+	var body getStreamEventsResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	s.Require().Len(body.Events, 1)
+	s.Equal(map[string]string{"traceparent": traceparent}, body.Events[0].TraceContext)
+}
+
 func (s *appSuite) Test_ProcessEvents_StreamNotFoundError() {
 	conn := s.newConn()
 
@@ -0,0 +1,84 @@
+//+build integration
+
+package integration
+
+import (
+	"net"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *appSuite) Test_WriteEvent_StreamQuota() {
+	conn := s.newConn()
+	s.write(conn, "create_stream", `{"stream_name": "quota-stream", "max_event_size": 12, "max_events": 1}`)
+	s.assertCreateStreamRes(conn, "quota-stream")
+
+	s.write(conn, "write_event", `{"stream_name": "quota-stream", "event": {"name": "steve"}}`)
+	s.assertWriteEventErr(conn, "event of 16 bytes exceeds the maximum event size of 12 bytes", nil)
+	s.write(conn, "write_event", `{"stream_name": "quota-stream", "event": {"k": "v"}}`)
+	s.assertWriteEventRes(conn)
+	s.write(conn, "write_event", `{"stream_name": "quota-stream", "event": {"k": "v"}}`)
+	s.assertWriteEventErr(conn, "stream 'quota-stream' quota exceeded: max_events is 1", JSON{
+		"stream": "quota-stream",
+		"quota":  "max_events",
+		"limit":  float64(1),
+	})
+
+	stream := s.getStreamInfo(conn, "quota-stream")
+	s.Equal(models.Quota{MaxEventSize: 12, MaxEvents: 1}, stream.Quota)
+	s.Equal(&models.Usage{Events: 1, Bytes: 9}, stream.Usage)
+}
+
+func (s *appSuite) Test_UpdateStream_Quota() {
+	conn := s.newConn()
+	s.write(conn, "update_stream", `{"stream_name": "s1-name", "max_events": 4}`)
+	var res response
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+
+	s.write(conn, "write_event", `{"stream_name": "s1-name", "event": {"k": "v"}}`)
+	s.assertWriteEventErr(conn, "stream 's1-name' quota exceeded: max_events is 4", JSON{
+		"stream": "s1-name",
+		"quota":  "max_events",
+		"limit":  float64(4),
+	})
+
+	s.write(conn, "update_stream", `{"stream_name": "s1-name"}`)
+	s.read(conn, &res)
+	s.Require().True(res.Status)
+	s.write(conn, "write_event", `{"stream_name": "s1-name", "event": {"k": "v"}}`)
+	s.assertWriteEventRes(conn)
+	s.Equal(int64(5), s.getStreamInfo(conn, "s1-name").Usage.Events)
+}
+
+func (s *appSuite) Test_UpdateStream_InvalidQuotaError() {
+	conn := s.newConn()
+
+	s.write(conn, "update_stream", `{"stream_name": "s1-name", "max_bytes": -1}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("update_stream", res.Operation)
+	s.False(res.Status)
+	s.Equal("max_event_size, max_events and max_bytes must not be negative", res.Reason)
+}
+
+func (s *appSuite) assertWriteEventErr(conn net.Conn, reason string, ctx interface{}) {
+	var res response
+	s.read(conn, &res)
+	s.Equal("write_event", res.Operation)
+	s.False(res.Status)
+	s.Equal(reason, res.Reason)
+	s.Equal(ctx, res.Context)
+	s.Empty(res.Body)
+}
+
+func (s *appSuite) getStreamInfo(conn net.Conn, streamName string) models.Stream {
+	s.write(conn, "get_stream_info", `{"stream_name": "`+streamName+`"}`)
+	var res response
+	s.read(conn, &res)
+	s.Require().True(res.Status, res.Reason)
+	var body getStreamInfoResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	return body.Stream
+}
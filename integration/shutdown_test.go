@@ -0,0 +1,170 @@
+//+build integration
+
+package integration
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/config"
+	"github.com/go-web-dev/event-bus/controllers"
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/server"
+	"github.com/go-web-dev/event-bus/services"
+	"github.com/go-web-dev/event-bus/storage"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+const (
+	shutdownAddr    = "localhost:8001"
+	shutdownClients = 5
+)
+
+type shutdownSuite struct {
+	testutils.Suite
+	cfg      *config.Manager
+	settings storage.Settings
+}
+
+func (s *shutdownSuite) SetupSuite() {
+	logging.Logger = testutils.Logger(s.T(), nil)
+	cfg, err := config.NewManager(testCfgFile)
+	s.Require().NoError(err)
+	s.cfg = cfg
+}
+
+func (s *shutdownSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "event-bus-shutdown")
+	s.Require().NoError(err)
+	s.settings = storage.Settings{
+		Dir:              dir,
+		SyncWrites:       true,
+		ValueLogFileSize: 1 << 20,
+		Compression:      "none",
+	}
+}
+
+func (s *shutdownSuite) TearDownTest() {
+	s.Require().NoError(os.RemoveAll(s.settings.Dir))
+}
+
+func (s *shutdownSuite) Test_Stop_NoWriteLost() {
+	db, err := storage.Open(s.settings)
+	s.Require().NoError(err)
+	bus := services.NewBus(db, services.Quotas{})
+	s.Require().NoError(bus.Init())
+	stream, err := bus.CreateStream(context.Background(), "root", "shutdown-stream", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	srv, err := server.ListenAndServe(server.Settings{
+		Addr:            shutdownAddr,
+		Router:          controllers.NewRouter(bus, s.cfg, nil, nil),
+		DB:              db,
+		Deadline:        50 * time.Millisecond,
+		ShutdownTimeout: 5 * time.Second,
+	})
+	s.Require().NoError(err)
+
+	var wg sync.WaitGroup
+	acked := make(chan int, shutdownClients)
+	for i := 0; i < shutdownClients; i++ {
+		conn, err := net.Dial("tcp", shutdownAddr)
+		s.Require().NoError(err)
+		wg.Add(1)
+		go func(client int) {
+			defer wg.Done()
+			acked <- s.writeUntilShutdown(conn, client)
+		}(i)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	s.Require().NoError(srv.Stop())
+	wg.Wait()
+	close(acked)
+
+	total := 0
+	for count := range acked {
+		total += count
+	}
+	s.Greater(total, 0)
+	s.Equal(total, s.storedEvents(stream.ID))
+}
+
+// writeUntilShutdown keeps writing events one by one until the server shuts down
+// and returns how many of them were acknowledged
+func (s *shutdownSuite) writeUntilShutdown(conn net.Conn, client int) int {
+	defer conn.Close()
+	auth := s.cfg.GetAuth()["integration"]
+	reader := bufio.NewReader(conn)
+	acked := 0
+	for i := 0; ; i++ {
+		req := fmt.Sprintf(
+			`{"operation": "write_event", "auth": {"client_id": "%s", "client_secret": "%s"}, "body": {"stream_name": "shutdown-stream", "event": {"client": %d, "index": %d}}}`+"\n",
+			auth.ClientID,
+			auth.ClientSecret,
+			client,
+			i,
+		)
+		if _, err := conn.Write([]byte(req)); err != nil {
+			return acked
+		}
+		for {
+			bs, err := reader.ReadBytes('\n')
+			if err != nil {
+				return acked
+			}
+			var res response
+			s.JSONUnmarshal(bs, &res)
+			if res.Operation == "shutdown" {
+				continue
+			}
+			s.Equal("write_event", res.Operation)
+			s.True(res.Status)
+			acked++
+			break
+		}
+	}
+}
+
+func (s *shutdownSuite) storedEvents(streamID string) int {
+	db, err := storage.Open(s.settings)
+	s.Require().NoError(err)
+	defer func() {
+		s.Require().NoError(db.Close())
+	}()
+
+	count := 0
+	s.Require().NoError(db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		prefix := []byte("event:" + streamID)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			err := it.Item().Value(func(val []byte) error {
+				var evt models.Event
+				s.JSONUnmarshal(val, &evt)
+				s.Equal(streamID, evt.StreamID)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	}))
+	return count
+}
+
+func Test_Shutdown(t *testing.T) {
+	suite.Run(t, new(shutdownSuite))
+}
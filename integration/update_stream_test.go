@@ -0,0 +1,99 @@
+//+build integration
+
+package integration
+
+import (
+	"net"
+)
+
+const testSchema = `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`
+
+type updateStreamResponseBody struct {
+	Stream struct {
+		Name   string      `json:"name"`
+		Schema interface{} `json:"schema"`
+	} `json:"stream"`
+}
+
+func (s *appSuite) Test_CreateStream_Schema() {
+	conn := s.newConn()
+
+	s.write(conn, "create_stream", `{"stream_name": "schema-stream", "schema": `+testSchema+`}`)
+
+	s.assertCreateStreamRes(conn, "schema-stream")
+	s.write(conn, "write_event", `{"stream_name": "schema-stream", "event": {"name": "steve"}}`)
+	s.assertWriteEventRes(conn)
+	s.write(conn, "write_event", `{"stream_name": "schema-stream", "event": {"name": 1}}`)
+	s.assertEventValidationErr(conn, JSON{
+		"path":    "name",
+		"message": "Invalid type. Expected: string, given: integer",
+	})
+}
+
+func (s *appSuite) Test_CreateStream_InvalidSchemaError() {
+	conn := s.newConn()
+
+	s.write(conn, "create_stream", `{"stream_name": "schema-stream", "schema": {"type": "unknown"}}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("create_stream", res.Operation)
+	s.False(res.Status)
+	s.Contains(res.Reason, "invalid schema provided: ")
+	s.Empty(res.Body)
+}
+
+func (s *appSuite) Test_UpdateStream_Success() {
+	conn := s.newConn()
+
+	s.write(conn, "update_stream", `{"stream_name": "s1-name", "schema": `+testSchema+`}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("update_stream", res.Operation)
+	s.True(res.Status)
+	var body updateStreamResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	s.Equal("s1-name", body.Stream.Name)
+	s.NotNil(body.Stream.Schema)
+	s.write(conn, "write_event", `{"stream_name": "s1-name", "event": {}}`)
+	s.assertEventValidationErr(conn, JSON{
+		"path":    "name",
+		"message": "name is required",
+	})
+
+	s.write(conn, "update_stream", `{"stream_name": "s1-name"}`)
+
+	s.read(conn, &res)
+	s.True(res.Status)
+	var updatedBody updateStreamResponseBody
+	s.JSONUnmarshal(res.Body, &updatedBody)
+	s.Nil(updatedBody.Stream.Schema)
+	s.write(conn, "write_event", `{"stream_name": "s1-name", "event": {}}`)
+	s.assertWriteEventRes(conn)
+}
+
+func (s *appSuite) Test_UpdateStream_StreamNotFoundError() {
+	conn := s.newConn()
+
+	s.write(conn, "update_stream", `{"stream_name": "not-found-stream-name", "schema": `+testSchema+`}`)
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("update_stream", res.Operation)
+	s.False(res.Status)
+	s.Equal("stream 'not-found-stream-name' not found", res.Reason)
+}
+
+func (s *appSuite) assertEventValidationErr(conn net.Conn, violations ...JSON) {
+	expectedCtx := JSON{"violations": []interface{}{}}
+	for _, violation := range violations {
+		expectedCtx["violations"] = append(expectedCtx["violations"].([]interface{}), violation)
+	}
+	var res response
+	s.read(conn, &res)
+	s.Equal("write_event", res.Operation)
+	s.False(res.Status)
+	s.Equal("event does not match the stream schema", res.Reason)
+	s.Equal(expectedCtx, res.Context)
+}
@@ -3,8 +3,11 @@
 package integration
 
 import (
+	"bytes"
+	"fmt"
 	"net"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,6 +26,31 @@ func (s *appSuite) Test_WriteEvent_Success() {
 	s.assertDBEvent(events[0], "s3-id", `{"e1-field": "e1-value"}`)
 }
 
+func (s *appSuite) Test_WriteEvent_Data() {
+	conn := s.newConn()
+	data := []byte{0, 1, 2, 255}
+
+	s.write(conn, "write_event", `{"stream_name": "s3-name", "content_type": "application/x-protobuf", "data": "AAEC/w=="}`)
+
+	s.assertWriteEventRes(conn)
+	values := s.dbFetch("event:s3-id:0")
+	s.Require().Len(values, 1)
+	s.True(bytes.HasSuffix(values[0], data))
+	s.NotContains(string(values[0]), "AAEC/w==")
+
+	s.write(conn, "get_stream_events", `{"stream_name": "s3-name"}`)
+
+	var res response
+	s.read(conn, &res)
+	s.True(res.Status)
+	var body getStreamEventsResponseBody
+	s.JSONUnmarshal(res.Body, &body)
+	s.Require().Len(body.Events, 1)
+	s.Equal("application/x-protobuf", body.Events[0].ContentType)
+	s.Equal(data, body.Events[0].Data)
+	s.Empty(body.Events[0].Body)
+}
+
 func (s *appSuite) Test_WriteEvent_StreamNotFoundError() {
 	conn := s.newConn()
 
@@ -38,6 +66,37 @@ func (s *appSuite) Test_WriteEvent_StreamNotFoundError() {
 	s.Len(values, 0)
 }
 
+func (s *appSuite) Test_WriteEvent_LargeEvent() {
+	conn := s.newConn()
+	value := strings.Repeat("v", 100*1024)
+
+	s.write(conn, "write_event", fmt.Sprintf(`{"stream_name": "s3-name", "event": {"large": "%s"}}`, value))
+
+	s.assertWriteEventRes(conn)
+	events := s.sortedEvents(s.dbFetch("event:s3-id:0"))
+	s.Len(events, 1)
+	s.assertDBEvent(events[0], "s3-id", fmt.Sprintf(`{"large": "%s"}`, value))
+}
+
+func (s *appSuite) Test_WriteEvent_RequestTooLargeError() {
+	conn := s.newConn()
+	value := strings.Repeat("v", maxRequestSize)
+
+	s.write(conn, "write_event", fmt.Sprintf(`{"stream_name": "s3-name", "event": {"large": "%s"}}`, value))
+
+	var res response
+	s.read(conn, &res)
+	s.Equal("decode_request", res.Operation)
+	s.False(res.Status)
+	s.Equal("request exceeds the maximum size of 1048576 bytes", res.Reason)
+	s.Len(s.dbFetch("event:s3-id:0"), 0)
+
+	s.write(conn, "write_event", `{"stream_name": "s3-name", "event": {"small": "value"}}`)
+
+	s.assertWriteEventRes(conn)
+	s.Len(s.dbFetch("event:s3-id:0"), 1)
+}
+
 func (s *appSuite) Test_WriteEvent_Concurrent() {
 	conn1 := s.newConn()
 	conn2 := s.newConn()
@@ -8,10 +8,18 @@ import (
 // Logger represents the application logger
 var Logger *zap.Logger
 
+// AccessLogger represents the logger of the access log, one entry per request.
+// It discards every entry until initialized with the access log enabled
+var AccessLogger = zap.NewNop()
+
 // Settings represents the application logger settings
 type Settings struct {
 	Level  string
 	Output []string
+	// AccessLog enables the access log
+	AccessLog bool
+	// AccessOutput is where the access log is written, the application logger output when empty
+	AccessOutput []string
 }
 
 // Init initializes application logger
@@ -29,7 +37,32 @@ func Init(settings Settings) error {
 	if err != nil {
 		return err
 	}
+	accessLogger := zap.NewNop()
+	if settings.AccessLog {
+		accessLogger, err = newAccessLogger(settings)
+		if err != nil {
+			return err
+		}
+	}
 	Logger = l
+	AccessLogger = accessLogger
 	l.Sync()
 	return nil
 }
+
+// newAccessLogger builds the access logger, which logs every request whatever the application logger level
+func newAccessLogger(settings Settings) (*zap.Logger, error) {
+	accessCfg := zap.NewProductionConfig()
+	accessCfg.Level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	accessCfg.OutputPaths = settings.AccessOutput
+	if len(accessCfg.OutputPaths) == 0 {
+		accessCfg.OutputPaths = settings.Output
+	}
+	accessCfg.DisableCaller = true
+	accessCfg.DisableStacktrace = true
+	l, err := accessCfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	return l.Named("access"), nil
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func Test_Init_Success(t *testing.T) {
@@ -34,3 +36,41 @@ func Test_Init_BuildConfig(t *testing.T) {
 	assert.Nil(t, Logger)
 	Logger = nil
 }
+
+func Test_Init_AccessLog(t *testing.T) {
+	settings := Settings{
+		Level:        "error",
+		Output:       []string{"stdout"},
+		AccessLog:    true,
+		AccessOutput: []string{"stderr"},
+	}
+	err := Init(settings)
+
+	assert.NoError(t, err)
+	assert.True(t, AccessLogger.Core().Enabled(zapcore.InfoLevel))
+	assert.False(t, Logger.Core().Enabled(zapcore.InfoLevel))
+	Logger = nil
+	AccessLogger = zap.NewNop()
+}
+
+func Test_Init_AccessLogDisabled(t *testing.T) {
+	settings := Settings{Output: []string{"stdout"}}
+	err := Init(settings)
+
+	assert.NoError(t, err)
+	assert.False(t, AccessLogger.Core().Enabled(zapcore.InfoLevel))
+	Logger = nil
+}
+
+func Test_Init_AccessLogBuildError(t *testing.T) {
+	settings := Settings{
+		Output:       []string{"stdout"},
+		AccessLog:    true,
+		AccessOutput: []string{"http://www.google.com"},
+	}
+	err := Init(settings)
+
+	assert.EqualError(t, err, `couldn't open sink "http://www.google.com": no sink found for scheme "http"`)
+	assert.Nil(t, Logger)
+	Logger = nil
+}
@@ -1,30 +1,48 @@
-//+build !test
+//go:build !test
+// +build !test
 
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
-	"time"
 
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/cli"
 	"github.com/go-web-dev/event-bus/config"
 	"github.com/go-web-dev/event-bus/controllers"
+	"github.com/go-web-dev/event-bus/gateway"
+	"github.com/go-web-dev/event-bus/health"
 	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/metrics"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/rpc"
 	"github.com/go-web-dev/event-bus/server"
 	"github.com/go-web-dev/event-bus/services"
-
-	"github.com/dgraph-io/badger/v2"
+	"github.com/go-web-dev/event-bus/storage"
+	"github.com/go-web-dev/event-bus/tracing"
 )
 
 func main() {
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		err := cli.Run(os.Args[1:], os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	configPath := flag.String("config", "config/config.yaml", "config file path")
 	flag.Parse()
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	cfg, err := config.NewManager(*configPath)
 	if err != nil {
@@ -32,46 +50,201 @@ func main() {
 	}
 
 	loggerSettings := logging.Settings{
-		Level:  cfg.GetLoggerLevel(),
-		Output: cfg.GetLoggerOutput(),
+		Level:        cfg.GetLoggerLevel(),
+		Output:       cfg.GetLoggerOutput(),
+		AccessLog:    cfg.GetLoggerAccessLog(),
+		AccessOutput: cfg.GetLoggerAccessOutput(),
 	}
 	err = logging.Init(loggerSettings)
 	if err != nil {
 		log.Fatal("could not initialize logger: ", err)
 	}
 
-	dbOptions := badger.DefaultOptions("badger")
-	dbOptions.Logger = nil
-	db, err := badger.Open(dbOptions)
+	tracingSettings := tracing.Settings{
+		Exporter:    cfg.GetTracingExporter(),
+		Endpoint:    cfg.GetTracingEndpoint(),
+		Insecure:    cfg.GetTracingInsecure(),
+		SampleRatio: cfg.GetTracingSampleRatio(),
+		ServiceName: cfg.GetTracingServiceName(),
+	}
+	shutdownTracing, err := tracing.Init(tracingSettings)
+	if err != nil {
+		log.Fatal("could not initialize tracing: ", err)
+	}
+
+	storageSettings, err := storage.NewSettings(cfg)
+	if err != nil {
+		log.Fatal("could not load storage settings: ", err)
+	}
+	db, err := storage.Open(storageSettings)
 	if err != nil {
 		log.Fatal("could not open badger db: ", err)
 	}
 
-	bus := services.NewBus(db)
+	quotas := services.Quotas{
+		MaxEventSize: cfg.GetLimitsMaxEventSize(),
+		Clients:      clientQuotas(cfg.GetAuth()),
+	}
+	bus := services.NewBus(db, quotas)
+	bus.SetBackupDir(cfg.GetBackupDir())
 	err = bus.Init()
 	if err != nil {
 		log.Fatal("could not initialize event bus: ", err)
 	}
 
-	router := controllers.NewRouter(bus, cfg)
+	// the checks of the servers are registered once they are listening
+	checker := health.New(cfg.GetHealthTimeout())
+	checker.Register("storage", health.Storage(db))
+	checker.Register("goroutines", health.Goroutines(cfg.GetHealthMaxGoroutines()))
+	if !storageSettings.InMemory {
+		checker.Register("disk", health.Disk(storageSettings.Dir, uint64(cfg.GetHealthMinFreeDisk())))
+	}
+
+	// the connections are shared between the server and the admin operations
+	conns := server.NewConnections()
+	router := controllers.NewRouter(bus, cfg, checker, conns)
 
 	serverSettings := server.Settings{
-		Addr:     "localhost:8080",
-		Router:   router,
-		DB:       db,
-		Deadline: 500 * time.Millisecond,
+		Addr:                 cfg.GetServerAddr(),
+		Router:               router,
+		DB:                   db,
+		Deadline:             cfg.GetServerDeadline(),
+		ShutdownTimeout:      cfg.GetServerShutdownTimeout(),
+		IdleTimeout:          cfg.GetServerIdleTimeout(),
+		ReadTimeout:          cfg.GetServerReadTimeout(),
+		WriteTimeout:         cfg.GetServerWriteTimeout(),
+		MaxRequestSize:       cfg.GetServerMaxRequestSize(),
+		MaxPipelined:         cfg.GetServerMaxPipelinedRequests(),
+		CompressionThreshold: cfg.GetServerCompressionThreshold(),
+		Limits: server.Limits{
+			MaxConnections:       cfg.GetLimitsMaxConnections(),
+			MaxClientConnections: cfg.GetLimitsMaxClientConnections(),
+			RequestsPerSecond:    cfg.GetLimitsRequestsPerSecond(),
+			RequestBurst:         cfg.GetLimitsRequestBurst(),
+			BytesPerSecond:       cfg.GetLimitsBytesPerSecond(),
+			BytesBurst:           cfg.GetLimitsBytesBurst(),
+		},
+		Connections: conns,
 	}
 
 	srv, err := server.ListenAndServe(serverSettings)
 	if err != nil {
 		log.Fatal("could not create server listener: ", err)
 	}
+	checker.Register("server", srv.Check)
 
-	select {
-	case <-stop:
-		err := srv.Stop()
+	var gw *gateway.Gateway
+	if cfg.GetHTTPAddr() != "" {
+		gatewaySettings := gateway.Settings{
+			Addr:           cfg.GetHTTPAddr(),
+			Router:         router,
+			MaxRequestSize: int64(cfg.GetServerMaxRequestSize()),
+			AllowedOrigins: cfg.GetHTTPAllowedOrigins(),
+			Connections:    conns,
+			Bus:            bus,
+		}
+		gw, err = gateway.ListenAndServe(gatewaySettings)
 		if err != nil {
-			log.Fatal("could not stop server: ", err)
+			log.Fatal("could not create http gateway listener: ", err)
 		}
+		checker.Register("http_gateway", health.Job(gw.Err))
+	}
+
+	var grpcSrv *rpc.Server
+	if cfg.GetGRPCAddr() != "" {
+		grpcSettings := rpc.Settings{
+			Addr:           cfg.GetGRPCAddr(),
+			Bus:            bus,
+			Cfg:            cfg,
+			Health:         checker,
+			Connections:    conns,
+			Limiter:        conns,
+			MaxRequestSize: cfg.GetServerMaxRequestSize(),
+		}
+		grpcSrv, err = rpc.ListenAndServe(grpcSettings)
+		if err != nil {
+			log.Fatal("could not create grpc server listener: ", err)
+		}
+		checker.Register("grpc", health.Job(grpcSrv.Err))
+	}
+
+	var metricsSrv *metrics.Server
+	if cfg.GetMetricsAddr() != "" {
+		metrics.Registry.MustRegister(metrics.NewBacklogCollector(bus), metrics.NewStorageCollector(db))
+		metricsSrv, err = metrics.ListenAndServe(cfg.GetMetricsAddr())
+		if err != nil {
+			log.Fatal("could not create metrics server listener: ", err)
+		}
+		checker.Register("metrics", health.Job(metricsSrv.Err))
+	}
+
+	// SIGHUP reloads the clients allowed to make requests, any other signal shuts the server down
+	for sig := range signals {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		reloadAuth(cfg, bus, *configPath)
+	}
+
+	if metricsSrv != nil {
+		err := metricsSrv.Stop(cfg.GetServerShutdownTimeout())
+		if err != nil {
+			log.Fatal("could not stop metrics server: ", err)
+		}
+	}
+	if grpcSrv != nil {
+		grpcSrv.Stop(cfg.GetServerShutdownTimeout())
+	}
+	if gw != nil {
+		err := gw.Stop(cfg.GetServerShutdownTimeout())
+		if err != nil {
+			log.Fatal("could not stop http gateway: ", err)
+		}
+	}
+	err = srv.Stop()
+	if err != nil {
+		log.Fatal("could not stop server: ", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.GetServerShutdownTimeout())
+	defer cancel()
+	err = shutdownTracing(ctx)
+	if err != nil {
+		log.Fatal("could not flush trace spans: ", err)
+	}
+}
+
+func clientQuotas(clientAuth config.ClientAuth) map[string]models.Quota {
+	quotas := map[string]models.Quota{}
+	for client, c := range clientAuth {
+		quotas[client] = models.Quota{
+			MaxEventSize: c.MaxEventSize,
+			MaxEvents:    c.MaxEvents,
+			MaxBytes:     c.MaxBytes,
+		}
+	}
+	return quotas
+}
+
+// reloadAuth reloads the clients and their quotas from the config file and records the reload in the audit log
+func reloadAuth(cfg *config.Manager, bus *services.Bus, configPath string) {
+	logger := logging.Logger
+	err := cfg.ReloadAuth()
+	if err != nil {
+		logger.Error("could not reload config, keeping the current clients", zap.Error(err))
+		return
+	}
+	clientAuth := cfg.GetAuth()
+	bus.SetClientQuotas(clientQuotas(clientAuth))
+	entry := models.AuditEntry{
+		Action: models.AuditConfigReload,
+		Details: map[string]string{
+			"config":  configPath,
+			"clients": strconv.Itoa(len(clientAuth)),
+		},
+	}
+	err = bus.Audit(context.Background(), entry)
+	if err != nil {
+		logger.Error("could not record config reload", zap.Error(err))
 	}
+	logger.Info("successfully reloaded config clients", zap.Int("clients", len(clientAuth)))
 }
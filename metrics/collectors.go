@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+)
+
+// Backlog statuses
+const (
+	BacklogUnprocessed = "unprocessed"
+	BacklogRetry       = "retry"
+)
+
+var (
+	backlogDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "stream_backlog"),
+		"Number of events waiting to be processed, per stream and status.",
+		[]string{"stream", "status"}, nil,
+	)
+	lsmSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "storage", "lsm_size_bytes"),
+		"Size of the storage LSM tree files.",
+		nil, nil,
+	)
+	vlogSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "storage", "vlog_size_bytes"),
+		"Size of the storage value log files.",
+		nil, nil,
+	)
+)
+
+// Backlogger represents the source of the stream backlogs
+type Backlogger interface {
+	Backlog() (map[string]models.Backlog, error)
+}
+
+// Sizer represents the storage reporting its LSM tree and value log sizes
+type Sizer interface {
+	Size() (lsm, vlog int64)
+}
+
+type backlogCollector struct {
+	backlogger Backlogger
+}
+
+// NewBacklogCollector creates a collector counting the stream backlogs on every scrape
+func NewBacklogCollector(backlogger Backlogger) prometheus.Collector {
+	return backlogCollector{backlogger: backlogger}
+}
+
+func (c backlogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- backlogDesc
+}
+
+func (c backlogCollector) Collect(ch chan<- prometheus.Metric) {
+	backlogs, err := c.backlogger.Backlog()
+	if err != nil {
+		logging.Logger.Error("could not count stream backlogs", zap.Error(err))
+		ch <- prometheus.NewInvalidMetric(backlogDesc, err)
+		return
+	}
+	for stream, backlog := range backlogs {
+		ch <- prometheus.MustNewConstMetric(backlogDesc, prometheus.GaugeValue, float64(backlog.Unprocessed), stream, BacklogUnprocessed)
+		ch <- prometheus.MustNewConstMetric(backlogDesc, prometheus.GaugeValue, float64(backlog.Retry), stream, BacklogRetry)
+	}
+}
+
+type storageCollector struct {
+	sizer Sizer
+}
+
+// NewStorageCollector creates a collector reporting the storage sizes on every scrape
+func NewStorageCollector(sizer Sizer) prometheus.Collector {
+	return storageCollector{sizer: sizer}
+}
+
+func (c storageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lsmSizeDesc
+	ch <- vlogSizeDesc
+}
+
+func (c storageCollector) Collect(ch chan<- prometheus.Metric) {
+	lsm, vlog := c.sizer.Size()
+	ch <- prometheus.MustNewConstMetric(lsmSizeDesc, prometheus.GaugeValue, float64(lsm))
+	ch <- prometheus.MustNewConstMetric(vlogSizeDesc, prometheus.GaugeValue, float64(vlog))
+}
@@ -0,0 +1,108 @@
+// Package metrics exposes the Event Bus internals as Prometheus metrics
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "eventbus"
+
+// Request outcomes
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Registry holds every Event Bus metric along with the Go runtime and process ones
+var Registry = prometheus.NewRegistry()
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_total",
+		Help:      "Number of requests handled, per operation and outcome.",
+	}, []string{"operation", "status"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to handle a request, per operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+	activeConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connections_active",
+		Help:      "Number of open TCP connections.",
+	})
+	eventsWritten = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_written_total",
+		Help:      "Number of events written, per stream.",
+	}, []string{"stream"})
+	eventsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_processed_total",
+		Help:      "Number of events marked as processed, per stream.",
+	}, []string{"stream"})
+	eventsRetried = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_retried_total",
+		Help:      "Number of events marked for retry, per stream.",
+	}, []string{"stream"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		activeConnections,
+		eventsWritten,
+		eventsProcessed,
+		eventsRetried,
+	)
+}
+
+// ObserveRequest records a handled request and how long it took
+func ObserveRequest(operation string, err error, duration time.Duration) {
+	status := StatusSuccess
+	if err != nil {
+		status = StatusError
+	}
+	requestsTotal.WithLabelValues(operation, status).Inc()
+	requestDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// ConnectionOpened records a new TCP connection
+func ConnectionOpened() {
+	activeConnections.Inc()
+}
+
+// ConnectionClosed records a closed TCP connection
+func ConnectionClosed() {
+	activeConnections.Dec()
+}
+
+// EventWritten records an event written to the stream
+func EventWritten(stream string) {
+	eventsWritten.WithLabelValues(stream).Inc()
+}
+
+// EventProcessed records an event of the stream marked as processed
+func EventProcessed(stream string) {
+	eventsProcessed.WithLabelValues(stream).Inc()
+}
+
+// EventRetried records an event of the stream marked for retry
+func EventRetried(stream string) {
+	eventsRetried.WithLabelValues(stream).Inc()
+}
+
+// StreamDeleted drops the metrics of a deleted stream
+func StreamDeleted(stream string) {
+	eventsWritten.DeleteLabelValues(stream)
+	eventsProcessed.DeleteLabelValues(stream)
+	eventsRetried.DeleteLabelValues(stream)
+}
@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+type metricsSuite struct {
+	testutils.Suite
+	loggerEntry zapcore.Entry
+}
+
+func (s *metricsSuite) SetupSuite() {
+	logging.Logger = testutils.Logger(s.T(), &s.loggerEntry)
+}
+
+func (s *metricsSuite) SetupTest() {
+	requestsTotal.Reset()
+	requestDuration.Reset()
+	activeConnections.Set(0)
+	eventsWritten.Reset()
+	eventsProcessed.Reset()
+	eventsRetried.Reset()
+}
+
+func (s *metricsSuite) Test_ObserveRequest() {
+	expected := `
+# HELP eventbus_requests_total Number of requests handled, per operation and outcome.
+# TYPE eventbus_requests_total counter
+eventbus_requests_total{operation="write_event",status="error"} 1
+eventbus_requests_total{operation="write_event",status="success"} 2
+`
+
+	ObserveRequest("write_event", nil, time.Millisecond)
+	ObserveRequest("write_event", nil, time.Millisecond)
+	ObserveRequest("write_event", errors.New("some error"), 2*time.Second)
+
+	s.NoError(testutil.CollectAndCompare(requestsTotal, strings.NewReader(expected)))
+	s.Equal(1, testutil.CollectAndCount(requestDuration, "eventbus_request_duration_seconds"))
+}
+
+func (s *metricsSuite) Test_Connections() {
+	expected := `
+# HELP eventbus_connections_active Number of open TCP connections.
+# TYPE eventbus_connections_active gauge
+eventbus_connections_active 1
+`
+
+	ConnectionOpened()
+	ConnectionOpened()
+	ConnectionClosed()
+
+	s.NoError(testutil.CollectAndCompare(activeConnections, strings.NewReader(expected)))
+}
+
+func (s *metricsSuite) Test_Events() {
+	expected := `
+# HELP eventbus_events_processed_total Number of events marked as processed, per stream.
+# TYPE eventbus_events_processed_total counter
+eventbus_events_processed_total{stream="s1"} 1
+# HELP eventbus_events_retried_total Number of events marked for retry, per stream.
+# TYPE eventbus_events_retried_total counter
+eventbus_events_retried_total{stream="s1"} 1
+# HELP eventbus_events_written_total Number of events written, per stream.
+# TYPE eventbus_events_written_total counter
+eventbus_events_written_total{stream="s1"} 2
+eventbus_events_written_total{stream="s2"} 1
+`
+
+	EventWritten("s1")
+	EventWritten("s1")
+	EventWritten("s2")
+	EventProcessed("s1")
+	EventRetried("s1")
+
+	s.NoError(testutil.GatherAndCompare(
+		Registry,
+		strings.NewReader(expected),
+		"eventbus_events_written_total",
+		"eventbus_events_processed_total",
+		"eventbus_events_retried_total",
+	))
+}
+
+func (s *metricsSuite) Test_StreamDeleted() {
+	EventWritten("s1")
+	EventProcessed("s1")
+	EventWritten("s2")
+
+	StreamDeleted("s1")
+
+	s.Equal(1, testutil.CollectAndCount(eventsWritten))
+	s.Equal(float64(1), testutil.ToFloat64(eventsWritten.WithLabelValues("s2")))
+	s.Zero(testutil.CollectAndCount(eventsProcessed))
+}
+
+func (s *metricsSuite) Test_BacklogCollector() {
+	backlogger := &backloggerMock{}
+	backlogger.
+		On("Backlog").
+		Return(map[string]models.Backlog{"s1": {Unprocessed: 3, Retry: 1}}, nil).
+		Once()
+	expected := `
+# HELP eventbus_stream_backlog Number of events waiting to be processed, per stream and status.
+# TYPE eventbus_stream_backlog gauge
+eventbus_stream_backlog{status="retry",stream="s1"} 1
+eventbus_stream_backlog{status="unprocessed",stream="s1"} 3
+`
+
+	err := testutil.CollectAndCompare(NewBacklogCollector(backlogger), strings.NewReader(expected))
+
+	s.NoError(err)
+	backlogger.AssertExpectations(s.T())
+}
+
+func (s *metricsSuite) Test_BacklogCollector_Error() {
+	backlogger := &backloggerMock{}
+	backlogger.
+		On("Backlog").
+		Return(map[string]models.Backlog(nil), errors.New("some error")).
+		Once()
+
+	err := testutil.CollectAndCompare(NewBacklogCollector(backlogger), strings.NewReader(""))
+
+	s.Error(err)
+	s.Equal("could not count stream backlogs", s.loggerEntry.Message)
+}
+
+func (s *metricsSuite) Test_StorageCollector() {
+	expected := `
+# HELP eventbus_storage_lsm_size_bytes Size of the storage LSM tree files.
+# TYPE eventbus_storage_lsm_size_bytes gauge
+eventbus_storage_lsm_size_bytes 1024
+# HELP eventbus_storage_vlog_size_bytes Size of the storage value log files.
+# TYPE eventbus_storage_vlog_size_bytes gauge
+eventbus_storage_vlog_size_bytes 2048
+`
+
+	err := testutil.CollectAndCompare(NewStorageCollector(sizerMock{lsm: 1024, vlog: 2048}), strings.NewReader(expected))
+
+	s.NoError(err)
+}
+
+func (s *metricsSuite) Test_Handler() {
+	EventWritten("s1")
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, metricsPath, nil)
+
+	Handler().ServeHTTP(rec, req)
+
+	s.Equal(http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	s.Contains(body, `eventbus_events_written_total{stream="s1"} 1`)
+	s.Contains(body, "go_goroutines")
+}
+
+func (s *metricsSuite) Test_ListenAndServe() {
+	srv, err := ListenAndServe("localhost:0")
+	s.Require().NoError(err)
+
+	res, err := http.Get("http://" + srv.Addr().String() + metricsPath)
+	s.Require().NoError(err)
+	s.Require().NoError(res.Body.Close())
+	s.Equal(http.StatusOK, res.StatusCode)
+	s.NoError(srv.Err())
+
+	s.NoError(srv.Stop(time.Second))
+	_, err = http.Get("http://" + srv.Addr().String() + metricsPath)
+	s.Error(err)
+}
+
+func TestMetrics(t *testing.T) {
+	suite.Run(t, new(metricsSuite))
+}
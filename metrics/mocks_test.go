@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+type backloggerMock struct {
+	mock.Mock
+}
+
+func (m *backloggerMock) Backlog() (map[string]models.Backlog, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]models.Backlog), args.Error(1)
+}
+
+type sizerMock struct {
+	lsm  int64
+	vlog int64
+}
+
+func (m sizerMock) Size() (int64, int64) {
+	return m.lsm, m.vlog
+}
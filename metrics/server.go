@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+)
+
+const metricsPath = "/metrics"
+
+// Server represents the HTTP server exposing the metrics
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+	mu       sync.Mutex
+	serveErr error
+}
+
+// Handler serves the metrics of the Registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ListenAndServe spins up the metrics HTTP server
+func ListenAndServe(addr string) (*Server, error) {
+	li, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, Handler())
+	srv := &Server{
+		server:   &http.Server{Handler: mux},
+		listener: li,
+	}
+	go func() {
+		logging.Logger.Info("metrics server is up and running on address", zap.String("addr", li.Addr().String()))
+		err := srv.server.Serve(li)
+		if err != nil && err != http.ErrServerClosed {
+			logging.Logger.Error("metrics server stopped unexpectedly", zap.Error(err))
+			srv.mu.Lock()
+			srv.serveErr = err
+			srv.mu.Unlock()
+		}
+	}()
+	return srv, nil
+}
+
+// Addr returns the address the metrics server listens on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Err returns the error the metrics server stopped serving with, nil while serving
+func (s *Server) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.serveErr
+}
+
+// Stop gracefully shuts down the metrics server waiting for in-flight scrapes up to the given timeout
+func (s *Server) Stop(timeout time.Duration) error {
+	logging.Logger.Info("shutting down the metrics server")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+)
+
+// Audited actions
+const (
+	AuditCreateStream = "create_stream"
+	AuditUpdateStream = "update_stream"
+	AuditDeleteStream = "delete_stream"
+	AuditMarkEvent    = "mark_event"
+	AuditConfigReload = "config_reload"
+	AuditDisconnect   = "disconnect"
+	AuditBackup       = "backup"
+	AuditRestore      = "restore"
+	AuditExportStream = "export_stream"
+	AuditImportStream = "import_stream"
+)
+
+// AuditEntry represents a record of the audit log, the append-only log of the administrative operations.
+// Client is the name of the client which made the operation, empty for the operations made by the server itself
+type AuditEntry struct {
+	ID        string            `json:"id"`
+	Action    string            `json:"action"`
+	Client    string            `json:"client,omitempty"`
+	Stream    string            `json:"stream,omitempty"`
+	EventID   string            `json:"event_id,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// AuditFilter represents the criteria the audit log entries are queried by, empty fields match every entry
+type AuditFilter struct {
+	Action string
+	Client string
+	Stream string
+	Since  time.Time
+	Until  time.Time
+	// Limit is the maximum number of the most recent matching entries, zero means unlimited
+	Limit int
+}
+
+// NewDisconnectEntry creates the audit entry of an admin client closing connections,
+// disconnectedClient being the client whose connections were all closed, if any
+func NewDisconnectEntry(client, disconnectedClient string, connIDs []int) AuditEntry {
+	ids := make([]string, 0, len(connIDs))
+	for _, id := range connIDs {
+		ids = append(ids, strconv.Itoa(id))
+	}
+	details := map[string]string{
+		"connections": strings.Join(ids, ","),
+	}
+	if disconnectedClient != "" {
+		details["client"] = disconnectedClient
+	}
+	return AuditEntry{
+		Action:  AuditDisconnect,
+		Client:  client,
+		Details: details,
+	}
+}
+
+// NewBackupEntry creates the audit entry of an admin client backing up the store into a server file
+func NewBackupEntry(client, path string, since, version uint64) AuditEntry {
+	return AuditEntry{
+		Action: AuditBackup,
+		Client: client,
+		Details: map[string]string{
+			"path":    path,
+			"since":   strconv.FormatUint(since, 10),
+			"version": strconv.FormatUint(version, 10),
+		},
+	}
+}
+
+// NewRestoreEntry creates the audit entry of an admin client restoring the store from a server file
+func NewRestoreEntry(client, path string, incremental bool) AuditEntry {
+	return AuditEntry{
+		Action: AuditRestore,
+		Client: client,
+		Details: map[string]string{
+			"path":        path,
+			"incremental": strconv.FormatBool(incremental),
+		},
+	}
+}
+
+// NewExportEntry creates the audit entry of an admin client exporting a stream into a server file
+func NewExportEntry(client, streamName, path string, events int) AuditEntry {
+	return AuditEntry{
+		Action: AuditExportStream,
+		Client: client,
+		Stream: streamName,
+		Details: map[string]string{
+			"path":   path,
+			"events": strconv.Itoa(events),
+		},
+	}
+}
+
+// NewImportEntry creates the audit entry of an admin client importing a server file into a stream
+func NewImportEntry(client, path string, res ImportResult) AuditEntry {
+	return AuditEntry{
+		Action: AuditImportStream,
+		Client: client,
+		Stream: res.Stream.Name,
+		Details: map[string]string{
+			"path":     path,
+			"imported": strconv.Itoa(res.Imported),
+			"skipped":  strconv.Itoa(res.Skipped),
+			"renamed":  strconv.Itoa(res.Renamed),
+		},
+	}
+}
+
+// Key generates audit entry specific key to be stored inside the database.
+// Keys are ordered by creation time
+func (e AuditEntry) Key() []byte {
+	return []byte(fmt.Sprintf("%s:%s", AuditTimeKey(e.CreatedAt), e.ID))
+}
+
+// AuditTimeKey generates the key the audit entries created at t are stored right after,
+// the audit log being ordered by creation time
+func AuditTimeKey(t time.Time) []byte {
+	return []byte(fmt.Sprintf("audit:%020d", t.UnixNano()))
+}
+
+// Value generates audit entry specific value to be stored inside the database
+func (e AuditEntry) Value() []byte {
+	bs, err := json.Marshal(e)
+	if err != nil {
+		logging.Logger.Error("could not marshal audit entry", zap.Error(err))
+		return []byte{}
+	}
+	return bs
+}
+
+// Match tells whether the audit entry matches the filter
+func (f AuditFilter) Match(e AuditEntry) bool {
+	switch {
+	case f.Action != "" && f.Action != e.Action:
+		return false
+	case f.Client != "" && f.Client != e.Client:
+		return false
+	case f.Stream != "" && f.Stream != e.Stream:
+		return false
+	case !f.Since.IsZero() && e.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.CreatedAt.Before(f.Until):
+		return false
+	}
+	return true
+}
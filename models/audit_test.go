@@ -0,0 +1,139 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testAuditEntry = AuditEntry{
+		ID:        "audit-id",
+		Action:    AuditMarkEvent,
+		Client:    "root",
+		Stream:    "s1",
+		EventID:   "event-id",
+		Details:   map[string]string{"status": "1"},
+		CreatedAt: testTime,
+	}
+)
+
+func Test_NewDisconnectEntry(t *testing.T) {
+	expected := AuditEntry{
+		Action:  AuditDisconnect,
+		Client:  "root",
+		Details: map[string]string{"connections": "1,4", "client": "go-client"},
+	}
+
+	entry := NewDisconnectEntry("root", "go-client", []int{1, 4})
+
+	assert.Equal(t, expected, entry)
+	assert.Equal(t, map[string]string{"connections": "3"}, NewDisconnectEntry("root", "", []int{3}).Details)
+}
+
+func Test_NewBackupEntry(t *testing.T) {
+	expected := AuditEntry{
+		Action:  AuditBackup,
+		Client:  "root",
+		Details: map[string]string{"path": "/backups/bus.bak", "since": "3", "version": "10"},
+	}
+
+	entry := NewBackupEntry("root", "/backups/bus.bak", 3, 10)
+
+	assert.Equal(t, expected, entry)
+}
+
+func Test_NewRestoreEntry(t *testing.T) {
+	expected := AuditEntry{
+		Action:  AuditRestore,
+		Client:  "root",
+		Details: map[string]string{"path": "/backups/bus.bak", "incremental": "true"},
+	}
+
+	entry := NewRestoreEntry("root", "/backups/bus.bak", true)
+
+	assert.Equal(t, expected, entry)
+}
+
+func Test_NewExportEntry(t *testing.T) {
+	expected := AuditEntry{
+		Action:  AuditExportStream,
+		Client:  "root",
+		Stream:  "s1",
+		Details: map[string]string{"path": "/exports/s1.jsonl", "events": "42"},
+	}
+
+	entry := NewExportEntry("root", "s1", "/exports/s1.jsonl", 42)
+
+	assert.Equal(t, expected, entry)
+}
+
+func Test_NewImportEntry(t *testing.T) {
+	expected := AuditEntry{
+		Action:  AuditImportStream,
+		Client:  "root",
+		Stream:  "s1",
+		Details: map[string]string{"path": "/exports/s1.jsonl", "imported": "40", "skipped": "2", "renamed": "1"},
+	}
+
+	entry := NewImportEntry("root", "/exports/s1.jsonl", ImportResult{Stream: Stream{Name: "s1"}, Imported: 40, Skipped: 2, Renamed: 1})
+
+	assert.Equal(t, expected, entry)
+}
+
+func Test_AuditEntry_Key(t *testing.T) {
+	expected := []byte("audit:01608010111490416000:audit-id")
+
+	bs := testAuditEntry.Key()
+
+	assert.Equal(t, expected, bs)
+}
+
+func Test_AuditTimeKey(t *testing.T) {
+	expected := []byte("audit:01608010111490416000")
+
+	bs := AuditTimeKey(testAuditEntry.CreatedAt)
+
+	assert.Equal(t, expected, bs)
+}
+
+func Test_AuditEntry_Value(t *testing.T) {
+	expected := `{
+		"id":"audit-id",
+		"action":"mark_event",
+		"client":"root",
+		"stream":"s1",
+		"event_id":"event-id",
+		"details":{"status":"1"},
+		"created_at":"2020-12-15T05:28:31.490416Z"
+	}`
+
+	bs := testAuditEntry.Value()
+
+	assert.JSONEq(t, expected, string(bs))
+}
+
+func Test_AuditFilter_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   AuditFilter
+		expected bool
+	}{
+		{name: "empty", filter: AuditFilter{}, expected: true},
+		{name: "all", filter: AuditFilter{Action: AuditMarkEvent, Client: "root", Stream: "s1"}, expected: true},
+		{name: "action", filter: AuditFilter{Action: AuditDeleteStream}, expected: false},
+		{name: "client", filter: AuditFilter{Client: "other"}, expected: false},
+		{name: "stream", filter: AuditFilter{Stream: "s2"}, expected: false},
+		{name: "since", filter: AuditFilter{Since: testTime}, expected: true},
+		{name: "since after", filter: AuditFilter{Since: testTime.Add(time.Second)}, expected: false},
+		{name: "until", filter: AuditFilter{Until: testTime}, expected: false},
+		{name: "until after", filter: AuditFilter{Until: testTime.Add(time.Second)}, expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.filter.Match(testAuditEntry))
+		})
+	}
+}
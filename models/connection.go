@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Transports the connections are served over
+const (
+	TCPTransport       = "tcp"
+	WebSocketTransport = "websocket"
+	GRPCTransport      = "grpc"
+)
+
+// Connection represents a client connection to the Event Bus,
+// either a TCP server connection, a WebSocket connection or a gRPC subscription
+type Connection struct {
+	ID         int    `json:"id"`
+	Transport  string `json:"transport"`
+	RemoteAddr string `json:"remote_addr"`
+	// Client is the name of the client authenticated on the connection, empty until it makes an authenticated request
+	Client        string    `json:"client,omitempty"`
+	ConnectedAt   time.Time `json:"connected_at"`
+	Requests      int64     `json:"requests"`
+	Subscriptions int       `json:"subscriptions"`
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"fmt"
 	"strings"
+
+	"github.com/go-web-dev/event-bus/schema"
 )
 
 // Error represents the Event Bus generic error
@@ -37,15 +39,28 @@ type OperationNotFoundError struct {
 func (e OperationNotFoundError) Error() string {
 	ops := []string{
 		"health",
+		"hello",
 		"create_stream",
 		"delete_stream",
+		"update_stream",
 		"get_stream_info",
+		"list_streams",
 		"get_stream_events",
 		"write_event",
 		"process_events",
 		"retry_events",
 		"retry_events",
 		"mark_event",
+		"register_event_type",
+		"list_event_types",
+		"get_event_type",
+		"get_audit_log",
+		"list_connections",
+		"disconnect",
+		"backup",
+		"restore",
+		"export_stream",
+		"import_stream",
 		"exit",
 	}
 	return fmt.Sprintf(
@@ -85,3 +100,268 @@ type AuthError struct {
 func (e AuthError) Error() string {
 	return "unauthorized to make request"
 }
+
+// ForbiddenError is returned in case an authenticated client makes a request restricted to admin clients
+type ForbiddenError struct {
+}
+
+func (e ForbiddenError) Error() string {
+	return "forbidden to make request"
+}
+
+// RequestTooLargeError is returned in case a request exceeds the maximum allowed size
+type RequestTooLargeError struct {
+	MaxSize int
+}
+
+func (e RequestTooLargeError) Error() string {
+	return fmt.Sprintf("request exceeds the maximum size of %d bytes", e.MaxSize)
+}
+
+// TooManyConnectionsError is returned in case the server or a client reached its connections limit
+type TooManyConnectionsError struct {
+	Client string
+}
+
+func (e TooManyConnectionsError) Error() string {
+	if e.Client == "" {
+		return "too many connections"
+	}
+	return fmt.Sprintf("too many connections for client '%s'", e.Client)
+}
+
+// RateLimitError is returned in case a client exceeds its requests or bytes rate limit.
+// Clients are expected to back off for at least RetryAfterMS milliseconds
+type RateLimitError struct {
+	RetryAfterMS int64 `json:"retry_after_ms"`
+}
+
+func (e RateLimitError) Error() string {
+	return "rate_limited"
+}
+
+// EventTooLargeError is returned in case an event payload exceeds the maximum event size
+type EventTooLargeError struct {
+	Size    int64
+	MaxSize int64
+}
+
+func (e EventTooLargeError) Error() string {
+	return fmt.Sprintf("event of %d bytes exceeds the maximum event size of %d bytes", e.Size, e.MaxSize)
+}
+
+// QuotaExceededError is returned in case writing an event would exceed a stream or a client quota
+type QuotaExceededError struct {
+	Stream string `json:"stream,omitempty"`
+	Client string `json:"client,omitempty"`
+	Quota  string `json:"quota"`
+	Limit  int64  `json:"limit"`
+}
+
+func (e QuotaExceededError) Error() string {
+	if e.Client != "" {
+		return fmt.Sprintf("client '%s' quota exceeded: %s is %d", e.Client, e.Quota, e.Limit)
+	}
+	return fmt.Sprintf("stream '%s' quota exceeded: %s is %d", e.Stream, e.Quota, e.Limit)
+}
+
+// InvalidQuotaError is returned when setting a negative stream quota
+type InvalidQuotaError struct {
+}
+
+func (e InvalidQuotaError) Error() string {
+	return "max_event_size, max_events and max_bytes must not be negative"
+}
+
+// StreamNotFoundError is returned in case the requested stream does not exist
+type StreamNotFoundError struct {
+	Name string
+}
+
+func (e StreamNotFoundError) Error() string {
+	return fmt.Sprintf("stream '%s' not found", e.Name)
+}
+
+// StreamExistsError is returned when creating a stream that already exists
+type StreamExistsError struct {
+	Name string
+}
+
+func (e StreamExistsError) Error() string {
+	return fmt.Sprintf("stream: '%s' already exists", e.Name)
+}
+
+// EventNotFoundError is returned in case the requested event does not exist
+type EventNotFoundError struct {
+	ID string
+}
+
+func (e EventNotFoundError) Error() string {
+	return fmt.Sprintf("event '%s' not found", e.ID)
+}
+
+// ConnectionNotFoundError is returned in case the requested connection does not exist
+type ConnectionNotFoundError struct {
+	ID int
+}
+
+func (e ConnectionNotFoundError) Error() string {
+	return fmt.Sprintf("connection '%d' not found", e.ID)
+}
+
+// SubscriptionLagError is returned in case a subscriber does not keep up with the events written to a stream
+type SubscriptionLagError struct {
+}
+
+func (e SubscriptionLagError) Error() string {
+	return "subscriber is lagging behind, events were dropped"
+}
+
+// UnsupportedEncodingError is returned when negotiating an unknown wire encoding
+type UnsupportedEncodingError struct {
+}
+
+func (e UnsupportedEncodingError) Error() string {
+	encodings := []string{
+		"json",
+		"msgpack",
+	}
+	return fmt.Sprintf(
+		"encoding must be one of: '%s'",
+		strings.Join(encodings, "', '"),
+	)
+}
+
+// InvalidEncodingError is returned in case a request cannot be decoded with the connection encoding
+type InvalidEncodingError struct {
+	Encoding string
+}
+
+func (e InvalidEncodingError) Error() string {
+	return fmt.Sprintf("invalid %s provided", e.Encoding)
+}
+
+// EventPayloadError is returned when an event carries both a JSON event and binary data
+type EventPayloadError struct {
+}
+
+func (e EventPayloadError) Error() string {
+	return "event and data cannot be both provided"
+}
+
+// EventTypePayloadError is returned when binary data is written with an event type, which only applies to JSON events
+type EventTypePayloadError struct {
+}
+
+func (e EventTypePayloadError) Error() string {
+	return "event_type can only be provided for json events"
+}
+
+// UnsupportedCompressionError is returned when asking for an unknown compression algorithm
+type UnsupportedCompressionError struct {
+}
+
+func (e UnsupportedCompressionError) Error() string {
+	algorithms := []string{
+		"snappy",
+		"zstd",
+	}
+	return fmt.Sprintf(
+		"compression must be one of: '%s'",
+		strings.Join(algorithms, "', '"),
+	)
+}
+
+// InvalidSchemaError is returned when attaching a JSON Schema which cannot be compiled to a stream
+type InvalidSchemaError struct {
+	Reason string
+}
+
+func (e InvalidSchemaError) Error() string {
+	return fmt.Sprintf("invalid schema provided: %s", e.Reason)
+}
+
+// EventValidationError is returned in case an event does not match the JSON Schema of its stream
+type EventValidationError struct {
+	Violations []schema.Violation `json:"violations"`
+}
+
+func (e EventValidationError) Error() string {
+	return "event does not match the stream schema"
+}
+
+// EventTypeNotFoundError is returned in case the requested event type does not exist in the schema registry
+type EventTypeNotFoundError struct {
+	ID      string
+	Name    string
+	Version int
+}
+
+func (e EventTypeNotFoundError) Error() string {
+	if e.ID != "" {
+		return fmt.Sprintf("event type with schema id '%s' not found", e.ID)
+	}
+	if e.Version > 0 {
+		return fmt.Sprintf("event type '%s' version %d not found", e.Name, e.Version)
+	}
+	return fmt.Sprintf("event type '%s' not found", e.Name)
+}
+
+// UnsupportedCompatibilityError is returned when registering an event type with an unknown compatibility mode
+type UnsupportedCompatibilityError struct {
+}
+
+func (e UnsupportedCompatibilityError) Error() string {
+	return fmt.Sprintf(
+		"compatibility must be one of: '%s'",
+		strings.Join(schema.CompatibilityModes(), "', '"),
+	)
+}
+
+// IncompatibleSchemaError is returned in case a new event type version breaks the compatibility with the latest one
+type IncompatibleSchemaError struct {
+	Compatibility string             `json:"compatibility"`
+	Version       int                `json:"version"`
+	Violations    []schema.Violation `json:"violations"`
+}
+
+func (e IncompatibleSchemaError) Error() string {
+	return fmt.Sprintf("schema is not %s compatible with version %d", e.Compatibility, e.Version)
+}
+
+// UnhealthyError is returned when a deep health check finds a component down
+type UnhealthyError struct {
+	Health
+}
+
+func (e UnhealthyError) Error() string {
+	return "service is unhealthy"
+}
+
+// InvalidImportError is returned in case a stream import holds a record which is not a valid stream or event
+type InvalidImportError struct {
+	Line   int
+	Reason string
+}
+
+func (e InvalidImportError) Error() string {
+	return fmt.Sprintf("invalid import at line %d: %s", e.Line, e.Reason)
+}
+
+// InvalidBackupError is returned in case a backup cannot be decoded, the stored data being left untouched
+type InvalidBackupError struct {
+	Reason string
+}
+
+func (e InvalidBackupError) Error() string {
+	return fmt.Sprintf("invalid backup: %s", e.Reason)
+}
+
+// InvalidPathError is returned in case a file path on the server is not within the backup directory
+type InvalidPathError struct {
+	Path string
+}
+
+func (e InvalidPathError) Error() string {
+	return fmt.Sprintf("path '%s' must be relative to the backup directory", e.Path)
+}
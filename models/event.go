@@ -1,12 +1,15 @@
 package models
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"mime"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/go-web-dev/event-bus/compression"
 	"github.com/go-web-dev/event-bus/logging"
 )
 
@@ -17,6 +20,25 @@ const (
 	EventRetryStatus       = uint8(2)
 )
 
+// Event content types
+const (
+	JSONContentType        = "application/json"
+	OctetStreamContentType = "application/octet-stream"
+)
+
+// Markers prefixing the stored events which are not plain JSON.
+// They can never start a JSON document, which tells them apart from the JSON events
+const (
+	binaryEventMarker     = byte(1)
+	compressedEventMarker = byte(2)
+)
+
+// compressionIDs identify the compression algorithm of the stored compressed events
+var compressionIDs = map[string]byte{
+	compression.Snappy: 1,
+	compression.Zstd:   2,
+}
+
 // AllowedEventStatus represents the allowed statuses for an event
 var AllowedEventStatus = map[uint8]struct{}{
 	EventUnprocessedStatus: {},
@@ -24,13 +46,47 @@ var AllowedEventStatus = map[uint8]struct{}{
 	EventRetryStatus:       {},
 }
 
-// Event represents the the event structure in the Event Bus
+// Event represents the the event structure in the Event Bus.
+// JSON events carry their payload in Body, any other content type in Data
 type Event struct {
-	ID        string          `json:"id"`
-	StreamID  string          `json:"stream_id"`
-	Status    uint8           `json:"status"`
-	CreatedAt time.Time       `json:"created_at"`
-	Body      json.RawMessage `json:"body"`
+	ID          string    `json:"id"`
+	StreamID    string    `json:"stream_id"`
+	Status      uint8     `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	ContentType string    `json:"content_type,omitempty"`
+	SchemaID    string    `json:"schema_id,omitempty"`
+	// Producer is the name of the client which wrote the event
+	Producer string `json:"producer,omitempty"`
+	// TraceContext is the W3C trace context of the write, consumers may continue the producer trace from it
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	Body         json.RawMessage   `json:"body,omitempty"`
+	Data         []byte            `json:"data,omitempty"`
+	// Compression is the algorithm the event is stored with, as set on its stream
+	Compression string `json:"-"`
+}
+
+// IsJSON tells whether the event payload is JSON.
+// Events written before content types existed are always JSON
+func (e Event) IsJSON() bool {
+	return IsJSONContentType(e.ContentType)
+}
+
+// IsJSONContentType tells whether the content type is JSON, which is the default one.
+// Parameters and case are ignored, e.g. application/json; charset=utf-8 is JSON too
+func IsJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == JSONContentType
+}
+
+// Payload gets the raw event payload regardless of its content type
+func (e Event) Payload() []byte {
+	if e.IsJSON() {
+		return e.Body
+	}
+	return e.Data
 }
 
 // Key generates the event specific key for storing in database
@@ -45,14 +101,82 @@ func (e Event) Key(status uint8) []byte {
 	return []byte(key)
 }
 
-// Value generates the event specific value for storing in database
+// Value generates the event specific value for storing in database.
+// JSON events are stored as JSON, while binary data is appended as is
+// after the JSON encoded event metadata, so that it is never base64 encoded.
+// The whole value is compressed when the event has a compression algorithm
 func (e Event) Value() []byte {
+	value := e.encode()
+	if e.Compression == compression.None {
+		return value
+	}
+	compressed, err := compression.Compress(e.Compression, value)
+	if err != nil {
+		logging.Logger.Error("could not compress event", zap.Error(err))
+		return value
+	}
+	return append([]byte{compressedEventMarker, compressionIDs[e.Compression]}, compressed...)
+}
+
+func (e Event) encode() []byte {
+	data := e.Data
+	e.Data = nil
 	bs, err := json.Marshal(e)
 	if err != nil {
 		logging.Logger.Error("could not marshal event", zap.Error(err))
 		return []byte{}
 	}
-	return bs
+	if data == nil {
+		return bs
+	}
+	value := make([]byte, 1+binary.MaxVarintLen64, 1+binary.MaxVarintLen64+len(bs)+len(data))
+	value[0] = binaryEventMarker
+	n := binary.PutUvarint(value[1:], uint64(len(bs)))
+	value = append(value[:1+n], bs...)
+	return append(value, data...)
+}
+
+// DecodeEvent decodes an event stored in database
+func DecodeEvent(value []byte) (Event, error) {
+	if len(value) == 0 || value[0] != compressedEventMarker {
+		return decodeEvent(value)
+	}
+	if len(value) < 2 {
+		return Event{}, Error{Message: "invalid compressed event value"}
+	}
+	for algorithm, id := range compressionIDs {
+		if id != value[1] {
+			continue
+		}
+		decompressed, err := compression.Decompress(algorithm, value[2:], 0)
+		if err != nil {
+			return Event{}, err
+		}
+		evt, err := decodeEvent(decompressed)
+		evt.Compression = algorithm
+		return evt, err
+	}
+	return Event{}, Error{Message: "invalid compressed event value"}
+}
+
+func decodeEvent(value []byte) (Event, error) {
+	var evt Event
+	if len(value) == 0 || value[0] != binaryEventMarker {
+		err := json.Unmarshal(value, &evt)
+		return evt, err
+	}
+	size, n := binary.Uvarint(value[1:])
+	start := 1 + n
+	if n <= 0 || uint64(len(value)-start) < size {
+		return evt, Error{Message: "invalid binary event value"}
+	}
+	end := start + int(size)
+	err := json.Unmarshal(value[start:end], &evt)
+	if err != nil {
+		return evt, err
+	}
+	evt.Data = append([]byte{}, value[end:]...)
+	return evt, nil
 }
 
 // ExpiresAt represents the TTL for an event to be stored inside the database
@@ -44,6 +44,100 @@ func Test_Event_Value(t *testing.T) {
 	assert.JSONEq(t, expected, string(bs))
 }
 
+func Test_Event_Value_Binary(t *testing.T) {
+	evt := testEvt
+	evt.Body = nil
+	evt.ContentType = "application/x-protobuf"
+	evt.Data = []byte{0, 1, 2, 255}
+
+	bs := evt.Value()
+
+	assert.Equal(t, binaryEventMarker, bs[0])
+	assert.Equal(t, []byte{0, 1, 2, 255}, bs[len(bs)-4:])
+	assert.NotContains(t, string(bs), "data")
+}
+
+func Test_DecodeEvent_JSON(t *testing.T) {
+	evt, err := DecodeEvent(testEvt.Value())
+
+	assert.NoError(t, err)
+	assert.Equal(t, testEvt.ID, evt.ID)
+	assert.JSONEq(t, string(testEvt.Body), string(evt.Body))
+	assert.True(t, evt.IsJSON())
+}
+
+func Test_IsJSONContentType(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		expected    bool
+	}{
+		{contentType: "", expected: true},
+		{contentType: "application/json", expected: true},
+		{contentType: "application/json; charset=utf-8", expected: true},
+		{contentType: "Application/JSON", expected: true},
+		{contentType: "application/x-protobuf", expected: false},
+		{contentType: "application/jsonx", expected: false},
+		{contentType: "application/json; charset", expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.contentType, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, IsJSONContentType(testCase.contentType))
+		})
+	}
+}
+
+func Test_DecodeEvent_Binary(t *testing.T) {
+	expected := testEvt
+	expected.Body = nil
+	expected.ContentType = "application/x-protobuf"
+	expected.Data = []byte{0, 1, 2, 255}
+
+	evt, err := DecodeEvent(expected.Value())
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, evt)
+	assert.False(t, evt.IsJSON())
+	assert.Equal(t, expected.Data, evt.Payload())
+}
+
+func Test_DecodeEvent_Compressed(t *testing.T) {
+	for _, algorithm := range []string{"snappy", "zstd"} {
+		t.Run(algorithm, func(t *testing.T) {
+			expected := testEvt
+			expected.Body = nil
+			expected.ContentType = "application/x-protobuf"
+			expected.Data = []byte{0, 1, 2, 255}
+			expected.Compression = algorithm
+			value := expected.Value()
+
+			evt, err := DecodeEvent(value)
+
+			assert.Equal(t, compressedEventMarker, value[0])
+			assert.NoError(t, err)
+			assert.Equal(t, expected, evt)
+		})
+	}
+}
+
+func Test_DecodeEvent_InvalidCompressedError(t *testing.T) {
+	_, err := DecodeEvent([]byte{compressedEventMarker, 100})
+
+	assert.EqualError(t, err, "invalid compressed event value")
+}
+
+func Test_DecodeEvent_InvalidBinaryError(t *testing.T) {
+	_, err := DecodeEvent([]byte{binaryEventMarker, 100, '{', '}'})
+
+	assert.EqualError(t, err, "invalid binary event value")
+}
+
+func Test_DecodeEvent_InvalidJSONError(t *testing.T) {
+	_, err := DecodeEvent([]byte("}"))
+
+	assert.Error(t, err)
+}
+
 func Test_Event_ExpiresAt(t *testing.T) {
 	expiresAt := testEvt.ExpiresAt()
 
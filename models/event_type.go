@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+)
+
+// EventType represents a version of a named event format registered in the schema registry.
+// Its ID is the schema ID the events written with it are tagged with
+type EventType struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Version       int             `json:"version"`
+	Compatibility string          `json:"compatibility"`
+	Schema        json.RawMessage `json:"schema"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// Key generates event type specific key to be stored inside the database
+func (t EventType) Key() []byte {
+	return []byte(fmt.Sprintf("schema:%s", t.ID))
+}
+
+// Value generates event type specific value to be stored inside the database
+func (t EventType) Value() []byte {
+	bs, err := json.Marshal(t)
+	if err != nil {
+		logging.Logger.Error("could not marshal event type", zap.Error(err))
+		return []byte{}
+	}
+	return bs
+}
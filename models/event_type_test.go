@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testEventType = EventType{
+		ID:            "event-type-id",
+		Name:          "user_created",
+		Version:       2,
+		Compatibility: "backward",
+		Schema:        json.RawMessage(`{"type":"object"}`),
+		CreatedAt:     testTime,
+	}
+)
+
+func Test_EventType_Key(t *testing.T) {
+	expected := []byte("schema:event-type-id")
+
+	bs := testEventType.Key()
+
+	assert.Equal(t, expected, bs)
+}
+
+func Test_EventType_Value(t *testing.T) {
+	expected := `{
+		"id":"event-type-id",
+		"name":"user_created",
+		"version":2,
+		"compatibility":"backward",
+		"schema":{"type":"object"},
+		"created_at":"2020-12-15T05:28:31.490416Z"
+	}`
+
+	bs := testEventType.Value()
+
+	assert.JSONEq(t, expected, string(bs))
+}
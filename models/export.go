@@ -0,0 +1,11 @@
+package models
+
+// ImportResult represents the outcome of importing a stream export into a stream
+type ImportResult struct {
+	Stream   Stream `json:"stream"`
+	Imported int    `json:"imported"`
+	// Skipped counts the events already in the stream, e.g. when importing the same export twice
+	Skipped int `json:"skipped"`
+	// Renamed counts the events imported under a new ID, their original one being taken by an event of another stream
+	Renamed int `json:"renamed"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// Health statuses
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// Health represents the health of the Event Bus, made of the health of its components
+type Health struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+}
+
+// ComponentHealth represents the health of a single component, along with the details of its check
+type ComponentHealth struct {
+	Status  string                 `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
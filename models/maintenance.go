@@ -0,0 +1,39 @@
+package models
+
+import "encoding/json"
+
+// StoredStream represents a stream as found in the storage, along with its stored events counted by status
+type StoredStream struct {
+	Stream      Stream `json:"stream"`
+	Unprocessed int64  `json:"unprocessed"`
+	Processed   int64  `json:"processed"`
+	Retry       int64  `json:"retry"`
+}
+
+// StoredKey represents a stored key along with its value as JSON, events being decoded
+// and the values which are not JSON being turned into JSON strings
+type StoredKey struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// IntegrityIssue represents a stored key failing the integrity checks
+type IntegrityIssue struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+// IntegrityReport represents the outcome of checking the stored data integrity
+type IntegrityReport struct {
+	// Keys counts the checked keys
+	Keys int `json:"keys"`
+	// Orphaned lists the events whose stream no longer exists
+	Orphaned []IntegrityIssue `json:"orphaned"`
+	// Unparseable lists the keys whose value cannot be decoded, which the Event Bus skips or fails on
+	Unparseable []IntegrityIssue `json:"unparseable"`
+}
+
+// Issues counts the issues found
+func (r IntegrityReport) Issues() int {
+	return len(r.Orphaned) + len(r.Unparseable)
+}
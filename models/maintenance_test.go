@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IntegrityReport_Issues(t *testing.T) {
+	report := IntegrityReport{
+		Keys:        10,
+		Orphaned:    []IntegrityIssue{{Key: "event:s1-id:0:2020-12-15T00:00:00Z:e1-id"}},
+		Unparseable: []IntegrityIssue{{Key: "stream:s2-id"}, {Key: "schema:t1-id"}},
+	}
+
+	assert.Equal(t, 3, report.Issues())
+	assert.Zero(t, IntegrityReport{Keys: 10}.Issues())
+}
@@ -0,0 +1,38 @@
+package models
+
+// Quota represents the limits enforced on the events of a stream or of a client.
+// Zero values mean unlimited
+type Quota struct {
+	// MaxEventSize is the maximum size in bytes of a single event payload
+	MaxEventSize int64 `json:"max_event_size,omitempty"`
+	// MaxEvents is the maximum number of stored events
+	MaxEvents int64 `json:"max_events,omitempty"`
+	// MaxBytes is the maximum total size in bytes of the stored event payloads
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+}
+
+// Usage represents the events currently stored for a stream or a client
+type Usage struct {
+	Events int64 `json:"events"`
+	Bytes  int64 `json:"bytes"`
+}
+
+// Validate checks that none of the quota limits is negative
+func (q Quota) Validate() error {
+	if q.MaxEventSize < 0 || q.MaxEvents < 0 || q.MaxBytes < 0 {
+		return InvalidQuotaError{}
+	}
+	return nil
+}
+
+// Exceeded names the limit an additional event of the given size would exceed, if any.
+// The event size itself is checked against MaxEventSize separately
+func (q Quota) Exceeded(usage Usage, size int64) (string, int64) {
+	if q.MaxEvents > 0 && usage.Events+1 > q.MaxEvents {
+		return "max_events", q.MaxEvents
+	}
+	if q.MaxBytes > 0 && usage.Bytes+size > q.MaxBytes {
+		return "max_bytes", q.MaxBytes
+	}
+	return "", 0
+}
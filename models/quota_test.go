@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Quota_Validate(t *testing.T) {
+	assert.NoError(t, Quota{}.Validate())
+	assert.NoError(t, Quota{MaxEventSize: 1, MaxEvents: 2, MaxBytes: 3}.Validate())
+	assert.Equal(t, InvalidQuotaError{}, Quota{MaxBytes: -1}.Validate())
+}
+
+func Test_Quota_Exceeded(t *testing.T) {
+	quota := Quota{MaxEvents: 2, MaxBytes: 100}
+
+	tests := []struct {
+		name  string
+		usage Usage
+		size  int64
+		limit string
+		value int64
+	}{
+		{name: "Within", usage: Usage{Events: 1, Bytes: 50}, size: 50},
+		{name: "MaxEvents", usage: Usage{Events: 2, Bytes: 50}, size: 10, limit: "max_events", value: 2},
+		{name: "MaxBytes", usage: Usage{Events: 1, Bytes: 50}, size: 51, limit: "max_bytes", value: 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limit, value := quota.Exceeded(test.usage, test.size)
+
+			assert.Equal(t, test.limit, limit)
+			assert.Equal(t, test.value, value)
+		})
+	}
+	limit, _ := Quota{}.Exceeded(Usage{Events: 100, Bytes: 100}, 100)
+	assert.Empty(t, limit)
+}
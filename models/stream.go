@@ -12,9 +12,20 @@ import (
 
 // Stream represents the Event Bus stream that stores certain events
 type Stream struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Compression string          `json:"compression,omitempty"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+	Quota
+	// Usage is the stream current usage, it is never stored
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// Backlog represents the events of a stream still waiting to be processed
+type Backlog struct {
+	Unprocessed int64 `json:"unprocessed"`
+	Retry       int64 `json:"retry"`
 }
 
 // Key generates stream specific key to be stored inside the database
@@ -24,6 +35,7 @@ func (s Stream) Key() []byte {
 
 // Value generates stream specific value to be stored inside the database
 func (s Stream) Value() []byte {
+	s.Usage = nil
 	bs, err := json.Marshal(s)
 	if err != nil {
 		logging.Logger.Error("could not marshal stream", zap.Error(err))
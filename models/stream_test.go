@@ -33,3 +33,20 @@ func Test_Stream_Value(t *testing.T) {
 
 	assert.JSONEq(t, expected, string(bs))
 }
+
+func Test_Stream_Value_Quota(t *testing.T) {
+	expected := `{
+		"id":"stream-id",
+		"name":"stream-name",
+		"created_at":"2020-12-15T05:28:31.490416Z",
+		"max_events":10,
+		"max_bytes":1024
+	}`
+	stream := testStream
+	stream.Quota = Quota{MaxEvents: 10, MaxBytes: 1024}
+	stream.Usage = &Usage{Events: 1, Bytes: 2}
+
+	bs := stream.Value()
+
+	assert.JSONEq(t, expected, string(bs))
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/metrics"
+)
+
+// accessKey is the context key of the access log entry of a call
+type accessKey struct{}
+
+// accessEntry collects what the access log records about a single call, with the same fields
+// the router logs the requests of the other transports with. Credentials are never part of it
+type accessEntry struct {
+	operation  string
+	remoteAddr string
+	client     string
+	stream     string
+	events     int
+	hasEvents  bool
+	bytes      int64
+}
+
+func newAccessEntry(ctx context.Context, fullMethod string) *accessEntry {
+	entry := &accessEntry{operation: operationName(fullMethod)}
+	if p, ok := peer.FromContext(ctx); ok {
+		entry.remoteAddr = p.Addr.String()
+	}
+	return entry
+}
+
+// setClient records the client authenticated on the call
+func setClient(ctx context.Context, client string) {
+	if entry, ok := ctx.Value(accessKey{}).(*accessEntry); ok {
+		entry.client = client
+	}
+}
+
+// setRequest records the received request, the stream being the one named in the request, if any
+func (e *accessEntry) setRequest(req interface{}) {
+	if msg, ok := req.(proto.Message); ok {
+		e.bytes += int64(proto.Size(msg))
+	}
+	if r, ok := req.(interface{ GetStreamName() string }); ok {
+		e.stream = r.GetStreamName()
+	}
+}
+
+// setResponse records the number of events written or returned
+func (e *accessEntry) setResponse(res interface{}) {
+	switch value := res.(type) {
+	case *EventsResponse:
+		e.events = len(value.Events)
+		e.hasEvents = true
+	case *WriteEventResponse:
+		e.events = 1
+		e.hasEvents = true
+	case *Event:
+		e.events++
+		e.hasEvents = true
+	}
+}
+
+// log writes the entry to the access log and observes the request metrics
+func (e *accessEntry) log(err error, duration time.Duration) {
+	metrics.ObserveRequest(e.operation, err, duration)
+
+	fields := []zap.Field{
+		zap.String("operation", e.operation),
+		zap.Int64("bytes", e.bytes),
+		zap.Duration("duration", duration),
+	}
+	if e.remoteAddr != "" {
+		fields = append(fields, zap.String("remote_addr", e.remoteAddr))
+	}
+	if e.client != "" {
+		fields = append(fields, zap.String("client", e.client))
+	}
+	if e.stream != "" {
+		fields = append(fields, zap.String("stream", e.stream))
+	}
+	if e.hasEvents {
+		fields = append(fields, zap.Int("events", e.events))
+	}
+	if err != nil {
+		fields = append(fields, zap.String("result", metrics.StatusError), zap.String("error", err.Error()))
+	} else {
+		fields = append(fields, zap.String("result", metrics.StatusSuccess))
+	}
+	logging.AccessLogger.Info("request", fields...)
+}
+
+// accessStream records the messages of a stream in its access log entry
+type accessStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	entry *accessEntry
+}
+
+// Context gets the context of the stream, carrying its access log entry
+func (s *accessStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg records the received request
+func (s *accessStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.entry.setRequest(m)
+	}
+	return err
+}
+
+// SendMsg records the sent events
+func (s *accessStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.entry.setResponse(m)
+	}
+	return err
+}
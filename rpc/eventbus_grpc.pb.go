@@ -0,0 +1,857 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// EventBusClient is the client API for EventBus service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EventBusClient interface {
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	CreateStream(ctx context.Context, in *CreateStreamRequest, opts ...grpc.CallOption) (*Stream, error)
+	DeleteStream(ctx context.Context, in *DeleteStreamRequest, opts ...grpc.CallOption) (*DeleteStreamResponse, error)
+	UpdateStream(ctx context.Context, in *UpdateStreamRequest, opts ...grpc.CallOption) (*Stream, error)
+	GetStreamInfo(ctx context.Context, in *GetStreamInfoRequest, opts ...grpc.CallOption) (*Stream, error)
+	ListStreams(ctx context.Context, in *ListStreamsRequest, opts ...grpc.CallOption) (*ListStreamsResponse, error)
+	GetStreamEvents(ctx context.Context, in *GetStreamEventsRequest, opts ...grpc.CallOption) (*EventsResponse, error)
+	WriteEvent(ctx context.Context, in *WriteEventRequest, opts ...grpc.CallOption) (*WriteEventResponse, error)
+	MarkEvent(ctx context.Context, in *MarkEventRequest, opts ...grpc.CallOption) (*MarkEventResponse, error)
+	ProcessEvents(ctx context.Context, in *ProcessEventsRequest, opts ...grpc.CallOption) (*EventsResponse, error)
+	RegisterEventType(ctx context.Context, in *RegisterEventTypeRequest, opts ...grpc.CallOption) (*EventType, error)
+	ListEventTypes(ctx context.Context, in *ListEventTypesRequest, opts ...grpc.CallOption) (*ListEventTypesResponse, error)
+	GetEventType(ctx context.Context, in *GetEventTypeRequest, opts ...grpc.CallOption) (*EventType, error)
+	GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*GetAuditLogResponse, error)
+	// ListConnections and Disconnect manage the TCP server connections, they are restricted to admin clients
+	ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error)
+	Disconnect(ctx context.Context, in *DisconnectRequest, opts ...grpc.CallOption) (*DisconnectResponse, error)
+	// Backup and Restore work on backup files on the server, they are restricted to admin clients
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error)
+	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
+	// ExportStream and ImportStream work on JSON Lines files on the server, they are restricted to admin clients
+	ExportStream(ctx context.Context, in *ExportStreamRequest, opts ...grpc.CallOption) (*ExportStreamResponse, error)
+	ImportStream(ctx context.Context, in *ImportStreamRequest, opts ...grpc.CallOption) (*ImportStreamResponse, error)
+	// Subscribe streams every event written to the stream after subscribing
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (EventBus_SubscribeClient, error)
+}
+
+type eventBusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEventBusClient(cc grpc.ClientConnInterface) EventBusClient {
+	return &eventBusClient{cc}
+}
+
+func (c *eventBusClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) CreateStream(ctx context.Context, in *CreateStreamRequest, opts ...grpc.CallOption) (*Stream, error) {
+	out := new(Stream)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/CreateStream", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) DeleteStream(ctx context.Context, in *DeleteStreamRequest, opts ...grpc.CallOption) (*DeleteStreamResponse, error) {
+	out := new(DeleteStreamResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/DeleteStream", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) UpdateStream(ctx context.Context, in *UpdateStreamRequest, opts ...grpc.CallOption) (*Stream, error) {
+	out := new(Stream)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/UpdateStream", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) GetStreamInfo(ctx context.Context, in *GetStreamInfoRequest, opts ...grpc.CallOption) (*Stream, error) {
+	out := new(Stream)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/GetStreamInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) ListStreams(ctx context.Context, in *ListStreamsRequest, opts ...grpc.CallOption) (*ListStreamsResponse, error) {
+	out := new(ListStreamsResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/ListStreams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) GetStreamEvents(ctx context.Context, in *GetStreamEventsRequest, opts ...grpc.CallOption) (*EventsResponse, error) {
+	out := new(EventsResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/GetStreamEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) WriteEvent(ctx context.Context, in *WriteEventRequest, opts ...grpc.CallOption) (*WriteEventResponse, error) {
+	out := new(WriteEventResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/WriteEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) MarkEvent(ctx context.Context, in *MarkEventRequest, opts ...grpc.CallOption) (*MarkEventResponse, error) {
+	out := new(MarkEventResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/MarkEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) ProcessEvents(ctx context.Context, in *ProcessEventsRequest, opts ...grpc.CallOption) (*EventsResponse, error) {
+	out := new(EventsResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/ProcessEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) RegisterEventType(ctx context.Context, in *RegisterEventTypeRequest, opts ...grpc.CallOption) (*EventType, error) {
+	out := new(EventType)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/RegisterEventType", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) ListEventTypes(ctx context.Context, in *ListEventTypesRequest, opts ...grpc.CallOption) (*ListEventTypesResponse, error) {
+	out := new(ListEventTypesResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/ListEventTypes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) GetEventType(ctx context.Context, in *GetEventTypeRequest, opts ...grpc.CallOption) (*EventType, error) {
+	out := new(EventType)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/GetEventType", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*GetAuditLogResponse, error) {
+	out := new(GetAuditLogResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/GetAuditLog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error) {
+	out := new(ListConnectionsResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/ListConnections", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) Disconnect(ctx context.Context, in *DisconnectRequest, opts ...grpc.CallOption) (*DisconnectResponse, error) {
+	out := new(DisconnectResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/Disconnect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error) {
+	out := new(BackupResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/Backup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error) {
+	out := new(RestoreResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/Restore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) ExportStream(ctx context.Context, in *ExportStreamRequest, opts ...grpc.CallOption) (*ExportStreamResponse, error) {
+	out := new(ExportStreamResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/ExportStream", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) ImportStream(ctx context.Context, in *ImportStreamRequest, opts ...grpc.CallOption) (*ImportStreamResponse, error) {
+	out := new(ImportStreamResponse)
+	err := c.cc.Invoke(ctx, "/eventbus.EventBus/ImportStream", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventBusClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (EventBus_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventBus_ServiceDesc.Streams[0], "/eventbus.EventBus/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventBusSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EventBus_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type eventBusSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventBusSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// EventBusServer is the server API for EventBus service.
+// All implementations must embed UnimplementedEventBusServer
+// for forward compatibility
+type EventBusServer interface {
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	CreateStream(context.Context, *CreateStreamRequest) (*Stream, error)
+	DeleteStream(context.Context, *DeleteStreamRequest) (*DeleteStreamResponse, error)
+	UpdateStream(context.Context, *UpdateStreamRequest) (*Stream, error)
+	GetStreamInfo(context.Context, *GetStreamInfoRequest) (*Stream, error)
+	ListStreams(context.Context, *ListStreamsRequest) (*ListStreamsResponse, error)
+	GetStreamEvents(context.Context, *GetStreamEventsRequest) (*EventsResponse, error)
+	WriteEvent(context.Context, *WriteEventRequest) (*WriteEventResponse, error)
+	MarkEvent(context.Context, *MarkEventRequest) (*MarkEventResponse, error)
+	ProcessEvents(context.Context, *ProcessEventsRequest) (*EventsResponse, error)
+	RegisterEventType(context.Context, *RegisterEventTypeRequest) (*EventType, error)
+	ListEventTypes(context.Context, *ListEventTypesRequest) (*ListEventTypesResponse, error)
+	GetEventType(context.Context, *GetEventTypeRequest) (*EventType, error)
+	GetAuditLog(context.Context, *GetAuditLogRequest) (*GetAuditLogResponse, error)
+	// ListConnections and Disconnect manage the TCP server connections, they are restricted to admin clients
+	ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error)
+	Disconnect(context.Context, *DisconnectRequest) (*DisconnectResponse, error)
+	// Backup and Restore work on backup files on the server, they are restricted to admin clients
+	Backup(context.Context, *BackupRequest) (*BackupResponse, error)
+	Restore(context.Context, *RestoreRequest) (*RestoreResponse, error)
+	// ExportStream and ImportStream work on JSON Lines files on the server, they are restricted to admin clients
+	ExportStream(context.Context, *ExportStreamRequest) (*ExportStreamResponse, error)
+	ImportStream(context.Context, *ImportStreamRequest) (*ImportStreamResponse, error)
+	// Subscribe streams every event written to the stream after subscribing
+	Subscribe(*SubscribeRequest, EventBus_SubscribeServer) error
+	mustEmbedUnimplementedEventBusServer()
+}
+
+// UnimplementedEventBusServer must be embedded to have forward compatible implementations.
+type UnimplementedEventBusServer struct {
+}
+
+func (UnimplementedEventBusServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedEventBusServer) CreateStream(context.Context, *CreateStreamRequest) (*Stream, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateStream not implemented")
+}
+func (UnimplementedEventBusServer) DeleteStream(context.Context, *DeleteStreamRequest) (*DeleteStreamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteStream not implemented")
+}
+func (UnimplementedEventBusServer) UpdateStream(context.Context, *UpdateStreamRequest) (*Stream, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateStream not implemented")
+}
+func (UnimplementedEventBusServer) GetStreamInfo(context.Context, *GetStreamInfoRequest) (*Stream, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStreamInfo not implemented")
+}
+func (UnimplementedEventBusServer) ListStreams(context.Context, *ListStreamsRequest) (*ListStreamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStreams not implemented")
+}
+func (UnimplementedEventBusServer) GetStreamEvents(context.Context, *GetStreamEventsRequest) (*EventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStreamEvents not implemented")
+}
+func (UnimplementedEventBusServer) WriteEvent(context.Context, *WriteEventRequest) (*WriteEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteEvent not implemented")
+}
+func (UnimplementedEventBusServer) MarkEvent(context.Context, *MarkEventRequest) (*MarkEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkEvent not implemented")
+}
+func (UnimplementedEventBusServer) ProcessEvents(context.Context, *ProcessEventsRequest) (*EventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessEvents not implemented")
+}
+func (UnimplementedEventBusServer) RegisterEventType(context.Context, *RegisterEventTypeRequest) (*EventType, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterEventType not implemented")
+}
+func (UnimplementedEventBusServer) ListEventTypes(context.Context, *ListEventTypesRequest) (*ListEventTypesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEventTypes not implemented")
+}
+func (UnimplementedEventBusServer) GetEventType(context.Context, *GetEventTypeRequest) (*EventType, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEventType not implemented")
+}
+func (UnimplementedEventBusServer) GetAuditLog(context.Context, *GetAuditLogRequest) (*GetAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
+func (UnimplementedEventBusServer) ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConnections not implemented")
+}
+func (UnimplementedEventBusServer) Disconnect(context.Context, *DisconnectRequest) (*DisconnectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Disconnect not implemented")
+}
+func (UnimplementedEventBusServer) Backup(context.Context, *BackupRequest) (*BackupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedEventBusServer) Restore(context.Context, *RestoreRequest) (*RestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedEventBusServer) ExportStream(context.Context, *ExportStreamRequest) (*ExportStreamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportStream not implemented")
+}
+func (UnimplementedEventBusServer) ImportStream(context.Context, *ImportStreamRequest) (*ImportStreamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportStream not implemented")
+}
+func (UnimplementedEventBusServer) Subscribe(*SubscribeRequest, EventBus_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedEventBusServer) mustEmbedUnimplementedEventBusServer() {}
+
+// UnsafeEventBusServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EventBusServer will
+// result in compilation errors.
+type UnsafeEventBusServer interface {
+	mustEmbedUnimplementedEventBusServer()
+}
+
+func RegisterEventBusServer(s grpc.ServiceRegistrar, srv EventBusServer) {
+	s.RegisterService(&EventBus_ServiceDesc, srv)
+}
+
+func _EventBus_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_CreateStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).CreateStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/CreateStream",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).CreateStream(ctx, req.(*CreateStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_DeleteStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).DeleteStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/DeleteStream",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).DeleteStream(ctx, req.(*DeleteStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_UpdateStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).UpdateStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/UpdateStream",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).UpdateStream(ctx, req.(*UpdateStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_GetStreamInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStreamInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).GetStreamInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/GetStreamInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).GetStreamInfo(ctx, req.(*GetStreamInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_ListStreams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStreamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).ListStreams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/ListStreams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).ListStreams(ctx, req.(*ListStreamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_GetStreamEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStreamEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).GetStreamEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/GetStreamEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).GetStreamEvents(ctx, req.(*GetStreamEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_WriteEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).WriteEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/WriteEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).WriteEvent(ctx, req.(*WriteEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_MarkEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).MarkEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/MarkEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).MarkEvent(ctx, req.(*MarkEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_ProcessEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).ProcessEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/ProcessEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).ProcessEvents(ctx, req.(*ProcessEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_RegisterEventType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterEventTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).RegisterEventType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/RegisterEventType",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).RegisterEventType(ctx, req.(*RegisterEventTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_ListEventTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventTypesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).ListEventTypes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/ListEventTypes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).ListEventTypes(ctx, req.(*ListEventTypesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_GetEventType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).GetEventType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/GetEventType",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).GetEventType(ctx, req.(*GetEventTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/GetAuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).GetAuditLog(ctx, req.(*GetAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_ListConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConnectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).ListConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/ListConnections",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).ListConnections(ctx, req.(*ListConnectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).Disconnect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/Disconnect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).Disconnect(ctx, req.(*DisconnectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_Backup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).Backup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/Backup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).Backup(ctx, req.(*BackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/Restore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).Restore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_ExportStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).ExportStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/ExportStream",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).ExportStream(ctx, req.(*ExportStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_ImportStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventBusServer).ImportStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eventbus.EventBus/ImportStream",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventBusServer).ImportStream(ctx, req.(*ImportStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventBus_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventBusServer).Subscribe(m, &eventBusSubscribeServer{stream})
+}
+
+type EventBus_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type eventBusSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventBusSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// EventBus_ServiceDesc is the grpc.ServiceDesc for EventBus service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EventBus_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eventbus.EventBus",
+	HandlerType: (*EventBusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Health",
+			Handler:    _EventBus_Health_Handler,
+		},
+		{
+			MethodName: "CreateStream",
+			Handler:    _EventBus_CreateStream_Handler,
+		},
+		{
+			MethodName: "DeleteStream",
+			Handler:    _EventBus_DeleteStream_Handler,
+		},
+		{
+			MethodName: "UpdateStream",
+			Handler:    _EventBus_UpdateStream_Handler,
+		},
+		{
+			MethodName: "GetStreamInfo",
+			Handler:    _EventBus_GetStreamInfo_Handler,
+		},
+		{
+			MethodName: "ListStreams",
+			Handler:    _EventBus_ListStreams_Handler,
+		},
+		{
+			MethodName: "GetStreamEvents",
+			Handler:    _EventBus_GetStreamEvents_Handler,
+		},
+		{
+			MethodName: "WriteEvent",
+			Handler:    _EventBus_WriteEvent_Handler,
+		},
+		{
+			MethodName: "MarkEvent",
+			Handler:    _EventBus_MarkEvent_Handler,
+		},
+		{
+			MethodName: "ProcessEvents",
+			Handler:    _EventBus_ProcessEvents_Handler,
+		},
+		{
+			MethodName: "RegisterEventType",
+			Handler:    _EventBus_RegisterEventType_Handler,
+		},
+		{
+			MethodName: "ListEventTypes",
+			Handler:    _EventBus_ListEventTypes_Handler,
+		},
+		{
+			MethodName: "GetEventType",
+			Handler:    _EventBus_GetEventType_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _EventBus_GetAuditLog_Handler,
+		},
+		{
+			MethodName: "ListConnections",
+			Handler:    _EventBus_ListConnections_Handler,
+		},
+		{
+			MethodName: "Disconnect",
+			Handler:    _EventBus_Disconnect_Handler,
+		},
+		{
+			MethodName: "Backup",
+			Handler:    _EventBus_Backup_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _EventBus_Restore_Handler,
+		},
+		{
+			MethodName: "ExportStream",
+			Handler:    _EventBus_ExportStream_Handler,
+		},
+		{
+			MethodName: "ImportStream",
+			Handler:    _EventBus_ImportStream_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _EventBus_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "eventbus.proto",
+}
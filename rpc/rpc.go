@@ -0,0 +1,381 @@
+// Package rpc exposes the Event Bus operations over gRPC.
+// The service definition lives in eventbus.proto, regenerate the code after changing it:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative eventbus.proto
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-web-dev/event-bus/config"
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/services"
+	"github.com/go-web-dev/event-bus/tracing"
+)
+
+// Auth metadata keys
+const (
+	clientIDKey     = "client-id"
+	clientSecretKey = "client-secret"
+)
+
+// healthMethod is the only method which does not require authentication
+const healthMethod = "/eventbus.EventBus/Health"
+
+// adminMethods are the methods only the admin clients are allowed to call
+var adminMethods = map[string]bool{
+	"/eventbus.EventBus/ListConnections": true,
+	"/eventbus.EventBus/Disconnect":      true,
+	"/eventbus.EventBus/Backup":          true,
+	"/eventbus.EventBus/Restore":         true,
+	"/eventbus.EventBus/ExportStream":    true,
+	"/eventbus.EventBus/ImportStream":    true,
+	"/eventbus.EventBus/GetAuditLog":     true,
+}
+
+// clientKey is the context key of the name of the client authenticated on a call
+type clientKey struct{}
+
+// connKey is the context key of the ID the limiter registered a stream with
+type connKey struct{}
+
+// Bus represents the Event Bus operations exposed over gRPC
+type Bus interface {
+	CreateStream(ctx context.Context, client, streamName, compression string, schema json.RawMessage, quota models.Quota) (models.Stream, error)
+	DeleteStream(ctx context.Context, client, streamName string) error
+	UpdateStream(ctx context.Context, client, streamName string, schema json.RawMessage, quota models.Quota) (models.Stream, error)
+	GetStreamInfo(streamName string) (models.Stream, error)
+	ListStreams() []models.Stream
+	GetStreamEvents(ctx context.Context, streamName string) ([]models.Event, error)
+	WriteEvent(ctx context.Context, client, streamName string, event json.RawMessage) error
+	WriteData(ctx context.Context, client, streamName, contentType string, data []byte) error
+	MarkEvent(ctx context.Context, client, eventID string, status uint8) error
+	ProcessEvents(ctx context.Context, streamName string, retry bool) ([]models.Event, error)
+	WriteTypedEvent(ctx context.Context, client, streamName, eventType string, version int, event json.RawMessage) error
+	RegisterEventType(ctx context.Context, name, compatibility string, schema json.RawMessage) (models.EventType, error)
+	ListEventTypes(name string) ([]models.EventType, error)
+	GetEventType(name string, version int) (models.EventType, error)
+	GetEventTypeBySchemaID(schemaID string) (models.EventType, error)
+	GetAuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+	Audit(ctx context.Context, entry models.AuditEntry) error
+	BackupFile(ctx context.Context, path string, since uint64) (uint64, error)
+	RestoreFile(ctx context.Context, path string, incremental bool) error
+	ExportStreamFile(ctx context.Context, streamName, path string) (int, error)
+	ImportStreamFile(ctx context.Context, streamName, path string) (models.ImportResult, error)
+	Subscribe(streamName string) (*services.Subscription, error)
+	Unsubscribe(sub *services.Subscription)
+}
+
+// ConfigManager represents the application configuration manager
+type ConfigManager interface {
+	GetAuth() config.ClientAuth
+}
+
+// HealthChecker represents the deep health checks of the Event Bus components
+type HealthChecker interface {
+	Check(ctx context.Context) models.Health
+}
+
+// ConnectionManager represents the registry of the connections admin clients list and disconnect
+type ConnectionManager interface {
+	List() []models.Connection
+	Disconnect(connID int) error
+	DisconnectClient(client string) []int
+}
+
+// ClientLimiter represents the registry of the connections sharing the client limits with the TCP server
+type ClientLimiter interface {
+	Open() (int, error)
+	Connect(transport, remoteAddr string, disconnect func()) (int, error)
+	Authorize(connID int, client string, size int) error
+	AddSubscriptions(connID int, delta int)
+	Release(connID int)
+}
+
+// Settings represents the gRPC server settings
+type Settings struct {
+	Addr           string
+	Bus            Bus
+	Cfg            ConfigManager
+	Health         HealthChecker
+	Connections    ConnectionManager
+	MaxRequestSize int
+	// Limiter enforces the client limits of the TCP server on the calls, no limits are enforced when nil
+	Limiter ClientLimiter
+}
+
+// Server represents the gRPC server
+type Server struct {
+	grpcServer *grpc.Server
+	quit       chan struct{}
+	stopOnce   sync.Once
+	mu         sync.Mutex
+	serveErr   error
+}
+
+// NewServer creates a new gRPC server serving the Event Bus service
+func NewServer(settings Settings) *Server {
+	srv := &Server{
+		quit: make(chan struct{}),
+	}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryObserve, unaryTrace, unaryAuth(settings.Cfg, settings.Limiter)),
+		grpc.ChainStreamInterceptor(streamObserve, streamAuth(settings.Cfg, settings.Limiter)),
+	}
+	if settings.MaxRequestSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(settings.MaxRequestSize))
+	}
+	srv.grpcServer = grpc.NewServer(opts...)
+	RegisterEventBusServer(srv.grpcServer, &service{
+		bus:     settings.Bus,
+		health:  settings.Health,
+		conns:   settings.Connections,
+		limiter: settings.Limiter,
+		quit:    srv.quit,
+	})
+	return srv
+}
+
+// ListenAndServe spins up the gRPC server
+func ListenAndServe(settings Settings) (*Server, error) {
+	li, err := net.Listen("tcp", settings.Addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := NewServer(settings)
+	go func() {
+		logging.Logger.Info("grpc server is up and running on address", zap.String("addr", li.Addr().String()))
+		err := srv.grpcServer.Serve(li)
+		if err != nil {
+			logging.Logger.Error("grpc server stopped unexpectedly", zap.Error(err))
+			srv.mu.Lock()
+			srv.serveErr = err
+			srv.mu.Unlock()
+		}
+	}()
+	return srv, nil
+}
+
+// Err returns the error the gRPC server stopped serving with, nil while serving
+func (srv *Server) Err() error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.serveErr
+}
+
+// Stop gracefully shuts down the gRPC server waiting for in-flight calls up to the given timeout.
+// Subscriptions are ended right away
+func (srv *Server) Stop(timeout time.Duration) {
+	srv.stopOnce.Do(func() {
+		logging.Logger.Info("shutting down the grpc server")
+		close(srv.quit)
+		done := make(chan struct{})
+		go func() {
+			srv.grpcServer.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(timeout):
+			logging.Logger.Info("grpc shutdown timeout exceeded, closing all calls")
+			srv.grpcServer.Stop()
+		}
+	})
+}
+
+// unaryObserve logs every call to the access log and records it in the request metrics,
+// under the name of the matching operation
+func unaryObserve(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	entry := newAccessEntry(ctx, info.FullMethod)
+	entry.setRequest(req)
+	defer func(start time.Time) {
+		if err == nil {
+			entry.setResponse(res)
+		}
+		entry.log(err, time.Since(start))
+	}(time.Now())
+	return handler(context.WithValue(ctx, accessKey{}, entry), req)
+}
+
+// streamObserve logs every stream to the access log once it ends, the same way unaryObserve does
+func streamObserve(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	entry := newAccessEntry(ss.Context(), info.FullMethod)
+	defer func(start time.Time) {
+		entry.log(err, time.Since(start))
+	}(time.Now())
+	return handler(srv, &accessStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), accessKey{}, entry),
+		entry:        entry,
+	})
+}
+
+// operationName names a method the way the matching operation is named, e.g. create_stream for /eventbus.EventBus/CreateStream
+func operationName(fullMethod string) string {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	var b strings.Builder
+	for i, r := range method {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// unaryTrace wraps every call in a span continuing the trace context passed in the call metadata
+func unaryTrace(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	traceContext := map[string]string{}
+	for key, values := range md {
+		traceContext[key] = first(values)
+	}
+	ctx, span := tracing.Start(
+		tracing.Extract(ctx, traceContext),
+		info.FullMethod,
+		trace.SpanKindServer,
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.method", info.FullMethod),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+	return handler(ctx, req)
+}
+
+// unaryAuth authenticates every call but the health one, taking it out of the client's limits.
+// Health calls need no credentials, valid ones only get the details of the deep checks
+func unaryAuth(cfg ConfigManager, limiter ClientLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == healthMethod {
+			if client, _, err := authorize(ctx, cfg); err == nil {
+				setClient(ctx, client)
+				ctx = context.WithValue(ctx, clientKey{}, client)
+			}
+		} else {
+			client, credentials, err := authorize(ctx, cfg)
+			if err != nil {
+				return nil, err
+			}
+			setClient(ctx, client)
+			if adminMethods[info.FullMethod] && !credentials.Admin {
+				return nil, status.Error(codes.PermissionDenied, models.ForbiddenError{}.Error())
+			}
+			if limiter != nil {
+				// every call counts as a client connection while it is served
+				connID, err := limiter.Open()
+				if err != nil {
+					return nil, toStatus(err)
+				}
+				defer limiter.Release(connID)
+				size := 0
+				if msg, ok := req.(proto.Message); ok {
+					size = proto.Size(msg)
+				}
+				err = limiter.Authorize(connID, client, size)
+				if err != nil {
+					return nil, toStatus(err)
+				}
+			}
+			ctx = context.WithValue(ctx, clientKey{}, client)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuth authenticates the streams, every stream counting as a client connection until it ends.
+// Streams are listed along with the TCP connections, an admin client disconnecting one ending it with ABORTED
+func streamAuth(cfg ConfigManager, limiter ClientLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		client, _, err := authorize(ss.Context(), cfg)
+		if err != nil {
+			return err
+		}
+		setClient(ss.Context(), client)
+		if limiter == nil {
+			return handler(srv, ss)
+		}
+
+		ctx, cancel := context.WithCancel(ss.Context())
+		defer cancel()
+		var disconnected int32
+		remoteAddr := ""
+		if p, ok := peer.FromContext(ctx); ok {
+			remoteAddr = p.Addr.String()
+		}
+		connID, err := limiter.Connect(models.GRPCTransport, remoteAddr, func() {
+			atomic.StoreInt32(&disconnected, 1)
+			cancel()
+		})
+		if err != nil {
+			return toStatus(err)
+		}
+		defer limiter.Release(connID)
+		// the request is not received yet, streams only take a request out of the rate limits
+		err = limiter.Authorize(connID, client, 0)
+		if err != nil {
+			return toStatus(err)
+		}
+		err = handler(srv, &serverStream{ServerStream: ss, ctx: context.WithValue(ctx, connKey{}, connID)})
+		if atomic.LoadInt32(&disconnected) == 1 {
+			return status.Error(codes.Aborted, "disconnected by an admin client")
+		}
+		return err
+	}
+}
+
+// serverStream replaces the context of a stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context gets the context of the stream
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize checks the client credentials passed in the call metadata and names the client
+func authorize(ctx context.Context, cfg ConfigManager) (string, config.ClientCredentials, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	client, c, ok := cfg.GetAuth().Authenticate(first(md.Get(clientIDKey)), first(md.Get(clientSecretKey)))
+	if !ok {
+		return "", config.ClientCredentials{}, status.Error(codes.Unauthenticated, models.AuthError{}.Error())
+	}
+	logging.Logger.Debug("authorized client", zap.String("client", client))
+	return client, c, nil
+}
+
+// clientName gets the name of the client authenticated on the call
+func clientName(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
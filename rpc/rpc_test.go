@@ -0,0 +1,1285 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/go-web-dev/event-bus/config"
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/metrics"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
+	"github.com/go-web-dev/event-bus/services"
+	"github.com/go-web-dev/event-bus/testutils"
+	"github.com/go-web-dev/event-bus/tracing"
+)
+
+const (
+	testTimeStr = "2020-12-15T05:28:31.490416Z"
+)
+
+var (
+	testTime, _ = time.Parse(time.RFC3339, testTimeStr)
+	errTest     = errors.New("some test error")
+)
+
+type rpcSuite struct {
+	testutils.Suite
+	bus     *busMock
+	cfg     *cfgMock
+	checker *checkerMock
+	conns   *connsMock
+	limiter ClientLimiter
+	server  *Server
+	conn    *grpc.ClientConn
+	client  EventBusClient
+	ctx     context.Context
+}
+
+func (s *rpcSuite) SetupSuite() {
+	logging.Logger = testutils.Logger(s.T(), nil)
+}
+
+func (s *rpcSuite) SetupTest() {
+	s.bus = new(busMock)
+	s.bus.Test(s.T())
+	s.cfg = new(cfgMock)
+	s.cfg.Test(s.T())
+	s.checker = new(checkerMock)
+	s.checker.Test(s.T())
+	s.conns = new(connsMock)
+	s.conns.Test(s.T())
+	s.limiter = nil
+	s.cfg.
+		On("GetAuth").
+		Return(config.ClientAuth{
+			"root": config.ClientCredentials{
+				ClientID:     "root_client_id",
+				ClientSecret: "root_client_secret",
+			},
+			"admin": config.ClientCredentials{
+				ClientID:     "admin_client_id",
+				ClientSecret: "admin_client_secret",
+				Admin:        true,
+			},
+		})
+	s.serve(s.bus)
+	s.ctx = metadata.AppendToOutgoingContext(
+		context.Background(),
+		clientIDKey, "root_client_id",
+		clientSecretKey, "root_client_secret",
+	)
+}
+
+func (s *rpcSuite) TearDownTest() {
+	s.Require().NoError(s.conn.Close())
+	s.server.Stop(time.Second)
+	s.bus.AssertExpectations(s.T())
+	s.checker.AssertExpectations(s.T())
+	s.conns.AssertExpectations(s.T())
+}
+
+func (s *rpcSuite) Test_Health_Success() {
+	res, err := s.client.Health(context.Background(), &HealthRequest{})
+
+	s.Require().NoError(err)
+	s.NotNil(res)
+	s.Empty(res.Status)
+}
+
+func (s *rpcSuite) Test_Health_Deep_Success() {
+	s.checker.
+		On("Check", mock.Anything).
+		Return(models.Health{
+			Status: models.HealthUp,
+			Components: map[string]models.ComponentHealth{
+				"goroutines": {Status: models.HealthUp, Details: map[string]interface{}{"count": 12}},
+			},
+		}).
+		Once()
+
+	res, err := s.client.Health(s.ctx, &HealthRequest{Deep: true})
+
+	s.Require().NoError(err)
+	s.Equal("up", res.Status)
+	s.Require().Contains(res.Components, "goroutines")
+	s.Equal("up", res.Components["goroutines"].Status)
+	s.Equal(map[string]string{"count": "12"}, res.Components["goroutines"].Details)
+}
+
+func (s *rpcSuite) Test_Health_Deep_UnhealthyError() {
+	s.checker.
+		On("Check", mock.Anything).
+		Return(models.Health{
+			Status: models.HealthDown,
+			Components: map[string]models.ComponentHealth{
+				"storage": {Status: models.HealthDown, Error: "DB Closed"},
+			},
+		}).
+		Once()
+
+	res, err := s.client.Health(s.ctx, &HealthRequest{Deep: true})
+
+	s.assertStatus(codes.Unavailable, "service is unhealthy", err)
+	s.Nil(res)
+	details := status.Convert(err).Details()
+	s.Require().Len(details, 1)
+	health, ok := details[0].(*HealthResponse)
+	s.Require().True(ok)
+	s.Equal("down", health.Status)
+	s.Equal("DB Closed", health.Components["storage"].Error)
+}
+
+func (s *rpcSuite) Test_Health_Deep_Unauthenticated() {
+	s.checker.
+		On("Check", mock.Anything).
+		Return(models.Health{
+			Status: models.HealthDown,
+			Components: map[string]models.ComponentHealth{
+				"storage": {Status: models.HealthDown, Error: "DB Closed"},
+			},
+		}).
+		Once()
+
+	_, err := s.client.Health(context.Background(), &HealthRequest{Deep: true})
+
+	s.assertStatus(codes.Unavailable, "service is unhealthy", err)
+	details := status.Convert(err).Details()
+	s.Require().Len(details, 1)
+	health, ok := details[0].(*HealthResponse)
+	s.Require().True(ok)
+	s.Equal("down", health.Status)
+	s.Empty(health.Components)
+}
+
+func (s *rpcSuite) Test_Auth_Error() {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), clientIDKey, "root_client_id")
+
+	res, err := s.client.GetStreamInfo(ctx, &GetStreamInfoRequest{StreamName: "s1"})
+
+	s.assertStatus(codes.Unauthenticated, "unauthorized to make request", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_CreateStream_Success() {
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "s1", "", json.RawMessage(nil), models.Quota{}).
+		Return(models.Stream{ID: "s1-id", Name: "s1", CreatedAt: testTime}, nil).
+		Once()
+
+	res, err := s.client.CreateStream(s.ctx, &CreateStreamRequest{StreamName: "s1"})
+
+	s.Require().NoError(err)
+	s.Equal("s1-id", res.Id)
+	s.Equal("s1", res.Name)
+	s.Equal(testTime, res.CreatedAt.AsTime())
+}
+
+func (s *rpcSuite) Test_CreateStream_RequiredFieldError() {
+	res, err := s.client.CreateStream(s.ctx, &CreateStreamRequest{})
+
+	s.assertStatus(codes.InvalidArgument, "'stream_name' field is required", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_CreateStream_StreamExistsError() {
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "s1", "", json.RawMessage(nil), models.Quota{}).
+		Return(models.Stream{}, models.StreamExistsError{Name: "s1"}).
+		Once()
+
+	res, err := s.client.CreateStream(s.ctx, &CreateStreamRequest{StreamName: "s1"})
+
+	s.assertStatus(codes.AlreadyExists, "stream: 's1' already exists", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_CreateStream_Compression() {
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "s1", "zstd", json.RawMessage(nil), models.Quota{}).
+		Return(models.Stream{ID: "s1-id", Name: "s1", CreatedAt: testTime, Compression: "zstd"}, nil).
+		Once()
+
+	res, err := s.client.CreateStream(s.ctx, &CreateStreamRequest{StreamName: "s1", Compression: "zstd"})
+
+	s.Require().NoError(err)
+	s.Equal("zstd", res.Compression)
+}
+
+func (s *rpcSuite) Test_CreateStream_UnsupportedCompressionError() {
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "s1", "gzip", json.RawMessage(nil), models.Quota{}).
+		Return(models.Stream{}, models.UnsupportedCompressionError{}).
+		Once()
+
+	res, err := s.client.CreateStream(s.ctx, &CreateStreamRequest{StreamName: "s1", Compression: "gzip"})
+
+	s.assertStatus(codes.InvalidArgument, "compression must be one of: 'snappy', 'zstd'", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_CreateStream_Schema() {
+	schema := []byte(`{"type": "object"}`)
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "s1", "", json.RawMessage(schema), models.Quota{}).
+		Return(models.Stream{ID: "s1-id", Name: "s1", CreatedAt: testTime, Schema: schema}, nil).
+		Once()
+
+	res, err := s.client.CreateStream(s.ctx, &CreateStreamRequest{StreamName: "s1", Schema: schema})
+
+	s.Require().NoError(err)
+	s.Equal(schema, res.Schema)
+}
+
+func (s *rpcSuite) Test_CreateStream_InvalidSchemaError() {
+	schema := []byte(`{"type": "unknown"}`)
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "s1", "", json.RawMessage(schema), models.Quota{}).
+		Return(models.Stream{}, models.InvalidSchemaError{Reason: "unknown type"}).
+		Once()
+
+	res, err := s.client.CreateStream(s.ctx, &CreateStreamRequest{StreamName: "s1", Schema: schema})
+
+	s.assertStatus(codes.InvalidArgument, "invalid schema provided: unknown type", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_UpdateStream_Success() {
+	schema := []byte(`{"type": "object"}`)
+	s.bus.
+		On("UpdateStream", mock.Anything, "root", "s1", json.RawMessage(schema), models.Quota{}).
+		Return(models.Stream{ID: "s1-id", Name: "s1", CreatedAt: testTime, Schema: schema}, nil).
+		Once()
+
+	res, err := s.client.UpdateStream(s.ctx, &UpdateStreamRequest{StreamName: "s1", Schema: schema})
+
+	s.Require().NoError(err)
+	s.Equal("s1-id", res.Id)
+	s.Equal(schema, res.Schema)
+}
+
+func (s *rpcSuite) Test_UpdateStream_StreamNotFoundError() {
+	s.bus.
+		On("UpdateStream", mock.Anything, "root", "s1", json.RawMessage(nil), models.Quota{}).
+		Return(models.Stream{}, models.StreamNotFoundError{Name: "s1"}).
+		Once()
+
+	res, err := s.client.UpdateStream(s.ctx, &UpdateStreamRequest{StreamName: "s1"})
+
+	s.assertStatus(codes.NotFound, "stream 's1' not found", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_DeleteStream_Success() {
+	s.bus.
+		On("DeleteStream", mock.Anything, "root", "s1").
+		Return(nil).
+		Once()
+
+	res, err := s.client.DeleteStream(s.ctx, &DeleteStreamRequest{StreamName: "s1"})
+
+	s.Require().NoError(err)
+	s.NotNil(res)
+}
+
+func (s *rpcSuite) Test_TraceContext() {
+	memory := tracetest.NewInMemoryExporter()
+	shutdown, err := tracing.Init(tracing.Settings{Exporter: tracing.MemoryExporter, SampleRatio: 1, Memory: memory})
+	s.Require().NoError(err)
+	defer func() {
+		s.NoError(shutdown(context.Background()))
+	}()
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.AppendToOutgoingContext(s.ctx, "traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	inTrace := mock.MatchedBy(func(ctx context.Context) bool {
+		return trace.SpanContextFromContext(ctx).TraceID().String() == traceID
+	})
+	s.bus.
+		On("DeleteStream", inTrace, "root", "s1").
+		Return(nil).
+		Once()
+
+	_, err = s.client.DeleteStream(ctx, &DeleteStreamRequest{StreamName: "s1"})
+
+	s.Require().NoError(err)
+	spans := memory.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal("/eventbus.EventBus/DeleteStream", spans[0].Name)
+	s.Equal("00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+}
+
+func (s *rpcSuite) Test_Metrics() {
+	successCount := s.requestsCount("delete_stream", "success")
+	errorCount := s.requestsCount("get_stream_info", "error")
+	s.bus.
+		On("DeleteStream", mock.Anything, "root", "s1").
+		Return(nil).
+		Once()
+
+	_, err := s.client.DeleteStream(s.ctx, &DeleteStreamRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+	_, err = s.client.GetStreamInfo(context.Background(), &GetStreamInfoRequest{StreamName: "s1"})
+	s.Require().Error(err)
+
+	s.Equal(successCount+1, s.requestsCount("delete_stream", "success"))
+	s.Equal(errorCount+1, s.requestsCount("get_stream_info", "error"))
+}
+
+func (s *rpcSuite) Test_Metrics_Subscribe() {
+	s.newBus()
+	errorCount := s.requestsCount("subscribe", "error")
+	stream, err := s.client.Subscribe(s.ctx, &SubscribeRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+
+	_, err = stream.Recv()
+
+	s.Require().Error(err)
+	s.Eventually(func() bool {
+		return s.requestsCount("subscribe", "error") == errorCount+1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *rpcSuite) Test_operationName() {
+	s.Equal("create_stream", operationName("/eventbus.EventBus/CreateStream"))
+	s.Equal("get_audit_log", operationName("/eventbus.EventBus/GetAuditLog"))
+	s.Equal("subscribe", operationName("/eventbus.EventBus/Subscribe"))
+}
+
+func (s *rpcSuite) Test_AccessLog() {
+	logs := s.observeAccessLog()
+	s.bus.
+		On("ProcessEvents", mock.Anything, "s1", false).
+		Return([]models.Event{{ID: "e1"}, {ID: "e2"}}, nil).
+		Once()
+	req := &ProcessEventsRequest{StreamName: "s1"}
+
+	_, err := s.client.ProcessEvents(s.ctx, req)
+
+	s.Require().NoError(err)
+	entries := logs.AllUntimed()
+	s.Require().Len(entries, 1)
+	s.Equal("request", entries[0].Message)
+	fields := entries[0].ContextMap()
+	s.Require().Contains(fields, "duration")
+	delete(fields, "duration")
+	s.Equal(map[string]interface{}{
+		"remote_addr": "bufconn",
+		"operation":   "process_events",
+		"client":      "root",
+		"stream":      "s1",
+		"events":      int64(2),
+		"bytes":       int64(proto.Size(req)),
+		"result":      "success",
+	}, fields)
+	s.NotContains(s.JSONMarshal(fields), "root_client_secret")
+}
+
+func (s *rpcSuite) Test_AccessLog_Error() {
+	logs := s.observeAccessLog()
+
+	_, err := s.client.WriteEvent(context.Background(), &WriteEventRequest{StreamName: "s1", Body: []byte("{}")})
+
+	s.Require().Error(err)
+	entries := logs.AllUntimed()
+	s.Require().Len(entries, 1)
+	fields := entries[0].ContextMap()
+	s.Equal("write_event", fields["operation"])
+	s.Equal("s1", fields["stream"])
+	s.Equal("error", fields["result"])
+	s.Contains(fields["error"], models.AuthError{}.Error())
+	s.NotContains(fields, "client")
+	s.NotContains(fields, "events")
+}
+
+func (s *rpcSuite) Test_AccessLog_Subscribe() {
+	logs := s.observeAccessLog()
+	s.newBus()
+	stream, err := s.client.Subscribe(s.ctx, &SubscribeRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+
+	_, err = stream.Recv()
+
+	s.Require().Error(err)
+	s.Eventually(func() bool {
+		return logs.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+	fields := logs.AllUntimed()[0].ContextMap()
+	s.Equal("subscribe", fields["operation"])
+	s.Equal("root", fields["client"])
+	s.Equal("s1", fields["stream"])
+	s.Equal("error", fields["result"])
+}
+
+func (s *rpcSuite) Test_CreateStream_Quota() {
+	quota := models.Quota{MaxEventSize: 1024, MaxEvents: 100, MaxBytes: 10240}
+	s.bus.
+		On("CreateStream", mock.Anything, "root", "s1", "", json.RawMessage(nil), quota).
+		Return(models.Stream{ID: "s1-id", Name: "s1", CreatedAt: testTime, Quota: quota}, nil).
+		Once()
+
+	res, err := s.client.CreateStream(s.ctx, &CreateStreamRequest{
+		StreamName: "s1",
+		Quota:      &Quota{MaxEventSize: 1024, MaxEvents: 100, MaxBytes: 10240},
+	})
+
+	s.Require().NoError(err)
+	s.Equal(int64(100), res.Quota.MaxEvents)
+	s.Nil(res.Usage)
+}
+
+func (s *rpcSuite) Test_GetStreamInfo_Usage() {
+	s.bus.
+		On("GetStreamInfo", "s1").
+		Return(models.Stream{ID: "s1-id", Name: "s1", CreatedAt: testTime, Usage: &models.Usage{Events: 2, Bytes: 20}}, nil).
+		Once()
+
+	res, err := s.client.GetStreamInfo(s.ctx, &GetStreamInfoRequest{StreamName: "s1"})
+
+	s.Require().NoError(err)
+	s.Equal(int64(2), res.Usage.Events)
+	s.Equal(int64(20), res.Usage.Bytes)
+}
+
+func (s *rpcSuite) Test_GetStreamInfo_StreamNotFoundError() {
+	s.bus.
+		On("GetStreamInfo", "s1").
+		Return(models.Stream{}, models.StreamNotFoundError{Name: "s1"}).
+		Once()
+
+	res, err := s.client.GetStreamInfo(s.ctx, &GetStreamInfoRequest{StreamName: "s1"})
+
+	s.assertStatus(codes.NotFound, "stream 's1' not found", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_ListStreams_Success() {
+	s.bus.
+		On("ListStreams").
+		Return([]models.Stream{{ID: "s1-id", Name: "s1", CreatedAt: testTime, Usage: &models.Usage{Events: 2, Bytes: 20}}}).
+		Once()
+
+	res, err := s.client.ListStreams(s.ctx, &ListStreamsRequest{})
+
+	s.Require().NoError(err)
+	s.Require().Len(res.Streams, 1)
+	s.Equal("s1-id", res.Streams[0].Id)
+	s.Equal("s1", res.Streams[0].Name)
+	s.Equal(int64(2), res.Streams[0].Usage.Events)
+}
+
+func (s *rpcSuite) Test_GetStreamEvents_Success() {
+	events := []models.Event{
+		{ID: "e1", StreamID: "s1-id", Status: models.EventRetryStatus, CreatedAt: testTime, Body: []byte(`{"k":"v"}`)},
+	}
+	s.bus.
+		On("GetStreamEvents", mock.Anything, "s1").
+		Return(events, nil).
+		Once()
+
+	res, err := s.client.GetStreamEvents(s.ctx, &GetStreamEventsRequest{StreamName: "s1"})
+
+	s.Require().NoError(err)
+	s.Require().Len(res.Events, 1)
+	s.Equal("e1", res.Events[0].Id)
+	s.Equal("s1-id", res.Events[0].StreamId)
+	s.Equal(EventStatus_RETRY, res.Events[0].Status)
+	s.Equal(testTime, res.Events[0].CreatedAt.AsTime())
+	s.JSONEq(`{"k":"v"}`, string(res.Events[0].Body))
+}
+
+func (s *rpcSuite) Test_GetStreamEvents_Data() {
+	events := []models.Event{
+		{ID: "e1", StreamID: "s1-id", CreatedAt: testTime, ContentType: "application/x-protobuf", Data: []byte{0, 1, 2}},
+	}
+	s.bus.
+		On("GetStreamEvents", mock.Anything, "s1").
+		Return(events, nil).
+		Once()
+
+	res, err := s.client.GetStreamEvents(s.ctx, &GetStreamEventsRequest{StreamName: "s1"})
+
+	s.Require().NoError(err)
+	s.Require().Len(res.Events, 1)
+	s.Equal("application/x-protobuf", res.Events[0].ContentType)
+	s.Equal([]byte{0, 1, 2}, res.Events[0].Body)
+}
+
+func (s *rpcSuite) Test_GetStreamEvents_Error() {
+	s.bus.
+		On("GetStreamEvents", mock.Anything, "s1").
+		Return([]models.Event{}, errTest).
+		Once()
+
+	res, err := s.client.GetStreamEvents(s.ctx, &GetStreamEventsRequest{StreamName: "s1"})
+
+	s.assertStatus(codes.Internal, errTest.Error(), err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_WriteEvent_Success() {
+	s.bus.
+		On("WriteEvent", mock.Anything, "root", "s1", json.RawMessage(`{"k":"v"}`)).
+		Return(nil).
+		Once()
+
+	res, err := s.client.WriteEvent(s.ctx, &WriteEventRequest{StreamName: "s1", Body: []byte(`{"k":"v"}`)})
+
+	s.Require().NoError(err)
+	s.NotNil(res)
+}
+
+func (s *rpcSuite) Test_WriteEvent_QuotaExceededError() {
+	quotaErr := models.QuotaExceededError{Client: "root", Quota: "max_bytes", Limit: 10}
+	s.bus.
+		On("WriteEvent", mock.Anything, "root", "s1", json.RawMessage(`{"k":"v"}`)).
+		Return(quotaErr).
+		Once()
+
+	res, err := s.client.WriteEvent(s.ctx, &WriteEventRequest{StreamName: "s1", Body: []byte(`{"k":"v"}`)})
+
+	s.assertStatus(codes.ResourceExhausted, quotaErr.Error(), err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_WriteEvent_Data() {
+	s.bus.
+		On("WriteData", mock.Anything, "root", "s1", "application/x-protobuf", []byte{0, 1, 2}).
+		Return(nil).
+		Once()
+
+	res, err := s.client.WriteEvent(s.ctx, &WriteEventRequest{
+		StreamName:  "s1",
+		ContentType: "application/x-protobuf",
+		Body:        []byte{0, 1, 2},
+	})
+
+	s.Require().NoError(err)
+	s.NotNil(res)
+}
+
+func (s *rpcSuite) Test_WriteEvent_JSONContentTypeParameters() {
+	s.bus.
+		On("WriteEvent", mock.Anything, "root", "s1", json.RawMessage(`{"a":1}`)).
+		Return(nil).
+		Once()
+
+	res, err := s.client.WriteEvent(s.ctx, &WriteEventRequest{
+		StreamName:  "s1",
+		ContentType: "application/json; charset=utf-8",
+		Body:        []byte(`{"a":1}`),
+	})
+
+	s.Require().NoError(err)
+	s.NotNil(res)
+}
+
+func (s *rpcSuite) Test_WriteEvent_InvalidJSONError() {
+	res, err := s.client.WriteEvent(s.ctx, &WriteEventRequest{StreamName: "s1", Body: []byte(`{`)})
+
+	s.assertStatus(codes.InvalidArgument, "invalid json provided", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_WriteEvent_EventValidationError() {
+	s.bus.
+		On("WriteEvent", mock.Anything, "root", "s1", json.RawMessage(`{}`)).
+		Return(models.EventValidationError{
+			Violations: []schema.Violation{{Path: "k", Message: "k is required"}},
+		}).
+		Once()
+
+	res, err := s.client.WriteEvent(s.ctx, &WriteEventRequest{StreamName: "s1", Body: []byte(`{}`)})
+
+	s.assertStatus(codes.InvalidArgument, "event does not match the stream schema", err)
+	s.Nil(res)
+	details := status.Convert(err).Details()
+	s.Require().Len(details, 1)
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	s.Require().True(ok)
+	s.Require().Len(badRequest.FieldViolations, 1)
+	s.Equal("k", badRequest.FieldViolations[0].Field)
+	s.Equal("k is required", badRequest.FieldViolations[0].Description)
+}
+
+func (s *rpcSuite) Test_WriteEvent_EventType() {
+	s.bus.
+		On("WriteTypedEvent", mock.Anything, "root", "s1", "user_created", 2, json.RawMessage(`{"k":"v"}`)).
+		Return(nil).
+		Once()
+
+	res, err := s.client.WriteEvent(s.ctx, &WriteEventRequest{
+		StreamName:       "s1",
+		Body:             []byte(`{"k":"v"}`),
+		EventType:        "user_created",
+		EventTypeVersion: 2,
+	})
+
+	s.Require().NoError(err)
+	s.NotNil(res)
+}
+
+func (s *rpcSuite) Test_WriteEvent_EventTypePayloadError() {
+	res, err := s.client.WriteEvent(s.ctx, &WriteEventRequest{
+		StreamName:  "s1",
+		ContentType: "text/plain",
+		Body:        []byte("hello"),
+		EventType:   "user_created",
+	})
+
+	s.assertStatus(codes.InvalidArgument, "event_type can only be provided for json events", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_RegisterEventType_Success() {
+	schema := []byte(`{"type":"object"}`)
+	s.bus.
+		On("RegisterEventType", mock.Anything, "user_created", "full", json.RawMessage(schema)).
+		Return(models.EventType{ID: "schema-id", Name: "user_created", Version: 1, Compatibility: "full", Schema: schema, CreatedAt: testTime}, nil).
+		Once()
+
+	res, err := s.client.RegisterEventType(s.ctx, &RegisterEventTypeRequest{Name: "user_created", Schema: schema, Compatibility: "full"})
+
+	s.Require().NoError(err)
+	s.Equal("schema-id", res.Id)
+	s.Equal(int32(1), res.Version)
+	s.Equal("full", res.Compatibility)
+	s.Equal(schema, res.Schema)
+	s.Equal(testTime, res.CreatedAt.AsTime())
+}
+
+func (s *rpcSuite) Test_RegisterEventType_RequiredFieldError() {
+	res, err := s.client.RegisterEventType(s.ctx, &RegisterEventTypeRequest{})
+
+	s.assertStatus(codes.InvalidArgument, "'name' field is required", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_RegisterEventType_IncompatibleSchemaError() {
+	s.bus.
+		On("RegisterEventType", mock.Anything, "user_created", "", json.RawMessage(`{}`)).
+		Return(models.EventType{}, models.IncompatibleSchemaError{
+			Compatibility: "forward",
+			Version:       3,
+			Violations:    []schema.Violation{{Path: "k", Message: "the previous version requires the property"}},
+		}).
+		Once()
+
+	res, err := s.client.RegisterEventType(s.ctx, &RegisterEventTypeRequest{Name: "user_created", Schema: []byte(`{}`)})
+
+	s.assertStatus(codes.InvalidArgument, "schema is not forward compatible with version 3", err)
+	s.Nil(res)
+	s.Len(status.Convert(err).Details(), 1)
+}
+
+func (s *rpcSuite) Test_ListEventTypes_Success() {
+	s.bus.
+		On("ListEventTypes", "").
+		Return([]models.EventType{{ID: "id1", Name: "a"}, {ID: "id2", Name: "b"}}, nil).
+		Once()
+
+	res, err := s.client.ListEventTypes(s.ctx, &ListEventTypesRequest{})
+
+	s.Require().NoError(err)
+	s.Require().Len(res.EventTypes, 2)
+	s.Equal("id1", res.EventTypes[0].Id)
+	s.Equal("id2", res.EventTypes[1].Id)
+}
+
+func (s *rpcSuite) Test_GetAuditLog_Success() {
+	filter := models.AuditFilter{Action: models.AuditDeleteStream, Stream: "s1", Since: testTime, Limit: 5}
+	entry := models.AuditEntry{
+		ID:        "audit-id",
+		Action:    models.AuditDeleteStream,
+		Client:    "root",
+		Stream:    "s1",
+		Details:   map[string]string{"events": "2"},
+		CreatedAt: testTime,
+	}
+	s.bus.
+		On("GetAuditLog", mock.Anything, filter).
+		Return([]models.AuditEntry{entry}, nil).
+		Once()
+
+	res, err := s.client.GetAuditLog(s.adminCtx(), &GetAuditLogRequest{
+		Action:     models.AuditDeleteStream,
+		StreamName: "s1",
+		Since:      timestamppb.New(testTime),
+		Limit:      5,
+	})
+
+	s.Require().NoError(err)
+	s.Require().Len(res.Entries, 1)
+	s.Equal("audit-id", res.Entries[0].Id)
+	s.Equal("root", res.Entries[0].Client)
+	s.Equal(map[string]string{"events": "2"}, res.Entries[0].Details)
+	s.Equal(testTime, res.Entries[0].CreatedAt.AsTime())
+}
+
+func (s *rpcSuite) Test_GetAuditLog_PermissionDeniedError() {
+	res, err := s.client.GetAuditLog(s.ctx, &GetAuditLogRequest{})
+
+	s.assertStatus(codes.PermissionDenied, "forbidden to make request", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_ListConnections_Success() {
+	s.conns.
+		On("List").
+		Return([]models.Connection{{
+			ID:            2,
+			Transport:     models.GRPCTransport,
+			RemoteAddr:    "127.0.0.1:54321",
+			Client:        "root",
+			ConnectedAt:   testTime,
+			Requests:      7,
+			Subscriptions: 1,
+		}}).
+		Once()
+
+	res, err := s.client.ListConnections(s.adminCtx(), &ListConnectionsRequest{})
+
+	s.Require().NoError(err)
+	s.Require().Len(res.Connections, 1)
+	s.Equal(int64(2), res.Connections[0].Id)
+	s.Equal("127.0.0.1:54321", res.Connections[0].RemoteAddr)
+	s.Equal("root", res.Connections[0].Client)
+	s.Equal(testTime, res.Connections[0].ConnectedAt.AsTime())
+	s.Equal(int64(7), res.Connections[0].Requests)
+	s.Equal("grpc", res.Connections[0].Transport)
+	s.Equal(int64(1), res.Connections[0].Subscriptions)
+}
+
+func (s *rpcSuite) Test_ListConnections_PermissionDeniedError() {
+	res, err := s.client.ListConnections(s.ctx, &ListConnectionsRequest{})
+
+	s.assertStatus(codes.PermissionDenied, "forbidden to make request", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_Disconnect_Connection() {
+	s.conns.
+		On("Disconnect", 3).
+		Return(nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewDisconnectEntry("admin", "", []int{3})).
+		Return(nil).
+		Once()
+
+	res, err := s.client.Disconnect(s.adminCtx(), &DisconnectRequest{Target: &DisconnectRequest_ConnectionId{ConnectionId: 3}})
+
+	s.Require().NoError(err)
+	s.Equal([]int64{3}, res.Disconnected)
+}
+
+func (s *rpcSuite) Test_Disconnect_Client() {
+	s.conns.
+		On("DisconnectClient", "root").
+		Return([]int{1, 4}).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewDisconnectEntry("admin", "root", []int{1, 4})).
+		Return(nil).
+		Once()
+
+	res, err := s.client.Disconnect(s.adminCtx(), &DisconnectRequest{Target: &DisconnectRequest_Client{Client: "root"}})
+
+	s.Require().NoError(err)
+	s.Equal([]int64{1, 4}, res.Disconnected)
+}
+
+func (s *rpcSuite) Test_Disconnect_ConnectionNotFoundError() {
+	s.conns.
+		On("Disconnect", 3).
+		Return(models.ConnectionNotFoundError{ID: 3}).
+		Once()
+
+	res, err := s.client.Disconnect(s.adminCtx(), &DisconnectRequest{Target: &DisconnectRequest_ConnectionId{ConnectionId: 3}})
+
+	s.assertStatus(codes.NotFound, "connection '3' not found", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_Disconnect_RequiredFieldError() {
+	res, err := s.client.Disconnect(s.adminCtx(), &DisconnectRequest{})
+
+	s.assertStatus(codes.InvalidArgument, "one of 'connection_id' and 'client' fields is required", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_Backup_Success() {
+	s.bus.
+		On("BackupFile", mock.Anything, "/backups/bus.bak", uint64(5)).
+		Return(uint64(12), nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewBackupEntry("admin", "/backups/bus.bak", 5, 12)).
+		Return(nil).
+		Once()
+
+	res, err := s.client.Backup(s.adminCtx(), &BackupRequest{Path: "/backups/bus.bak", Since: 5})
+
+	s.Require().NoError(err)
+	s.Equal("/backups/bus.bak", res.Path)
+	s.Equal(uint64(12), res.Version)
+}
+
+func (s *rpcSuite) Test_Backup_ForbiddenError() {
+	res, err := s.client.Backup(s.ctx, &BackupRequest{Path: "/backups/bus.bak"})
+
+	s.assertStatus(codes.PermissionDenied, "forbidden to make request", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_Backup_RequiredFieldError() {
+	res, err := s.client.Backup(s.adminCtx(), &BackupRequest{})
+
+	s.assertStatus(codes.InvalidArgument, "'path' field is required", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_Restore_Success() {
+	s.bus.
+		On("RestoreFile", mock.Anything, "/backups/bus.bak", true).
+		Return(nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewRestoreEntry("admin", "/backups/bus.bak", true)).
+		Return(nil).
+		Once()
+
+	res, err := s.client.Restore(s.adminCtx(), &RestoreRequest{Path: "/backups/bus.bak", Incremental: true})
+
+	s.Require().NoError(err)
+	s.NotNil(res)
+}
+
+func (s *rpcSuite) Test_Restore_Error() {
+	s.bus.
+		On("RestoreFile", mock.Anything, "/backups/bus.bak", false).
+		Return(models.Error{Message: "could not open backup file"}).
+		Once()
+
+	res, err := s.client.Restore(s.adminCtx(), &RestoreRequest{Path: "/backups/bus.bak"})
+
+	s.assertStatus(codes.Internal, "could not open backup file", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_ExportStream_Success() {
+	s.bus.
+		On("ExportStreamFile", mock.Anything, "s1", "/exports/s1.jsonl").
+		Return(42, nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewExportEntry("admin", "s1", "/exports/s1.jsonl", 42)).
+		Return(nil).
+		Once()
+
+	res, err := s.client.ExportStream(s.adminCtx(), &ExportStreamRequest{StreamName: "s1", Path: "/exports/s1.jsonl"})
+
+	s.Require().NoError(err)
+	s.Equal("/exports/s1.jsonl", res.Path)
+	s.Equal(int64(42), res.Events)
+}
+
+func (s *rpcSuite) Test_ExportStream_RequiredFieldError() {
+	res, err := s.client.ExportStream(s.adminCtx(), &ExportStreamRequest{Path: "/exports/s1.jsonl"})
+
+	s.assertStatus(codes.InvalidArgument, "'stream_name' field is required", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_ExportStream_ForbiddenError() {
+	res, err := s.client.ExportStream(s.ctx, &ExportStreamRequest{StreamName: "s1", Path: "/exports/s1.jsonl"})
+
+	s.assertStatus(codes.PermissionDenied, "forbidden to make request", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_ImportStream_Success() {
+	result := models.ImportResult{
+		Stream:   models.Stream{ID: "s2-id", Name: "s2", CreatedAt: testTime},
+		Imported: 40,
+		Skipped:  2,
+		Renamed:  1,
+	}
+	s.bus.
+		On("ImportStreamFile", mock.Anything, "s2", "/exports/s1.jsonl").
+		Return(result, nil).
+		Once()
+	s.bus.
+		On("Audit", mock.Anything, models.NewImportEntry("admin", "/exports/s1.jsonl", result)).
+		Return(nil).
+		Once()
+
+	res, err := s.client.ImportStream(s.adminCtx(), &ImportStreamRequest{Path: "/exports/s1.jsonl", StreamName: "s2"})
+
+	s.Require().NoError(err)
+	s.Equal("s2-id", res.Stream.Id)
+	s.Equal(int64(40), res.Imported)
+	s.Equal(int64(2), res.Skipped)
+	s.Equal(int64(1), res.Renamed)
+}
+
+func (s *rpcSuite) Test_ImportStream_InvalidImportError() {
+	s.bus.
+		On("ImportStreamFile", mock.Anything, "", "/exports/s1.jsonl").
+		Return(models.ImportResult{}, models.InvalidImportError{Line: 3, Reason: "unexpected EOF"}).
+		Once()
+
+	res, err := s.client.ImportStream(s.adminCtx(), &ImportStreamRequest{Path: "/exports/s1.jsonl"})
+
+	s.assertStatus(codes.InvalidArgument, "invalid import at line 3: unexpected EOF", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_GetEventType_Success() {
+	s.bus.
+		On("GetEventType", "user_created", 2).
+		Return(models.EventType{ID: "schema-id", Name: "user_created", Version: 2}, nil).
+		Once()
+
+	res, err := s.client.GetEventType(s.ctx, &GetEventTypeRequest{Name: "user_created", Version: 2})
+
+	s.Require().NoError(err)
+	s.Equal("schema-id", res.Id)
+}
+
+func (s *rpcSuite) Test_GetEventType_SchemaID() {
+	s.bus.
+		On("GetEventTypeBySchemaID", "schema-id").
+		Return(models.EventType{}, models.EventTypeNotFoundError{ID: "schema-id"}).
+		Once()
+
+	res, err := s.client.GetEventType(s.ctx, &GetEventTypeRequest{SchemaId: "schema-id"})
+
+	s.assertStatus(codes.NotFound, "event type with schema id 'schema-id' not found", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_MarkEvent_Success() {
+	s.bus.
+		On("MarkEvent", mock.Anything, "root", "e1", models.EventProcessedStatus).
+		Return(nil).
+		Once()
+
+	res, err := s.client.MarkEvent(s.ctx, &MarkEventRequest{EventId: "e1", Status: EventStatus_PROCESSED})
+
+	s.Require().NoError(err)
+	s.NotNil(res)
+}
+
+func (s *rpcSuite) Test_MarkEvent_InvalidEventStatusError() {
+	res, err := s.client.MarkEvent(s.ctx, &MarkEventRequest{EventId: "e1", Status: 3})
+
+	s.assertStatus(codes.InvalidArgument, models.InvalidEventStatusError{}.Error(), err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_ProcessEvents_Success() {
+	s.bus.
+		On("ProcessEvents", mock.Anything, "s1", true).
+		Return([]models.Event{{ID: "e1"}}, nil).
+		Once()
+
+	res, err := s.client.ProcessEvents(s.ctx, &ProcessEventsRequest{StreamName: "s1", Retry: true})
+
+	s.Require().NoError(err)
+	s.Require().Len(res.Events, 1)
+	s.Equal("e1", res.Events[0].Id)
+}
+
+func (s *rpcSuite) Test_Subscribe_Success() {
+	bus := s.newBus()
+	_, err := bus.CreateStream(context.Background(), "root", "s1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	stream, err := s.client.Subscribe(s.ctx, &SubscribeRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+	s.Require().NoError(stream.CloseSend())
+	s.waitForSubscribe(stream)
+
+	s.Require().NoError(bus.WriteEvent(context.Background(), "root", "s1", json.RawMessage(`{"k":"v"}`)))
+
+	evt, err := stream.Recv()
+	s.Require().NoError(err)
+	s.JSONEq(`{"k":"v"}`, string(evt.Body))
+	s.Equal(EventStatus_UNPROCESSED, evt.Status)
+
+	s.Require().NoError(bus.DeleteStream(context.Background(), "root", "s1"))
+
+	_, err = stream.Recv()
+	s.assertStatus(codes.NotFound, "stream 's1' not found", err)
+}
+
+func (s *rpcSuite) Test_Subscribe_StreamNotFoundError() {
+	s.newBus()
+	stream, err := s.client.Subscribe(s.ctx, &SubscribeRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+
+	_, err = stream.Recv()
+
+	s.assertStatus(codes.NotFound, "stream 's1' not found", err)
+}
+
+func (s *rpcSuite) Test_Subscribe_Stop() {
+	bus := s.newBus()
+	_, err := bus.CreateStream(context.Background(), "root", "s1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	stream, err := s.client.Subscribe(s.ctx, &SubscribeRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+	s.waitForSubscribe(stream)
+
+	s.server.Stop(time.Second)
+
+	_, err = stream.Recv()
+	s.assertStatus(codes.Unavailable, "server is shutting down", err)
+}
+
+func (s *rpcSuite) Test_Subscribe_TooManyConnectionsError() {
+	limiter := s.newLimiter()
+	limiter.On("Connect", models.GRPCTransport, "bufconn", mock.Anything).Return(3, nil).Once()
+	limiter.On("Authorize", 3, "root", 0).Return(models.TooManyConnectionsError{Client: "root"}).Once()
+	limiter.On("Release", 3).Once()
+	s.newBus()
+	stream, err := s.client.Subscribe(s.ctx, &SubscribeRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+
+	_, err = stream.Recv()
+
+	s.assertStatus(codes.ResourceExhausted, "too many connections for client 'root'", err)
+}
+
+func (s *rpcSuite) Test_Subscribe_ReleasesConnection() {
+	limiter := s.newLimiter()
+	released := make(chan struct{})
+	limiter.On("Connect", models.GRPCTransport, "bufconn", mock.Anything).Return(3, nil).Once()
+	limiter.On("Authorize", 3, "root", 0).Return(nil).Once()
+	limiter.On("AddSubscriptions", 3, 1).Once()
+	limiter.On("AddSubscriptions", 3, -1).Once()
+	limiter.On("Release", 3).Run(func(mock.Arguments) { close(released) }).Once()
+	bus := s.newBus()
+	_, err := bus.CreateStream(context.Background(), "root", "s1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	ctx, cancel := context.WithCancel(s.ctx)
+	stream, err := s.client.Subscribe(ctx, &SubscribeRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+	s.waitForSubscribe(stream)
+
+	cancel()
+
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		s.Fail("subscription connection not released")
+	}
+}
+
+func (s *rpcSuite) Test_Subscribe_Disconnect() {
+	limiter := s.newLimiter()
+	connected := make(chan func(), 1)
+	limiter.
+		On("Connect", models.GRPCTransport, "bufconn", mock.Anything).
+		Run(func(args mock.Arguments) { connected <- args.Get(2).(func()) }).
+		Return(3, nil).
+		Once()
+	limiter.On("Authorize", 3, "root", 0).Return(nil).Once()
+	limiter.On("AddSubscriptions", 3, 1).Once()
+	limiter.On("AddSubscriptions", 3, -1).Once()
+	limiter.On("Release", 3).Once()
+	bus := s.newBus()
+	_, err := bus.CreateStream(context.Background(), "root", "s1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	stream, err := s.client.Subscribe(s.ctx, &SubscribeRequest{StreamName: "s1"})
+	s.Require().NoError(err)
+	s.waitForSubscribe(stream)
+
+	disconnect := <-connected
+	disconnect()
+
+	_, err = stream.Recv()
+	s.assertStatus(codes.Aborted, "disconnected by an admin client", err)
+}
+
+func (s *rpcSuite) Test_Limits_Success() {
+	limiter := s.newLimiter()
+	req := &GetStreamInfoRequest{StreamName: "s1"}
+	limiter.On("Open").Return(1, nil).Once()
+	limiter.On("Authorize", 1, "root", proto.Size(req)).Return(nil).Once()
+	limiter.On("Release", 1).Once()
+	s.restart()
+	s.bus.
+		On("GetStreamInfo", "s1").
+		Return(models.Stream{ID: "s1-id", Name: "s1"}, nil).
+		Once()
+
+	res, err := s.client.GetStreamInfo(s.ctx, req)
+
+	s.Require().NoError(err)
+	s.Equal("s1-id", res.Id)
+}
+
+func (s *rpcSuite) Test_Limits_RateLimitError() {
+	limiter := s.newLimiter()
+	limiter.On("Open").Return(1, nil).Once()
+	limiter.On("Authorize", 1, "root", mock.AnythingOfType("int")).Return(models.RateLimitError{RetryAfterMS: 250}).Once()
+	limiter.On("Release", 1).Once()
+	s.restart()
+
+	res, err := s.client.GetStreamInfo(s.ctx, &GetStreamInfoRequest{StreamName: "s1"})
+
+	s.assertStatus(codes.ResourceExhausted, "rate_limited", err)
+	s.Nil(res)
+	details := status.Convert(err).Details()
+	s.Require().Len(details, 1)
+	retryInfo, ok := details[0].(*errdetails.RetryInfo)
+	s.Require().True(ok)
+	s.Equal(250*time.Millisecond, retryInfo.RetryDelay.AsDuration())
+}
+
+func (s *rpcSuite) Test_Limits_TooManyConnectionsError() {
+	limiter := s.newLimiter()
+	limiter.On("Open").Return(0, models.TooManyConnectionsError{}).Once()
+	s.restart()
+
+	res, err := s.client.GetStreamInfo(s.ctx, &GetStreamInfoRequest{StreamName: "s1"})
+
+	s.assertStatus(codes.ResourceExhausted, "too many connections", err)
+	s.Nil(res)
+}
+
+func (s *rpcSuite) Test_Limits_Health() {
+	s.newLimiter()
+	s.restart()
+
+	_, err := s.client.Health(context.Background(), &HealthRequest{})
+
+	s.Require().NoError(err)
+}
+
+func (s *rpcSuite) Test_ListenAndServe_Error() {
+	srv, err := ListenAndServe(Settings{Addr: "9700", Bus: s.bus, Cfg: s.cfg})
+
+	s.EqualError(err, "listen tcp: address 9700: missing port in address")
+	s.Nil(srv)
+}
+
+// newBus replaces the mocked bus with a real one, subscriptions cannot be mocked
+func (s *rpcSuite) newBus() *services.Bus {
+	s.Require().NoError(s.conn.Close())
+	s.server.Stop(time.Second)
+	bus := services.NewBus(testutils.NewBadger(s.T()), services.Quotas{})
+	s.serve(bus)
+	return bus
+}
+
+// newLimiter enforces mocked client limits on the server started next
+func (s *rpcSuite) newLimiter() *limiterMock {
+	limiter := new(limiterMock)
+	limiter.Test(s.T())
+	s.T().Cleanup(func() {
+		limiter.AssertExpectations(s.T())
+	})
+	s.limiter = limiter
+	return limiter
+}
+
+// restart restarts the server with the mocked bus
+func (s *rpcSuite) restart() {
+	s.Require().NoError(s.conn.Close())
+	s.server.Stop(time.Second)
+	s.serve(s.bus)
+}
+
+func (s *rpcSuite) serve(bus Bus) {
+	li := bufconn.Listen(1 << 20)
+	srv := NewServer(Settings{Bus: bus, Cfg: s.cfg, Health: s.checker, Connections: s.conns, Limiter: s.limiter})
+	go func() {
+		_ = srv.grpcServer.Serve(li)
+	}()
+	s.server = srv
+	conn, err := grpc.Dial(
+		"bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return li.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	s.Require().NoError(err)
+	s.conn = conn
+	s.client = NewEventBusClient(conn)
+}
+
+// waitForSubscribe waits for the headers sent once the subscription is in place
+func (s *rpcSuite) waitForSubscribe(stream EventBus_SubscribeClient) {
+	_, err := stream.Header()
+	s.Require().NoError(err)
+}
+
+func (s *rpcSuite) adminCtx() context.Context {
+	return metadata.AppendToOutgoingContext(
+		context.Background(),
+		clientIDKey, "admin_client_id",
+		clientSecretKey, "admin_client_secret",
+	)
+}
+
+func (s *rpcSuite) observeAccessLog() *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logging.AccessLogger = zap.New(core)
+	s.T().Cleanup(func() {
+		logging.AccessLogger = zap.NewNop()
+	})
+	return logs
+}
+
+func (s *rpcSuite) requestsCount(operation, status string) float64 {
+	families, err := metrics.Registry.Gather()
+	s.Require().NoError(err)
+	for _, family := range families {
+		if family.GetName() != "eventbus_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["operation"] == operation && labels["status"] == status {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func (s *rpcSuite) assertStatus(code codes.Code, msg string, err error) {
+	st, ok := status.FromError(err)
+	s.Require().True(ok)
+	s.Equal(code, st.Code())
+	s.Equal(msg, st.Message())
+}
+
+func Test_RPCSuite(t *testing.T) {
+	suite.Run(t, new(rpcSuite))
+}
@@ -0,0 +1,534 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
+)
+
+// service implements the EventBus gRPC service on top of the Event Bus
+type service struct {
+	UnimplementedEventBusServer
+	bus     Bus
+	health  HealthChecker
+	conns   ConnectionManager
+	limiter ClientLimiter
+	quit    <-chan struct{}
+}
+
+func (s *service) Health(ctx context.Context, req *HealthRequest) (*HealthResponse, error) {
+	if !req.Deep {
+		return &HealthResponse{}, nil
+	}
+	health := models.Health{Status: models.HealthUp}
+	if s.health != nil {
+		health = s.health.Check(ctx)
+	}
+	// only the authenticated clients get the components
+	if clientName(ctx) == "" {
+		health = models.Health{Status: health.Status}
+	}
+	res := toHealthResponse(health)
+	if health.Status == models.HealthUp {
+		return res, nil
+	}
+	msg := models.UnhealthyError{Health: health}.Error()
+	st, err := status.New(codes.Unavailable, msg).WithDetails(res)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, msg)
+	}
+	return nil, st.Err()
+}
+
+func (s *service) CreateStream(ctx context.Context, req *CreateStreamRequest) (*Stream, error) {
+	if req.StreamName == "" {
+		return nil, requiredFieldError("stream_name")
+	}
+	stream, err := s.bus.CreateStream(ctx, clientName(ctx), req.StreamName, req.Compression, req.Schema, toQuota(req.Quota))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toStream(stream), nil
+}
+
+func (s *service) DeleteStream(ctx context.Context, req *DeleteStreamRequest) (*DeleteStreamResponse, error) {
+	err := s.bus.DeleteStream(ctx, clientName(ctx), req.StreamName)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &DeleteStreamResponse{}, nil
+}
+
+func (s *service) UpdateStream(ctx context.Context, req *UpdateStreamRequest) (*Stream, error) {
+	stream, err := s.bus.UpdateStream(ctx, clientName(ctx), req.StreamName, req.Schema, toQuota(req.Quota))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toStream(stream), nil
+}
+
+func (s *service) GetStreamInfo(_ context.Context, req *GetStreamInfoRequest) (*Stream, error) {
+	stream, err := s.bus.GetStreamInfo(req.StreamName)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toStream(stream), nil
+}
+
+func (s *service) ListStreams(context.Context, *ListStreamsRequest) (*ListStreamsResponse, error) {
+	streams := s.bus.ListStreams()
+	res := &ListStreamsResponse{
+		Streams: make([]*Stream, 0, len(streams)),
+	}
+	for _, stream := range streams {
+		res.Streams = append(res.Streams, toStream(stream))
+	}
+	return res, nil
+}
+
+func (s *service) GetStreamEvents(ctx context.Context, req *GetStreamEventsRequest) (*EventsResponse, error) {
+	events, err := s.bus.GetStreamEvents(ctx, req.StreamName)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toEventsResponse(events), nil
+}
+
+func (s *service) WriteEvent(ctx context.Context, req *WriteEventRequest) (*WriteEventResponse, error) {
+	client := clientName(ctx)
+	var err error
+	if models.IsJSONContentType(req.ContentType) {
+		if !json.Valid(req.Body) {
+			return nil, toStatus(models.InvalidJSONError{})
+		}
+		if req.EventType != "" {
+			err = s.bus.WriteTypedEvent(ctx, client, req.StreamName, req.EventType, int(req.EventTypeVersion), req.Body)
+		} else {
+			err = s.bus.WriteEvent(ctx, client, req.StreamName, req.Body)
+		}
+	} else if req.EventType != "" {
+		err = models.EventTypePayloadError{}
+	} else {
+		err = s.bus.WriteData(ctx, client, req.StreamName, req.ContentType, req.Body)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &WriteEventResponse{}, nil
+}
+
+func (s *service) MarkEvent(ctx context.Context, req *MarkEventRequest) (*MarkEventResponse, error) {
+	if _, ok := EventStatus_name[int32(req.Status)]; !ok {
+		return nil, toStatus(models.InvalidEventStatusError{})
+	}
+	err := s.bus.MarkEvent(ctx, clientName(ctx), req.EventId, uint8(req.Status))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &MarkEventResponse{}, nil
+}
+
+func (s *service) ProcessEvents(ctx context.Context, req *ProcessEventsRequest) (*EventsResponse, error) {
+	events, err := s.bus.ProcessEvents(ctx, req.StreamName, req.Retry)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toEventsResponse(events), nil
+}
+
+func (s *service) RegisterEventType(ctx context.Context, req *RegisterEventTypeRequest) (*EventType, error) {
+	if req.Name == "" {
+		return nil, requiredFieldError("name")
+	}
+	eventType, err := s.bus.RegisterEventType(ctx, req.Name, req.Compatibility, req.Schema)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toEventType(eventType), nil
+}
+
+func (s *service) ListEventTypes(_ context.Context, req *ListEventTypesRequest) (*ListEventTypesResponse, error) {
+	eventTypes, err := s.bus.ListEventTypes(req.Name)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &ListEventTypesResponse{
+		EventTypes: make([]*EventType, 0, len(eventTypes)),
+	}
+	for _, eventType := range eventTypes {
+		res.EventTypes = append(res.EventTypes, toEventType(eventType))
+	}
+	return res, nil
+}
+
+func (s *service) GetEventType(_ context.Context, req *GetEventTypeRequest) (*EventType, error) {
+	var eventType models.EventType
+	var err error
+	if req.SchemaId != "" {
+		eventType, err = s.bus.GetEventTypeBySchemaID(req.SchemaId)
+	} else {
+		eventType, err = s.bus.GetEventType(req.Name, int(req.Version))
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toEventType(eventType), nil
+}
+
+func (s *service) GetAuditLog(ctx context.Context, req *GetAuditLogRequest) (*GetAuditLogResponse, error) {
+	filter := models.AuditFilter{
+		Action: req.Action,
+		Client: req.Client,
+		Stream: req.StreamName,
+		Limit:  int(req.Limit),
+	}
+	if req.Since != nil {
+		filter.Since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		filter.Until = req.Until.AsTime()
+	}
+	entries, err := s.bus.GetAuditLog(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &GetAuditLogResponse{
+		Entries: make([]*AuditEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		res.Entries = append(res.Entries, toAuditEntry(entry))
+	}
+	return res, nil
+}
+
+func (s *service) ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	if s.conns == nil {
+		return nil, errNoConnections
+	}
+	conns := s.conns.List()
+	res := &ListConnectionsResponse{
+		Connections: make([]*Connection, 0, len(conns)),
+	}
+	for _, conn := range conns {
+		res.Connections = append(res.Connections, toConnection(conn))
+	}
+	return res, nil
+}
+
+func (s *service) Disconnect(ctx context.Context, req *DisconnectRequest) (*DisconnectResponse, error) {
+	if req.Target == nil {
+		return nil, status.Error(codes.InvalidArgument, "one of 'connection_id' and 'client' fields is required")
+	}
+	if s.conns == nil {
+		return nil, errNoConnections
+	}
+	var ids []int
+	switch target := req.Target.(type) {
+	case *DisconnectRequest_ConnectionId:
+		err := s.conns.Disconnect(int(target.ConnectionId))
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		ids = []int{int(target.ConnectionId)}
+	case *DisconnectRequest_Client:
+		ids = s.conns.DisconnectClient(target.Client)
+	}
+
+	err := s.bus.Audit(ctx, models.NewDisconnectEntry(clientName(ctx), req.GetClient(), ids))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &DisconnectResponse{
+		Disconnected: make([]int64, 0, len(ids)),
+	}
+	for _, id := range ids {
+		res.Disconnected = append(res.Disconnected, int64(id))
+	}
+	return res, nil
+}
+
+func (s *service) Backup(ctx context.Context, req *BackupRequest) (*BackupResponse, error) {
+	if req.Path == "" {
+		return nil, requiredFieldError("path")
+	}
+	version, err := s.bus.BackupFile(ctx, req.Path, req.Since)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	err = s.bus.Audit(ctx, models.NewBackupEntry(clientName(ctx), req.Path, req.Since, version))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &BackupResponse{Path: req.Path, Version: version}, nil
+}
+
+func (s *service) Restore(ctx context.Context, req *RestoreRequest) (*RestoreResponse, error) {
+	if req.Path == "" {
+		return nil, requiredFieldError("path")
+	}
+	err := s.bus.RestoreFile(ctx, req.Path, req.Incremental)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	err = s.bus.Audit(ctx, models.NewRestoreEntry(clientName(ctx), req.Path, req.Incremental))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &RestoreResponse{}, nil
+}
+
+func (s *service) ExportStream(ctx context.Context, req *ExportStreamRequest) (*ExportStreamResponse, error) {
+	if req.StreamName == "" {
+		return nil, requiredFieldError("stream_name")
+	}
+	if req.Path == "" {
+		return nil, requiredFieldError("path")
+	}
+	n, err := s.bus.ExportStreamFile(ctx, req.StreamName, req.Path)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	err = s.bus.Audit(ctx, models.NewExportEntry(clientName(ctx), req.StreamName, req.Path, n))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &ExportStreamResponse{Path: req.Path, Events: int64(n)}, nil
+}
+
+func (s *service) ImportStream(ctx context.Context, req *ImportStreamRequest) (*ImportStreamResponse, error) {
+	if req.Path == "" {
+		return nil, requiredFieldError("path")
+	}
+	res, err := s.bus.ImportStreamFile(ctx, req.StreamName, req.Path)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	err = s.bus.Audit(ctx, models.NewImportEntry(clientName(ctx), req.Path, res))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &ImportStreamResponse{
+		Stream:   toStream(res.Stream),
+		Imported: int64(res.Imported),
+		Skipped:  int64(res.Skipped),
+		Renamed:  int64(res.Renamed),
+	}, nil
+}
+
+func (s *service) Subscribe(req *SubscribeRequest, stream EventBus_SubscribeServer) error {
+	sub, err := s.bus.Subscribe(req.StreamName)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer s.bus.Unsubscribe(sub)
+	if connID, ok := stream.Context().Value(connKey{}).(int); ok {
+		s.limiter.AddSubscriptions(connID, 1)
+		defer s.limiter.AddSubscriptions(connID, -1)
+	}
+	// headers let clients know the subscription is in place before any event is written
+	err = stream.SendHeader(metadata.MD{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case evt, ok := <-sub.Events():
+			if !ok {
+				return toStatus(sub.Err())
+			}
+			err := stream.Send(toEvent(evt))
+			if err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		case <-s.quit:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+	}
+}
+
+// toStatus maps the Event Bus errors onto gRPC status errors
+func toStatus(err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case models.EventValidationError:
+		return validationStatus(e.Error(), e.Violations)
+	case models.IncompatibleSchemaError:
+		return validationStatus(e.Error(), e.Violations)
+	case models.StreamNotFoundError, models.EventNotFoundError, models.EventTypeNotFoundError, models.ConnectionNotFoundError:
+		return status.Error(codes.NotFound, err.Error())
+	case models.StreamExistsError:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case models.InvalidJSONError, models.InvalidEventStatusError, models.UnsupportedCompressionError, models.InvalidSchemaError,
+		models.UnsupportedCompatibilityError, models.EventTypePayloadError, models.EventTooLargeError, models.InvalidQuotaError, models.InvalidImportError,
+		models.InvalidBackupError, models.InvalidPathError:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case models.SubscriptionLagError, models.QuotaExceededError, models.TooManyConnectionsError:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case models.RateLimitError:
+		return rateLimitStatus(e)
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// rateLimitStatus tells how long to back off in the retry info details
+func rateLimitStatus(e models.RateLimitError) error {
+	details := &errdetails.RetryInfo{
+		RetryDelay: durationpb.New(time.Duration(e.RetryAfterMS) * time.Millisecond),
+	}
+	st, err := status.New(codes.ResourceExhausted, e.Error()).WithDetails(details)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, e.Error())
+	}
+	return st.Err()
+}
+
+// validationStatus lists the schema violations as bad request field violations
+func validationStatus(msg string, violations []schema.Violation) error {
+	details := &errdetails.BadRequest{}
+	for _, violation := range violations {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Path,
+			Description: violation.Message,
+		})
+	}
+	st, err := status.New(codes.InvalidArgument, msg).WithDetails(details)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, msg)
+	}
+	return st.Err()
+}
+
+// errNoConnections is returned by the connection methods of a server with no connections registry
+var errNoConnections = status.Error(codes.Unimplemented, "connections are not managed by this server")
+
+func requiredFieldError(field string) error {
+	return status.Errorf(codes.InvalidArgument, "'%s' field is required", field)
+}
+
+func toStream(stream models.Stream) *Stream {
+	return &Stream{
+		Id:          stream.ID,
+		Name:        stream.Name,
+		CreatedAt:   timestamppb.New(stream.CreatedAt),
+		Compression: stream.Compression,
+		Schema:      stream.Schema,
+		Quota: &Quota{
+			MaxEventSize: stream.MaxEventSize,
+			MaxEvents:    stream.MaxEvents,
+			MaxBytes:     stream.MaxBytes,
+		},
+		Usage: toUsage(stream.Usage),
+	}
+}
+
+func toQuota(quota *Quota) models.Quota {
+	return models.Quota{
+		MaxEventSize: quota.GetMaxEventSize(),
+		MaxEvents:    quota.GetMaxEvents(),
+		MaxBytes:     quota.GetMaxBytes(),
+	}
+}
+
+func toUsage(usage *models.Usage) *Usage {
+	if usage == nil {
+		return nil
+	}
+	return &Usage{
+		Events: usage.Events,
+		Bytes:  usage.Bytes,
+	}
+}
+
+func toEvent(evt models.Event) *Event {
+	return &Event{
+		Id:           evt.ID,
+		StreamId:     evt.StreamID,
+		Status:       EventStatus(evt.Status),
+		CreatedAt:    timestamppb.New(evt.CreatedAt),
+		Body:         evt.Payload(),
+		ContentType:  evt.ContentType,
+		SchemaId:     evt.SchemaID,
+		Producer:     evt.Producer,
+		TraceContext: evt.TraceContext,
+	}
+}
+
+func toEventType(eventType models.EventType) *EventType {
+	return &EventType{
+		Id:            eventType.ID,
+		Name:          eventType.Name,
+		Version:       int32(eventType.Version),
+		Compatibility: eventType.Compatibility,
+		Schema:        eventType.Schema,
+		CreatedAt:     timestamppb.New(eventType.CreatedAt),
+	}
+}
+
+func toAuditEntry(entry models.AuditEntry) *AuditEntry {
+	return &AuditEntry{
+		Id:        entry.ID,
+		Action:    entry.Action,
+		Client:    entry.Client,
+		Stream:    entry.Stream,
+		EventId:   entry.EventID,
+		Details:   entry.Details,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func toHealthResponse(health models.Health) *HealthResponse {
+	res := &HealthResponse{
+		Status:     health.Status,
+		Components: make(map[string]*ComponentHealth, len(health.Components)),
+	}
+	for name, component := range health.Components {
+		details := make(map[string]string, len(component.Details))
+		for key, value := range component.Details {
+			details[key] = fmt.Sprint(value)
+		}
+		res.Components[name] = &ComponentHealth{
+			Status:  component.Status,
+			Details: details,
+			Error:   component.Error,
+		}
+	}
+	return res
+}
+
+func toConnection(conn models.Connection) *Connection {
+	return &Connection{
+		Id:            int64(conn.ID),
+		RemoteAddr:    conn.RemoteAddr,
+		Client:        conn.Client,
+		ConnectedAt:   timestamppb.New(conn.ConnectedAt),
+		Requests:      conn.Requests,
+		Transport:     conn.Transport,
+		Subscriptions: int64(conn.Subscriptions),
+	}
+}
+
+func toEventsResponse(events []models.Event) *EventsResponse {
+	res := &EventsResponse{
+		Events: make([]*Event, 0, len(events)),
+	}
+	for _, evt := range events {
+		res.Events = append(res.Events, toEvent(evt))
+	}
+	return res
+}
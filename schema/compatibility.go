@@ -0,0 +1,249 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Compatibility modes enforced between consecutive versions of a schema
+const (
+	BackwardCompatibility = "backward"
+	ForwardCompatibility  = "forward"
+	FullCompatibility     = "full"
+	NoCompatibility       = "none"
+)
+
+// minimumKeywords are the keywords whose growth makes a schema stricter
+var minimumKeywords = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+
+// maximumKeywords are the keywords whose shrinking makes a schema stricter
+var maximumKeywords = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+
+// CompatibilityModes lists all the supported compatibility modes
+func CompatibilityModes() []string {
+	return []string{BackwardCompatibility, ForwardCompatibility, FullCompatibility, NoCompatibility}
+}
+
+// SupportedCompatibility tells whether the compatibility mode is known
+func SupportedCompatibility(mode string) bool {
+	for _, m := range CompatibilityModes() {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCompatibility lists what makes the next version of a schema incompatible with the previous one.
+// Backward compatibility means the documents valid for the previous version stay valid for the next one,
+// so consumers using the next version can read the already written events.
+// Forward compatibility means the documents valid for the next version are valid for the previous one,
+// so consumers still using the previous version can read the new events.
+// The check covers the keywords changing the accepted documents the most: types, required and
+// additional properties, enums, array items and the numeric, length and size bounds
+func CheckCompatibility(mode string, previous, next []byte) ([]Violation, error) {
+	if mode == NoCompatibility {
+		return []Violation{}, nil
+	}
+	var prevDoc, nextDoc interface{}
+	err := json.Unmarshal(previous, &prevDoc)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(next, &nextDoc)
+	if err != nil {
+		return nil, err
+	}
+	violations := make([]Violation, 0)
+	if mode == BackwardCompatibility || mode == FullCompatibility {
+		violations = append(violations, stricter(prevDoc, nextDoc, RootPath, "the new version")...)
+	}
+	if mode == ForwardCompatibility || mode == FullCompatibility {
+		violations = append(violations, stricter(nextDoc, prevDoc, RootPath, "the previous version")...)
+	}
+	return violations, nil
+}
+
+// stricter lists the ways the to schema rejects documents the from schema accepts,
+// the subject naming the version the to schema is
+func stricter(from, to interface{}, path, subject string) []Violation {
+	if from == false {
+		return nil
+	}
+	fromObj, _ := from.(map[string]interface{})
+	toObj, ok := to.(map[string]interface{})
+	if !ok {
+		// boolean true or missing schemas accept any document
+		if to == false {
+			return []Violation{{Path: path, Message: subject + " rejects every value"}}
+		}
+		return nil
+	}
+	if fromObj == nil {
+		fromObj = map[string]interface{}{}
+	}
+
+	violations := make([]Violation, 0)
+	add := func(p, format string, args ...interface{}) {
+		violations = append(violations, Violation{Path: p, Message: subject + " " + fmt.Sprintf(format, args...)})
+	}
+
+	if fromTypes, toTypes := types(fromObj), types(toObj); toTypes != nil && !subset(fromTypes, toTypes) {
+		add(path, "restricts the type to %s", strings.Join(toTypes, ", "))
+	}
+	if toEnum, ok := toObj["enum"].([]interface{}); ok {
+		fromEnum, ok := fromObj["enum"].([]interface{})
+		if !ok || !enumSubset(fromEnum, toEnum) {
+			add(path, "restricts the allowed values")
+		}
+	}
+	for _, keyword := range minimumKeywords {
+		if bound, ok := toObj[keyword].(float64); ok {
+			if fromBound, ok := fromObj[keyword].(float64); !ok || bound > fromBound {
+				add(path, "sets %s to %v", keyword, bound)
+			}
+		}
+	}
+	for _, keyword := range maximumKeywords {
+		if bound, ok := toObj[keyword].(float64); ok {
+			if fromBound, ok := fromObj[keyword].(float64); !ok || bound < fromBound {
+				add(path, "sets %s to %v", keyword, bound)
+			}
+		}
+	}
+
+	fromRequired := stringSet(fromObj["required"])
+	for _, property := range sortedKeys(stringSet(toObj["required"])) {
+		if _, ok := fromRequired[property]; !ok {
+			add(propertyPath(path, property), "requires the property")
+		}
+	}
+
+	fromProperties, _ := fromObj["properties"].(map[string]interface{})
+	toProperties, _ := toObj["properties"].(map[string]interface{})
+	fromAdditional, toAdditional := additionalProperties(fromObj), additionalProperties(toObj)
+	closed := toAdditional == false
+	if closed && fromAdditional != false {
+		add(path, "rejects additional properties")
+	} else if !closed {
+		violations = append(violations, stricter(fromAdditional, toAdditional, propertyPath(path, "*"), subject)...)
+	}
+	for _, property := range sortedKeys(fromProperties) {
+		toProperty, ok := toProperties[property]
+		if !ok {
+			if closed {
+				add(propertyPath(path, property), "rejects the property")
+				continue
+			}
+			// the property falls under the additional properties of the to schema
+			toProperty = toAdditional
+		}
+		violations = append(violations, stricter(fromProperties[property], toProperty, propertyPath(path, property), subject)...)
+	}
+	// the properties the from schema does not declare fall under its additional properties
+	for _, property := range sortedKeys(toProperties) {
+		if _, ok := fromProperties[property]; ok {
+			continue
+		}
+		violations = append(violations, stricter(fromAdditional, toProperties[property], propertyPath(path, property), subject)...)
+	}
+
+	if toItems, ok := toObj["items"]; ok {
+		violations = append(violations, stricter(fromObj["items"], toItems, path+"[]", subject)...)
+	}
+	return violations
+}
+
+// types lists the types allowed by the schema, nil meaning any type
+func types(obj map[string]interface{}) []string {
+	switch t := obj["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		res := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// additionalProperties gets the schema of the properties the schema does not declare, any value being allowed by default
+func additionalProperties(obj map[string]interface{}) interface{} {
+	if additional, ok := obj["additionalProperties"]; ok {
+		return additional
+	}
+	return true
+}
+
+// subset tells whether every type of the from list is allowed by the to list, integers being numbers too
+func subset(from, to []string) bool {
+	if from == nil {
+		return false
+	}
+	allowed := map[string]bool{}
+	for _, t := range to {
+		allowed[t] = true
+	}
+	for _, t := range from {
+		if allowed[t] || (t == "integer" && allowed["number"]) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func enumSubset(from, to []interface{}) bool {
+	for _, fromValue := range from {
+		found := false
+		for _, toValue := range to {
+			if jsonEqual(fromValue, toValue) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonEqual(a, b interface{}) bool {
+	aBytes, errA := json.Marshal(a)
+	bBytes, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aBytes) == string(bBytes)
+}
+
+func stringSet(value interface{}) map[string]interface{} {
+	res := map[string]interface{}{}
+	values, _ := value.([]interface{})
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			res[s] = nil
+		}
+	}
+	return res
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func propertyPath(path, property string) string {
+	if path == RootPath {
+		return property
+	}
+	return path + "." + property
+}
@@ -0,0 +1,182 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+type compatibilitySuite struct {
+	testutils.Suite
+}
+
+func (s *compatibilitySuite) Test_SupportedCompatibility() {
+	s.True(SupportedCompatibility("backward"))
+	s.True(SupportedCompatibility("forward"))
+	s.True(SupportedCompatibility("full"))
+	s.True(SupportedCompatibility("none"))
+	s.False(SupportedCompatibility(""))
+	s.False(SupportedCompatibility("transitive"))
+}
+
+func (s *compatibilitySuite) Test_CheckCompatibility_Backward() {
+	testCases := []struct {
+		name       string
+		next       string
+		violations []Violation
+	}{
+		{
+			name: "UnconstrainedPropertyAdded",
+			next: `{"type": "object", "properties": {"name": {"type": "string"}, "age": {}}, "required": ["name"]}`,
+		},
+		{
+			name: "ConstrainedPropertyAdded",
+			next: `{"type": "object", "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}, "required": ["name"]}`,
+			violations: []Violation{
+				{Path: "age", Message: "the new version restricts the type to integer"},
+			},
+		},
+		{
+			name: "AdditionalPropertiesConstrained",
+			next: `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"], "additionalProperties": {"type": "string"}}`,
+			violations: []Violation{
+				{Path: "*", Message: "the new version restricts the type to string"},
+			},
+		},
+		{
+			name: "RequiredPropertyRemoved",
+			next: `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+		},
+		{
+			name: "TypeWidened",
+			next: `{"type": "object", "properties": {"name": {"type": ["string", "null"]}}, "required": ["name"]}`,
+		},
+		{
+			name: "RequiredPropertyAdded",
+			next: `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name", "age"]}`,
+			violations: []Violation{
+				{Path: "age", Message: "the new version requires the property"},
+			},
+		},
+		{
+			name: "TypeChanged",
+			next: `{"type": "object", "properties": {"name": {"type": "integer"}}, "required": ["name"]}`,
+			violations: []Violation{
+				{Path: "name", Message: "the new version restricts the type to integer"},
+			},
+		},
+		{
+			name: "AdditionalPropertiesClosed",
+			next: `{"type": "object", "additionalProperties": false, "required": ["name"]}`,
+			violations: []Violation{
+				{Path: "(root)", Message: "the new version rejects additional properties"},
+				{Path: "name", Message: "the new version rejects the property"},
+			},
+		},
+		{
+			name: "BoundsAndEnum",
+			next: `{"type": "object", "properties": {"name": {"type": "string", "maxLength": 10, "enum": ["a"]}}, "required": ["name"]}`,
+			violations: []Violation{
+				{Path: "name", Message: "the new version restricts the allowed values"},
+				{Path: "name", Message: "the new version sets maxLength to 10"},
+			},
+		},
+	}
+	previous := `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			violations, err := CheckCompatibility(BackwardCompatibility, []byte(previous), []byte(testCase.next))
+
+			s.Require().NoError(err)
+			s.ElementsMatch(testCase.violations, violations)
+		})
+	}
+}
+
+func (s *compatibilitySuite) Test_CheckCompatibility_ClosedPrevious() {
+	previous := `{"type": "object", "properties": {"name": {"type": "string"}}, "additionalProperties": false}`
+	testCases := []struct {
+		name       string
+		next       string
+		violations []Violation
+	}{
+		{
+			name: "ConstrainedPropertyAdded",
+			next: `{"type": "object", "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}, "additionalProperties": false}`,
+		},
+		{
+			name: "PropertyMovedToAdditionalProperties",
+			next: `{"type": "object", "additionalProperties": {"type": "integer"}}`,
+			violations: []Violation{
+				{Path: "name", Message: "the new version restricts the type to integer"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			violations, err := CheckCompatibility(BackwardCompatibility, []byte(previous), []byte(testCase.next))
+
+			s.Require().NoError(err)
+			s.ElementsMatch(testCase.violations, violations)
+		})
+	}
+}
+
+func (s *compatibilitySuite) Test_CheckCompatibility_Forward() {
+	previous := `{"type": "object", "properties": {"tags": {"type": "array", "items": {"type": "string"}}}, "required": ["tags"]}`
+	next := `{"type": "object", "properties": {"tags": {"type": "array", "items": {"type": ["string", "integer"]}}}}`
+	expected := []Violation{
+		{Path: "tags", Message: "the previous version requires the property"},
+		{Path: "tags[]", Message: "the previous version restricts the type to string"},
+	}
+
+	violations, err := CheckCompatibility(ForwardCompatibility, []byte(previous), []byte(next))
+
+	s.Require().NoError(err)
+	s.ElementsMatch(expected, violations)
+}
+
+func (s *compatibilitySuite) Test_CheckCompatibility_Full() {
+	previous := `{"type": "object", "properties": {"age": {"type": "integer", "minimum": 0}}}`
+	next := `{"type": "object", "properties": {"age": {"type": "integer", "minimum": 18}}}`
+	expected := []Violation{
+		{Path: "age", Message: "the new version sets minimum to 18"},
+	}
+
+	violations, err := CheckCompatibility(FullCompatibility, []byte(previous), []byte(next))
+	s.Require().NoError(err)
+	s.Equal(expected, violations)
+
+	violations, err = CheckCompatibility(FullCompatibility, []byte(next), []byte(previous))
+	s.Require().NoError(err)
+	s.Equal([]Violation{{Path: "age", Message: "the previous version sets minimum to 18"}}, violations)
+}
+
+func (s *compatibilitySuite) Test_CheckCompatibility_None() {
+	violations, err := CheckCompatibility(NoCompatibility, []byte(`{"type": "string"}`), []byte(`{"type": "integer"}`))
+
+	s.NoError(err)
+	s.Empty(violations)
+}
+
+func (s *compatibilitySuite) Test_CheckCompatibility_FalseSchema() {
+	violations, err := CheckCompatibility(BackwardCompatibility, []byte(`{}`), []byte(`false`))
+
+	s.NoError(err)
+	s.Equal([]Violation{{Path: "(root)", Message: "the new version rejects every value"}}, violations)
+}
+
+func (s *compatibilitySuite) Test_CheckCompatibility_InvalidJSONError() {
+	violations, err := CheckCompatibility(BackwardCompatibility, []byte(`{`), []byte(`{}`))
+
+	s.Error(err)
+	s.Nil(violations)
+}
+
+func Test_CompatibilitySuite(t *testing.T) {
+	suite.Run(t, new(compatibilitySuite))
+}
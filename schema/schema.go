@@ -0,0 +1,87 @@
+// Package schema validates the JSON events against the JSON Schema of their stream
+package schema
+
+import (
+	"fmt"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// RootPath is the path of the violations concerning the whole event
+const RootPath = "(root)"
+
+// Violation represents a single part of the event not matching the schema
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Schema represents a compiled JSON Schema, safe for concurrent use
+type Schema struct {
+	schema *gojsonschema.Schema
+}
+
+// Compile parses and compiles the JSON Schema document.
+// Only the references within the document are resolved, external ones such as http:// or file:// are refused
+func Compile(document []byte) (*Schema, error) {
+	s, err := gojsonschema.NewSchema(localLoader{JSONLoader: gojsonschema.NewBytesLoader(document)})
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{schema: s}, nil
+}
+
+// localLoader loads the schema document, refusing to load any document it references
+type localLoader struct {
+	gojsonschema.JSONLoader
+}
+
+func (localLoader) LoaderFactory() gojsonschema.JSONLoaderFactory {
+	return externalLoaderFactory{}
+}
+
+type externalLoaderFactory struct{}
+
+func (externalLoaderFactory) New(source string) gojsonschema.JSONLoader {
+	return externalLoader{JSONLoader: gojsonschema.NewReferenceLoader(source), source: source}
+}
+
+// externalLoader refuses to load the external references, so compiling a schema never reads a file nor makes a request
+type externalLoader struct {
+	gojsonschema.JSONLoader
+	source string
+}
+
+func (l externalLoader) LoadJSON() (interface{}, error) {
+	return nil, fmt.Errorf("external reference '%s' is not allowed, only references within the schema are", l.source)
+}
+
+// Validate validates the JSON document against the schema and lists the violations, if any
+func (s *Schema) Validate(document []byte) ([]Violation, error) {
+	res, err := s.schema.Validate(gojsonschema.NewBytesLoader(document))
+	if err != nil {
+		return nil, err
+	}
+	violations := make([]Violation, 0, len(res.Errors()))
+	for _, resErr := range res.Errors() {
+		violations = append(violations, Violation{
+			Path:    path(resErr),
+			Message: resErr.Description(),
+		})
+	}
+	return violations, nil
+}
+
+// path points the violation at the failing field,
+// including the missing properties which are reported on their parent otherwise
+func path(resErr gojsonschema.ResultError) string {
+	field := resErr.Field()
+	property, ok := resErr.Details()["property"]
+	if resErr.Type() != "required" || !ok {
+		return field
+	}
+	if field == RootPath {
+		return fmt.Sprint(property)
+	}
+	return fmt.Sprintf("%s.%v", field, property)
+}
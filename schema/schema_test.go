@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+const userSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer", "minimum": 0},
+		"address": {
+			"type": "object",
+			"properties": {"city": {"type": "string"}},
+			"required": ["city"]
+		}
+	},
+	"required": ["name"]
+}`
+
+type schemaSuite struct {
+	testutils.Suite
+	schema *Schema
+}
+
+func (s *schemaSuite) SetupTest() {
+	schema, err := Compile([]byte(userSchema))
+	s.Require().NoError(err)
+	s.schema = schema
+}
+
+func (s *schemaSuite) Test_Compile_InvalidSchemaError() {
+	schema, err := Compile([]byte(`{"type": "unknown"}`))
+
+	s.Error(err)
+	s.Nil(schema)
+}
+
+func (s *schemaSuite) Test_Compile_InvalidJSONError() {
+	schema, err := Compile([]byte(`{"type": `))
+
+	s.Error(err)
+	s.Nil(schema)
+}
+
+func (s *schemaSuite) Test_Compile_LocalReference() {
+	schema, err := Compile([]byte(`{
+		"definitions": {"name": {"type": "string"}},
+		"properties": {"name": {"$ref": "#/definitions/name"}}
+	}`))
+	s.Require().NoError(err)
+
+	violations, err := schema.Validate([]byte(`{"name": 1}`))
+
+	s.NoError(err)
+	s.Len(violations, 1)
+}
+
+func (s *schemaSuite) Test_Compile_ExternalReferenceError() {
+	testCases := []struct {
+		name     string
+		document string
+		ref      string
+	}{
+		{name: "file", document: `{"$ref": "file:///etc/passwd"}`, ref: "file:///etc/passwd"},
+		{name: "http", document: `{"properties": {"a": {"$ref": "http://localhost:9999/schema.json"}}}`, ref: "http://localhost:9999/schema.json"},
+		{name: "relative to id", document: `{"$id": "http://localhost:9999/a.json", "properties": {"a": {"$ref": "b.json"}}}`, ref: "http://localhost:9999/b.json"},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			schema, err := Compile([]byte(testCase.document))
+
+			s.EqualError(err, "external reference '"+testCase.ref+"' is not allowed, only references within the schema are")
+			s.Nil(schema)
+		})
+	}
+}
+
+func (s *schemaSuite) Test_Validate_Success() {
+	violations, err := s.schema.Validate([]byte(`{"name": "steve", "age": 30, "address": {"city": "NY"}}`))
+
+	s.NoError(err)
+	s.Empty(violations)
+}
+
+func (s *schemaSuite) Test_Validate_Violations() {
+	violations, err := s.schema.Validate([]byte(`{"age": -1, "address": {}}`))
+
+	s.NoError(err)
+	s.ElementsMatch([]Violation{
+		{Path: "name", Message: "name is required"},
+		{Path: "age", Message: "Must be greater than or equal to 0"},
+		{Path: "address.city", Message: "city is required"},
+	}, violations)
+}
+
+func (s *schemaSuite) Test_Validate_RootViolation() {
+	violations, err := s.schema.Validate([]byte(`["steve"]`))
+
+	s.NoError(err)
+	s.Equal([]Violation{{Path: RootPath, Message: "Invalid type. Expected: object, given: array"}}, violations)
+}
+
+func (s *schemaSuite) Test_Validate_InvalidJSONError() {
+	violations, err := s.schema.Validate([]byte(`{"name": `))
+
+	s.Error(err)
+	s.Nil(violations)
+}
+
+func Test_SchemaSuite(t *testing.T) {
+	suite.Run(t, new(schemaSuite))
+}
@@ -1,40 +1,298 @@
 package server
 
 import (
+	"bytes"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/go-web-dev/event-bus/compression"
 	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/metrics"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
 )
 
+// wireFormat represents how the requests and responses of a connection are sent over the wire
+type wireFormat struct {
+	encoding    string
+	compression string
+}
+
+// framed tells whether length prefixed frames are used instead of newline delimited messages,
+// which is the case for any other encoding than JSON and for compressed connections
+func (f wireFormat) framed() bool {
+	return (f.encoding != "" && f.encoding != transport.JSONEncoding) || f.compression != compression.None
+}
+
+// codec gets the codec of the wire encoding, JSON unless another one was negotiated
+func (f wireFormat) codec() transport.Codec {
+	if codec, ok := transport.GetCodec(f.encoding); ok {
+		return codec
+	}
+	codec, _ := transport.GetCodec(transport.JSONEncoding)
+	return codec
+}
+
+// disconnectOperation is the notice sent to the connections closed by an admin client
+const disconnectOperation = "disconnect"
+
 type connection struct {
-	id   int
-	conn net.Conn
+	// requests comes first to be 64-bit aligned for the atomic operations
+	requests             int64
+	id                   int
+	conn                 net.Conn
+	connectedAt          time.Time
+	mu                   sync.Mutex
+	writeTimeout         time.Duration
+	compressionThreshold int
+	limiter              *clientLimiter
+	format               wireFormat
+	stateMu              sync.Mutex
+	client               string
+}
+
+// authorize binds the connection to the client authenticated on it
+// and takes a request of the given size out of the client's limits
+func (c *connection) authorize(client string, size int) error {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if c.limiter == nil {
+		c.client = client
+		return nil
+	}
+	err := c.limiter.rebind(c.id, c.client, client)
+	if err != nil {
+		return err
+	}
+	c.client = client
+	return c.limiter.allow(client, size)
+}
+
+func (c *connection) release() {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if c.limiter != nil && c.client != "" {
+		c.limiter.unregister(c.client, c.id)
+	}
+}
+
+// served counts a request served on the connection
+func (c *connection) served() {
+	atomic.AddInt64(&c.requests, 1)
+}
+
+func (c *connection) info() models.Connection {
+	c.stateMu.Lock()
+	client := c.client
+	c.stateMu.Unlock()
+	return models.Connection{
+		ID:          c.id,
+		Transport:   models.TCPTransport,
+		RemoteAddr:  c.conn.RemoteAddr().String(),
+		Client:      client,
+		ConnectedAt: c.connectedAt,
+		Requests:    atomic.LoadInt64(&c.requests),
+	}
+}
+
+// ID identifies the connection, the same way the server logs it as client_id
+func (c *connection) ID() int {
+	return c.id
+}
+
+// RemoteAddr gets the address of the client on the other end of the connection
+func (c *connection) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetEncoding switches the wire encoding of the connection.
+// Any other encoding than JSON uses length prefixed frames for both requests and responses
+func (c *connection) SetEncoding(encoding string) error {
+	if _, ok := transport.GetCodec(encoding); !ok {
+		return models.UnsupportedEncodingError{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.format.encoding = encoding
+	return nil
+}
+
+// SetCompression switches the compression of the responses larger than the compression threshold.
+// Compressed connections use length prefixed frames for both requests and responses
+func (c *connection) SetCompression(algorithm string) error {
+	if !compression.Supported(algorithm) {
+		return models.UnsupportedCompressionError{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.format.compression = algorithm
+	return nil
+}
+
+func (c *connection) getFormat() wireFormat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	format := c.format
+	if format.encoding == "" {
+		format.encoding = transport.JSONEncoding
+	}
+	return format
+}
+
+// Encode writes the response with the connection wire encoding. The writes are serialized,
+// so that server notices never interleave with operation responses
+func (c *connection) Encode(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bs, err := c.format.codec().Marshal(v)
+	if err != nil {
+		return err
+	}
+	if !c.format.framed() {
+		bs = append(bs, '\n')
+	}
+	_, err = c.write(bs)
+	return err
+}
+
+// Write writes a message already encoded with the connection wire encoding,
+// serializing the writes the same way Encode does
+func (c *connection) Write(bs []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.write(bs)
+}
+
+func (c *connection) write(bs []byte) (int, error) {
+	out := bs
+	if c.format.framed() {
+		msg, err := c.frame(bs)
+		if err != nil {
+			return 0, err
+		}
+		out = msg
+	}
+	if c.writeTimeout > 0 {
+		err := c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+		if err != nil {
+			return 0, err
+		}
+	}
+	_, err := c.conn.Write(out)
+	if err != nil {
+		return 0, err
+	}
+	return len(bs), nil
 }
 
-type connections struct {
-	connMap map[int]*connection
-	mu      sync.RWMutex
-	index   int
+// frame turns the message into a frame of the connection wire format,
+// compressing it when it is larger than the compression threshold
+func (c *connection) frame(bs []byte) ([]byte, error) {
+	msg := bytes.TrimSuffix(bs, []byte("\n"))
+	if c.format.compression == compression.None || len(msg) < c.compressionThreshold {
+		return frame(msg, false), nil
+	}
+	compressed, err := compression.Compress(c.format.compression, msg)
+	if err != nil {
+		return nil, err
+	}
+	return frame(compressed, true), nil
+}
+
+// Connections represents the connections of the TCP server along with the ones of the other transports
+// sharing the client limits, it lets admin clients list and close them
+type Connections struct {
+	connMap              map[int]*connection
+	mu                   sync.RWMutex
+	index                int
+	writeTimeout         time.Duration
+	compressionThreshold int
+	maxConnections       int
+	limiter              *clientLimiter
+	sessions             map[int]*session
+}
+
+// NewConnections creates the registry of the TCP server connections, to be given to the server settings
+func NewConnections() *Connections {
+	return &Connections{
+		connMap:  map[int]*connection{},
+		sessions: map[int]*session{},
+	}
 }
 
-func (c *connections) add(conn net.Conn) *connection {
+// configure sets up the connections the server adds with the server settings
+func (c *Connections) configure(writeTimeout time.Duration, compressionThreshold, maxConnections int, limiter *clientLimiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeTimeout = writeTimeout
+	c.compressionThreshold = compressionThreshold
+	c.maxConnections = maxConnections
+	c.limiter = limiter
+}
+
+// List lists the open connections ordered by ID, the ones opened by Connect included
+func (c *Connections) List() []models.Connection {
+	conns := c.all()
+	res := c.listSessions()
+	for _, conn := range conns {
+		res = append(res, conn.info())
+	}
+	sortConnections(res)
+	return res
+}
+
+// Disconnect notifies the connection it is being disconnected and closes it
+func (c *Connections) Disconnect(connID int) error {
+	c.mu.RLock()
+	conn, ok := c.connMap[connID]
+	c.mu.RUnlock()
+	if !ok {
+		return c.disconnectSession(connID)
+	}
+	transport.SendJSON(conn, disconnectOperation, nil)
+	c.close(connID)
+	return nil
+}
+
+// DisconnectClient disconnects every connection the client is authenticated on,
+// giving back the IDs of the closed connections
+func (c *Connections) DisconnectClient(client string) []int {
+	ids := make([]int, 0)
+	for _, conn := range c.List() {
+		if conn.Client != client {
+			continue
+		}
+		if c.Disconnect(conn.ID) == nil {
+			ids = append(ids, conn.ID)
+		}
+	}
+	return ids
+}
+
+func (c *Connections) add(conn net.Conn) *connection {
 	logger := logging.Logger
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	res := &connection{
-		id:   c.index,
-		conn: conn,
+		id:                   c.index,
+		conn:                 conn,
+		connectedAt:          time.Now().UTC(),
+		writeTimeout:         c.writeTimeout,
+		compressionThreshold: c.compressionThreshold,
+		limiter:              c.limiter,
 	}
 	c.connMap[c.index] = res
+	metrics.ConnectionOpened()
 	logger.Info("client joined", zap.Int("client_id", c.index))
 	c.index++
 	return res
 }
 
-func (c *connections) close(connID int) {
+func (c *Connections) close(connID int) {
 	logger := logging.Logger
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -52,12 +310,31 @@ func (c *connections) close(connID int) {
 			zap.Error(err),
 		)
 	}
+	connection.release()
 	delete(c.connMap, connID)
+	metrics.ConnectionClosed()
 	logger.Info("client left", zap.Int("client_id", connID))
 }
 
-func (c *connections) closeAll() {
-	for id := range c.connMap {
-		c.close(id)
+func (c *Connections) closeAll() {
+	for _, conn := range c.all() {
+		c.close(conn.id)
+	}
+}
+
+// count counts the open connections, the ones of the other transports included
+func (c *Connections) count() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.connMap) + len(c.sessions)
+}
+
+func (c *Connections) all() []*connection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	res := make([]*connection, 0, len(c.connMap))
+	for _, conn := range c.connMap {
+		res = append(res, conn)
 	}
+	return res
 }
@@ -1,19 +1,26 @@
 package server
 
 import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
 	"net"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/go-web-dev/event-bus/compression"
 	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/testutils"
+	"github.com/go-web-dev/event-bus/transport"
 )
 
 type connectionSuite struct {
 	testutils.Suite
-	conns       connections
+	conns       *Connections
 	li          net.Listener
 	loggerEntry zapcore.Entry
 }
@@ -23,9 +30,9 @@ func (s *connectionSuite) SetupSuite() {
 	li, err := net.Listen("tcp", "localhost:8080")
 	s.Require().NoError(err)
 	s.li = li
+	// the connections are dialed for their net.Conn only, the accepted one being left unused
 	go func() {
-		_, err = li.Accept()
-		s.Require().NoError(err)
+		_, _ = li.Accept()
 	}()
 }
 
@@ -34,7 +41,7 @@ func (s *connectionSuite) TearDownSuite() {
 }
 
 func (s *connectionSuite) SetupTest() {
-	s.conns = connections{connMap: map[int]*connection{}}
+	s.conns = NewConnections()
 }
 
 func (s *connectionSuite) Test_add() {
@@ -46,6 +53,8 @@ func (s *connectionSuite) Test_add() {
 
 	c := s.conns.add(conn)
 
+	s.False(c.connectedAt.IsZero())
+	expected.connectedAt = c.connectedAt
 	s.Equal(expected, c)
 	s.Equal(expected, s.conns.connMap[0])
 	s.Equal(1, s.conns.index)
@@ -126,6 +135,253 @@ func (s *connectionSuite) Test_closeAll_ConnCloseError() {
 	s.Equal("could not close connection", s.loggerEntry.Message)
 }
 
+func (s *connectionSuite) Test_List() {
+	conn1 := s.conns.add(s.newConn())
+	conn2 := s.conns.add(s.newConn())
+	s.Require().NoError(conn2.authorize("client", 1))
+	conn2.served()
+
+	conns := s.conns.List()
+
+	s.Equal([]models.Connection{
+		{
+			ID:          0,
+			Transport:   models.TCPTransport,
+			RemoteAddr:  conn1.conn.RemoteAddr().String(),
+			ConnectedAt: conn1.connectedAt,
+		},
+		{
+			ID:          1,
+			Transport:   models.TCPTransport,
+			RemoteAddr:  conn2.conn.RemoteAddr().String(),
+			Client:      "client",
+			ConnectedAt: conn2.connectedAt,
+			Requests:    1,
+		},
+	}, conns)
+}
+
+func (s *connectionSuite) Test_Disconnect_Success() {
+	s.conns.add(s.newConn())
+
+	err := s.conns.Disconnect(0)
+
+	s.Require().NoError(err)
+	s.Len(s.conns.connMap, 0)
+}
+
+func (s *connectionSuite) Test_Disconnect_ConnectionNotFoundError() {
+	err := s.conns.Disconnect(3)
+
+	s.Equal(models.ConnectionNotFoundError{ID: 3}, err)
+}
+
+func (s *connectionSuite) Test_DisconnectClient() {
+	for i := 0; i < 3; i++ {
+		s.conns.add(s.newConn())
+	}
+	s.Require().NoError(s.conns.connMap[0].authorize("client", 1))
+	s.Require().NoError(s.conns.connMap[2].authorize("client", 1))
+
+	ids := s.conns.DisconnectClient("client")
+
+	s.Equal([]int{0, 2}, ids)
+	s.Len(s.conns.connMap, 1)
+	s.Contains(s.conns.connMap, 1)
+}
+
+func (s *connectionSuite) Test_Authorize_NoLimiter() {
+	c := &connection{id: 0}
+
+	err := c.authorize("client", 0)
+
+	s.Require().NoError(err)
+	s.Equal("client", c.client)
+}
+
+func (s *connectionSuite) Test_Authorize_SwitchClient() {
+	limiter := newClientLimiter(Limits{MaxClientConnections: 1})
+	c := &connection{id: 0, limiter: limiter}
+
+	s.Require().NoError(c.authorize("client1", 0))
+	s.Require().NoError(c.authorize("client2", 0))
+
+	s.Equal("client2", c.client)
+	s.Len(limiter.clients["client1"].connections, 0)
+	s.Len(limiter.clients["client2"].connections, 1)
+}
+
+func (s *connectionSuite) Test_Authorize_TooManyConnectionsError() {
+	limiter := newClientLimiter(Limits{MaxClientConnections: 1})
+	c1 := &connection{id: 0, limiter: limiter}
+	c2 := &connection{id: 1, limiter: limiter}
+	s.Require().NoError(c1.authorize("client", 0))
+
+	err := c2.authorize("client", 0)
+
+	s.Equal(models.TooManyConnectionsError{Client: "client"}, err)
+	s.Empty(c2.client)
+}
+
+func (s *connectionSuite) Test_close_ReleasesClient() {
+	limiter := newClientLimiter(Limits{MaxClientConnections: 1})
+	s.conns.limiter = limiter
+	c := s.conns.add(s.newConn())
+	s.Require().NoError(c.authorize("client", 0))
+
+	s.conns.close(c.id)
+
+	s.Len(limiter.clients["client"].connections, 0)
+}
+
+func (s *connectionSuite) Test_SetEncoding_Success() {
+	c := &connection{id: 0}
+
+	err := c.SetEncoding("msgpack")
+
+	s.Require().NoError(err)
+	s.Equal("msgpack", c.getFormat().encoding)
+}
+
+func (s *connectionSuite) Test_SetEncoding_UnsupportedEncodingError() {
+	c := &connection{id: 0}
+
+	err := c.SetEncoding("xml")
+
+	s.Equal(models.UnsupportedEncodingError{}, err)
+	s.Equal("json", c.getFormat().encoding)
+}
+
+func (s *connectionSuite) Test_Write_JSON() {
+	client, conn := net.Pipe()
+	defer client.Close()
+	c := &connection{conn: conn, id: 0}
+	msg := []byte(`{"operation":"health","status":true}` + "\n")
+	res := make(chan []byte)
+	go func() {
+		bs := make([]byte, len(msg))
+		_, err := io.ReadFull(client, bs)
+		s.Require().NoError(err)
+		res <- bs
+	}()
+
+	n, err := c.Write(msg)
+
+	s.Require().NoError(err)
+	s.Equal(len(msg), n)
+	s.Equal(msg, <-res)
+}
+
+func (s *connectionSuite) Test_Encode_JSON() {
+	client, conn := net.Pipe()
+	defer client.Close()
+	c := &connection{conn: conn, id: 0}
+	res := make(chan string)
+	go func() {
+		line, err := bufio.NewReader(client).ReadString('\n')
+		s.Require().NoError(err)
+		res <- line
+	}()
+
+	err := c.Encode(transport.Response{Operation: "health", Status: true})
+
+	s.Require().NoError(err)
+	s.Equal(`{"operation":"health","status":true}`+"\n", <-res)
+}
+
+func (s *connectionSuite) Test_Encode_MsgPack() {
+	client, conn := net.Pipe()
+	defer client.Close()
+	c := &connection{conn: conn, id: 0}
+	s.Require().NoError(c.SetEncoding("msgpack"))
+	res := make(chan []byte)
+	go func() {
+		r := bufio.NewReader(client)
+		srv := &Server{maxRequestSize: 100}
+		bs, err := srv.readFrame(r, "")
+		s.Require().NoError(err)
+		res <- bs
+	}()
+	body := map[string]interface{}{
+		"event": json.RawMessage(`{"retries":3}`),
+		"data":  []byte("hi"),
+	}
+
+	err := c.Encode(transport.Response{Operation: "health", Status: true, Body: body})
+
+	s.Require().NoError(err)
+	codec, _ := transport.GetCodec("msgpack")
+	var decoded map[string]interface{}
+	s.Require().NoError(codec.Unmarshal(<-res, &decoded))
+	s.Equal("health", decoded["operation"])
+	s.Equal(true, decoded["status"])
+	decodedBody := decoded["body"].(map[string]interface{})
+	s.Equal([]byte("hi"), decodedBody["data"])
+	s.EqualValues(3, decodedBody["event"].(map[string]interface{})["retries"])
+}
+
+func (s *connectionSuite) Test_SetCompression_Success() {
+	c := &connection{id: 0}
+
+	err := c.SetCompression("zstd")
+
+	s.Require().NoError(err)
+	s.Equal(wireFormat{encoding: "json", compression: "zstd"}, c.getFormat())
+}
+
+func (s *connectionSuite) Test_SetCompression_UnsupportedCompressionError() {
+	c := &connection{id: 0}
+
+	err := c.SetCompression("gzip")
+
+	s.Equal(models.UnsupportedCompressionError{}, err)
+	s.Equal(wireFormat{encoding: "json"}, c.getFormat())
+}
+
+func (s *connectionSuite) Test_Write_Compressed() {
+	testCases := []struct {
+		name       string
+		threshold  int
+		compressed bool
+	}{
+		{name: "AboveThreshold", threshold: 10, compressed: true},
+		{name: "BelowThreshold", threshold: 1000, compressed: false},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			client, conn := net.Pipe()
+			defer client.Close()
+			c := &connection{conn: conn, id: 0, compressionThreshold: testCase.threshold}
+			s.Require().NoError(c.SetCompression("snappy"))
+			msg := []byte(`{"operation":"health","status":true}` + "\n")
+			res := make(chan []byte)
+			go func() {
+				header := make([]byte, frameHeaderSize)
+				_, err := io.ReadFull(client, header)
+				s.Require().NoError(err)
+				length := binary.BigEndian.Uint32(header)
+				s.Equal(testCase.compressed, length&compressedFrameFlag != 0)
+				bs := make([]byte, length&^compressedFrameFlag)
+				_, err = io.ReadFull(client, bs)
+				s.Require().NoError(err)
+				res <- bs
+			}()
+
+			n, err := c.Write(msg)
+
+			s.Require().NoError(err)
+			s.Equal(len(msg), n)
+			bs := <-res
+			if testCase.compressed {
+				bs, err = compression.Decompress("snappy", bs, 0)
+				s.Require().NoError(err)
+			}
+			s.Equal(`{"operation":"health","status":true}`, string(bs))
+		})
+	}
+}
+
 func (s *connectionSuite) newConn() net.Conn {
 	conn, err := net.Dial("tcp", "localhost:8080")
 	s.Require().NoError(err)
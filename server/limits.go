@@ -0,0 +1,134 @@
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+// Limits represents the connection and rate limits enforced by the server.
+// Zero values mean unlimited
+type Limits struct {
+	MaxConnections       int
+	MaxClientConnections int
+	RequestsPerSecond    float64
+	RequestBurst         int
+	BytesPerSecond       float64
+	BytesBurst           int
+}
+
+type clientState struct {
+	connections map[int]struct{}
+	requests    *rate.Limiter
+	bytes       *rate.Limiter
+}
+
+// clientLimiter enforces the per client limits.
+// Client state outlives the client connections, so reconnecting does not reset the rate limits
+type clientLimiter struct {
+	limits  Limits
+	mu      sync.Mutex
+	clients map[string]*clientState
+}
+
+func newClientLimiter(limits Limits) *clientLimiter {
+	return &clientLimiter{
+		limits:  limits,
+		clients: map[string]*clientState{},
+	}
+}
+
+func (l *clientLimiter) state(client string) *clientState {
+	state, ok := l.clients[client]
+	if ok {
+		return state
+	}
+	state = &clientState{
+		connections: map[int]struct{}{},
+		requests:    newLimiter(l.limits.RequestsPerSecond, l.limits.RequestBurst),
+		bytes:       newLimiter(l.limits.BytesPerSecond, l.limits.BytesBurst),
+	}
+	l.clients[client] = state
+	return state
+}
+
+// register binds a connection to a client enforcing the per client connections limit
+func (l *clientLimiter) register(client string, connID int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state := l.state(client)
+	if _, ok := state.connections[connID]; ok {
+		return nil
+	}
+	max := l.limits.MaxClientConnections
+	if max > 0 && len(state.connections) >= max {
+		return models.TooManyConnectionsError{Client: client}
+	}
+	state.connections[connID] = struct{}{}
+	return nil
+}
+
+func (l *clientLimiter) unregister(client string, connID int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if state, ok := l.clients[client]; ok {
+		delete(state.connections, connID)
+	}
+}
+
+// rebind moves a connection from the client it was bound to onto another one,
+// enforcing the per client connections limit
+func (l *clientLimiter) rebind(connID int, from, to string) error {
+	if from == to {
+		return nil
+	}
+	err := l.register(to, connID)
+	if err != nil {
+		return err
+	}
+	if from != "" {
+		l.unregister(from, connID)
+	}
+	return nil
+}
+
+// allow takes a request of the given size out of the client's rate limits
+func (l *clientLimiter) allow(client string, size int) error {
+	l.mu.Lock()
+	state := l.state(client)
+	l.mu.Unlock()
+
+	now := time.Now()
+	req := state.requests.ReserveN(now, 1)
+	if delay := req.DelayFrom(now); !req.OK() || delay > 0 {
+		req.CancelAt(now)
+		return rateLimited(delay)
+	}
+	bytes := state.bytes.ReserveN(now, size)
+	if !bytes.OK() {
+		req.CancelAt(now)
+		return rateLimited(time.Duration(float64(size) / l.limits.BytesPerSecond * float64(time.Second)))
+	}
+	if delay := bytes.DelayFrom(now); delay > 0 {
+		bytes.CancelAt(now)
+		req.CancelAt(now)
+		return rateLimited(delay)
+	}
+	return nil
+}
+
+func newLimiter(perSecond float64, burst int) *rate.Limiter {
+	if perSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), burst)
+}
+
+func rateLimited(delay time.Duration) models.RateLimitError {
+	ms := int64(math.Ceil(float64(delay) / float64(time.Millisecond)))
+	return models.RateLimitError{RetryAfterMS: ms}
+}
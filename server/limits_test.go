@@ -0,0 +1,106 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+type limitsSuite struct {
+	testutils.Suite
+}
+
+func (s *limitsSuite) SetupSuite() {
+	logging.Logger = testutils.Logger(s.T(), nil)
+}
+
+func (s *limitsSuite) Test_register_Success() {
+	limiter := newClientLimiter(Limits{MaxClientConnections: 2})
+
+	s.Require().NoError(limiter.register("client", 1))
+	s.Require().NoError(limiter.register("client", 1))
+	s.Require().NoError(limiter.register("client", 2))
+	s.Require().NoError(limiter.register("other-client", 3))
+
+	s.Len(limiter.clients["client"].connections, 2)
+	s.Len(limiter.clients["other-client"].connections, 1)
+}
+
+func (s *limitsSuite) Test_register_TooManyConnectionsError() {
+	limiter := newClientLimiter(Limits{MaxClientConnections: 1})
+	s.Require().NoError(limiter.register("client", 1))
+
+	err := limiter.register("client", 2)
+
+	s.Equal(models.TooManyConnectionsError{Client: "client"}, err)
+	s.EqualError(err, "too many connections for client 'client'")
+}
+
+func (s *limitsSuite) Test_unregister() {
+	limiter := newClientLimiter(Limits{MaxClientConnections: 1})
+	s.Require().NoError(limiter.register("client", 1))
+
+	limiter.unregister("client", 1)
+	limiter.unregister("unknown-client", 1)
+
+	s.Require().NoError(limiter.register("client", 2))
+}
+
+func (s *limitsSuite) Test_allow_Unlimited() {
+	limiter := newClientLimiter(Limits{})
+
+	for i := 0; i < 1000; i++ {
+		s.Require().NoError(limiter.allow("client", 1<<20))
+	}
+}
+
+func (s *limitsSuite) Test_allow_RequestsRateLimitError() {
+	limiter := newClientLimiter(Limits{RequestsPerSecond: 10, RequestBurst: 2})
+	s.Require().NoError(limiter.allow("client", 10))
+	s.Require().NoError(limiter.allow("client", 10))
+
+	err := limiter.allow("client", 10)
+
+	s.Require().IsType(models.RateLimitError{}, err)
+	s.InDelta(100, err.(models.RateLimitError).RetryAfterMS, 10)
+	s.Require().NoError(limiter.allow("other-client", 10))
+}
+
+func (s *limitsSuite) Test_allow_BytesRateLimitError() {
+	limiter := newClientLimiter(Limits{BytesPerSecond: 100, BytesBurst: 100})
+	s.Require().NoError(limiter.allow("client", 60))
+
+	err := limiter.allow("client", 60)
+
+	s.Require().IsType(models.RateLimitError{}, err)
+	s.InDelta(200, err.(models.RateLimitError).RetryAfterMS, 10)
+}
+
+func (s *limitsSuite) Test_allow_RequestLargerThanBytesBurst() {
+	limiter := newClientLimiter(Limits{BytesPerSecond: 100, BytesBurst: 100})
+
+	err := limiter.allow("client", 200)
+
+	s.Equal(models.RateLimitError{RetryAfterMS: 2000}, err)
+}
+
+func (s *limitsSuite) Test_allow_CancelsRequestReservation() {
+	limiter := newClientLimiter(Limits{
+		RequestsPerSecond: 1,
+		RequestBurst:      1,
+		BytesPerSecond:    100,
+		BytesBurst:        100,
+	})
+
+	s.Require().IsType(models.RateLimitError{}, limiter.allow("client", 200))
+
+	s.Require().NoError(limiter.allow("client", 10))
+}
+
+func Test_LimitsSuite(t *testing.T) {
+	suite.Run(t, new(limitsSuite))
+}
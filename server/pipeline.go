@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-web-dev/event-bus/transport"
+)
+
+// requestConn is the writer a single request is switched with.
+// It carries the request size, so that pipelined requests take their own size out of the client's limits,
+// along with the codec the request was read with
+type requestConn struct {
+	*connection
+	size  int
+	codec transport.Codec
+}
+
+// Codec gets the codec the request is decoded with
+func (rc requestConn) Codec() transport.Codec {
+	return rc.codec
+}
+
+// Authorize binds the connection to the client authenticated on it
+// and takes the request out of the client's limits
+func (rc requestConn) Authorize(client string) error {
+	return rc.connection.authorize(client, rc.size)
+}
+
+// pipeline processes the requests of a single connection concurrently, up to a limit
+type pipeline struct {
+	slots   chan struct{}
+	pending sync.WaitGroup
+}
+
+func newPipeline(size int) *pipeline {
+	return &pipeline{
+		slots: make(chan struct{}, size),
+	}
+}
+
+// run processes the request in the background, it blocks while the pipeline is full
+func (p *pipeline) run(process func()) {
+	p.slots <- struct{}{}
+	p.pending.Add(1)
+	go func() {
+		defer func() {
+			<-p.slots
+			p.pending.Done()
+		}()
+		process()
+	}()
+}
+
+// wait waits for all the pipelined requests to finish
+func (p *pipeline) wait() {
+	p.pending.Wait()
+}
+
+// pipelined peeks the request to tell whether it can be processed concurrently.
+// Only requests with an ID are pipelined, the ones without keep being processed in order.
+// The hello operation is never pipelined since it changes how the following requests are read
+func pipelined(codec transport.Codec, req []byte) bool {
+	var r struct {
+		Operation string `json:"operation"`
+		RequestID string `json:"request_id"`
+	}
+	err := codec.Unmarshal(req, &r)
+	if err != nil {
+		return false
+	}
+	return r.RequestID != "" && r.Operation != helloOperation
+}
+
+// stopReading unblocks the connection reader once a pipelined request asked to exit
+func stopReading(conn net.Conn) error {
+	if cr, ok := conn.(interface{ CloseRead() error }); ok {
+		return cr.CloseRead()
+	}
+	return conn.SetReadDeadline(time.Now())
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *serverSuite) Test_Pipeline_ConcurrentRequests() {
+	release := make(chan struct{})
+	s.router.
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
+		Run(func(args mock.Arguments) {
+			req := s.requestBody(args)
+			// the first request only finishes after the second one, which is impossible unless pipelined
+			if bytes.Contains(req, []byte("r1")) {
+				select {
+				case <-release:
+				case <-time.After(time.Second):
+				}
+			} else {
+				close(release)
+			}
+			_, err := args.Get(0).(requestConn).Write(append(req, '\n'))
+			s.Require().NoError(err)
+		}).
+		Return(false, nil).
+		Twice()
+	srv := s.pipelinedServer("localhost:9097", 2)
+	conn := s.newConn("localhost:9097")
+
+	s.connWrite(conn, `{"operation": "op", "request_id": "r1"}`)
+	s.connWrite(conn, `{"operation": "op", "request_id": "r2"}`)
+
+	reader := bufio.NewReader(conn)
+	s.JSONEq(`{"operation": "op", "request_id": "r2"}`, s.readLineFrom(reader))
+	s.JSONEq(`{"operation": "op", "request_id": "r1"}`, s.readLineFrom(reader))
+	s.Require().NoError(srv.listener.Close())
+}
+
+func (s *serverSuite) Test_Pipeline_RequestsWithoutIDInOrder() {
+	var mu sync.Mutex
+	var order []string
+	s.router.
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
+		Run(func(args mock.Arguments) {
+			req := s.requestBody(args)
+			if bytes.Contains(req, []byte("r1")) {
+				time.Sleep(100 * time.Millisecond)
+			}
+			mu.Lock()
+			order = append(order, string(req))
+			mu.Unlock()
+			_, err := args.Get(0).(requestConn).Write(append(req, '\n'))
+			s.Require().NoError(err)
+		}).
+		Return(false, nil).
+		Twice()
+	srv := s.pipelinedServer("localhost:9098", 2)
+	conn := s.newConn("localhost:9098")
+
+	s.connWrite(conn, `{"operation": "op", "request_id": "r1"}`)
+	s.connWrite(conn, `{"operation": "op"}`)
+
+	reader := bufio.NewReader(conn)
+	s.JSONEq(`{"operation": "op", "request_id": "r1"}`, s.readLineFrom(reader))
+	s.JSONEq(`{"operation": "op"}`, s.readLineFrom(reader))
+	s.Equal([]string{`{"operation": "op", "request_id": "r1"}`, `{"operation": "op"}`}, order)
+	s.Require().NoError(srv.listener.Close())
+}
+
+func (s *serverSuite) Test_Pipeline_Exit() {
+	s.router.
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
+		Return(true, nil).
+		Once()
+	srv := s.pipelinedServer("localhost:9099", 2)
+	conn := s.newConn("localhost:9099")
+	s.waitForConnections(srv, 1)
+
+	s.connWrite(conn, `{"operation": "exit", "request_id": "r1"}`)
+
+	s.waitForConnections(srv, 0)
+	s.Require().NoError(srv.listener.Close())
+}
+
+func (s *serverSuite) Test_requestConn_Authorize() {
+	limiter := newClientLimiter(Limits{BytesPerSecond: 1, BytesBurst: 10})
+	c := &connection{id: 0, limiter: limiter}
+
+	s.Require().NoError(requestConn{connection: c, size: 10}.Authorize("client"))
+	err := requestConn{connection: c, size: 10}.Authorize("client")
+
+	s.IsType(models.RateLimitError{}, err)
+	s.Equal("client", c.client)
+}
+
+func (s *serverSuite) Test_pipelined() {
+	testCases := []struct {
+		name     string
+		req      string
+		expected bool
+	}{
+		{name: "with id", req: `{"operation": "op", "request_id": "r1"}`, expected: true},
+		{name: "without id", req: `{"operation": "op"}`, expected: false},
+		{name: "hello", req: `{"operation": "hello", "request_id": "r1"}`, expected: false},
+		{name: "invalid json", req: `{"operation": "op", "request_id": "r1"`, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			s.Equal(testCase.expected, pipelined(wireFormat{}.codec(), []byte(testCase.req)))
+		})
+	}
+}
+
+func (s *serverSuite) pipelinedServer(addr string, maxPipelined int) *Server {
+	srv, err := ListenAndServe(Settings{
+		Addr:         addr,
+		DB:           s.db,
+		Router:       s.router,
+		Deadline:     50 * time.Millisecond,
+		MaxPipelined: maxPipelined,
+	})
+	s.Require().NoError(err)
+	return srv
+}
+
+func (s *serverSuite) requestBody(args mock.Arguments) []byte {
+	bs, err := ioutil.ReadAll(args.Get(1).(*bytes.Reader))
+	s.Require().NoError(err)
+	return bs
+}
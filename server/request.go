@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"time"
+
+	"github.com/go-web-dev/event-bus/compression"
+	"github.com/go-web-dev/event-bus/models"
+)
+
+const (
+	// frameHeaderSize is the size of the big endian length prefixing the framed requests and responses
+	frameHeaderSize = 4
+	// compressedFrameFlag is the highest bit of the frame length, set when the frame is compressed
+	compressedFrameFlag = 1 << 31
+)
+
+// readRequest reads a single request from the connection, either newline delimited or length prefixed.
+// The idle timeout applies while waiting for a request to start and the read timeout
+// while reading the rest of it. Requests larger than the max request size are discarded
+// and reported with models.RequestTooLargeError, leaving the connection usable
+func (srv *Server) readRequest(conn net.Conn, r *bufio.Reader, format wireFormat) ([]byte, error) {
+	err := setReadDeadline(conn, srv.idleTimeout)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	err = setReadDeadline(conn, srv.readTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if format.framed() {
+		return srv.readFrame(r, format.compression)
+	}
+	return srv.readLine(r)
+}
+
+func (srv *Server) readLine(r *bufio.Reader) ([]byte, error) {
+	var req []byte
+	tooLarge := false
+	for {
+		line, err := r.ReadSlice('\n')
+		if !tooLarge {
+			req = append(req, line...)
+			if len(bytes.TrimRight(req, "\r\n")) > srv.maxRequestSize {
+				tooLarge = true
+				req = nil
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		// a last request without trailing newline is still a request
+		if err != nil && (err != io.EOF || (len(req) == 0 && !tooLarge)) {
+			return nil, err
+		}
+		break
+	}
+	if tooLarge {
+		return nil, models.RequestTooLargeError{MaxSize: srv.maxRequestSize}
+	}
+	req = bytes.TrimSuffix(req, []byte("\n"))
+	return bytes.TrimSuffix(req, []byte("\r")), nil
+}
+
+// readFrame reads a length prefixed request and decompresses it when its compressed flag is set.
+// Decompressed requests are limited to the max request size as well
+func (srv *Server) readFrame(r *bufio.Reader, algorithm string) ([]byte, error) {
+	header := make([]byte, frameHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	compressed := length&compressedFrameFlag != 0
+	size := int64(length &^ compressedFrameFlag)
+	if size > int64(srv.maxRequestSize) {
+		_, err := io.CopyN(ioutil.Discard, r, size)
+		if err != nil {
+			return nil, err
+		}
+		return nil, models.RequestTooLargeError{MaxSize: srv.maxRequestSize}
+	}
+	req := make([]byte, size)
+	_, err = io.ReadFull(r, req)
+	if err != nil {
+		return nil, err
+	}
+	if !compressed {
+		return req, nil
+	}
+	if algorithm == compression.None {
+		return nil, models.InvalidEncodingError{Encoding: "compressed request"}
+	}
+	req, err = compression.Decompress(algorithm, req, srv.maxRequestSize)
+	if err == compression.ErrTooLarge {
+		return nil, models.RequestTooLargeError{MaxSize: srv.maxRequestSize}
+	}
+	if err != nil {
+		return nil, models.InvalidEncodingError{Encoding: algorithm}
+	}
+	return req, nil
+}
+
+// frame prefixes the message with its length and the compressed flag
+func frame(msg []byte, compressed bool) []byte {
+	res := make([]byte, frameHeaderSize+len(msg))
+	length := uint32(len(msg))
+	if compressed {
+		length |= compressedFrameFlag
+	}
+	binary.BigEndian.PutUint32(res, length)
+	copy(res[frameHeaderSize:], msg)
+	return res
+}
+
+func setReadDeadline(conn net.Conn, timeout time.Duration) error {
+	if timeout <= 0 {
+		return conn.SetReadDeadline(time.Time{})
+	}
+	return conn.SetReadDeadline(time.Now().Add(timeout))
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/compression"
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+var msgPackFormat = wireFormat{encoding: "msgpack"}
+
+type requestSuite struct {
+	testutils.Suite
+	srv    *Server
+	client net.Conn
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (s *requestSuite) SetupSuite() {
+	logging.Logger = testutils.Logger(s.T(), nil)
+}
+
+func (s *requestSuite) SetupTest() {
+	s.srv = &Server{
+		idleTimeout:    time.Second,
+		readTimeout:    time.Second,
+		maxRequestSize: 10,
+	}
+	s.client, s.conn = net.Pipe()
+	s.reader = bufio.NewReaderSize(s.conn, 16)
+}
+
+func (s *requestSuite) TearDownTest() {
+	_ = s.client.Close()
+	_ = s.conn.Close()
+}
+
+func (s *requestSuite) Test_readRequest_Success() {
+	sent := s.send("request\r\nsecond\n", false)
+
+	req, err := s.srv.readRequest(s.conn, s.reader, wireFormat{})
+
+	s.Require().NoError(err)
+	s.Equal("request", string(req))
+	req, err = s.srv.readRequest(s.conn, s.reader, wireFormat{})
+	s.Require().NoError(err)
+	s.Equal("second", string(req))
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_LastRequestWithoutNewline() {
+	sent := s.send("last", true)
+
+	req, err := s.srv.readRequest(s.conn, s.reader, wireFormat{})
+
+	s.Require().NoError(err)
+	s.Equal("last", string(req))
+	_, err = s.srv.readRequest(s.conn, s.reader, wireFormat{})
+	s.Equal(io.ErrClosedPipe, err)
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_TooLargeError() {
+	sent := s.send("this request is way larger than the buffer\nsmall\n", false)
+
+	req, err := s.srv.readRequest(s.conn, s.reader, wireFormat{})
+
+	s.Equal(models.RequestTooLargeError{MaxSize: 10}, err)
+	s.Nil(req)
+	req, err = s.srv.readRequest(s.conn, s.reader, wireFormat{})
+	s.Require().NoError(err)
+	s.Equal("small", string(req))
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_MaxSize() {
+	sent := s.send("0123456789\n", false)
+
+	req, err := s.srv.readRequest(s.conn, s.reader, wireFormat{})
+
+	s.Require().NoError(err)
+	s.Equal("0123456789", string(req))
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_IdleTimeout() {
+	s.srv.idleTimeout = 50 * time.Millisecond
+
+	_, err := s.srv.readRequest(s.conn, s.reader, wireFormat{})
+
+	s.True(isTimeout(err))
+}
+
+func (s *requestSuite) Test_readRequest_ReadTimeout() {
+	s.srv.readTimeout = 50 * time.Millisecond
+	sent := s.send("incomplete", false)
+
+	_, err := s.srv.readRequest(s.conn, s.reader, wireFormat{})
+
+	s.True(isTimeout(err))
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_Framed() {
+	sent := s.send(string(frame([]byte("request"), false))+string(frame([]byte("second"), false)), false)
+
+	req, err := s.srv.readRequest(s.conn, s.reader, msgPackFormat)
+
+	s.Require().NoError(err)
+	s.Equal("request", string(req))
+	req, err = s.srv.readRequest(s.conn, s.reader, msgPackFormat)
+	s.Require().NoError(err)
+	s.Equal("second", string(req))
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_FramedTooLargeError() {
+	sent := s.send(string(frame([]byte("this request is too large"), false))+string(frame([]byte("small"), false)), false)
+
+	req, err := s.srv.readRequest(s.conn, s.reader, msgPackFormat)
+
+	s.Equal(models.RequestTooLargeError{MaxSize: 10}, err)
+	s.Nil(req)
+	req, err = s.srv.readRequest(s.conn, s.reader, msgPackFormat)
+	s.Require().NoError(err)
+	s.Equal("small", string(req))
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_FramedIncompleteError() {
+	sent := s.send("\x00\x00\x00\x05abc", true)
+
+	_, err := s.srv.readRequest(s.conn, s.reader, msgPackFormat)
+
+	s.Equal(io.ErrUnexpectedEOF, err)
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_FramedCompressed() {
+	format := wireFormat{encoding: "json", compression: "snappy"}
+	compressed, err := compression.Compress("snappy", []byte("request"))
+	s.Require().NoError(err)
+	sent := s.send(string(frame(compressed, true))+string(frame([]byte("second"), false)), false)
+
+	req, err := s.srv.readRequest(s.conn, s.reader, format)
+
+	s.Require().NoError(err)
+	s.Equal("request", string(req))
+	req, err = s.srv.readRequest(s.conn, s.reader, format)
+	s.Require().NoError(err)
+	s.Equal("second", string(req))
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_FramedCompressedTooLargeError() {
+	format := wireFormat{encoding: "json", compression: "snappy"}
+	compressed, err := compression.Compress("snappy", []byte("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"))
+	s.Require().NoError(err)
+	s.Require().True(len(compressed) <= 10)
+	sent := s.send(string(frame(compressed, true)), false)
+
+	req, err := s.srv.readRequest(s.conn, s.reader, format)
+
+	s.Equal(models.RequestTooLargeError{MaxSize: 10}, err)
+	s.Nil(req)
+	s.NoError(<-sent)
+}
+
+func (s *requestSuite) Test_readRequest_FramedCompressedError() {
+	sent := s.send(string(frame([]byte("corrupt"), true))+string(frame([]byte("small"), true)), false)
+
+	_, err := s.srv.readRequest(s.conn, s.reader, wireFormat{compression: "zstd"})
+	s.Equal(models.InvalidEncodingError{Encoding: "zstd"}, err)
+	_, err = s.srv.readRequest(s.conn, s.reader, msgPackFormat)
+	s.Equal(models.InvalidEncodingError{Encoding: "compressed request"}, err)
+	s.NoError(<-sent)
+}
+
+// send writes the data on the client end of the pipe from another goroutine, the pipe writes blocking
+// until read. The write result is sent back for the test goroutine to assert on, the client end being
+// closed afterwards when closing is set
+func (s *requestSuite) send(data string, closing bool) <-chan error {
+	sent := make(chan error, 1)
+	go func() {
+		_, err := s.client.Write([]byte(data))
+		if err == nil && closing {
+			err = s.client.Close()
+		}
+		sent <- err
+	}()
+	return sent
+}
+
+func Test_RequestSuite(t *testing.T) {
+	suite.Run(t, new(requestSuite))
+}
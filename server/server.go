@@ -3,36 +3,81 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/transport"
 )
 
+const (
+	shutdownOperation = "shutdown"
+	connectOperation  = "connect"
+	decodeOperation   = "decode_request"
+	helloOperation    = "hello"
+)
+
+// defaultMaxRequestSize matches the bufio.Scanner token size the server used to be limited to
+const defaultMaxRequestSize = bufio.MaxScanTokenSize
+
+// ErrShutdownTimeout is returned when in-flight requests or connection handlers did not finish within the shutdown timeout
+var ErrShutdownTimeout = errors.New("timed out waiting for in-flight requests to finish")
+
 type router interface {
 	Switch(io.Writer, io.Reader) (bool, error)
 }
 
 // Settings represents the Event Bus server settings
 type Settings struct {
-	Addr     string
-	Router   router
-	DB       io.Closer
-	Deadline time.Duration
+	Addr                 string
+	Router               router
+	DB                   io.Closer
+	Deadline             time.Duration
+	ShutdownTimeout      time.Duration
+	IdleTimeout          time.Duration
+	ReadTimeout          time.Duration
+	WriteTimeout         time.Duration
+	MaxRequestSize       int
+	MaxPipelined         int
+	CompressionThreshold int
+	Limits               Limits
+	// Connections lets the connections be managed from outside the server, a registry of its own is used when nil
+	Connections *Connections
 }
 
 // Server represents the Event Bus TCP server
 type Server struct {
-	listener    net.Listener
-	quit        chan struct{}
-	exited      chan struct{}
-	connections connections
-	router      router
-	db          io.Closer
-	deadline    time.Duration
+	listener        net.Listener
+	quit            chan struct{}
+	exited          chan struct{}
+	connections     *Connections
+	router          router
+	db              io.Closer
+	deadline        time.Duration
+	shutdownTimeout time.Duration
+	idleTimeout     time.Duration
+	readTimeout     time.Duration
+	maxRequestSize  int
+	maxPipelined    int
+	maxConnections  int
+	stopOnce        sync.Once
+	stopErr         error
+	mu              sync.Mutex
+	stopping        bool
+	serveErr        error
+	inFlight        sync.WaitGroup
+	handlers        sync.WaitGroup
+	// running counts the connection handlers, the ones still running past the shutdown timeout are abandoned
+	running int32
 }
 
 // ListenAndServe spins up the Event Bus TCP server
@@ -41,30 +86,111 @@ func ListenAndServe(settings Settings) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	maxRequestSize := settings.MaxRequestSize
+	if maxRequestSize <= 0 {
+		maxRequestSize = defaultMaxRequestSize
+	}
+	maxPipelined := settings.MaxPipelined
+	if maxPipelined <= 0 {
+		maxPipelined = 1
+	}
+	conns := settings.Connections
+	if conns == nil {
+		conns = NewConnections()
+	}
+	conns.configure(settings.WriteTimeout, settings.CompressionThreshold, settings.Limits.MaxConnections, newClientLimiter(settings.Limits))
 	srv := &Server{
-		listener:    li,
-		quit:        make(chan struct{}),
-		exited:      make(chan struct{}),
-		connections: connections{connMap: map[int]*connection{}},
-		router:      settings.Router,
-		db:          settings.DB,
-		deadline:    settings.Deadline,
+		listener:        li,
+		quit:            make(chan struct{}),
+		exited:          make(chan struct{}),
+		connections:     conns,
+		router:          settings.Router,
+		db:              settings.DB,
+		deadline:        settings.Deadline,
+		shutdownTimeout: settings.ShutdownTimeout,
+		idleTimeout:     settings.IdleTimeout,
+		readTimeout:     settings.ReadTimeout,
+		maxRequestSize:  maxRequestSize,
+		maxPipelined:    maxPipelined,
+		maxConnections:  settings.Limits.MaxConnections,
 	}
 	go srv.serve()
 	return srv, nil
 }
 
-// Stop is responsible for cleanup process before application server shutdown
+// Stop is responsible for cleanup process before application server shutdown.
+// It stops accepting connections, notifies the connected clients, drains in-flight requests,
+// closes all connections and finally closes the database, all within the shutdown timeout.
+// The database is not closed when connection handlers are still running past the timeout,
+// ErrShutdownTimeout being returned instead
 func (srv *Server) Stop() error {
+	srv.stopOnce.Do(func() {
+		srv.stopErr = srv.stop()
+	})
+	return srv.stopErr
+}
+
+func (srv *Server) stop() error {
 	logger := logging.Logger
+	srv.mu.Lock()
+	srv.stopping = true
+	srv.mu.Unlock()
+
 	close(srv.quit)
+	<-srv.exited
+
+	logger.Info("notifying connected clients about shutdown")
+	for _, c := range srv.connections.all() {
+		transport.SendJSON(c, shutdownOperation, nil)
+	}
+
+	// a single deadline for the whole drain, so that shutdown never takes longer than the shutdown timeout.
+	// Zero shutdown timeout means waiting for as long as it takes
+	ctx := context.Background()
+	if srv.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, srv.shutdownTimeout)
+		defer cancel()
+	}
+
+	logger.Info("waiting for in-flight requests to finish")
+	drainErr := waitFor(ctx, &srv.inFlight)
+	if drainErr != nil {
+		logger.Error("could not drain in-flight requests", zap.Error(drainErr))
+	}
+	srv.connections.closeAll()
+	err := waitFor(ctx, &srv.handlers)
+	if err != nil {
+		// the abandoned handlers may still be writing, so the database is left open for the process exit to release
+		logger.Error(
+			"abandoning connection handlers still running after the shutdown timeout, leaving the database open",
+			zap.Int32("handlers", atomic.LoadInt32(&srv.running)),
+		)
+		return err
+	}
+
 	logger.Info("stopping the database")
-	err := srv.db.Close()
+	err = srv.db.Close()
 	if err != nil {
 		return err
 	}
-	<-srv.exited
-	return nil
+	return drainErr
+}
+
+// waitFor waits for the wait group until the shutdown deadline
+func waitFor(ctx context.Context, wg *sync.WaitGroup) error {
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ErrShutdownTimeout
+	}
 }
 
 func (srv *Server) serve() {
@@ -82,13 +208,13 @@ func (srv *Server) serve() {
 			if err != nil {
 				logger.Error("could not close listener", zap.Error(err))
 			}
-			srv.connections.closeAll()
 			close(srv.exited)
 			return
 		default:
 			tcpListener := srv.listener.(*net.TCPListener)
 			err := tcpListener.SetDeadline(time.Now().Add(srv.deadline))
 			if srv.closedConnection(err) {
+				srv.serveFailed(err)
 				return
 			}
 			if err != nil {
@@ -100,22 +226,72 @@ func (srv *Server) serve() {
 				continue
 			}
 			if srv.closedConnection(err) {
+				srv.serveFailed(err)
 				return
 			}
 			if err != nil {
 				logger.Error("failed to accept connection", zap.Error(err))
+				srv.serveFailed(err)
 				return
 			}
 
+			if srv.maxConnections > 0 && srv.connections.count() >= srv.maxConnections {
+				srv.reject(conn)
+				continue
+			}
+
 			c := srv.connections.add(conn)
+			srv.handlers.Add(1)
+			atomic.AddInt32(&srv.running, 1)
 			go func() {
-				srv.handle(conn)
+				defer srv.handlers.Done()
+				defer atomic.AddInt32(&srv.running, -1)
+				srv.handle(c)
 				srv.connections.close(c.id)
 			}()
 		}
 	}
 }
 
+// serveFailed records the error the server stopped accepting connections with
+func (srv *Server) serveFailed(err error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.serveErr = err
+}
+
+// Check reports the open connections. The server is down once shutting down
+// or when it stopped accepting connections
+func (srv *Server) Check(context.Context) (map[string]interface{}, error) {
+	srv.mu.Lock()
+	stopping, serveErr := srv.stopping, srv.serveErr
+	srv.mu.Unlock()
+
+	details := map[string]interface{}{"connections": srv.connections.count()}
+	if srv.maxConnections > 0 {
+		details["max_connections"] = srv.maxConnections
+	}
+	if stopping {
+		return details, errors.New("server is shutting down")
+	}
+	if serveErr != nil {
+		return details, fmt.Errorf("server stopped accepting connections: %s", serveErr)
+	}
+	return details, nil
+}
+
+// reject refuses a connection over the max connections limit
+func (srv *Server) reject(conn net.Conn) {
+	logger := logging.Logger
+	logger.Info("rejecting connection, too many connections", zap.String("addr", conn.RemoteAddr().String()))
+	c := &connection{conn: conn, writeTimeout: srv.connections.writeTimeout}
+	transport.SendError(c, connectOperation, models.TooManyConnectionsError{})
+	err := conn.Close()
+	if err != nil {
+		logger.Error("could not close rejected connection", zap.Error(err))
+	}
+}
+
 func (srv *Server) closedConnection(err error) bool {
 	if oppErr, ok := err.(*net.OpError); ok && oppErr.Unwrap().Error() == "use of closed network connection" {
 		return true
@@ -123,21 +299,87 @@ func (srv *Server) closedConnection(err error) bool {
 	return false
 }
 
-func (srv *Server) handle(conn net.Conn) {
-	scanner := bufio.NewScanner(conn)
+// begin registers a new in-flight request unless the server is shutting down
+func (srv *Server) begin() bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.stopping {
+		return false
+	}
+	srv.inFlight.Add(1)
+	return true
+}
+
+func (srv *Server) handle(c *connection) {
+	reader := bufio.NewReader(c.conn)
 	logger := logging.Logger
-	for scanner.Scan() {
-		if len(scanner.Bytes()) == 0 {
+	p := newPipeline(srv.maxPipelined)
+	defer p.wait()
+	for {
+		format := c.getFormat()
+		req, err := srv.readRequest(c.conn, reader, format)
+		if tooLargeErr, ok := err.(models.RequestTooLargeError); ok {
+			logger.Error("request too large", zap.Int("client_id", c.id), zap.Error(err))
+			transport.SendError(c, decodeOperation, tooLargeErr)
+			continue
+		}
+		if encodingErr, ok := err.(models.InvalidEncodingError); ok {
+			logger.Error("could not decompress request", zap.Int("client_id", c.id), zap.Error(err))
+			transport.SendError(c, decodeOperation, encodingErr)
+			continue
+		}
+		if isTimeout(err) {
+			logger.Info("closing idle connection", zap.Int("client_id", c.id))
+			break
+		}
+		if err != nil {
+			if err != io.EOF && !srv.closedConnection(err) {
+				logger.Error("could not read request", zap.Int("client_id", c.id), zap.Error(err))
+			}
+			break
+		}
+		if len(req) == 0 {
 			logger.Error("empty request line")
 			continue
 		}
+		if !srv.begin() {
+			logger.Info("dropping request received during shutdown", zap.Int("client_id", c.id))
+			break
+		}
 
-		exited, err := srv.router.Switch(conn, bytes.NewReader(scanner.Bytes()))
-		if err != nil {
-			logger.Error("switch error", zap.Error(err))
+		// the request is decoded with the encoding it was read with, even if it switches the connection encoding
+		codec := format.codec()
+		rc := requestConn{connection: c, size: len(req), codec: codec}
+		if srv.maxPipelined > 1 && pipelined(codec, req) {
+			p.run(func() {
+				if srv.process(rc, req) {
+					srv.stopReading(c)
+				}
+			})
+			continue
 		}
-		if exited {
+		// requests without an ID wait for the pipelined ones to keep the responses in order
+		p.wait()
+		if srv.process(rc, req) {
 			break
 		}
 	}
 }
+
+// process switches a single request and reports whether the client asked to exit
+func (srv *Server) process(rc requestConn, req []byte) bool {
+	defer srv.inFlight.Done()
+	rc.served()
+	exited, err := srv.router.Switch(rc, bytes.NewReader(req))
+	if err != nil {
+		logging.Logger.Error("switch error", zap.Error(err))
+	}
+	return exited
+}
+
+func (srv *Server) stopReading(c *connection) {
+	err := stopReading(c.conn)
+	if err != nil {
+		logging.Logger.Error("could not stop reading from connection", zap.Int("client_id", c.id), zap.Error(err))
+	}
+}
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +16,7 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
 	"github.com/go-web-dev/event-bus/testutils"
 )
 
@@ -25,6 +30,7 @@ type serverSuite struct {
 	router      *routerMock
 	settings    Settings
 	server      *Server
+	conns       []net.Conn
 	loggerEntry zapcore.Entry
 }
 
@@ -53,11 +59,15 @@ func (s *serverSuite) TearDownTest() {
 	s.db.AssertExpectations(s.T())
 	s.router.AssertExpectations(s.T())
 	s.Require().NoError(s.server.listener.Close())
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.conns = nil
 }
 
 func (s *serverSuite) Test_Switch_OpenConnections() {
 	s.router.
-		On("Switch", mock.AnythingOfType("*net.TCPConn"), mock.AnythingOfType("*bytes.Reader")).
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
 		Return(false, nil).
 		Twice()
 
@@ -72,7 +82,7 @@ func (s *serverSuite) Test_Switch_OpenConnections() {
 
 func (s *serverSuite) Test_Switch_ClosedConnections() {
 	s.router.
-		On("Switch", mock.AnythingOfType("*net.TCPConn"), mock.AnythingOfType("*bytes.Reader")).
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
 		Return(true, nil).
 		Twice()
 
@@ -127,9 +137,221 @@ func (s *serverSuite) Test_Stop_Error() {
 	s.Equal(errTest, srv.Stop())
 }
 
+func (s *serverSuite) Test_Stop_NotifiesClients() {
+	s.db.
+		On("Close").
+		Return(nil).
+		Once()
+	srv, err := ListenAndServe(Settings{
+		Addr:     "localhost:9091",
+		DB:       s.db,
+		Router:   s.router,
+		Deadline: 50 * time.Millisecond,
+	})
+	s.Require().NoError(err)
+	conn := s.newConn("localhost:9091")
+	s.waitForConnections(srv, 1)
+
+	s.Require().NoError(srv.Stop())
+
+	s.JSONEq(`{"operation": "shutdown", "status": true}`, s.readLine(conn))
+	s.Len(srv.connections.connMap, 0)
+}
+
+func (s *serverSuite) Test_Stop_DrainsInFlightRequests() {
+	switched := make(chan struct{})
+	s.router.
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
+		Run(func(args mock.Arguments) {
+			close(switched)
+			time.Sleep(200 * time.Millisecond)
+			_, err := args.Get(0).(requestConn).Write([]byte("{\"operation\": \"slow_op\"}\n"))
+			s.Require().NoError(err)
+		}).
+		Return(false, nil).
+		Once()
+	s.db.
+		On("Close").
+		Return(nil).
+		Once()
+	srv, err := ListenAndServe(Settings{
+		Addr:            "localhost:9092",
+		DB:              s.db,
+		Router:          s.router,
+		Deadline:        50 * time.Millisecond,
+		ShutdownTimeout: time.Second,
+	})
+	s.Require().NoError(err)
+	conn := s.newConn("localhost:9092")
+	s.connWrite(conn, `{"operation": "slow_op"}`)
+	<-switched
+
+	s.Require().NoError(srv.Stop())
+
+	reader := bufio.NewReader(conn)
+	s.JSONEq(`{"operation": "shutdown", "status": true}`, s.readLineFrom(reader))
+	s.JSONEq(`{"operation": "slow_op"}`, s.readLineFrom(reader))
+}
+
+func (s *serverSuite) Test_Stop_ShutdownTimeoutError() {
+	switched := make(chan struct{})
+	s.router.
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
+		Run(func(mock.Arguments) {
+			close(switched)
+			time.Sleep(300 * time.Millisecond)
+		}).
+		Return(false, nil).
+		Once()
+	db := new(dbMock)
+	srv, err := ListenAndServe(Settings{
+		Addr:            "localhost:9093",
+		DB:              db,
+		Router:          s.router,
+		Deadline:        50 * time.Millisecond,
+		ShutdownTimeout: 50 * time.Millisecond,
+	})
+	s.Require().NoError(err)
+	conn := s.newConn("localhost:9093")
+	s.connWrite(conn, `{"operation": "slow_op"}`)
+	<-switched
+	start := time.Now()
+
+	err = srv.Stop()
+
+	s.Equal(ErrShutdownTimeout, err)
+	s.Equal(ErrShutdownTimeout, srv.Stop())
+	// the handler stuck in the slow request is abandoned rather than waited for
+	s.Less(int64(time.Since(start)), int64(250*time.Millisecond))
+	s.Equal(int32(1), atomic.LoadInt32(&srv.running))
+	// the database is left open while the abandoned handler may still be using it
+	db.AssertNotCalled(s.T(), "Close")
+	srv.handlers.Wait()
+}
+
+func (s *serverSuite) Test_handle_DropsRequestsWhileStopping() {
+	conn := s.newConn(s.settings.Addr)
+	s.waitForConnections(s.server, 1)
+	s.setStopping(true)
+	defer s.setStopping(false)
+
+	s.connWrite(conn, `{"operation": "conn1"}`)
+
+	s.waitForConnections(s.server, 0)
+}
+
+func (s *serverSuite) Test_handle_RequestTooLarge() {
+	srv, err := ListenAndServe(Settings{
+		Addr:           "localhost:9094",
+		DB:             s.db,
+		Router:         s.router,
+		Deadline:       50 * time.Millisecond,
+		MaxRequestSize: 16,
+	})
+	s.Require().NoError(err)
+	defer srv.listener.Close()
+	conn := s.newConn("localhost:9094")
+
+	s.connWrite(conn, `{"operation": "way_too_large_operation"}`)
+
+	s.JSONEq(
+		`{"operation": "decode_request", "status": false, "reason": "request exceeds the maximum size of 16 bytes"}`,
+		s.readLine(conn),
+	)
+	s.waitForConnections(srv, 1)
+	s.Equal("request too large", s.loggerEntry.Message)
+}
+
+func (s *serverSuite) Test_handle_IdleTimeout() {
+	srv, err := ListenAndServe(Settings{
+		Addr:        "localhost:9095",
+		DB:          s.db,
+		Router:      s.router,
+		Deadline:    50 * time.Millisecond,
+		IdleTimeout: 50 * time.Millisecond,
+	})
+	s.Require().NoError(err)
+	defer srv.listener.Close()
+	conn := s.newConn("localhost:9095")
+	s.waitForConnections(srv, 1)
+
+	s.waitForConnections(srv, 0)
+	_, err = bufio.NewReader(conn).ReadByte()
+	s.Equal(io.EOF, err)
+}
+
+func (s *serverSuite) Test_serve_TooManyConnections() {
+	srv, err := ListenAndServe(Settings{
+		Addr:     "localhost:9096",
+		DB:       s.db,
+		Router:   s.router,
+		Deadline: 50 * time.Millisecond,
+		Limits:   Limits{MaxConnections: 1},
+	})
+	s.Require().NoError(err)
+	defer srv.listener.Close()
+	_ = s.newConn("localhost:9096")
+	s.waitForConnections(srv, 1)
+
+	conn := s.newConn("localhost:9096")
+
+	s.JSONEq(
+		`{"operation": "connect", "status": false, "reason": "too many connections"}`,
+		s.readLine(conn),
+	)
+	s.Len(srv.connections.connMap, 1)
+}
+
+func (s *serverSuite) Test_serve_TooManyConnections_Sessions() {
+	conns := NewConnections()
+	srv, err := ListenAndServe(Settings{
+		Addr:        "localhost:9089",
+		DB:          s.db,
+		Router:      s.router,
+		Deadline:    50 * time.Millisecond,
+		Limits:      Limits{MaxConnections: 1},
+		Connections: conns,
+	})
+	s.Require().NoError(err)
+	defer srv.listener.Close()
+	_, err = conns.Connect(models.WebSocketTransport, "127.0.0.1:54321", func() {})
+	s.Require().NoError(err)
+
+	conn := s.newConn("localhost:9089")
+
+	s.JSONEq(
+		`{"operation": "connect", "status": false, "reason": "too many connections"}`,
+		s.readLine(conn),
+	)
+	s.Empty(srv.connections.connMap)
+}
+
+func (s *serverSuite) Test_serve_Disconnect() {
+	conns := NewConnections()
+	srv, err := ListenAndServe(Settings{
+		Addr:        "localhost:9097",
+		DB:          s.db,
+		Router:      s.router,
+		Deadline:    50 * time.Millisecond,
+		Connections: conns,
+	})
+	s.Require().NoError(err)
+	defer srv.listener.Close()
+	conn := s.newConn("localhost:9097")
+	s.waitForConnections(srv, 1)
+	reader := bufio.NewReader(conn)
+
+	s.Require().NoError(conns.Disconnect(conns.List()[0].ID))
+
+	s.JSONEq(`{"operation": "disconnect", "status": true}`, s.readLineFrom(reader))
+	_, err = reader.ReadByte()
+	s.Equal(io.EOF, err)
+	s.waitForConnections(srv, 0)
+}
+
 func (s *serverSuite) Test_Switch_Error() {
 	s.router.
-		On("Switch", mock.AnythingOfType("*net.TCPConn"), mock.AnythingOfType("*bytes.Reader")).
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
 		Return(false, errTest).
 		Once()
 
@@ -155,6 +377,46 @@ func (s *serverSuite) Test_serve_ConnectionTimeoutError() {
 	s.Len(srv.connections.connMap, 1)
 }
 
+func (s *serverSuite) Test_Check_Success() {
+	s.router.
+		On("Switch", mock.AnythingOfType("server.requestConn"), mock.AnythingOfType("*bytes.Reader")).
+		Return(false, nil).
+		Once()
+	conn := s.newConn(s.settings.Addr)
+	s.connWrite(conn, `{"operation": "conn"}`)
+	s.waitForConnections(s.server, 1)
+
+	details, err := s.server.Check(context.Background())
+
+	s.Require().NoError(err)
+	s.Equal(map[string]interface{}{"connections": 1}, details)
+}
+
+func (s *serverSuite) Test_Check_StoppingError() {
+	s.setStopping(true)
+	defer s.setStopping(false)
+
+	details, err := s.server.Check(context.Background())
+
+	s.EqualError(err, "server is shutting down")
+	s.Equal(map[string]interface{}{"connections": 0}, details)
+}
+
+func (s *serverSuite) Test_Check_ServeError() {
+	settings := s.settings
+	settings.Addr = "localhost:9001"
+	srv, err := ListenAndServe(settings)
+	s.Require().NoError(err)
+	s.Require().NoError(srv.listener.Close())
+
+	s.Eventually(func() bool {
+		_, err := srv.Check(context.Background())
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	_, err = srv.Check(context.Background())
+	s.Contains(err.Error(), "server stopped accepting connections")
+}
+
 func (s *serverSuite) Test_ListenAndServeError() {
 	srv, err := ListenAndServe(Settings{
 		Addr:   "9000",
@@ -169,6 +431,8 @@ func (s *serverSuite) Test_ListenAndServeError() {
 func (s *serverSuite) newConn(addr string) net.Conn {
 	conn, err := net.Dial("tcp", addr)
 	s.Require().NoError(err)
+	// keeping a reference prevents the connection from being closed by the garbage collector mid test
+	s.conns = append(s.conns, conn)
 	return conn
 }
 
@@ -177,6 +441,22 @@ func (s *serverSuite) connWrite(conn net.Conn, message string) {
 	s.Require().NoError(err)
 }
 
+func (s *serverSuite) setStopping(stopping bool) {
+	s.server.mu.Lock()
+	defer s.server.mu.Unlock()
+	s.server.stopping = stopping
+}
+
+func (s *serverSuite) readLine(conn net.Conn) string {
+	return s.readLineFrom(bufio.NewReader(conn))
+}
+
+func (s *serverSuite) readLineFrom(reader *bufio.Reader) string {
+	bs, err := reader.ReadBytes('\n')
+	s.Require().NoError(err)
+	return string(bs)
+}
+
 func (s *serverSuite) waitForConnections(srv *Server, connCount int) {
 	timeout := time.After(1 * time.Second)
 	tick := time.Tick(10 * time.Millisecond)
@@ -186,7 +466,7 @@ func (s *serverSuite) waitForConnections(srv *Server, connCount int) {
 			s.Fail(fmt.Sprintf("waiting for %d connections timed out", connCount))
 			return
 		case <-tick:
-			if len(srv.connections.connMap) == connCount {
+			if srv.connections.count() == connCount {
 				return
 			}
 		}
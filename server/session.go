@@ -0,0 +1,142 @@
+package server
+
+import (
+	"sort"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+// session represents a connection served by another transport than the TCP server,
+// bound to the client authenticated on it the same way the TCP connections are
+type session struct {
+	id            int
+	client        string
+	transport     string
+	remoteAddr    string
+	connectedAt   time.Time
+	requests      int64
+	subscriptions int
+	// disconnect closes the connection on behalf of an admin client, only the listed sessions have one
+	disconnect func()
+}
+
+func (s *session) info() models.Connection {
+	return models.Connection{
+		ID:            s.id,
+		Transport:     s.transport,
+		RemoteAddr:    s.remoteAddr,
+		Client:        s.client,
+		ConnectedAt:   s.connectedAt,
+		Requests:      s.requests,
+		Subscriptions: s.subscriptions,
+	}
+}
+
+// Open registers a connection served by another transport than the TCP server, such as an HTTP request,
+// so that it shares the connections and client limits with the TCP connections. It gives back the ID of the connection,
+// or TooManyConnectionsError when the server reached its connections limit
+func (c *Connections) Open() (int, error) {
+	return c.open(&session{})
+}
+
+// Connect registers a lasting connection served by another transport than the TCP server, such as
+// a WebSocket connection, the same way Open does. Admin clients list the connection along with the TCP ones
+// and disconnect it through the disconnect func
+func (c *Connections) Connect(transport, remoteAddr string, disconnect func()) (int, error) {
+	return c.open(&session{
+		transport:   transport,
+		remoteAddr:  remoteAddr,
+		connectedAt: time.Now().UTC(),
+		disconnect:  disconnect,
+	})
+}
+
+func (c *Connections) open(s *session) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxConnections > 0 && len(c.connMap)+len(c.sessions) >= c.maxConnections {
+		return 0, models.TooManyConnectionsError{}
+	}
+	s.id = c.index
+	c.sessions[s.id] = s
+	c.index++
+	return s.id, nil
+}
+
+// Authorize binds the connection opened by Open to the client authenticated on it
+// and takes a request of the given size out of the client's limits
+func (c *Connections) Authorize(connID int, client string, size int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.sessions[connID]
+	if !ok {
+		return models.ConnectionNotFoundError{ID: connID}
+	}
+	s.requests++
+	if c.limiter == nil {
+		s.client = client
+		return nil
+	}
+	err := c.limiter.rebind(connID, s.client, client)
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return c.limiter.allow(client, size)
+}
+
+// AddSubscriptions adds delta to the active subscriptions of the connection opened by Connect,
+// a negative delta once they end
+func (c *Connections) AddSubscriptions(connID int, delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.sessions[connID]; ok {
+		s.subscriptions += delta
+	}
+}
+
+// Release unregisters the connection opened by Open, giving back its slot in the client limits
+func (c *Connections) Release(connID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.sessions[connID]
+	if !ok {
+		return
+	}
+	if c.limiter != nil && s.client != "" {
+		c.limiter.unregister(s.client, connID)
+	}
+	delete(c.sessions, connID)
+}
+
+// listSessions lists the sessions opened by Connect
+func (c *Connections) listSessions() []models.Connection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	res := make([]models.Connection, 0, len(c.sessions))
+	for _, s := range c.sessions {
+		if s.disconnect != nil {
+			res = append(res, s.info())
+		}
+	}
+	return res
+}
+
+// disconnectSession closes a session opened by Connect, which gets released once its transport is done with it
+func (c *Connections) disconnectSession(connID int) error {
+	c.mu.RLock()
+	s, ok := c.sessions[connID]
+	c.mu.RUnlock()
+	if !ok || s.disconnect == nil {
+		return models.ConnectionNotFoundError{ID: connID}
+	}
+	s.disconnect()
+	return nil
+}
+
+func sortConnections(conns []models.Connection) {
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].ID < conns[j].ID
+	})
+}
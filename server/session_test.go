@@ -0,0 +1,127 @@
+package server
+
+import (
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *connectionSuite) Test_Open_SharesIndex() {
+	c := s.conns.add(s.newConn())
+
+	id, err := s.conns.Open()
+
+	s.Require().NoError(err)
+	s.Equal(c.id+1, id)
+	s.Contains(s.conns.sessions, id)
+}
+
+func (s *connectionSuite) Test_Open_TooManyConnectionsError() {
+	s.conns.maxConnections = 2
+	s.conns.add(s.newConn())
+	_, err := s.conns.Open()
+	s.Require().NoError(err)
+
+	_, err = s.conns.Connect(models.WebSocketTransport, "127.0.0.1:54321", func() {})
+
+	s.Equal(models.TooManyConnectionsError{}, err)
+	s.Len(s.conns.sessions, 1)
+}
+
+func (s *connectionSuite) Test_Authorize_Session_NoLimiter() {
+	id, err := s.conns.Open()
+	s.Require().NoError(err)
+
+	err = s.conns.Authorize(id, "client", 10)
+
+	s.Require().NoError(err)
+	s.Equal("client", s.conns.sessions[id].client)
+}
+
+func (s *connectionSuite) Test_Authorize_Session_RateLimitError() {
+	s.conns.limiter = newClientLimiter(Limits{RequestsPerSecond: 1, RequestBurst: 1})
+	id, err := s.conns.Open()
+	s.Require().NoError(err)
+	s.Require().NoError(s.conns.Authorize(id, "client", 0))
+
+	err = s.conns.Authorize(id, "client", 0)
+
+	s.IsType(models.RateLimitError{}, err)
+}
+
+func (s *connectionSuite) Test_Authorize_Session_TooManyConnectionsError() {
+	s.conns.limiter = newClientLimiter(Limits{MaxClientConnections: 1})
+	c := s.conns.add(s.newConn())
+	s.Require().NoError(c.authorize("client", 0))
+	id, err := s.conns.Open()
+	s.Require().NoError(err)
+
+	err = s.conns.Authorize(id, "client", 0)
+
+	s.Equal(models.TooManyConnectionsError{Client: "client"}, err)
+}
+
+func (s *connectionSuite) Test_Authorize_Session_ConnectionNotFoundError() {
+	err := s.conns.Authorize(7, "client", 0)
+
+	s.Equal(models.ConnectionNotFoundError{ID: 7}, err)
+}
+
+func (s *connectionSuite) Test_Release_Session() {
+	limiter := newClientLimiter(Limits{MaxClientConnections: 1})
+	s.conns.limiter = limiter
+	id, err := s.conns.Open()
+	s.Require().NoError(err)
+	s.Require().NoError(s.conns.Authorize(id, "client", 0))
+
+	s.conns.Release(id)
+	s.conns.Release(id)
+
+	s.NotContains(s.conns.sessions, id)
+	s.Len(limiter.clients["client"].connections, 0)
+}
+
+func (s *connectionSuite) Test_List_Sessions() {
+	conn := s.conns.add(s.newConn())
+	_, err := s.conns.Open()
+	s.Require().NoError(err)
+	id, err := s.conns.Connect(models.GRPCTransport, "127.0.0.1:54321", func() {})
+	s.Require().NoError(err)
+	s.Require().NoError(s.conns.Authorize(id, "client", 0))
+	s.conns.AddSubscriptions(id, 1)
+
+	conns := s.conns.List()
+
+	s.Require().Len(conns, 2)
+	s.Equal(conn.id, conns[0].ID)
+	s.Equal(models.Connection{
+		ID:            id,
+		Transport:     models.GRPCTransport,
+		RemoteAddr:    "127.0.0.1:54321",
+		Client:        "client",
+		ConnectedAt:   s.conns.sessions[id].connectedAt,
+		Requests:      1,
+		Subscriptions: 1,
+	}, conns[1])
+}
+
+func (s *connectionSuite) Test_Disconnect_Session() {
+	disconnected := make(chan struct{})
+	id, err := s.conns.Connect(models.WebSocketTransport, "127.0.0.1:54321", func() {
+		close(disconnected)
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.conns.Authorize(id, "client", 0))
+
+	ids := s.conns.DisconnectClient("client")
+
+	s.Equal([]int{id}, ids)
+	s.Require().NotPanics(func() { <-disconnected })
+}
+
+func (s *connectionSuite) Test_Disconnect_Session_NotListedError() {
+	id, err := s.conns.Open()
+	s.Require().NoError(err)
+
+	err = s.conns.Disconnect(id)
+
+	s.Equal(models.ConnectionNotFoundError{ID: id}, err)
+}
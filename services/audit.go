@@ -0,0 +1,94 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+)
+
+const auditPrefix = "audit:"
+
+// audit appends the entry to the audit log, within the transaction of the audited operation
+func (d db) audit(entry models.AuditEntry) transactionFunc {
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = time.Now().UTC()
+	return d.set(entry.Key(), entry.Value(), 0)
+}
+
+// Audit appends an entry for an operation made outside of the Event Bus service, such as a config reload
+func (b *Bus) Audit(ctx context.Context, entry models.AuditEntry) error {
+	err := b.db.txn(ctx, true, b.db.audit(entry))
+	if err != nil {
+		logging.Logger.Error("could not write audit entry", zap.String("action", entry.Action), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// auditLog iterates over the audit log newest first, from until back to since, a zero time leaving the range open.
+// The iteration stops once cb returns false
+func (d db) auditLog(since, until time.Time, cb func(models.AuditEntry) bool) transactionFunc {
+	return func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(auditPrefix)
+		// reverse iterations start from the last key before the seek key, entries created at until are left out
+		start := append([]byte(auditPrefix), 0xff)
+		if !until.IsZero() {
+			start = models.AuditTimeKey(until)
+		}
+		var end []byte
+		if !since.IsZero() {
+			end = models.AuditTimeKey(since)
+		}
+		for it.Seek(start); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			if end != nil && bytes.Compare(item.Key(), end) < 0 {
+				return nil
+			}
+			var entry models.AuditEntry
+			err := item.Value(func(val []byte) error {
+				return decodeValue(val, &entry)
+			})
+			if err != nil {
+				logging.Logger.Error("could not unmarshal value from db", zap.Error(err))
+				return err
+			}
+			if !cb(entry) {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// GetAuditLog gets the audit log entries matching the filter, oldest first.
+// Only the entries within the time range are read, newest first until the limit is reached
+func (b *Bus) GetAuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	entries := make([]models.AuditEntry, 0)
+	txn := b.db.auditLog(filter.Since, filter.Until, func(entry models.AuditEntry) bool {
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+		return filter.Limit <= 0 || len(entries) < filter.Limit
+	})
+	err := b.db.txn(ctx, false, txn)
+	if err != nil {
+		logging.Logger.Error("could not fetch audit log", zap.Error(err))
+		return []models.AuditEntry{}, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
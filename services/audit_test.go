@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *busSuite) Test_Bus_AuditLog_StreamOperations() {
+	streamName := "stream-name"
+	_, err := s.bus.CreateStream(testCtx, testClient, streamName, "", nil, models.Quota{})
+	s.Require().NoError(err)
+	_, err = s.bus.UpdateStream(testCtx, "admin", streamName, nil, models.Quota{MaxEvents: 10})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, streamName, []byte(`{}`)))
+	s.Require().NoError(s.bus.DeleteStream(testCtx, "admin", streamName))
+
+	entries, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{})
+
+	s.Require().NoError(err)
+	s.Require().Len(entries, 3)
+	s.Equal(models.AuditCreateStream, entries[0].Action)
+	s.Equal(testClient, entries[0].Client)
+	s.Equal(models.AuditUpdateStream, entries[1].Action)
+	s.Equal("admin", entries[1].Client)
+	s.Equal(models.AuditDeleteStream, entries[2].Action)
+	s.Equal("admin", entries[2].Client)
+	s.Equal(map[string]string{"events": "1"}, entries[2].Details)
+	for _, entry := range entries {
+		s.NotEmpty(entry.ID)
+		s.Equal(streamName, entry.Stream)
+		s.WithinDuration(time.Now(), entry.CreatedAt, time.Minute)
+	}
+}
+
+func (s *busSuite) Test_Bus_AuditLog_MarkEvent() {
+	streamName := "stream-name"
+	streamID := "stream-id"
+	s.setEvents(models.Event{ID: "evt1-id", StreamID: streamID, Body: []byte("{}")})
+	s.bus.streams[streamName] = models.Stream{ID: streamID}
+
+	s.Require().NoError(s.bus.MarkEvent(testCtx, testClient, "evt1-id", models.EventProcessedStatus))
+	entries, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{Action: models.AuditMarkEvent})
+
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+	s.Equal(testClient, entries[0].Client)
+	s.Equal(streamName, entries[0].Stream)
+	s.Equal("evt1-id", entries[0].EventID)
+	s.Equal(map[string]string{"status": "1"}, entries[0].Details)
+}
+
+func (s *busSuite) Test_Bus_AuditLog_NotWrittenOnError() {
+	s.Require().Error(s.bus.DeleteStream(testCtx, testClient, "stream-name"))
+
+	entries, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{})
+
+	s.Require().NoError(err)
+	s.Empty(entries)
+}
+
+func (s *busSuite) Test_Bus_Audit() {
+	entry := models.AuditEntry{Action: models.AuditConfigReload, Details: map[string]string{"config": "config.yaml"}}
+
+	err := s.bus.Audit(testCtx, entry)
+
+	s.Require().NoError(err)
+	entries, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+	s.Equal(models.AuditConfigReload, entries[0].Action)
+	s.Empty(entries[0].Client)
+	s.Equal(entry.Details, entries[0].Details)
+}
+
+func (s *busSuite) Test_Bus_GetAuditLog_Filter() {
+	for _, name := range []string{"s1", "s2", "s3", "s4"} {
+		_, err := s.bus.CreateStream(testCtx, testClient, name, "", nil, models.Quota{})
+		s.Require().NoError(err)
+	}
+	s.Require().NoError(s.bus.DeleteStream(testCtx, testClient, "s2"))
+
+	created, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{Action: models.AuditCreateStream, Limit: 2})
+	s.Require().NoError(err)
+	stream, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{Stream: "s2"})
+	s.Require().NoError(err)
+	other, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{Client: "other"})
+	s.Require().NoError(err)
+
+	s.Require().Len(created, 2)
+	s.Equal("s3", created[0].Stream)
+	s.Equal("s4", created[1].Stream)
+	s.Require().Len(stream, 2)
+	s.Equal(models.AuditCreateStream, stream[0].Action)
+	s.Equal(models.AuditDeleteStream, stream[1].Action)
+	s.Empty(other)
+}
+
+func (s *busSuite) Test_Bus_GetAuditLog_TimeRange() {
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		entry := models.AuditEntry{
+			ID:        fmt.Sprintf("audit-%d", i),
+			Action:    models.AuditCreateStream,
+			Stream:    fmt.Sprintf("s%d", i),
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+		}
+		s.Require().NoError(s.db.txn(testCtx, true, s.db.set(entry.Key(), entry.Value(), 0)))
+	}
+	// entries out of the time range are never read
+	for _, t := range []time.Time{start.Add(-time.Hour), start.Add(10 * time.Hour)} {
+		key := append(models.AuditTimeKey(t), []byte(":invalid")...)
+		s.Require().NoError(s.db.txn(testCtx, true, s.db.set(key, []byte("{"), 0)))
+	}
+	filter := models.AuditFilter{
+		Since: start,
+		Until: start.Add(4 * time.Hour),
+	}
+
+	entries, err := s.bus.GetAuditLog(testCtx, filter)
+	s.Require().NoError(err)
+	filter.Limit = 2
+	limited, err := s.bus.GetAuditLog(testCtx, filter)
+	s.Require().NoError(err)
+
+	s.Require().Len(entries, 4)
+	for i, entry := range entries {
+		s.Equal(fmt.Sprintf("s%d", i), entry.Stream)
+	}
+	s.Require().Len(limited, 2)
+	s.Equal("s2", limited[0].Stream)
+	s.Equal("s3", limited[1].Stream)
+}
+
+func (s *busSuite) Test_Bus_GetAuditLog_LimitStopsReading() {
+	// the oldest entry cannot be read
+	key := append(models.AuditTimeKey(time.Unix(0, 1)), []byte(":invalid")...)
+	s.Require().NoError(s.db.txn(testCtx, true, s.db.set(key, []byte("{"), 0)))
+	s.Require().NoError(s.bus.Audit(testCtx, models.AuditEntry{Action: models.AuditConfigReload}))
+
+	entries, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{Limit: 1})
+	s.Require().NoError(err)
+	_, err = s.bus.GetAuditLog(testCtx, models.AuditFilter{})
+
+	s.Require().Len(entries, 1)
+	s.Equal(models.AuditConfigReload, entries[0].Action)
+	s.Error(err)
+}
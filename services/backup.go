@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/storage"
+)
+
+// Backup writes a portable copy of the entries stored since the given version, zero meaning all of them,
+// and returns the version to back up since in the next incremental backup.
+// The backup is a consistent snapshot taken while the bus keeps serving requests
+func (b *Bus) Backup(ctx context.Context, w io.Writer, since uint64) (uint64, error) {
+	version, err := b.db.Backup(w, since)
+	if err != nil {
+		logging.Logger.Error("could not back up db", zap.Error(err))
+		return 0, err
+	}
+	version = storage.NextBackupVersion(since, version)
+	logging.Logger.Info("successfully backed up db", zap.Uint64("since", since), zap.Uint64("version", version))
+	return version, nil
+}
+
+// Restore loads a backup into the store and reloads the streams out of it.
+// A full restore drops the stored streams, events and schemas first, keeping the audit log,
+// an incremental one applies the backup on top of them,
+// e.g. a backup made since the version of the previously restored one.
+// The backup is validated before anything is dropped, a backup which cannot be decoded leaving the store untouched.
+// The subscriptions of the streams missing from the restored store are ended
+func (b *Bus) Restore(ctx context.Context, r io.ReadSeeker, incremental bool) error {
+	logger := logging.Logger
+	err := storage.ValidateBackup(r)
+	if err != nil {
+		logger.Error("could not validate backup", zap.Error(err))
+		return models.InvalidBackupError{Reason: err.Error()}
+	}
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		logger.Error("could not rewind backup", zap.Error(err))
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !incremental {
+		err := b.db.DropPrefix([]byte(streamPrefix), []byte(eventPrefix), []byte(schemaPrefix))
+		if err != nil {
+			logger.Error("could not drop db before restoring", zap.Error(err))
+			return err
+		}
+	}
+	previous := b.streams
+	err = b.db.Load(r, storage.MaxPendingWrites)
+	if err != nil {
+		logger.Error("could not load backup into db", zap.Error(err))
+		return err
+	}
+	err = b.load(ctx)
+	if err != nil {
+		return err
+	}
+	for name, stream := range previous {
+		if restored, ok := b.streams[name]; !ok || restored.ID != stream.ID {
+			b.endSubscriptions(stream.ID, models.StreamNotFoundError{Name: name})
+		}
+	}
+	logger.Info("successfully restored db", zap.Bool("incremental", incremental), zap.Int("streams", len(b.streams)))
+	return nil
+}
+
+// BackupFile writes a backup into a new file of the backup directory, the file being removed when the backup fails
+func (b *Bus) BackupFile(ctx context.Context, path string, since uint64) (uint64, error) {
+	var version uint64
+	err := b.createFile(path, "backup", func(w io.Writer) error {
+		var err error
+		version, err = b.Backup(ctx, w, since)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// RestoreFile restores a backup file of the backup directory
+func (b *Bus) RestoreFile(ctx context.Context, path string, incremental bool) error {
+	return b.openFile(path, "backup", func(r io.ReadSeeker) error {
+		return b.Restore(ctx, r, incremental)
+	})
+}
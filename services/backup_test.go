@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *busSuite) Test_Bus_BackupRestore_Success() {
+	streamName := "stream-name"
+	_, err := s.bus.CreateStream(testCtx, testClient, streamName, "", json.RawMessage(testSchema), models.Quota{})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": "v"}`)))
+	expectedStream := s.bus.streams[streamName]
+	expectedUsage := s.bus.streamUsage[expectedStream.ID]
+	var backup bytes.Buffer
+
+	version, err := s.bus.Backup(testCtx, &backup, 0)
+
+	s.Require().NoError(err)
+	s.NotZero(version)
+	s.Require().NoError(s.bus.DeleteStream(testCtx, testClient, streamName))
+	s.Require().NoError(s.bus.Restore(testCtx, bytes.NewReader(backup.Bytes()), false))
+	s.Require().Len(s.bus.streams, 1)
+	s.Equal(expectedStream.ID, s.bus.streams[streamName].ID)
+	s.Contains(s.bus.schemas, streamName)
+	s.Equal(expectedUsage, s.bus.streamUsage[expectedStream.ID])
+	events, err := s.bus.GetStreamEvents(testCtx, streamName)
+	s.Require().NoError(err)
+	s.Require().Len(events, 1)
+	s.JSONEq(`{"k": "v"}`, string(events[0].Body))
+}
+
+func (s *busSuite) Test_Bus_Restore_KeepsAuditLog() {
+	var backup bytes.Buffer
+	_, err := s.bus.Backup(testCtx, &backup, 0)
+	s.Require().NoError(err)
+	_, err = s.bus.CreateStream(testCtx, testClient, "stream-name", "", nil, models.Quota{})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.bus.Restore(testCtx, bytes.NewReader(backup.Bytes()), false))
+	s.Require().NoError(s.bus.Audit(testCtx, models.NewRestoreEntry("admin", "event-bus.bak", false)))
+
+	s.Empty(s.bus.streams)
+	entries, err := s.bus.GetAuditLog(testCtx, models.AuditFilter{})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 2)
+	s.Equal(models.AuditCreateStream, entries[0].Action)
+	s.Equal(models.AuditRestore, entries[1].Action)
+}
+
+func (s *busSuite) Test_Bus_BackupRestore_Incremental() {
+	_, err := s.bus.CreateStream(testCtx, testClient, "stream1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	var full, incremental bytes.Buffer
+	version, err := s.bus.Backup(testCtx, &full, 0)
+	s.Require().NoError(err)
+	_, err = s.bus.CreateStream(testCtx, testClient, "stream2", "", nil, models.Quota{})
+	s.Require().NoError(err)
+
+	_, err = s.bus.Backup(testCtx, &incremental, version)
+
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.Restore(testCtx, bytes.NewReader(full.Bytes()), false))
+	s.Len(s.bus.streams, 1)
+	s.Contains(s.bus.streams, "stream1")
+	s.Require().NoError(s.bus.Restore(testCtx, bytes.NewReader(incremental.Bytes()), true))
+	s.Len(s.bus.streams, 2)
+	s.Contains(s.bus.streams, "stream2")
+}
+
+func (s *busSuite) Test_Bus_Restore_EndsSubscriptions() {
+	streamName := "stream-name"
+	var backup bytes.Buffer
+	_, err := s.bus.Backup(testCtx, &backup, 0)
+	s.Require().NoError(err)
+	_, err = s.bus.CreateStream(testCtx, testClient, streamName, "", nil, models.Quota{})
+	s.Require().NoError(err)
+	sub, err := s.bus.Subscribe(streamName)
+	s.Require().NoError(err)
+
+	err = s.bus.Restore(testCtx, bytes.NewReader(backup.Bytes()), false)
+
+	s.Require().NoError(err)
+	s.Empty(s.bus.streams)
+	_, ok := <-sub.Events()
+	s.False(ok)
+	s.Equal(models.StreamNotFoundError{Name: streamName}, sub.Err())
+}
+
+func (s *busSuite) Test_Bus_Restore_Error() {
+	s.bus.streams["stream-name"] = models.Stream{ID: "stream-id"}
+
+	err := s.bus.Restore(testCtx, strings.NewReader("garbage"), true)
+
+	s.Equal(models.InvalidBackupError{Reason: "unexpected EOF"}, err)
+	s.Contains(s.bus.streams, "stream-name")
+}
+
+func (s *busSuite) Test_Bus_Restore_CorruptBackup() {
+	_, err := s.bus.CreateStream(testCtx, testClient, "stream-name", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	var backup bytes.Buffer
+	_, err = s.bus.Backup(testCtx, &backup, 0)
+	s.Require().NoError(err)
+	corrupt := backup.Bytes()[:backup.Len()-1]
+
+	err = s.bus.Restore(testCtx, bytes.NewReader(corrupt), false)
+
+	s.Equal(models.InvalidBackupError{Reason: "unexpected EOF"}, err)
+	stream, err := s.bus.GetStreamInfo("stream-name")
+	s.Require().NoError(err)
+	s.Equal("stream-name", stream.Name)
+}
+
+func (s *busSuite) Test_Bus_BackupRestoreFile_Success() {
+	_, err := s.bus.CreateStream(testCtx, testClient, "stream-name", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	dir := s.tempDir()
+	s.bus.SetBackupDir(dir)
+
+	_, err = s.bus.BackupFile(testCtx, "daily/bus.bak", 0)
+
+	s.Require().NoError(err)
+	info, err := os.Stat(filepath.Join(dir, "daily", "bus.bak"))
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+	s.Require().NoError(s.bus.DeleteStream(testCtx, testClient, "stream-name"))
+	s.Require().NoError(s.bus.RestoreFile(testCtx, "daily/bus.bak", false))
+	s.Contains(s.bus.streams, "stream-name")
+}
+
+func (s *busSuite) Test_Bus_BackupFile_ExistsError() {
+	dir := s.tempDir()
+	s.bus.SetBackupDir(dir)
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(dir, "bus.bak"), []byte("previous"), 0600))
+
+	version, err := s.bus.BackupFile(testCtx, "bus.bak", 0)
+
+	s.Equal(models.Error{Message: "backup file already exists"}, err)
+	s.Zero(version)
+	bs, err := ioutil.ReadFile(filepath.Join(dir, "bus.bak"))
+	s.Require().NoError(err)
+	s.Equal("previous", string(bs))
+}
+
+func (s *busSuite) Test_Bus_BackupFile_InvalidPathError() {
+	s.bus.SetBackupDir(s.tempDir())
+	paths := []string{"/etc/passwd", "../bus.bak", "daily/../../bus.bak", ".", ""}
+
+	for _, path := range paths {
+		s.Run(path, func() {
+			version, err := s.bus.BackupFile(testCtx, path, 0)
+
+			s.Equal(models.InvalidPathError{Path: path}, err)
+			s.Zero(version)
+		})
+	}
+}
+
+func (s *busSuite) Test_Bus_BackupFile_NoBackupDirError() {
+	version, err := s.bus.BackupFile(testCtx, "bus.bak", 0)
+
+	s.Equal(models.Error{Message: "backup files are disabled, no backup directory is configured"}, err)
+	s.Zero(version)
+}
+
+func (s *busSuite) Test_Bus_RestoreFile_OpenError() {
+	s.bus.SetBackupDir(s.tempDir())
+
+	err := s.bus.RestoreFile(testCtx, "missing.bak", false)
+
+	s.Equal(models.Error{Message: "could not open backup file"}, err)
+}
+
+func (s *busSuite) Test_Bus_RestoreFile_InvalidPathError() {
+	s.bus.SetBackupDir(s.tempDir())
+
+	err := s.bus.RestoreFile(testCtx, "/etc/passwd", false)
+
+	s.Equal(models.InvalidPathError{Path: "/etc/passwd"}, err)
+}
+
+func (s *busSuite) tempDir() string {
+	dir, err := ioutil.TempDir("", "event-bus-services")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return dir
+}
@@ -1,8 +1,12 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -11,75 +15,178 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/go-web-dev/event-bus/compression"
 	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/metrics"
 	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
+	"github.com/go-web-dev/event-bus/tracing"
 )
 
-// NewBus creates a new Event Bus service
-func NewBus(d DB) *Bus {
+// NewBus creates a new Event Bus service enforcing the given quotas
+func NewBus(d DB, quotas Quotas) *Bus {
 	b := &Bus{
-		db:      db{d},
-		streams: map[string]models.Stream{},
+		db:            db{d},
+		quotas:        quotas,
+		streams:       map[string]models.Stream{},
+		schemas:       map[string]*schema.Schema{},
+		registry:      newRegistry(),
+		streamUsage:   map[string]models.Usage{},
+		clientUsage:   map[string]models.Usage{},
+		expiries:      newExpiries(),
+		subscriptions: map[string]map[*Subscription]struct{}{},
 	}
 	return b
 }
 
 // Bus represents the Event Bus service
 type Bus struct {
-	mu      sync.RWMutex
-	db      db
-	streams map[string]models.Stream
+	mu            sync.RWMutex
+	db            db
+	quotas        Quotas
+	streams       map[string]models.Stream
+	schemas       map[string]*schema.Schema
+	registry      registry
+	streamUsage   map[string]models.Usage
+	clientUsage   map[string]models.Usage
+	expiries      *expiries
+	subscriptions map[string]map[*Subscription]struct{}
+	backupDir     string
 }
 
 // Init initializes the event bus with helper data such as streams
 func (b *Bus) Init() error {
-	logger := logging.Logger
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	return b.load(context.Background())
+}
 
+// load loads the helper data such as streams from the db, replacing the loaded ones.
+// To be called while holding the lock
+func (b *Bus) load(ctx context.Context) error {
+	logger := logging.Logger
 	logger.Info("initializing event bus with streams")
+	b.streams = map[string]models.Stream{}
+	b.schemas = map[string]*schema.Schema{}
 	var streamVar models.Stream
 	txn := b.db.fetch("stream:", &streamVar, func(res fetchResult) {
 		stream := res.item.(*models.Stream)
 		b.streams[stream.Name] = *stream
+		eventSchema, err := compileSchema(stream.Schema)
+		if err != nil {
+			logger.Error("could not compile stream schema", zap.String("stream_id", stream.ID), zap.Error(err))
+			return
+		}
+		if eventSchema != nil {
+			b.schemas[stream.Name] = eventSchema
+		}
 	})
-	err := b.db.txn(false, txn)
+	err := b.db.txn(ctx, false, txn)
 	if err != nil {
 		logger.Error("could not fetch streams from db", zap.Error(err))
 		return err
 	}
+	err = b.initRegistry(ctx)
+	if err != nil {
+		logger.Error("could not fetch event types from db", zap.Error(err))
+		return err
+	}
+	err = b.initUsage(ctx)
+	if err != nil {
+		logger.Error("could not count stream and client usage", zap.Error(err))
+		return err
+	}
 
 	logger.Info("successfully initialized event bus with streams")
 	return nil
 }
 
-// CreateStream creates a new stream for messaging in the Event Bus
-func (b *Bus) CreateStream(streamName string) (models.Stream, error) {
+// CreateStream creates a new stream for messaging in the Event Bus on behalf of a client.
+// The events of the stream are stored compressed with the given compression algorithm, if any,
+// the JSON events have to match the given JSON Schema, if any, and the stream events are limited by the quota
+func (b *Bus) CreateStream(ctx context.Context, client, streamName, compressionAlgorithm string, eventSchema json.RawMessage, quota models.Quota) (models.Stream, error) {
 	logger := logging.Logger
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if !compression.Supported(compressionAlgorithm) {
+		return models.Stream{}, models.UnsupportedCompressionError{}
+	}
+	err := quota.Validate()
+	if err != nil {
+		return models.Stream{}, err
+	}
+	compiledSchema, err := compileSchema(eventSchema)
+	if err != nil {
+		return models.Stream{}, err
+	}
 	if _, ok := b.streams[streamName]; ok {
-		return models.Stream{}, fmt.Errorf("stream: '%s' already exists", streamName)
+		return models.Stream{}, models.StreamExistsError{Name: streamName}
 	}
 	stream := models.Stream{
-		Name:      streamName,
-		ID:        uuid.New().String(),
-		CreatedAt: time.Now().UTC(),
+		Name:        streamName,
+		ID:          uuid.New().String(),
+		CreatedAt:   time.Now().UTC(),
+		Compression: compressionAlgorithm,
+		Quota:       quota,
+	}
+	if compiledSchema != nil {
+		stream.Schema = eventSchema
 	}
 	txn := b.db.set(stream.Key(), stream.Value(), 0)
-	err := b.db.txn(true, txn)
+	auditTxn := b.db.audit(models.AuditEntry{Action: models.AuditCreateStream, Client: client, Stream: streamName})
+	err = b.db.txn(ctx, true, txn, auditTxn)
 	if err != nil {
 		logger.Debug("could not save stream to db", zap.Error(err))
 		return models.Stream{}, err
 	}
 	b.streams[streamName] = stream
+	b.setSchema(streamName, compiledSchema)
 	logger.Info("successfully created stream", zap.String("stream_id", stream.ID))
 	return stream, nil
 }
 
-// DeleteStream deletes a stream and its associated messages
-func (b *Bus) DeleteStream(streamName string) error {
+// UpdateStream replaces on behalf of a client the JSON Schema the events written to a stream have to match
+// and the stream quota.
+// An empty schema removes it, the events already written are never validated again nor removed
+// when going over the new quota
+func (b *Bus) UpdateStream(ctx context.Context, client, streamName string, eventSchema json.RawMessage, quota models.Quota) (models.Stream, error) {
+	logger := logging.Logger
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stream, err := b.streamLookup(streamName)
+	if err != nil {
+		return models.Stream{}, err
+	}
+	err = quota.Validate()
+	if err != nil {
+		return models.Stream{}, err
+	}
+	compiledSchema, err := compileSchema(eventSchema)
+	if err != nil {
+		return models.Stream{}, err
+	}
+	stream.Quota = quota
+	stream.Schema = nil
+	if compiledSchema != nil {
+		stream.Schema = eventSchema
+	}
+	txn := b.db.set(stream.Key(), stream.Value(), 0)
+	auditTxn := b.db.audit(models.AuditEntry{Action: models.AuditUpdateStream, Client: client, Stream: streamName})
+	err = b.db.txn(ctx, true, txn, auditTxn)
+	if err != nil {
+		logger.Debug("could not save stream to db", zap.Error(err))
+		return models.Stream{}, err
+	}
+	b.streams[streamName] = stream
+	b.setSchema(streamName, compiledSchema)
+	logger.Info("successfully updated stream", zap.String("stream_id", stream.ID))
+	return stream, nil
+}
+
+// DeleteStream deletes on behalf of a client a stream and its associated messages
+func (b *Bus) DeleteStream(ctx context.Context, client, streamName string) error {
 	logger := logging.Logger
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -91,29 +198,48 @@ func (b *Bus) DeleteStream(streamName string) error {
 
 	key := fmt.Sprintf("event:%s", stream.ID)
 	keysToDelete := make([][]byte, 0)
+	deletedUsage := map[string]models.Usage{}
 	var eventVar models.Event
 	fetchTxn := b.db.fetch(key, &eventVar, func(res fetchResult) {
 		keysToDelete = append(keysToDelete, res.key)
+		evt := res.item.(*models.Event)
+		deletedUsage[evt.Producer] = addUsage(deletedUsage[evt.Producer], 1, int64(len(evt.Payload())))
 	})
-	err = b.db.txn(false, fetchTxn)
+	err = b.db.txn(ctx, false, fetchTxn)
 	if err != nil {
 		logger.Debug("could not fetch stream events", zap.Error(err))
 		return err
 	}
 
 	deleteTxn := b.db.delete(append(keysToDelete, stream.Key())...)
-	err = b.db.txn(true, deleteTxn)
+	auditTxn := b.db.audit(models.AuditEntry{
+		Action:  models.AuditDeleteStream,
+		Client:  client,
+		Stream:  streamName,
+		Details: map[string]string{"events": strconv.Itoa(len(keysToDelete))},
+	})
+	err = b.db.txn(ctx, true, deleteTxn, auditTxn)
 	if err != nil {
 		logger.Debug("could not delete stream and its events", zap.Error(err))
 		return err
 	}
 
 	delete(b.streams, streamName)
+	delete(b.schemas, streamName)
+	delete(b.streamUsage, stream.ID)
+	b.expiries.removeStream(stream.ID)
+	for producer, usage := range deletedUsage {
+		if producer != "" {
+			b.clientUsage[producer] = addUsage(b.clientUsage[producer], -usage.Events, -usage.Bytes)
+		}
+	}
+	b.endSubscriptions(stream.ID, models.StreamNotFoundError{Name: streamName})
+	metrics.StreamDeleted(streamName)
 	logger.Info("successfully deleted stream", zap.String("stream_id", stream.ID))
 	return nil
 }
 
-// GetStreamInfo gets a stream's short information
+// GetStreamInfo gets a stream's short information along with its current usage
 func (b *Bus) GetStreamInfo(streamName string) (models.Stream, error) {
 	logger := logging.Logger
 	b.mu.RLock()
@@ -123,13 +249,32 @@ func (b *Bus) GetStreamInfo(streamName string) (models.Stream, error) {
 	if err != nil {
 		return models.Stream{}, err
 	}
+	usage := b.streamUsage[stream.ID]
+	stream.Usage = &usage
 
 	logger.Info("successfully got stream info", zap.String("stream_id", stream.ID))
 	return stream, nil
 }
 
+// ListStreams lists every stream sorted by name, along with its current usage
+func (b *Bus) ListStreams() []models.Stream {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	streams := make([]models.Stream, 0, len(b.streams))
+	for _, stream := range b.streams {
+		usage := b.streamUsage[stream.ID]
+		stream.Usage = &usage
+		streams = append(streams, stream)
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		return streams[i].Name < streams[j].Name
+	})
+	return streams
+}
+
 // GetStreamEvents gets all events for a certain stream
-func (b *Bus) GetStreamEvents(streamName string) ([]models.Event, error) {
+func (b *Bus) GetStreamEvents(ctx context.Context, streamName string) ([]models.Event, error) {
 	logger := logging.Logger
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -141,7 +286,7 @@ func (b *Bus) GetStreamEvents(streamName string) ([]models.Event, error) {
 
 	logger.Info("getting all stream events", zap.String("stream_id", stream.ID))
 	key := fmt.Sprintf("event:%s", stream.ID)
-	events, err := b.db.streamEvents(key, nil)
+	events, err := b.db.streamEvents(ctx, key, nil)
 	if err != nil {
 		logger.Error("could not get stream events", zap.Error(err))
 		return []models.Event{}, err
@@ -150,8 +295,31 @@ func (b *Bus) GetStreamEvents(streamName string) ([]models.Event, error) {
 	return events, nil
 }
 
-// WriteEvent writes an event to a certain stream in the Event Bus
-func (b *Bus) WriteEvent(streamName string, body json.RawMessage) error {
+// WriteEvent writes a JSON event on behalf of a client to a certain stream in the Event Bus
+func (b *Bus) WriteEvent(ctx context.Context, client, streamName string, body json.RawMessage) error {
+	return b.writeEvent(ctx, client, streamName, models.JSONContentType, body, nil, "", 0)
+}
+
+// WriteTypedEvent writes a JSON event of a registered event type to a certain stream in the Event Bus.
+// The event is validated against the given version of the event type, zero meaning the latest one,
+// and tagged with its schema ID
+func (b *Bus) WriteTypedEvent(ctx context.Context, client, streamName, eventType string, version int, body json.RawMessage) error {
+	return b.writeEvent(ctx, client, streamName, models.JSONContentType, body, nil, eventType, version)
+}
+
+// WriteData writes an event with an arbitrary binary payload to a certain stream in the Event Bus.
+// The data is stored and returned to consumers unchanged
+func (b *Bus) WriteData(ctx context.Context, client, streamName, contentType string, data []byte) error {
+	if contentType == "" {
+		contentType = models.OctetStreamContentType
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return b.writeEvent(ctx, client, streamName, contentType, nil, data, "", 0)
+}
+
+func (b *Bus) writeEvent(ctx context.Context, client, streamName, contentType string, body json.RawMessage, data []byte, eventType string, version int) error {
 	logger := logging.Logger
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -160,27 +328,58 @@ func (b *Bus) WriteEvent(streamName string, body json.RawMessage) error {
 	if err != nil {
 		return err
 	}
+	if models.IsJSONContentType(contentType) {
+		err = b.validateEvent(streamName, body)
+		if err != nil {
+			return err
+		}
+		// JSON events are stored compacted, which is the size their quotas account for
+		compacted := bytes.Buffer{}
+		if json.Compact(&compacted, body) == nil {
+			body = compacted.Bytes()
+		}
+	}
+	var schemaID string
+	if eventType != "" {
+		schemaID, err = b.validateEventType(eventType, version, body)
+		if err != nil {
+			return err
+		}
+	}
 
 	evt := models.Event{
-		ID:        uuid.New().String(),
-		StreamID:  stream.ID,
-		CreatedAt: time.Now().UTC(),
-		Body:      body,
-		Status:    models.EventUnprocessedStatus,
+		ID:           uuid.New().String(),
+		StreamID:     stream.ID,
+		CreatedAt:    time.Now().UTC(),
+		ContentType:  contentType,
+		SchemaID:     schemaID,
+		Producer:     client,
+		TraceContext: tracing.Inject(ctx),
+		Body:         body,
+		Data:         data,
+		Status:       models.EventUnprocessedStatus,
+		Compression:  stream.Compression,
+	}
+	err = b.checkQuotas(client, stream, int64(len(evt.Payload())))
+	if err != nil {
+		return err
 	}
 	txn := b.db.set(evt.Key(models.EventUnprocessedStatus), evt.Value(), evt.ExpiresAt())
-	err = b.db.txn(true, txn)
+	err = b.db.txn(ctx, true, txn)
 	if err != nil {
 		logger.Debug("could not write event to db", zap.Error(err))
 		return err
 	}
+	b.addEventUsage(evt)
+	b.publish(evt)
+	metrics.EventWritten(streamName)
 
 	logger.Info("successfully wrote event", zap.String("event_id", evt.ID))
 	return nil
 }
 
-// MarkEvent marks an event / changes it's status
-func (b *Bus) MarkEvent(eventID string, status uint8) error {
+// MarkEvent marks an event / changes it's status on behalf of a client
+func (b *Bus) MarkEvent(ctx context.Context, client, eventID string, status uint8) error {
 	logger := logging.Logger
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -190,13 +389,13 @@ func (b *Bus) MarkEvent(eventID string, status uint8) error {
 		keys := strings.Split(string(item.Key()), ":")
 		return keys[len(keys)-1] == eventID
 	}
-	events, err := b.db.streamEvents(key, chooseKeyFunc)
+	events, err := b.db.streamEvents(ctx, key, chooseKeyFunc)
 	if err != nil {
 		logger.Error("could not stream events", zap.Error(err))
 		return err
 	}
 	if len(events) == 0 {
-		return fmt.Errorf("event '%s' not found", eventID)
+		return models.EventNotFoundError{ID: eventID}
 	}
 	evt := events[0]
 	evt.Status = status
@@ -208,7 +407,14 @@ func (b *Bus) MarkEvent(eventID string, status uint8) error {
 	}
 	deleteKeysTxn := b.db.delete(keys...)
 	markEventTxn := b.db.set(evt.Key(status), evt.Value(), evt.ExpiresAt())
-	err = b.db.txn(true, deleteKeysTxn, markEventTxn)
+	auditTxn := b.db.audit(models.AuditEntry{
+		Action:  models.AuditMarkEvent,
+		Client:  client,
+		Stream:  b.streamName(evt.StreamID),
+		EventID: eventID,
+		Details: map[string]string{"status": strconv.Itoa(int(status))},
+	})
+	err = b.db.txn(ctx, true, deleteKeysTxn, markEventTxn, auditTxn)
 	if err != nil {
 		logger.Error(
 			"could not mark event",
@@ -218,12 +424,56 @@ func (b *Bus) MarkEvent(eventID string, status uint8) error {
 		return err
 	}
 
+	b.observeMarkedEvent(evt)
+
 	logger.Info("successfully marked event", zap.String("event_id", eventID))
 	return nil
 }
 
+// Backlog counts the unprocessed and retry events of every stream by stream name
+func (b *Bus) Backlog() (map[string]models.Backlog, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	backlogs := make(map[string]models.Backlog, len(b.streams))
+	for name, stream := range b.streams {
+		var backlog models.Backlog
+		unprocessedTxn := b.db.count(fmt.Sprintf("event:%s:%d:", stream.ID, models.EventUnprocessedStatus), &backlog.Unprocessed)
+		retryTxn := b.db.count(fmt.Sprintf("event:%s:%d:", stream.ID, models.EventRetryStatus), &backlog.Retry)
+		err := b.db.txn(context.Background(), false, unprocessedTxn, retryTxn)
+		if err != nil {
+			return nil, err
+		}
+		backlogs[name] = backlog
+	}
+	return backlogs, nil
+}
+
+func (b *Bus) observeMarkedEvent(evt models.Event) {
+	name := b.streamName(evt.StreamID)
+	if name == "" {
+		return
+	}
+	switch evt.Status {
+	case models.EventProcessedStatus:
+		metrics.EventProcessed(name)
+	case models.EventRetryStatus:
+		metrics.EventRetried(name)
+	}
+}
+
+// streamName gets the name of the stream with the given ID, empty when there is none
+func (b *Bus) streamName(streamID string) string {
+	for name, stream := range b.streams {
+		if stream.ID == streamID {
+			return name
+		}
+	}
+	return ""
+}
+
 // ProcessEvents processes/retries all available events in the queue
-func (b *Bus) ProcessEvents(streamName string, retry bool) ([]models.Event, error) {
+func (b *Bus) ProcessEvents(ctx context.Context, streamName string, retry bool) ([]models.Event, error) {
 	logger := logging.Logger
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -239,7 +489,7 @@ func (b *Bus) ProcessEvents(streamName string, retry bool) ([]models.Event, erro
 		status = models.EventRetryStatus
 	}
 	key := fmt.Sprintf("event:%s:%d", stream.ID, status)
-	events, err := b.db.streamEvents(key, nil)
+	events, err := b.db.streamEvents(ctx, key, nil)
 	if err != nil {
 		logger.Error("could not process events", zap.Error(err))
 		return []models.Event{}, err
@@ -251,7 +501,48 @@ func (b *Bus) ProcessEvents(streamName string, retry bool) ([]models.Event, erro
 func (b *Bus) streamLookup(streamName string) (models.Stream, error) {
 	stream, ok := b.streams[streamName]
 	if !ok {
-		return models.Stream{}, fmt.Errorf("stream '%s' not found", streamName)
+		return models.Stream{}, models.StreamNotFoundError{Name: streamName}
 	}
 	return stream, nil
 }
+
+func (b *Bus) setSchema(streamName string, eventSchema *schema.Schema) {
+	if eventSchema == nil {
+		delete(b.schemas, streamName)
+		return
+	}
+	b.schemas[streamName] = eventSchema
+}
+
+// validateEvent validates the JSON event against the schema of its stream, if any
+func (b *Bus) validateEvent(streamName string, body json.RawMessage) error {
+	return validateBody(b.schemas[streamName], body)
+}
+
+// validateBody validates the JSON event against the schema, no schema meaning any event is valid
+func validateBody(eventSchema *schema.Schema, body json.RawMessage) error {
+	if eventSchema == nil {
+		return nil
+	}
+	violations, err := eventSchema.Validate(body)
+	if err != nil {
+		logging.Logger.Debug("could not validate event", zap.Error(err))
+		return models.InvalidJSONError{}
+	}
+	if len(violations) > 0 {
+		return models.EventValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// compileSchema compiles the stream JSON Schema, no schema or a null one meaning the stream has none
+func compileSchema(eventSchema json.RawMessage) (*schema.Schema, error) {
+	if len(eventSchema) == 0 || bytes.Equal(bytes.TrimSpace(eventSchema), []byte("null")) {
+		return nil, nil
+	}
+	compiled, err := schema.Compile(eventSchema)
+	if err != nil {
+		return nil, models.InvalidSchemaError{Reason: err.Error()}
+	}
+	return compiled, nil
+}
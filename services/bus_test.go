@@ -1,24 +1,33 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/go-web-dev/event-bus/logging"
 	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
 	"github.com/go-web-dev/event-bus/testutils"
+	"github.com/go-web-dev/event-bus/tracing"
 )
 
 const (
 	testTimeStr = "2020-12-15T05:28:31.490416Z"
+	testClient  = "test-client"
+	testSchema  = `{"type": "object", "properties": {"k": {"type": "string"}}, "required": ["k"]}`
 )
 
 var (
 	testTime, _ = time.Parse(time.RFC3339, testTimeStr)
+	testCtx     = context.Background()
 )
 
 type busSuite struct {
@@ -35,8 +44,14 @@ func (s *busSuite) SetupSuite() {
 
 func (s *busSuite) SetupTest() {
 	s.bus = &Bus{
-		db:      s.db,
-		streams: map[string]models.Stream{},
+		db:            s.db,
+		streams:       map[string]models.Stream{},
+		schemas:       map[string]*schema.Schema{},
+		registry:      newRegistry(),
+		streamUsage:   map[string]models.Usage{},
+		clientUsage:   map[string]models.Usage{},
+		expiries:      newExpiries(),
+		subscriptions: map[string]map[*Subscription]struct{}{},
 	}
 }
 
@@ -45,12 +60,20 @@ func (s *busSuite) TearDownTest() {
 }
 
 func (s *busSuite) Test_Bus_New() {
+	quotas := Quotas{MaxEventSize: 1024}
 	expected := &Bus{
-		db:      db{s.db},
-		streams: map[string]models.Stream{},
+		db:            db{s.db},
+		quotas:        quotas,
+		streams:       map[string]models.Stream{},
+		schemas:       map[string]*schema.Schema{},
+		registry:      newRegistry(),
+		streamUsage:   map[string]models.Usage{},
+		clientUsage:   map[string]models.Usage{},
+		expiries:      newExpiries(),
+		subscriptions: map[string]map[*Subscription]struct{}{},
 	}
 
-	bus := NewBus(s.db)
+	bus := NewBus(s.db, quotas)
 
 	s.Require().NotNil(bus)
 	s.Equal(expected, bus)
@@ -79,6 +102,24 @@ func (s *busSuite) Test_Bus_Init_Success() {
 	s.Equal(expected, s.bus.streams)
 }
 
+func (s *busSuite) Test_Bus_Init_Schema() {
+	stream := models.Stream{
+		ID:        "stream-id",
+		Name:      "stream-name",
+		CreatedAt: testTime,
+		Schema:    json.RawMessage(testSchema),
+	}
+	s.setStreams(stream)
+
+	err := s.bus.Init()
+
+	s.Require().NoError(err)
+	s.Contains(s.bus.schemas, stream.Name)
+	s.Equal(models.EventValidationError{
+		Violations: []schema.Violation{{Path: "k", Message: "k is required"}},
+	}, s.bus.WriteEvent(testCtx, testClient, stream.Name, json.RawMessage(`{}`)))
+}
+
 func (s *busSuite) Test_Bus_Init_Error() {
 	stream := models.Stream{
 		ID:        "stream-id",
@@ -100,17 +141,65 @@ func (s *busSuite) Test_Bus_Init_Error() {
 func (s *busSuite) Test_Bus_CreateStream_Success() {
 	streamName := "hello-stream"
 
-	stream, err := s.bus.CreateStream(streamName)
+	stream, err := s.bus.CreateStream(testCtx, testClient, streamName, "", nil, models.Quota{})
 
 	s.Require().NoError(err)
 	s.Equal(streamName, stream.Name)
 }
 
+func (s *busSuite) Test_Bus_CreateStream_Compression() {
+	streamName := "hello-stream"
+
+	stream, err := s.bus.CreateStream(testCtx, testClient, streamName, "snappy", nil, models.Quota{})
+
+	s.Require().NoError(err)
+	s.Equal("snappy", stream.Compression)
+	s.Equal(stream, s.bus.streams[streamName])
+}
+
+func (s *busSuite) Test_Bus_CreateStream_UnsupportedCompressionError() {
+	stream, err := s.bus.CreateStream(testCtx, testClient, "hello-stream", "gzip", nil, models.Quota{})
+
+	s.Equal(models.UnsupportedCompressionError{}, err)
+	s.Empty(stream)
+	s.Empty(s.bus.streams)
+}
+
+func (s *busSuite) Test_Bus_CreateStream_Schema() {
+	streamName := "hello-stream"
+
+	stream, err := s.bus.CreateStream(testCtx, testClient, streamName, "", json.RawMessage(testSchema), models.Quota{})
+
+	s.Require().NoError(err)
+	s.JSONEq(testSchema, string(stream.Schema))
+	s.Equal(stream, s.bus.streams[streamName])
+	s.Contains(s.bus.schemas, streamName)
+}
+
+func (s *busSuite) Test_Bus_CreateStream_NullSchema() {
+	streamName := "hello-stream"
+
+	stream, err := s.bus.CreateStream(testCtx, testClient, streamName, "", json.RawMessage("null"), models.Quota{})
+
+	s.Require().NoError(err)
+	s.Nil(stream.Schema)
+	s.Empty(s.bus.schemas)
+}
+
+func (s *busSuite) Test_Bus_CreateStream_InvalidSchemaError() {
+	stream, err := s.bus.CreateStream(testCtx, testClient, "hello-stream", "", json.RawMessage(`{"type": "unknown"}`), models.Quota{})
+
+	s.IsType(models.InvalidSchemaError{}, err)
+	s.Empty(stream)
+	s.Empty(s.bus.streams)
+	s.Empty(s.bus.schemas)
+}
+
 func (s *busSuite) Test_Bus_CreateStream_AlreadyExistsError() {
 	streamName := "hello-stream"
 	s.bus.streams[streamName] = models.Stream{}
 
-	stream, err := s.bus.CreateStream(streamName)
+	stream, err := s.bus.CreateStream(testCtx, testClient, streamName, "", nil, models.Quota{})
 
 	s.EqualError(err, "stream: 'hello-stream' already exists")
 	s.Empty(stream)
@@ -129,22 +218,73 @@ func (s *busSuite) Test_Bus_DeleteStream_Success() {
 		StreamID: streamID,
 	}
 	evt2 := models.Event{
-		ID:       "evt2-id",
-		StreamID: streamID,
+		ID:          "evt2-id",
+		StreamID:    streamID,
+		ContentType: "application/x-protobuf",
+		Data:        []byte{0, 1, 2},
 	}
 	s.setStreams(stream)
 	s.setEvents(evt1, evt2)
 	s.bus.streams[streamName] = stream
 
-	err := s.bus.DeleteStream(streamName)
+	err := s.bus.DeleteStream(testCtx, testClient, streamName)
 
 	s.Require().NoError(err)
 	s.Empty(s.bus.streams)
 	s.Empty(s.fetchEvents())
 }
 
+func (s *busSuite) Test_Bus_UpdateStream_Success() {
+	streamName := "stream-name"
+	stream := models.Stream{ID: "stream-id", Name: streamName, CreatedAt: testTime}
+	s.setStreams(stream)
+	s.bus.streams[streamName] = stream
+
+	updated, err := s.bus.UpdateStream(testCtx, testClient, streamName, json.RawMessage(testSchema), models.Quota{})
+
+	s.Require().NoError(err)
+	s.JSONEq(testSchema, string(updated.Schema))
+	s.Equal(updated, s.bus.streams[streamName])
+	s.Contains(s.bus.schemas, streamName)
+	s.Require().NoError(s.bus.Init())
+	s.JSONEq(testSchema, string(s.bus.streams[streamName].Schema))
+}
+
+func (s *busSuite) Test_Bus_UpdateStream_RemoveSchema() {
+	streamName := "stream-name"
+	stream := models.Stream{ID: "stream-id", Name: streamName, Schema: json.RawMessage(testSchema)}
+	s.bus.streams[streamName] = stream
+	s.bus.schemas[streamName], _ = schema.Compile([]byte(testSchema))
+
+	updated, err := s.bus.UpdateStream(testCtx, testClient, streamName, nil, models.Quota{})
+
+	s.Require().NoError(err)
+	s.Nil(updated.Schema)
+	s.Empty(s.bus.schemas)
+	s.NoError(s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{}`)))
+}
+
+func (s *busSuite) Test_Bus_UpdateStream_InvalidSchemaError() {
+	streamName := "stream-name"
+	stream := models.Stream{ID: "stream-id", Name: streamName}
+	s.bus.streams[streamName] = stream
+
+	updated, err := s.bus.UpdateStream(testCtx, testClient, streamName, json.RawMessage(`{"type": "unknown"}`), models.Quota{})
+
+	s.IsType(models.InvalidSchemaError{}, err)
+	s.Empty(updated)
+	s.Equal(stream, s.bus.streams[streamName])
+}
+
+func (s *busSuite) Test_Bus_UpdateStream_StreamNotFoundError() {
+	stream, err := s.bus.UpdateStream(testCtx, testClient, "stream-name", json.RawMessage(testSchema), models.Quota{})
+
+	s.Equal(models.StreamNotFoundError{Name: "stream-name"}, err)
+	s.Empty(stream)
+}
+
 func (s *busSuite) Test_Bus_DeleteStream_StreamNotFoundError() {
-	err := s.bus.DeleteStream("stream-name")
+	err := s.bus.DeleteStream(testCtx, testClient, "stream-name")
 
 	s.EqualError(err, "stream 'stream-name' not found")
 }
@@ -158,10 +298,12 @@ func (s *busSuite) Test_Bus_GetStreamInfo_Success() {
 	}
 	s.setStreams(expected)
 	s.bus.streams[streamName] = expected
+	s.bus.streamUsage[expected.ID] = models.Usage{Events: 2, Bytes: 20}
 
 	stream, err := s.bus.GetStreamInfo(streamName)
 
 	s.Require().NoError(err)
+	expected.Usage = &models.Usage{Events: 2, Bytes: 20}
 	s.Equal(expected, stream)
 }
 
@@ -172,6 +314,24 @@ func (s *busSuite) Test_Bus_GetStreamInfo_StreamNotFoundError() {
 	s.Empty(stream)
 }
 
+func (s *busSuite) Test_Bus_ListStreams() {
+	stream1 := models.Stream{ID: "stream1-id", Name: "stream1", CreatedAt: testTime}
+	stream2 := models.Stream{ID: "stream2-id", Name: "stream2", CreatedAt: testTime}
+	s.bus.streams[stream2.Name] = stream2
+	s.bus.streams[stream1.Name] = stream1
+	s.bus.streamUsage[stream2.ID] = models.Usage{Events: 1, Bytes: 10}
+
+	streams := s.bus.ListStreams()
+
+	stream1.Usage = &models.Usage{}
+	stream2.Usage = &models.Usage{Events: 1, Bytes: 10}
+	s.Equal([]models.Stream{stream1, stream2}, streams)
+}
+
+func (s *busSuite) Test_Bus_ListStreams_NoStreams() {
+	s.Empty(s.bus.ListStreams())
+}
+
 func (s *busSuite) Test_Bus_GetStreamEvents_Success() {
 	streamName := "stream-name"
 	streamID := "stream-id"
@@ -215,7 +375,7 @@ func (s *busSuite) Test_Bus_GetStreamEvents_Success() {
 	s.setEvents(s1Evt1, s1Evt2, s1Evt3, s2Evt1)
 	s.bus.streams[streamName] = stream1
 
-	events, err := s.bus.GetStreamEvents(streamName)
+	events, err := s.bus.GetStreamEvents(testCtx, streamName)
 
 	s.Require().NoError(err)
 	s.Equal(expectedEvents, events)
@@ -225,14 +385,14 @@ func (s *busSuite) Test_Bus_GetStreamEvents_EmptyResult() {
 	streamName := "stream-name"
 	s.bus.streams[streamName] = models.Stream{}
 
-	stream, err := s.bus.GetStreamEvents(streamName)
+	stream, err := s.bus.GetStreamEvents(testCtx, streamName)
 
 	s.Nil(err)
 	s.Empty(stream)
 }
 
 func (s *busSuite) Test_Bus_GetStreamEvents_StreamNotFoundError() {
-	stream, err := s.bus.GetStreamEvents("stream-name")
+	stream, err := s.bus.GetStreamEvents(testCtx, "stream-name")
 
 	s.EqualError(err, "stream 'stream-name' not found")
 	s.Empty(stream)
@@ -248,7 +408,7 @@ func (s *busSuite) Test_Bus_GetStreamEvents_UnmarshalJSONError() {
 	s.setRaw(evt.Key(0), []byte("}"))
 	s.bus.streams[streamName] = models.Stream{ID: streamID}
 
-	stream, err := s.bus.GetStreamEvents(streamName)
+	stream, err := s.bus.GetStreamEvents(testCtx, streamName)
 
 	s.Nil(err)
 	s.Empty(stream)
@@ -261,17 +421,132 @@ func (s *busSuite) Test_Bus_WriteEvent_Success() {
 	evtBody := `{"k": "v"}`
 	s.bus.streams[streamName] = models.Stream{ID: streamID}
 
-	err := s.bus.WriteEvent(streamName, json.RawMessage(evtBody))
+	err := s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(evtBody))
 
 	s.Require().NoError(err)
 	events := s.fetchEvents()
 	s.Require().Len(events, 1)
 	s.Equal(streamID, events[0].StreamID)
+	s.Equal("application/json", events[0].ContentType)
+	s.JSONEq(evtBody, string(events[0].Body))
+	s.Nil(events[0].Data)
+}
+
+func (s *busSuite) Test_Bus_WriteEvent_TraceContext() {
+	memory := tracetest.NewInMemoryExporter()
+	shutdown, err := tracing.Init(tracing.Settings{Exporter: tracing.MemoryExporter, SampleRatio: 1, Memory: memory})
+	s.Require().NoError(err)
+	defer func() {
+		s.NoError(shutdown(testCtx))
+	}()
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	ctx, span := tracing.Start(testCtx, "write_event", trace.SpanKindServer)
+
+	err = s.bus.WriteEvent(ctx, testClient, streamName, json.RawMessage(`{}`))
+	span.End()
+
+	s.Require().NoError(err)
+	spans := memory.GetSpans()
+	s.Require().Len(spans, 2)
+	s.Equal("badger.txn", spans[0].Name)
+	s.Equal(span.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	s.Contains(spans[0].Attributes, attribute.Bool("db.update", true))
+	events := s.fetchEvents()
+	s.Require().Len(events, 1)
+	s.Equal(tracing.Inject(ctx), events[0].TraceContext)
+}
+
+func (s *busSuite) Test_Bus_WriteEvent_Compressed() {
+	streamName := "stream-name"
+	streamID := "stream-id"
+	evtBody := `{"k": "v"}`
+	s.bus.streams[streamName] = models.Stream{ID: streamID, Compression: "zstd"}
+
+	err := s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(evtBody))
+
+	s.Require().NoError(err)
+	events := s.fetchEvents()
+	s.Require().Len(events, 1)
+	s.Equal("zstd", events[0].Compression)
 	s.JSONEq(evtBody, string(events[0].Body))
 }
 
+func (s *busSuite) Test_Bus_WriteEvent_Schema() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	s.bus.schemas[streamName], _ = schema.Compile([]byte(testSchema))
+
+	err := s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": "v"}`))
+
+	s.Require().NoError(err)
+	s.Len(s.fetchEvents(), 1)
+}
+
+func (s *busSuite) Test_Bus_WriteEvent_EventValidationError() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	s.bus.schemas[streamName], _ = schema.Compile([]byte(testSchema))
+	expected := models.EventValidationError{
+		Violations: []schema.Violation{{Path: "k", Message: "Invalid type. Expected: string, given: integer"}},
+	}
+
+	err := s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": 1}`))
+
+	s.Equal(expected, err)
+	s.Empty(s.fetchEvents())
+}
+
+func (s *busSuite) Test_Bus_WriteData_Schema() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	s.bus.schemas[streamName], _ = schema.Compile([]byte(testSchema))
+
+	err := s.bus.WriteData(testCtx, testClient, streamName, "text/plain", []byte("not validated"))
+
+	s.Require().NoError(err)
+	s.Len(s.fetchEvents(), 1)
+}
+
+func (s *busSuite) Test_Bus_WriteData_Success() {
+	streamName := "stream-name"
+	streamID := "stream-id"
+	data := []byte{0, 1, 2, 255}
+	s.bus.streams[streamName] = models.Stream{ID: streamID}
+
+	err := s.bus.WriteData(testCtx, testClient, streamName, "application/x-protobuf", data)
+
+	s.Require().NoError(err)
+	events := s.fetchEvents()
+	s.Require().Len(events, 1)
+	s.Equal(streamID, events[0].StreamID)
+	s.Equal("application/x-protobuf", events[0].ContentType)
+	s.Equal(data, events[0].Data)
+	s.Nil(events[0].Body)
+}
+
+func (s *busSuite) Test_Bus_WriteData_DefaultContentType() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+
+	err := s.bus.WriteData(testCtx, testClient, streamName, "", nil)
+
+	s.Require().NoError(err)
+	events := s.fetchEvents()
+	s.Require().Len(events, 1)
+	s.Equal("application/octet-stream", events[0].ContentType)
+	s.Equal([]byte{}, events[0].Data)
+}
+
+func (s *busSuite) Test_Bus_WriteData_StreamNotFoundError() {
+	err := s.bus.WriteData(testCtx, testClient, "stream-name", "", []byte{1})
+
+	s.EqualError(err, "stream 'stream-name' not found")
+	s.Empty(s.fetchEvents())
+}
+
 func (s *busSuite) Test_Bus_WriteEvent_StreamNotFoundError() {
-	err := s.bus.WriteEvent("stream-name", []byte(`{"k", "v"}`))
+	err := s.bus.WriteEvent(testCtx, testClient, "stream-name", []byte(`{"k", "v"}`))
 
 	s.EqualError(err, "stream 'stream-name' not found")
 	s.Empty(s.fetchEvents())
@@ -289,7 +564,7 @@ func (s *busSuite) Test_Bus_MarkEvent_Success() {
 	s.setEvents(evt)
 	s.bus.streams[streamName] = models.Stream{ID: streamID}
 
-	err := s.bus.MarkEvent(evtID, 1)
+	err := s.bus.MarkEvent(testCtx, testClient, evtID, 1)
 
 	s.Require().NoError(err)
 	events := s.fetchEvents()
@@ -299,7 +574,7 @@ func (s *busSuite) Test_Bus_MarkEvent_Success() {
 }
 
 func (s *busSuite) Test_Bus_MarkEvent_EventNotFound() {
-	err := s.bus.MarkEvent("evt-id", 1)
+	err := s.bus.MarkEvent(testCtx, testClient, "evt-id", 1)
 
 	s.EqualError(err, "event 'evt-id' not found")
 }
@@ -347,12 +622,12 @@ func (s *busSuite) Test_Bus_ProcessEvents_Success() {
 	s.bus.streams[stream1.Name] = stream1
 	s.bus.streams[stream2.Name] = stream2
 
-	events, err := s.bus.ProcessEvents(streamName, false)
+	events, err := s.bus.ProcessEvents(testCtx, streamName, false)
 
 	s.Require().NoError(err)
 	s.Equal([]models.Event{s1Evt1}, events)
 
-	events, err = s.bus.ProcessEvents(streamName, true)
+	events, err = s.bus.ProcessEvents(testCtx, streamName, true)
 
 	s.Require().NoError(err)
 	s.Equal([]models.Event{s1Evt3}, events)
@@ -370,21 +645,40 @@ func (s *busSuite) Test_Bus_ProcessEvents_EmptyResult() {
 	s.setEvents(evt)
 	s.bus.streams[streamName] = models.Stream{ID: streamID}
 
-	events, err := s.bus.ProcessEvents(streamName, false)
+	events, err := s.bus.ProcessEvents(testCtx, streamName, false)
 
 	s.Require().NoError(err)
 	s.Empty(events)
 }
 
 func (s *busSuite) Test_Bus_ProcessEvents_StreamNotFound() {
-	events, err := s.bus.ProcessEvents("non-existent-stream", false)
+	events, err := s.bus.ProcessEvents(testCtx, "non-existent-stream", false)
 
 	s.EqualError(err, "stream 'non-existent-stream' not found")
 	s.Empty(events)
 }
 
+func (s *busSuite) Test_Bus_Backlog_Success() {
+	s.bus.streams["s1-name"] = models.Stream{ID: "s1-id", Name: "s1-name"}
+	s.bus.streams["s2-name"] = models.Stream{ID: "s2-id", Name: "s2-name"}
+	s.setEvents(
+		models.Event{ID: "evt1-id", StreamID: "s1-id", Body: []byte("{}"), Status: models.EventUnprocessedStatus},
+		models.Event{ID: "evt2-id", StreamID: "s1-id", Body: []byte("{}"), Status: models.EventUnprocessedStatus},
+		models.Event{ID: "evt3-id", StreamID: "s1-id", Body: []byte("{}"), Status: models.EventProcessedStatus},
+		models.Event{ID: "evt4-id", StreamID: "s1-id", Body: []byte("{}"), Status: models.EventRetryStatus},
+	)
+
+	backlogs, err := s.bus.Backlog()
+
+	s.Require().NoError(err)
+	s.Equal(map[string]models.Backlog{
+		"s1-name": {Unprocessed: 2, Retry: 1},
+		"s2-name": {},
+	}, backlogs)
+}
+
 func (s *busSuite) setRaw(key, value []byte) {
-	s.Require().NoError(s.db.txn(true, s.db.set(key, value, 0)))
+	s.Require().NoError(s.db.txn(testCtx, true, s.db.set(key, value, 0)))
 }
 
 func (s *busSuite) setStreams(streams ...models.Stream) {
@@ -406,7 +700,7 @@ func (s *busSuite) fetchEvents() []models.Event {
 		evt := result.item.(*models.Event)
 		events = append(events, *evt)
 	})
-	s.Require().NoError(s.db.txn(false, txn))
+	s.Require().NoError(s.db.txn(testCtx, false, txn))
 	return events
 }
 
@@ -3,12 +3,19 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
 	"github.com/dgraph-io/badger/v2"
 	"github.com/dgraph-io/badger/v2/pb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/go-web-dev/event-bus/logging"
 	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/tracing"
 )
 
 // DB represents the file database that stores streams and events
@@ -16,6 +23,9 @@ type DB interface {
 	NewTransaction(update bool) *badger.Txn
 	NewStream() *badger.Stream
 	DropAll() error
+	DropPrefix(prefixes ...[]byte) error
+	Backup(w io.Writer, since uint64) (uint64, error)
+	Load(r io.Reader, maxPendingWrites int) error
 	Close() error
 }
 
@@ -24,13 +34,22 @@ type db struct {
 }
 
 type fetchResult struct {
-	item interface{}
-	key  []byte
+	item      interface{}
+	key       []byte
+	expiresAt uint64
 }
 
 type transactionFunc func(txn *badger.Txn) error
 
-func (d db) txn(update bool, transactions ...transactionFunc) error {
+func (d db) txn(ctx context.Context, update bool, transactions ...transactionFunc) (err error) {
+	_, span := tracing.Start(ctx, "badger.txn", trace.SpanKindInternal,
+		attribute.String("db.system", "badger"),
+		attribute.Bool("db.update", update),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	transaction := d.NewTransaction(update)
 	for _, txn := range transactions {
 		err := txn(transaction)
@@ -51,14 +70,18 @@ func (d db) fetch(key string, obj interface{}, cb func(fetchResult)) transaction
 		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
 			item := it.Item()
 			err := item.Value(func(val []byte) error {
-				err := json.Unmarshal(val, obj)
+				// the previous item must not leak into the next one through absent fields or reused buffers
+				target := reflect.ValueOf(obj).Elem()
+				target.Set(reflect.Zero(target.Type()))
+				err := decodeValue(val, obj)
 				if err != nil {
 					logger.Error("could not unmarshal value from db", zap.Error(err))
 					return err
 				}
 				res := fetchResult{
-					key:  item.Key(),
-					item: obj,
+					key:       item.Key(),
+					item:      obj,
+					expiresAt: item.ExpiresAt(),
 				}
 				cb(res)
 				return nil
@@ -71,6 +94,36 @@ func (d db) fetch(key string, obj interface{}, cb func(fetchResult)) transaction
 	}
 }
 
+func (d db) count(key string, n *int64) transactionFunc {
+	return func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		prefix := []byte(key)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			*n++
+		}
+		return nil
+	}
+}
+
+// eventStreams maps the ID of every stored event to the ID of its stream, out of the event keys only
+func (d db) eventStreams(streams map[string]string) transactionFunc {
+	return func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		prefix := []byte("event:")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			keys := strings.Split(string(it.Item().Key()), ":")
+			streams[keys[len(keys)-1]] = keys[1]
+		}
+		return nil
+	}
+}
+
 func (d db) delete(keys ...[]byte) transactionFunc {
 	return func(txn *badger.Txn) error {
 		for _, key := range keys {
@@ -94,9 +147,17 @@ func (d db) set(key, value []byte, expiresAt uint64) transactionFunc {
 	}
 }
 
-func (d db) streamEvents(key string, chooseKeyFunc func(item *badger.Item) bool) ([]models.Event, error) {
+func (d db) streamEvents(ctx context.Context, key string, chooseKeyFunc func(item *badger.Item) bool) (events []models.Event, err error) {
+	ctx, span := tracing.Start(ctx, "badger.stream", trace.SpanKindInternal,
+		attribute.String("db.system", "badger"),
+		attribute.String("db.prefix", key),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	logger := logging.Logger
-	events := make([]models.Event, 0)
+	events = make([]models.Event, 0)
 	stream := d.NewStream()
 	stream.NumGo = 16
 	stream.Prefix = []byte(key)
@@ -104,8 +165,7 @@ func (d db) streamEvents(key string, chooseKeyFunc func(item *badger.Item) bool)
 
 	stream.Send = func(list *pb.KVList) error {
 		for _, v := range list.Kv {
-			var evt models.Event
-			err := json.Unmarshal(v.Value, &evt)
+			evt, err := models.DecodeEvent(v.Value)
 			if err != nil {
 				logger.Error("could not unmarshal message", zap.Error(err))
 				continue
@@ -115,8 +175,21 @@ func (d db) streamEvents(key string, chooseKeyFunc func(item *badger.Item) bool)
 		return nil
 	}
 
-	if err := stream.Orchestrate(context.Background()); err != nil {
+	if err := stream.Orchestrate(ctx); err != nil {
 		return []models.Event{}, err
 	}
 	return events, nil
 }
+
+// decodeValue decodes a stored value, events being the only ones which may not be JSON
+func decodeValue(val []byte, obj interface{}) error {
+	if evt, ok := obj.(*models.Event); ok {
+		decoded, err := models.DecodeEvent(val)
+		if err != nil {
+			return err
+		}
+		*evt = decoded
+		return nil
+	}
+	return json.Unmarshal(val, obj)
+}
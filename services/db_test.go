@@ -49,7 +49,7 @@ func (s *dbSuite) Test_db_txn_Success() {
 		return nil
 	}
 
-	err := s.db.txn(true, txn1, txn2, txn3)
+	err := s.db.txn(testCtx, true, txn1, txn2, txn3)
 
 	s.Require().NoError(err)
 	k1, v1, _ := s.get("key1")
@@ -77,7 +77,7 @@ func (s *dbSuite) Test_db_txn_Error() {
 		return nil
 	}
 
-	err := s.db.txn(true, txn1, txn2, txn3)
+	err := s.db.txn(testCtx, true, txn1, txn2, txn3)
 
 	s.Equal(errTest, err)
 	_, _, found := s.get("key1")
@@ -213,7 +213,7 @@ func (s *dbSuite) Test_db_streamEvents_Success() {
 	s.Require().NoError(setTxn.Set(evt2.Key(0), evt2.Value()))
 	s.Require().NoError(setTxn.Commit())
 
-	events, err := s.db.streamEvents("event", nil)
+	events, err := s.db.streamEvents(testCtx, "event", nil)
 
 	s.Require().NoError(err)
 	s.Equal([]models.Event{evt1, evt2}, events)
@@ -235,7 +235,7 @@ func (s *dbSuite) Test_db_streamEvents_ChooseFunc() {
 	s.Require().NoError(setTxn.Set(evt2.Key(0), evt2.Value()))
 	s.Require().NoError(setTxn.Commit())
 
-	events, err := s.db.streamEvents("event", func(item *badger.Item) bool {
+	events, err := s.db.streamEvents(testCtx, "event", func(item *badger.Item) bool {
 		return bytes.Compare(evt1.Key(0), item.Key()) == 0
 	})
 
@@ -248,7 +248,7 @@ func (s *dbSuite) Test_db_streamEvents_Error() {
 	s.Require().NoError(setTxn.Set([]byte("event:broken"), []byte("}")))
 	s.Require().NoError(setTxn.Commit())
 
-	events, err := s.db.streamEvents("event", nil)
+	events, err := s.db.streamEvents(testCtx, "event", nil)
 
 	s.Require().NoError(err)
 	s.Empty(events)
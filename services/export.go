@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/compression"
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
+)
+
+// ExportStream writes a stream as JSON Lines, the stream itself on the first line followed by its events,
+// oldest first, with their IDs, creation times and statuses. It returns the number of exported events
+func (b *Bus) ExportStream(ctx context.Context, streamName string, w io.Writer) (int, error) {
+	logger := logging.Logger
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stream, err := b.streamLookup(streamName)
+	if err != nil {
+		return 0, err
+	}
+	events, err := b.db.streamEvents(ctx, fmt.Sprintf("event:%s:", stream.ID), nil)
+	if err != nil {
+		logger.Error("could not get stream events", zap.Error(err))
+		return 0, err
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+
+	enc := json.NewEncoder(w)
+	err = enc.Encode(stream)
+	if err != nil {
+		return 0, err
+	}
+	for _, evt := range events {
+		err = enc.Encode(evt)
+		if err != nil {
+			return 0, err
+		}
+	}
+	logger.Info("successfully exported stream", zap.String("stream_id", stream.ID), zap.Int("events", len(events)))
+	return len(events), nil
+}
+
+// ExportStreamFile exports a stream into a new file of the backup directory, the file being removed when the export fails
+func (b *Bus) ExportStreamFile(ctx context.Context, streamName, path string) (int, error) {
+	var n int
+	err := b.createFile(path, "export", func(w io.Writer) error {
+		var err error
+		n, err = b.ExportStream(ctx, streamName, w)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ImportStream imports a stream export into the stream of the given name, the exported one by default.
+// A missing stream is created with the exported compression, schema and quota, while the events imported
+// into an existing stream have to match its schema and fit within its quota. The events keep their IDs,
+// creation times and statuses: events already in the stream are skipped, so importing twice is harmless,
+// and events whose ID is taken by another stream get a new one derived from it. Events past their retention are skipped too.
+// The imported events count towards the quota of the client which produced them, as the written ones do.
+// The whole export is validated before any event is imported, the imported events are not published to the subscribers
+func (b *Bus) ImportStream(ctx context.Context, streamName string, r io.Reader) (models.ImportResult, error) {
+	logger := logging.Logger
+	exported, events, err := readExport(r)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+	if streamName == "" {
+		streamName = exported.Name
+	}
+	if streamName == "" {
+		return models.ImportResult{}, models.InvalidImportError{Line: 1, Reason: "stream name is required"}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stream, exists := b.streams[streamName]
+	eventSchema := b.schemas[streamName]
+	if !exists {
+		stream, eventSchema, err = newImportedStream(streamName, exported)
+		if err != nil {
+			return models.ImportResult{}, err
+		}
+	}
+	eventStreams := map[string]string{}
+	err = b.db.txn(ctx, false, b.db.eventStreams(eventStreams))
+	if err != nil {
+		logger.Error("could not fetch event ids", zap.Error(err))
+		return models.ImportResult{}, err
+	}
+
+	res := models.ImportResult{Stream: stream}
+	now := time.Now()
+	b.expireUsage(now)
+	usage := b.streamUsage[stream.ID]
+	producerUsage := map[string]models.Usage{}
+	imported := make([]models.Event, 0, len(events))
+	for i, evt := range events {
+		renamed := false
+		if streamID, ok := eventStreams[evt.ID]; ok && streamID != stream.ID {
+			// the new ID derives from the original one, so that importing twice still skips the event
+			evt.ID = uuid.NewSHA1(uuid.Nil, []byte(stream.ID+":"+evt.ID)).String()
+			renamed = true
+		}
+		if streamID, ok := eventStreams[evt.ID]; ok && streamID == stream.ID {
+			res.Skipped++
+			continue
+		}
+		if int64(evt.ExpiresAt()) <= now.Unix() {
+			res.Skipped++
+			continue
+		}
+		if renamed {
+			res.Renamed++
+		}
+		eventStreams[evt.ID] = stream.ID
+
+		if evt.IsJSON() {
+			err = validateBody(eventSchema, evt.Body)
+			if err != nil {
+				// the export line of the event, the stream taking the first one
+				return models.ImportResult{}, models.InvalidImportError{Line: i + 2, Reason: err.Error()}
+			}
+		}
+		size := int64(len(evt.Payload()))
+		clientQuota := b.quotas.Clients[evt.Producer]
+		for _, maxSize := range []int64{b.quotas.MaxEventSize, stream.MaxEventSize, clientQuota.MaxEventSize} {
+			if maxSize > 0 && size > maxSize {
+				return models.ImportResult{}, models.EventTooLargeError{Size: size, MaxSize: maxSize}
+			}
+		}
+		if quota, limit := stream.Exceeded(usage, size); quota != "" {
+			return models.ImportResult{}, models.QuotaExceededError{Stream: stream.Name, Quota: quota, Limit: limit}
+		}
+		usage = addUsage(usage, 1, size)
+		if evt.Producer != "" {
+			if _, ok := producerUsage[evt.Producer]; !ok {
+				producerUsage[evt.Producer] = b.clientUsage[evt.Producer]
+			}
+			if quota, limit := clientQuota.Exceeded(producerUsage[evt.Producer], size); quota != "" {
+				return models.ImportResult{}, models.QuotaExceededError{Client: evt.Producer, Quota: quota, Limit: limit}
+			}
+			producerUsage[evt.Producer] = addUsage(producerUsage[evt.Producer], 1, size)
+		}
+
+		evt.StreamID = stream.ID
+		evt.Compression = stream.Compression
+		imported = append(imported, evt)
+	}
+
+	if !exists {
+		err = b.db.txn(ctx, true, b.db.set(stream.Key(), stream.Value(), 0))
+		if err != nil {
+			logger.Debug("could not save stream to db", zap.Error(err))
+			return models.ImportResult{}, err
+		}
+		b.streams[streamName] = stream
+		b.setSchema(streamName, eventSchema)
+	}
+	// every event has its own transaction, an import being as large as the stream it comes from
+	for _, evt := range imported {
+		err = b.db.txn(ctx, true, b.db.set(evt.Key(evt.Status), evt.Value(), evt.ExpiresAt()))
+		if err != nil {
+			logger.Error("could not import event", zap.String("event_id", evt.ID), zap.Error(err))
+			return res, err
+		}
+		b.addEventUsage(evt)
+		res.Imported++
+	}
+	logger.Info(
+		"successfully imported stream",
+		zap.String("stream_id", stream.ID),
+		zap.Int("imported", res.Imported),
+		zap.Int("skipped", res.Skipped),
+	)
+	return res, nil
+}
+
+// ImportStreamFile imports a stream export file of the backup directory
+func (b *Bus) ImportStreamFile(ctx context.Context, streamName, path string) (models.ImportResult, error) {
+	var res models.ImportResult
+	err := b.openFile(path, "import", func(r io.ReadSeeker) error {
+		var err error
+		res, err = b.ImportStream(ctx, streamName, r)
+		return err
+	})
+	return res, err
+}
+
+// readExport reads the stream and the events of a stream export, one JSON record per line
+func readExport(r io.Reader) (models.Stream, []models.Event, error) {
+	dec := json.NewDecoder(r)
+	var stream models.Stream
+	err := dec.Decode(&stream)
+	if err == io.EOF {
+		return models.Stream{}, nil, models.InvalidImportError{Line: 1, Reason: "stream is required"}
+	}
+	if err != nil {
+		return models.Stream{}, nil, models.InvalidImportError{Line: 1, Reason: err.Error()}
+	}
+
+	events := make([]models.Event, 0)
+	for line := 2; ; line++ {
+		var evt models.Event
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return models.Stream{}, nil, models.InvalidImportError{Line: line, Reason: err.Error()}
+		}
+		if evt.ID == "" || evt.CreatedAt.IsZero() {
+			return models.Stream{}, nil, models.InvalidImportError{Line: line, Reason: "event id and created_at are required"}
+		}
+		if _, ok := models.AllowedEventStatus[evt.Status]; !ok {
+			return models.Stream{}, nil, models.InvalidImportError{Line: line, Reason: models.InvalidEventStatusError{}.Error()}
+		}
+		events = append(events, evt)
+	}
+	return stream, events, nil
+}
+
+// newImportedStream creates the stream an export is imported into, out of the exported one
+func newImportedStream(streamName string, exported models.Stream) (models.Stream, *schema.Schema, error) {
+	if !compression.Supported(exported.Compression) {
+		return models.Stream{}, nil, models.UnsupportedCompressionError{}
+	}
+	err := exported.Quota.Validate()
+	if err != nil {
+		return models.Stream{}, nil, err
+	}
+	compiledSchema, err := compileSchema(exported.Schema)
+	if err != nil {
+		return models.Stream{}, nil, err
+	}
+	stream := models.Stream{
+		Name:        streamName,
+		ID:          uuid.New().String(),
+		CreatedAt:   time.Now().UTC(),
+		Compression: exported.Compression,
+		Quota:       exported.Quota,
+	}
+	if compiledSchema != nil {
+		stream.Schema = exported.Schema
+	}
+	return stream, compiledSchema, nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *busSuite) Test_Bus_ExportStream_Success() {
+	stream := models.Stream{ID: "stream-id", Name: "stream-name", CreatedAt: testTime}
+	evt1 := models.Event{ID: "evt1-id", StreamID: stream.ID, CreatedAt: testTime, Body: json.RawMessage(`{"n":1}`)}
+	evt2 := models.Event{ID: "evt2-id", StreamID: stream.ID, CreatedAt: testTime.Add(time.Second), Status: models.EventProcessedStatus, Body: json.RawMessage(`{"n":2}`)}
+	s.setEvents(evt2, evt1)
+	s.bus.streams[stream.Name] = stream
+	var export bytes.Buffer
+
+	n, err := s.bus.ExportStream(testCtx, stream.Name, &export)
+
+	s.Require().NoError(err)
+	s.Equal(2, n)
+	lines := strings.Split(strings.TrimSpace(export.String()), "\n")
+	s.Require().Len(lines, 3)
+	s.JSONEq(string(stream.Value()), lines[0])
+	s.JSONEq(string(evt1.Value()), lines[1])
+	s.JSONEq(string(evt2.Value()), lines[2])
+}
+
+func (s *busSuite) Test_Bus_ExportStream_StreamNotFoundError() {
+	n, err := s.bus.ExportStream(testCtx, "stream-name", &bytes.Buffer{})
+
+	s.Equal(models.StreamNotFoundError{Name: "stream-name"}, err)
+	s.Zero(n)
+}
+
+func (s *busSuite) Test_Bus_ImportStream_NewStream() {
+	createdAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	export := `{"id":"source-id","name":"source","created_at":"2020-12-15T05:28:31Z","compression":"snappy","schema":` + testSchema + `,"max_events":10}
+{"id":"evt1-id","stream_id":"source-id","status":1,"created_at":"` + createdAt.Format(time.RFC3339) + `","producer":"producer","body":{"k":"v1"}}
+{"id":"evt2-id","stream_id":"source-id","status":0,"created_at":"` + createdAt.Format(time.RFC3339) + `","body":{"k":"v2"}}
+`
+
+	res, err := s.bus.ImportStream(testCtx, "target", strings.NewReader(export))
+
+	s.Require().NoError(err)
+	s.Equal(2, res.Imported)
+	s.Zero(res.Skipped)
+	s.Zero(res.Renamed)
+	stream := s.bus.streams["target"]
+	s.Equal(res.Stream, stream)
+	s.NotEqual("source-id", stream.ID)
+	s.Equal("snappy", stream.Compression)
+	s.Equal(int64(10), stream.MaxEvents)
+	s.Contains(s.bus.schemas, "target")
+	s.Equal(int64(2), s.bus.streamUsage[stream.ID].Events)
+	events, err := s.bus.GetStreamEvents(testCtx, "target")
+	s.Require().NoError(err)
+	s.Require().Len(events, 2)
+	byID := map[string]models.Event{}
+	for _, evt := range events {
+		byID[evt.ID] = evt
+	}
+	s.Equal(models.EventProcessedStatus, byID["evt1-id"].Status)
+	s.Equal("producer", byID["evt1-id"].Producer)
+	s.Equal(createdAt, byID["evt1-id"].CreatedAt)
+	s.Equal(stream.ID, byID["evt2-id"].StreamID)
+	s.JSONEq(`{"k":"v2"}`, string(byID["evt2-id"].Body))
+}
+
+func (s *busSuite) Test_Bus_ExportImportStream_RoundTrip() {
+	_, err := s.bus.CreateStream(testCtx, testClient, "source", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "source", json.RawMessage(`{"k":"v"}`)))
+	s.Require().NoError(s.bus.WriteData(testCtx, testClient, "source", "text/plain", []byte("data")))
+	s.bus.SetBackupDir(s.tempDir())
+	path := "source.jsonl"
+	n, err := s.bus.ExportStreamFile(testCtx, "source", path)
+	s.Require().NoError(err)
+	s.Equal(2, n)
+
+	res, err := s.bus.ImportStreamFile(testCtx, "copy", path)
+
+	s.Require().NoError(err)
+	s.Equal(2, res.Imported)
+	s.Equal(2, res.Renamed)
+	events, err := s.bus.GetStreamEvents(testCtx, "copy")
+	s.Require().NoError(err)
+	s.Len(events, 2)
+	res, err = s.bus.ImportStreamFile(testCtx, "copy", path)
+	s.Require().NoError(err)
+	s.Zero(res.Imported)
+	s.Equal(2, res.Skipped)
+	s.Zero(res.Renamed)
+}
+
+func (s *busSuite) Test_Bus_ImportStream_ExistingStream() {
+	stream := models.Stream{ID: "stream-id", Name: "stream-name", CreatedAt: testTime}
+	s.bus.streams[stream.Name] = stream
+	now := time.Now().UTC().Truncate(time.Second).Format(time.RFC3339)
+	s.setEvents(models.Event{ID: "evt1-id", StreamID: stream.ID, CreatedAt: time.Now(), Body: json.RawMessage(`{}`)})
+	export := `{"name":"source"}
+{"id":"evt1-id","created_at":"` + now + `","body":{}}
+{"id":"evt2-id","created_at":"` + now + `","body":{}}
+{"id":"evt3-id","created_at":"2020-12-15T05:28:31Z","body":{}}
+`
+
+	res, err := s.bus.ImportStream(testCtx, stream.Name, strings.NewReader(export))
+
+	s.Require().NoError(err)
+	s.Equal(models.ImportResult{Stream: stream, Imported: 1, Skipped: 2}, res)
+	s.Len(s.fetchEvents(), 2)
+	s.NotContains(s.bus.streams, "source")
+}
+
+func (s *busSuite) Test_Bus_ImportStream_ValidationError() {
+	stream := models.Stream{ID: "stream-id", Name: "stream-name", CreatedAt: testTime}
+	s.setStreams(stream)
+	s.Require().NoError(s.bus.Init())
+	_, err := s.bus.UpdateStream(testCtx, testClient, stream.Name, json.RawMessage(testSchema), models.Quota{})
+	s.Require().NoError(err)
+	now := time.Now().UTC().Format(time.RFC3339)
+	export := `{"name":"source"}
+{"id":"evt1-id","created_at":"` + now + `","body":{"k":"v"}}
+{"id":"evt2-id","created_at":"` + now + `","body":{}}
+`
+
+	res, err := s.bus.ImportStream(testCtx, stream.Name, strings.NewReader(export))
+
+	s.Equal(models.InvalidImportError{Line: 3, Reason: "event does not match the stream schema"}, err)
+	s.Zero(res)
+	s.Empty(s.fetchEvents())
+}
+
+func (s *busSuite) Test_Bus_ImportStream_QuotaExceededError() {
+	now := time.Now().UTC().Format(time.RFC3339)
+	export := `{"name":"source","max_events":1}
+{"id":"evt1-id","created_at":"` + now + `","body":{}}
+{"id":"evt2-id","created_at":"` + now + `","body":{}}
+`
+
+	res, err := s.bus.ImportStream(testCtx, "", strings.NewReader(export))
+
+	s.Equal(models.QuotaExceededError{Stream: "source", Quota: "max_events", Limit: 1}, err)
+	s.Zero(res)
+	s.Empty(s.bus.streams)
+}
+
+func (s *busSuite) Test_Bus_ImportStream_ClientQuotaExceededError() {
+	s.bus.quotas = Quotas{Clients: map[string]models.Quota{testClient: {MaxEvents: 2}}}
+	_, err := s.bus.CreateStream(testCtx, testClient, "stream-name", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name", json.RawMessage(`{}`)))
+	now := time.Now().UTC().Format(time.RFC3339)
+	export := `{"name":"source"}
+{"id":"evt1-id","created_at":"` + now + `","producer":"` + testClient + `","body":{}}
+{"id":"evt2-id","created_at":"` + now + `","producer":"` + testClient + `","body":{}}
+`
+
+	res, err := s.bus.ImportStream(testCtx, "", strings.NewReader(export))
+
+	s.Equal(models.QuotaExceededError{Client: testClient, Quota: "max_events", Limit: 2}, err)
+	s.Zero(res)
+	s.Equal(models.Usage{Events: 1, Bytes: 2}, s.bus.clientUsage[testClient])
+}
+
+func (s *busSuite) Test_Bus_ImportStream_InvalidImportError() {
+	tests := []struct {
+		name     string
+		export   string
+		expected models.InvalidImportError
+	}{
+		{
+			name:     "empty",
+			export:   "",
+			expected: models.InvalidImportError{Line: 1, Reason: "stream is required"},
+		},
+		{
+			name:     "stream name",
+			export:   `{"id":"source-id"}`,
+			expected: models.InvalidImportError{Line: 1, Reason: "stream name is required"},
+		},
+		{
+			name:     "invalid json",
+			export:   "{\"name\":\"source\"}\n{",
+			expected: models.InvalidImportError{Line: 2, Reason: "unexpected EOF"},
+		},
+		{
+			name:     "event id",
+			export:   "{\"name\":\"source\"}\n{\"body\":{}}",
+			expected: models.InvalidImportError{Line: 2, Reason: "event id and created_at are required"},
+		},
+		{
+			name:     "event status",
+			export:   "{\"name\":\"source\"}\n{\"id\":\"evt-id\",\"status\":7,\"created_at\":\"2020-12-15T05:28:31Z\"}",
+			expected: models.InvalidImportError{Line: 2, Reason: models.InvalidEventStatusError{}.Error()},
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			res, err := s.bus.ImportStream(testCtx, "", strings.NewReader(test.export))
+
+			s.Equal(test.expected, err)
+			s.Zero(res)
+		})
+	}
+}
+
+func (s *busSuite) Test_Bus_ImportStreamFile_OpenError() {
+	s.bus.SetBackupDir(s.tempDir())
+
+	res, err := s.bus.ImportStreamFile(testCtx, "", "missing.jsonl")
+
+	s.Equal(models.Error{Message: "could not open import file"}, err)
+	s.Zero(res)
+}
+
+func (s *busSuite) Test_Bus_ExportStreamFile_InvalidPathError() {
+	s.bus.SetBackupDir(s.tempDir())
+
+	n, err := s.bus.ExportStreamFile(testCtx, "source", "../source.jsonl")
+
+	s.Equal(models.InvalidPathError{Path: "../source.jsonl"}, err)
+	s.Zero(n)
+}
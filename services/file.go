@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+)
+
+// SetBackupDir sets the directory the backup and export files are written to and read from on the server.
+// Files cannot be written nor read until it is set, it must be set before serving any request
+func (b *Bus) SetBackupDir(dir string) {
+	b.backupDir = dir
+}
+
+// filePath resolves a path relative to the backup directory, refusing the ones out of it
+func (b *Bus) filePath(path, kind string) (string, error) {
+	if b.backupDir == "" {
+		return "", models.Error{Message: fmt.Sprintf("%s files are disabled, no backup directory is configured", kind)}
+	}
+	clean := filepath.Clean(path)
+	if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", models.InvalidPathError{Path: path}
+	}
+	return filepath.Join(b.backupDir, clean), nil
+}
+
+// createFile creates a new file in the backup directory and writes it, the file being removed when writing fails.
+// Existing files are never overwritten. The server paths are only logged,
+// the errors only telling the kind of file which could not be created
+func (b *Bus) createFile(path, kind string, write func(w io.Writer) error) error {
+	logger := logging.Logger
+	fullPath, err := b.filePath(path, kind)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(fullPath), 0700)
+	if err != nil {
+		logger.Error("could not create directory", zap.String("path", fullPath), zap.Error(err))
+		return models.Error{Message: fmt.Sprintf("could not create %s file", kind)}
+	}
+	f, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return models.Error{Message: fmt.Sprintf("%s file already exists", kind)}
+	}
+	if err != nil {
+		logger.Error("could not create file", zap.String("path", fullPath), zap.Error(err))
+		return models.Error{Message: fmt.Sprintf("could not create %s file", kind)}
+	}
+	err = write(f)
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(fullPath)
+		return err
+	}
+	return nil
+}
+
+// openFile opens a file of the backup directory and reads it
+func (b *Bus) openFile(path, kind string, read func(r io.ReadSeeker) error) error {
+	fullPath, err := b.filePath(path, kind)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fullPath)
+	if err != nil {
+		logging.Logger.Error("could not open file", zap.String("path", fullPath), zap.Error(err))
+		return models.Error{Message: fmt.Sprintf("could not open %s file", kind)}
+	}
+	defer f.Close()
+	return read(f)
+}
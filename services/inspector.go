@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/badger/v2"
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+)
+
+const (
+	streamPrefix = "stream:"
+	eventPrefix  = "event:"
+	schemaPrefix = "schema:"
+)
+
+// errStopScan stops scanning the keys without failing the scan
+var errStopScan = errors.New("stop scan")
+
+// Inspector inspects and repairs the stored data while the server is stopped.
+// It works on the raw keys rather than on the loaded streams, so that it never fails on the data the Event Bus cannot load
+type Inspector struct {
+	db db
+}
+
+// NewInspector creates a new instance of Inspector on the given database
+func NewInspector(d DB) *Inspector {
+	return &Inspector{
+		db: db{DB: d},
+	}
+}
+
+// Streams lists the stored streams sorted by name, with their events counted by status out of the event keys.
+// The streams which cannot be decoded are left out, Verify reports them
+func (i *Inspector) Streams(ctx context.Context) ([]models.StoredStream, error) {
+	logger := logging.Logger
+	streams := make([]models.StoredStream, 0)
+	err := i.db.txn(ctx, false, i.db.scan(streamPrefix, true, func(key, val []byte) error {
+		var stream models.Stream
+		err := json.Unmarshal(val, &stream)
+		if err != nil {
+			logger.Warn("could not unmarshal stream", zap.ByteString("key", key), zap.Error(err))
+			return nil
+		}
+		streams = append(streams, models.StoredStream{Stream: stream})
+		return nil
+	}))
+	if err != nil {
+		logger.Error("could not fetch streams", zap.Error(err))
+		return nil, err
+	}
+
+	counts := make([]transactionFunc, 0, 3*len(streams))
+	for j := range streams {
+		s := &streams[j]
+		counts = append(
+			counts,
+			i.db.count(fmt.Sprintf("%s%s:%d:", eventPrefix, s.Stream.ID, models.EventUnprocessedStatus), &s.Unprocessed),
+			i.db.count(fmt.Sprintf("%s%s:%d:", eventPrefix, s.Stream.ID, models.EventProcessedStatus), &s.Processed),
+			i.db.count(fmt.Sprintf("%s%s:%d:", eventPrefix, s.Stream.ID, models.EventRetryStatus), &s.Retry),
+		)
+	}
+	err = i.db.txn(ctx, false, counts...)
+	if err != nil {
+		logger.Error("could not count stream events", zap.Error(err))
+		return nil, err
+	}
+	sort.Slice(streams, func(a, b int) bool {
+		return streams[a].Stream.Name < streams[b].Stream.Name
+	})
+	return streams, nil
+}
+
+// Dump calls fn with every key starting with the prefix, in key order, up to limit keys unless limit is zero.
+// Only the keys are read when values is not set
+func (i *Inspector) Dump(ctx context.Context, prefix string, values bool, limit int, fn func(models.StoredKey) error) error {
+	n := 0
+	err := i.db.txn(ctx, false, i.db.scan(prefix, values, func(key, val []byte) error {
+		if limit > 0 && n == limit {
+			return errStopScan
+		}
+		n++
+		storedKey := models.StoredKey{Key: string(key)}
+		if values {
+			storedKey.Value = jsonValue(storedKey.Key, val)
+		}
+		return fn(storedKey)
+	}))
+	if err != nil && err != errStopScan {
+		logging.Logger.Error("could not dump keys", zap.String("prefix", prefix), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Verify checks every stored key, reporting the events whose stream no longer exists
+// and the values which cannot be decoded
+func (i *Inspector) Verify(ctx context.Context) (models.IntegrityReport, error) {
+	return i.check(ctx, false)
+}
+
+// Purge deletes the events whose stream no longer exists, along with the values which cannot be decoded
+// when unparseable is set, the events of such streams included. It reports the deleted keys.
+// Audit log entries are never deleted, the audit log being append-only
+func (i *Inspector) Purge(ctx context.Context, unparseable bool) (models.IntegrityReport, error) {
+	logger := logging.Logger
+	report, err := i.check(ctx, unparseable)
+	if err != nil {
+		return models.IntegrityReport{}, err
+	}
+
+	keys := make([][]byte, 0, len(report.Orphaned))
+	for _, issue := range report.Orphaned {
+		keys = append(keys, []byte(issue.Key))
+	}
+	purged := make([]models.IntegrityIssue, 0)
+	if unparseable {
+		for _, issue := range report.Unparseable {
+			if strings.HasPrefix(issue.Key, auditPrefix) {
+				continue
+			}
+			keys = append(keys, []byte(issue.Key))
+			purged = append(purged, issue)
+		}
+	}
+	report.Unparseable = purged
+
+	err = i.db.deleteAll(keys)
+	if err != nil {
+		logger.Error("could not purge keys", zap.Error(err))
+		return models.IntegrityReport{}, err
+	}
+	logger.Info("successfully purged keys", zap.Int("orphaned", len(report.Orphaned)), zap.Int("unparseable", len(purged)))
+	return report, nil
+}
+
+// check checks every stored key. Streams which cannot be decoded are taken for missing ones
+// when dropUnparseable is set, their events being orphaned then
+func (i *Inspector) check(ctx context.Context, dropUnparseable bool) (models.IntegrityReport, error) {
+	report := models.IntegrityReport{
+		Orphaned:    make([]models.IntegrityIssue, 0),
+		Unparseable: make([]models.IntegrityIssue, 0),
+	}
+	streamIDs := map[string]bool{}
+	// event keys by stream ID, the stream keys coming after the event ones
+	events := map[string][]string{}
+	err := i.db.txn(ctx, false, i.db.scan("", true, func(key, val []byte) error {
+		report.Keys++
+		k := string(key)
+		err := decodeStored(k, val)
+		if err != nil {
+			report.Unparseable = append(report.Unparseable, models.IntegrityIssue{Key: k, Reason: err.Error()})
+			if dropUnparseable || !strings.HasPrefix(k, streamPrefix) {
+				return nil
+			}
+		}
+		switch {
+		case strings.HasPrefix(k, streamPrefix):
+			streamIDs[strings.TrimPrefix(k, streamPrefix)] = true
+		case strings.HasPrefix(k, eventPrefix):
+			streamID := strings.Split(k, ":")[1]
+			events[streamID] = append(events[streamID], k)
+		}
+		return nil
+	}))
+	if err != nil {
+		logging.Logger.Error("could not check keys", zap.Error(err))
+		return models.IntegrityReport{}, err
+	}
+
+	for streamID, keys := range events {
+		if streamIDs[streamID] {
+			continue
+		}
+		for _, key := range keys {
+			reason := fmt.Sprintf("stream id '%s' not found", streamID)
+			report.Orphaned = append(report.Orphaned, models.IntegrityIssue{Key: key, Reason: reason})
+		}
+	}
+	sort.Slice(report.Orphaned, func(a, b int) bool {
+		return report.Orphaned[a].Key < report.Orphaned[b].Key
+	})
+	return report, nil
+}
+
+// decodeStored decodes a stored value the way the Event Bus does, the values of unknown keys being left unchecked
+func decodeStored(key string, val []byte) error {
+	switch {
+	case strings.HasPrefix(key, eventPrefix):
+		if len(strings.Split(key, ":")) < 5 {
+			return errors.New("invalid event key")
+		}
+		_, err := models.DecodeEvent(val)
+		return err
+	case strings.HasPrefix(key, streamPrefix):
+		return json.Unmarshal(val, &models.Stream{})
+	case strings.HasPrefix(key, schemaPrefix):
+		return json.Unmarshal(val, &models.EventType{})
+	case strings.HasPrefix(key, auditPrefix):
+		return json.Unmarshal(val, &models.AuditEntry{})
+	}
+	return nil
+}
+
+// jsonValue turns a stored value into JSON, decoding the events and quoting the values which are not JSON
+func jsonValue(key string, val []byte) json.RawMessage {
+	if strings.HasPrefix(key, eventPrefix) {
+		evt, err := models.DecodeEvent(val)
+		if err == nil {
+			if bs, err := json.Marshal(evt); err == nil {
+				return bs
+			}
+		}
+	}
+	if json.Valid(val) {
+		return append(json.RawMessage{}, val...)
+	}
+	bs, _ := json.Marshal(string(val))
+	return bs
+}
+
+// scan calls fn with every key starting with the prefix, along with its value unless values is not set.
+// The key and value are only valid during the call
+func (d db) scan(prefix string, values bool, fn func(key, val []byte) error) transactionFunc {
+	return func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = values
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+			item := it.Item()
+			if !values {
+				err := fn(item.Key(), nil)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			err := item.Value(func(val []byte) error {
+				return fn(item.Key(), val)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// deleteAll deletes the keys in as many transactions as needed
+func (d db) deleteAll(keys [][]byte) error {
+	txn := d.NewTransaction(true)
+	for _, key := range keys {
+		err := txn.Delete(key)
+		if err == badger.ErrTxnTooBig {
+			err = txn.Commit()
+			if err != nil {
+				return err
+			}
+			txn = d.NewTransaction(true)
+			err = txn.Delete(key)
+		}
+		if err != nil {
+			txn.Discard()
+			return err
+		}
+	}
+	return txn.Commit()
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *busSuite) Test_Inspector_Streams() {
+	stream1 := models.Stream{ID: "stream1-id", Name: "stream1", CreatedAt: testTime}
+	stream2 := models.Stream{ID: "stream2-id", Name: "stream2", CreatedAt: testTime}
+	s.setStreams(stream2, stream1)
+	s.setRaw([]byte("stream:broken-id"), []byte("{"))
+	s.setEvents(
+		models.Event{ID: "evt1-id", StreamID: stream1.ID, Status: models.EventUnprocessedStatus, Body: []byte("{}")},
+		models.Event{ID: "evt2-id", StreamID: stream1.ID, Status: models.EventUnprocessedStatus, Body: []byte("{}")},
+		models.Event{ID: "evt3-id", StreamID: stream1.ID, Status: models.EventRetryStatus, Body: []byte("{}")},
+		models.Event{ID: "evt4-id", StreamID: stream2.ID, Status: models.EventProcessedStatus, Body: []byte("{}")},
+	)
+
+	streams, err := NewInspector(s.db).Streams(testCtx)
+
+	s.Require().NoError(err)
+	expected := []models.StoredStream{
+		{Stream: stream1, Unprocessed: 2, Retry: 1},
+		{Stream: stream2, Processed: 1},
+	}
+	s.Equal(expected, streams)
+}
+
+func (s *busSuite) Test_Inspector_Dump() {
+	evt := models.Event{ID: "evt1-id", StreamID: "stream-id", CreatedAt: testTime, ContentType: "text/plain", Data: []byte("hi")}
+	s.setEvents(evt)
+	s.setRaw([]byte("stream:stream-id"), []byte(`{"id":"stream-id"}`))
+	s.setRaw([]byte("stream:x-broken-id"), []byte("{"))
+	s.setRaw([]byte("stream:z-id"), []byte(`{"id":"z-id"}`))
+	keys := make([]models.StoredKey, 0)
+
+	err := NewInspector(s.db).Dump(testCtx, "", true, 3, func(key models.StoredKey) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	s.Require().NoError(err)
+	s.Require().Len(keys, 3)
+	s.Equal(string(evt.Key(evt.Status)), keys[0].Key)
+	s.JSONEq(`{"id":"evt1-id","stream_id":"stream-id","status":0,"created_at":"`+testTimeStr+`","content_type":"text/plain","data":"aGk="}`, string(keys[0].Value))
+	s.Equal(models.StoredKey{Key: "stream:stream-id", Value: []byte(`{"id":"stream-id"}`)}, keys[1])
+	s.Equal(models.StoredKey{Key: "stream:x-broken-id", Value: []byte(`"{"`)}, keys[2])
+}
+
+func (s *busSuite) Test_Inspector_Dump_KeysOnly() {
+	s.setStreams(models.Stream{ID: "stream-id"})
+	s.setEvents(models.Event{ID: "evt1-id", StreamID: "stream-id", Body: []byte("{}")})
+	keys := make([]models.StoredKey, 0)
+
+	err := NewInspector(s.db).Dump(testCtx, "stream:", false, 0, func(key models.StoredKey) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	s.Require().NoError(err)
+	s.Equal([]models.StoredKey{{Key: "stream:stream-id"}}, keys)
+}
+
+func (s *busSuite) Test_Inspector_Verify() {
+	stream := models.Stream{ID: "stream-id", Name: "stream"}
+	evt := models.Event{ID: "evt1-id", StreamID: stream.ID, CreatedAt: testTime, Body: []byte("{}")}
+	orphan := models.Event{ID: "evt2-id", StreamID: "deleted-id", CreatedAt: testTime, Body: []byte("{}")}
+	s.setStreams(stream)
+	s.setEvents(evt, orphan)
+	s.setRaw([]byte("event:stream-id:0:broken"), []byte("{"))
+	s.setRaw([]byte("audit:broken"), []byte("["))
+	s.setRaw([]byte("health:probe"), []byte("probe"))
+
+	report, err := NewInspector(s.db).Verify(testCtx)
+
+	s.Require().NoError(err)
+	s.Equal(6, report.Keys)
+	s.Equal([]models.IntegrityIssue{
+		{Key: string(orphan.Key(orphan.Status)), Reason: "stream id 'deleted-id' not found"},
+	}, report.Orphaned)
+	s.Require().Len(report.Unparseable, 2)
+	s.Equal("audit:broken", report.Unparseable[0].Key)
+	s.Equal(models.IntegrityIssue{Key: "event:stream-id:0:broken", Reason: "invalid event key"}, report.Unparseable[1])
+	s.Equal(3, report.Issues())
+}
+
+func (s *busSuite) Test_Inspector_Verify_UnparseableStream() {
+	evt := models.Event{ID: "evt1-id", StreamID: "stream-id", CreatedAt: testTime, Body: []byte("{}")}
+	s.setRaw([]byte("stream:stream-id"), []byte("{"))
+	s.setEvents(evt)
+
+	report, err := NewInspector(s.db).Verify(testCtx)
+
+	s.Require().NoError(err)
+	s.Empty(report.Orphaned)
+	s.Require().Len(report.Unparseable, 1)
+	s.Equal("stream:stream-id", report.Unparseable[0].Key)
+}
+
+func (s *busSuite) Test_Inspector_Purge() {
+	stream := models.Stream{ID: "stream-id", Name: "stream"}
+	evt := models.Event{ID: "evt1-id", StreamID: stream.ID, CreatedAt: testTime, Body: []byte("{}")}
+	orphan := models.Event{ID: "evt2-id", StreamID: "deleted-id", CreatedAt: testTime, Body: []byte("{}")}
+	s.setStreams(stream)
+	s.setEvents(evt, orphan)
+	s.setRaw([]byte("event:stream-id:0:broken"), []byte("{"))
+
+	report, err := NewInspector(s.db).Purge(testCtx, false)
+
+	s.Require().NoError(err)
+	s.Len(report.Orphaned, 1)
+	s.Empty(report.Unparseable)
+	report, err = NewInspector(s.db).Verify(testCtx)
+	s.Require().NoError(err)
+	s.Empty(report.Orphaned)
+	s.Len(report.Unparseable, 1)
+}
+
+func (s *busSuite) Test_Inspector_Purge_Unparseable() {
+	evt := models.Event{ID: "evt1-id", StreamID: "stream-id", CreatedAt: testTime, Body: []byte("{}")}
+	s.setRaw([]byte("stream:stream-id"), []byte("{"))
+	s.setEvents(evt)
+	s.setRaw([]byte("audit:broken"), []byte("["))
+
+	report, err := NewInspector(s.db).Purge(testCtx, true)
+
+	s.Require().NoError(err)
+	s.Equal([]models.IntegrityIssue{
+		{Key: string(evt.Key(evt.Status)), Reason: "stream id 'stream-id' not found"},
+	}, report.Orphaned)
+	s.Require().Len(report.Unparseable, 1)
+	s.Equal("stream:stream-id", report.Unparseable[0].Key)
+	report, err = NewInspector(s.db).Verify(testCtx)
+	s.Require().NoError(err)
+	s.Equal(1, report.Keys)
+	s.Equal("audit:broken", report.Unparseable[0].Key)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+// Quotas represents the limits enforced on every written event besides the stream quotas
+type Quotas struct {
+	// MaxEventSize is the maximum size in bytes of any event payload, zero means unlimited
+	MaxEventSize int64
+	// Clients holds the quota of every client by name
+	Clients map[string]models.Quota
+}
+
+// initUsage counts the events currently stored for every stream and client.
+// Usage is kept up to date as events are written, deleted and expire
+func (b *Bus) initUsage(ctx context.Context) error {
+	streamUsage := map[string]models.Usage{}
+	clientUsage := map[string]models.Usage{}
+	expiring := newExpiries()
+	var eventVar models.Event
+	txn := b.db.fetch("event:", &eventVar, func(res fetchResult) {
+		evt := res.item.(*models.Event)
+		size := int64(len(evt.Payload()))
+		streamUsage[evt.StreamID] = addUsage(streamUsage[evt.StreamID], 1, size)
+		if evt.Producer != "" {
+			clientUsage[evt.Producer] = addUsage(clientUsage[evt.Producer], 1, size)
+		}
+		expiring.add(*evt, res.expiresAt, size)
+	})
+	err := b.db.txn(ctx, false, txn)
+	if err != nil {
+		return err
+	}
+	b.streamUsage = streamUsage
+	b.clientUsage = clientUsage
+	b.expiries = expiring
+	return nil
+}
+
+// SetClientQuotas replaces the quota of every client by name, e.g. when the clients are reloaded
+func (b *Bus) SetClientQuotas(clients map[string]models.Quota) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.quotas.Clients = clients
+}
+
+// checkQuotas checks that writing an event of the given size to the stream keeps
+// the stream and the client within their quotas, once the usage of the expired events is given back
+func (b *Bus) checkQuotas(client string, stream models.Stream, size int64) error {
+	clientQuota := b.quotas.Clients[client]
+	for _, maxSize := range []int64{b.quotas.MaxEventSize, stream.MaxEventSize, clientQuota.MaxEventSize} {
+		if maxSize > 0 && size > maxSize {
+			return models.EventTooLargeError{Size: size, MaxSize: maxSize}
+		}
+	}
+	b.expireUsage(time.Now())
+	return b.quotaExceeded(client, clientQuota, stream, size)
+}
+
+func (b *Bus) quotaExceeded(client string, clientQuota models.Quota, stream models.Stream, size int64) error {
+	if quota, limit := stream.Exceeded(b.streamUsage[stream.ID], size); quota != "" {
+		return models.QuotaExceededError{Stream: stream.Name, Quota: quota, Limit: limit}
+	}
+	if quota, limit := clientQuota.Exceeded(b.clientUsage[client], size); quota != "" {
+		return models.QuotaExceededError{Client: client, Quota: quota, Limit: limit}
+	}
+	return nil
+}
+
+// addEventUsage accounts for a written event
+func (b *Bus) addEventUsage(evt models.Event) {
+	size := int64(len(evt.Payload()))
+	b.streamUsage[evt.StreamID] = addUsage(b.streamUsage[evt.StreamID], 1, size)
+	if evt.Producer != "" {
+		b.clientUsage[evt.Producer] = addUsage(b.clientUsage[evt.Producer], 1, size)
+	}
+	b.expiries.add(evt, evt.ExpiresAt(), size)
+}
+
+// expireUsage gives back the usage of the events expired by now, Badger no longer returning them
+func (b *Bus) expireUsage(now time.Time) {
+	for key, usage := range b.expiries.expire(now) {
+		b.streamUsage[key.streamID] = addUsage(b.streamUsage[key.streamID], -usage.Events, -usage.Bytes)
+		if key.producer != "" {
+			b.clientUsage[key.producer] = addUsage(b.clientUsage[key.producer], -usage.Events, -usage.Bytes)
+		}
+	}
+}
+
+func addUsage(usage models.Usage, events, bytes int64) models.Usage {
+	usage.Events += events
+	usage.Bytes += bytes
+	return usage
+}
+
+// usageKey identifies the usage of the events of a stream written by a producer
+type usageKey struct {
+	streamID string
+	producer string
+}
+
+// expiries holds the usage of the stored events by the minute they expire in,
+// so that it's given back once they expire without counting the events again
+type expiries struct {
+	minutes minuteHeap
+	usage   map[int64]map[usageKey]models.Usage
+}
+
+func newExpiries() *expiries {
+	return &expiries{
+		usage: map[int64]map[usageKey]models.Usage{},
+	}
+}
+
+// add accounts for an event of the given size until the time it's stored to expire at, if any
+func (e *expiries) add(evt models.Event, expiresAt uint64, size int64) {
+	if expiresAt == 0 {
+		return
+	}
+	// the usage is given back at the end of the minute, once every event expiring in it has
+	minute := (int64(expiresAt) + 59) / 60
+	usage, ok := e.usage[minute]
+	if !ok {
+		usage = map[usageKey]models.Usage{}
+		e.usage[minute] = usage
+		heap.Push(&e.minutes, minute)
+	}
+	key := usageKey{streamID: evt.StreamID, producer: evt.Producer}
+	usage[key] = addUsage(usage[key], 1, size)
+}
+
+// expire removes and gives back the usage of the events expired by now
+func (e *expiries) expire(now time.Time) map[usageKey]models.Usage {
+	expired := map[usageKey]models.Usage{}
+	for len(e.minutes) > 0 && e.minutes[0]*60 <= now.Unix() {
+		minute := heap.Pop(&e.minutes).(int64)
+		for key, usage := range e.usage[minute] {
+			expired[key] = addUsage(expired[key], usage.Events, usage.Bytes)
+		}
+		delete(e.usage, minute)
+	}
+	return expired
+}
+
+// removeStream removes the usage of the events of a deleted stream, which are not going to expire
+func (e *expiries) removeStream(streamID string) {
+	for _, usage := range e.usage {
+		for key := range usage {
+			if key.streamID == streamID {
+				delete(usage, key)
+			}
+		}
+	}
+}
+
+// minuteHeap is a min-heap of the minutes the events expire in
+type minuteHeap []int64
+
+func (h minuteHeap) Len() int            { return len(h) }
+func (h minuteHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h minuteHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minuteHeap) Push(x interface{}) { *h = append(*h, x.(int64)) }
+func (h *minuteHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *busSuite) Test_Bus_WriteEvent_EventTooLargeError() {
+	streamName := "stream-name"
+	_, err := s.bus.CreateStream(testCtx, testClient, streamName, "", nil, models.Quota{MaxEventSize: 10})
+	s.Require().NoError(err)
+	s.bus.quotas = Quotas{MaxEventSize: 5}
+
+	err = s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": "v"}`))
+
+	s.Equal(models.EventTooLargeError{Size: 9, MaxSize: 5}, err)
+	s.bus.quotas = Quotas{}
+	s.NoError(s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": "v"}`)))
+	err = s.bus.WriteData(testCtx, testClient, streamName, "", make([]byte, 11))
+	s.Equal(models.EventTooLargeError{Size: 11, MaxSize: 10}, err)
+}
+
+func (s *busSuite) Test_Bus_WriteEvent_StreamQuotaExceededError() {
+	streamName := "stream-name"
+	_, err := s.bus.CreateStream(testCtx, testClient, streamName, "", nil, models.Quota{MaxEvents: 2, MaxBytes: 13})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": "v"}`)))
+
+	err = s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": "v"}`))
+	s.Equal(models.QuotaExceededError{Stream: streamName, Quota: "max_bytes", Limit: 13}, err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{}`)))
+	err = s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{}`))
+	s.Equal(models.QuotaExceededError{Stream: streamName, Quota: "max_events", Limit: 2}, err)
+
+	stream, err := s.bus.GetStreamInfo(streamName)
+	s.Require().NoError(err)
+	s.Equal(&models.Usage{Events: 2, Bytes: 11}, stream.Usage)
+}
+
+func (s *busSuite) Test_Bus_WriteEvent_ClientQuotaExceededError() {
+	_, err := s.bus.CreateStream(testCtx, testClient, "stream-name1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	_, err = s.bus.CreateStream(testCtx, testClient, "stream-name2", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	s.bus.quotas = Quotas{Clients: map[string]models.Quota{testClient: {MaxEvents: 1}}}
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name1", json.RawMessage(`{}`)))
+
+	err = s.bus.WriteEvent(testCtx, testClient, "stream-name2", json.RawMessage(`{}`))
+
+	s.Equal(models.QuotaExceededError{Client: testClient, Quota: "max_events", Limit: 1}, err)
+	s.NoError(s.bus.WriteEvent(testCtx, "other-client", "stream-name2", json.RawMessage(`{}`)))
+}
+
+func (s *busSuite) Test_Bus_SetClientQuotas() {
+	_, err := s.bus.CreateStream(testCtx, testClient, "stream-name", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	s.bus.quotas = Quotas{MaxEventSize: 1024, Clients: map[string]models.Quota{testClient: {MaxEvents: 1}}}
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name", json.RawMessage(`{}`)))
+
+	s.bus.SetClientQuotas(map[string]models.Quota{testClient: {MaxEvents: 2}})
+
+	s.Equal(int64(1024), s.bus.quotas.MaxEventSize)
+	s.NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name", json.RawMessage(`{}`)))
+}
+
+func (s *busSuite) Test_Bus_DeleteStream_Usage() {
+	_, err := s.bus.CreateStream(testCtx, testClient, "stream-name1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	_, err = s.bus.CreateStream(testCtx, testClient, "stream-name2", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name1", json.RawMessage(`{"k": "v"}`)))
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name2", json.RawMessage(`{}`)))
+
+	s.Require().NoError(s.bus.DeleteStream(testCtx, testClient, "stream-name1"))
+
+	s.Equal(models.Usage{Events: 1, Bytes: 2}, s.bus.clientUsage[testClient])
+	s.Len(s.bus.streamUsage, 1)
+}
+
+func (s *busSuite) Test_Bus_WriteEvent_ExpiredUsage() {
+	stream, err := s.bus.CreateStream(testCtx, testClient, "stream-name", "", nil, models.Quota{MaxEvents: 1})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name", json.RawMessage(`{}`)))
+	s.Require().Error(s.bus.WriteEvent(testCtx, testClient, "stream-name", json.RawMessage(`{}`)))
+
+	s.bus.expireUsage(time.Now().Add(720*time.Hour + time.Minute))
+
+	s.Equal(models.Usage{}, s.bus.streamUsage[stream.ID])
+	s.Equal(models.Usage{}, s.bus.clientUsage[testClient])
+	s.Empty(s.bus.expiries.minutes)
+	s.Empty(s.bus.expiries.usage)
+}
+
+func (s *busSuite) Test_Bus_DeleteStream_ExpiredUsage() {
+	_, err := s.bus.CreateStream(testCtx, testClient, "stream-name1", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	_, err = s.bus.CreateStream(testCtx, testClient, "stream-name2", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name1", json.RawMessage(`{"k": "v"}`)))
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name2", json.RawMessage(`{}`)))
+	s.Require().NoError(s.bus.DeleteStream(testCtx, testClient, "stream-name1"))
+
+	s.bus.expireUsage(time.Now().Add(720*time.Hour + time.Minute))
+
+	s.Equal(models.Usage{}, s.bus.clientUsage[testClient])
+	s.Len(s.bus.streamUsage, 1)
+}
+
+func (s *busSuite) Test_Bus_Init_Usage() {
+	stream, err := s.bus.CreateStream(testCtx, testClient, "stream-name", "", nil, models.Quota{})
+	s.Require().NoError(err)
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, "stream-name", json.RawMessage(`{"k": "v"}`)))
+	s.Require().NoError(s.bus.WriteData(testCtx, "", "stream-name", "", []byte{1, 2}))
+	bus := NewBus(s.db, Quotas{})
+
+	err = bus.Init()
+
+	s.Require().NoError(err)
+	s.Equal(map[string]models.Usage{stream.ID: {Events: 2, Bytes: 11}}, bus.streamUsage)
+	s.Equal(map[string]models.Usage{testClient: {Events: 1, Bytes: 9}}, bus.clientUsage)
+	bus.expireUsage(time.Now().Add(720*time.Hour + time.Minute))
+	s.Equal(models.Usage{}, bus.streamUsage[stream.ID])
+}
+
+func (s *busSuite) Test_Bus_CreateStream_InvalidQuotaError() {
+	stream, err := s.bus.CreateStream(testCtx, testClient, "stream-name", "", nil, models.Quota{MaxEvents: -1})
+
+	s.Equal(models.InvalidQuotaError{}, err)
+	s.Empty(stream)
+}
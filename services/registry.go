@@ -0,0 +1,215 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
+)
+
+// registry holds the versions of every event type registered in the schema registry
+type registry struct {
+	versions map[string][]models.EventType
+	byID     map[string]models.EventType
+	schemas  map[string]*schema.Schema
+}
+
+func newRegistry() registry {
+	return registry{
+		versions: map[string][]models.EventType{},
+		byID:     map[string]models.EventType{},
+		schemas:  map[string]*schema.Schema{},
+	}
+}
+
+func (r registry) add(eventType models.EventType, eventSchema *schema.Schema) {
+	versions := append(r.versions[eventType.Name], eventType)
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	r.versions[eventType.Name] = versions
+	r.byID[eventType.ID] = eventType
+	r.schemas[eventType.ID] = eventSchema
+}
+
+// get gets a version of an event type, zero version meaning the latest one
+func (r registry) get(name string, version int) (models.EventType, bool) {
+	versions := r.versions[name]
+	if len(versions) == 0 {
+		return models.EventType{}, false
+	}
+	if version == 0 {
+		return versions[len(versions)-1], true
+	}
+	for _, eventType := range versions {
+		if eventType.Version == version {
+			return eventType, true
+		}
+	}
+	return models.EventType{}, false
+}
+
+// initRegistry rebuilds the schema registry from the event types stored in database
+func (b *Bus) initRegistry(ctx context.Context) error {
+	logger := logging.Logger
+	b.registry = newRegistry()
+	var eventTypeVar models.EventType
+	txn := b.db.fetch("schema:", &eventTypeVar, func(res fetchResult) {
+		eventType := *res.item.(*models.EventType)
+		eventSchema, err := schema.Compile(eventType.Schema)
+		if err != nil {
+			logger.Error("could not compile event type schema", zap.String("schema_id", eventType.ID), zap.Error(err))
+			return
+		}
+		b.registry.add(eventType, eventSchema)
+	})
+	return b.db.txn(ctx, false, txn)
+}
+
+// RegisterEventType registers a new version of an event type in the schema registry.
+// The new version has to be compatible with the latest one according to the compatibility mode,
+// which defaults to the one of the latest version or to backward compatibility for new event types.
+// Registering the same schema as the latest version again gives back the latest version
+func (b *Bus) RegisterEventType(ctx context.Context, name, compatibility string, eventSchema json.RawMessage) (models.EventType, error) {
+	logger := logging.Logger
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if name == "" {
+		return models.EventType{}, models.Error{Message: "event type name is required"}
+	}
+	latest, exists := b.registry.get(name, 0)
+	if compatibility == "" {
+		compatibility = schema.BackwardCompatibility
+		if exists {
+			compatibility = latest.Compatibility
+		}
+	}
+	if !schema.SupportedCompatibility(compatibility) {
+		return models.EventType{}, models.UnsupportedCompatibilityError{}
+	}
+	compiledSchema, err := compileSchema(eventSchema)
+	if err != nil {
+		return models.EventType{}, err
+	}
+	if compiledSchema == nil {
+		return models.EventType{}, models.InvalidSchemaError{Reason: "schema is required"}
+	}
+	var compacted bytes.Buffer
+	err = json.Compact(&compacted, eventSchema)
+	if err != nil {
+		return models.EventType{}, models.InvalidSchemaError{Reason: err.Error()}
+	}
+
+	eventType := models.EventType{
+		ID:            uuid.New().String(),
+		Name:          name,
+		Version:       1,
+		Compatibility: compatibility,
+		Schema:        compacted.Bytes(),
+		CreatedAt:     time.Now().UTC(),
+	}
+	if exists {
+		if bytes.Equal(latest.Schema, eventType.Schema) && latest.Compatibility == compatibility {
+			return latest, nil
+		}
+		violations, err := schema.CheckCompatibility(compatibility, latest.Schema, eventType.Schema)
+		if err != nil {
+			return models.EventType{}, models.InvalidSchemaError{Reason: err.Error()}
+		}
+		if len(violations) > 0 {
+			return models.EventType{}, models.IncompatibleSchemaError{
+				Compatibility: compatibility,
+				Version:       latest.Version,
+				Violations:    violations,
+			}
+		}
+		eventType.Version = latest.Version + 1
+	}
+
+	txn := b.db.set(eventType.Key(), eventType.Value(), 0)
+	err = b.db.txn(ctx, true, txn)
+	if err != nil {
+		logger.Debug("could not save event type to db", zap.Error(err))
+		return models.EventType{}, err
+	}
+	b.registry.add(eventType, compiledSchema)
+	logger.Info(
+		"successfully registered event type",
+		zap.String("schema_id", eventType.ID),
+		zap.Int("version", eventType.Version),
+	)
+	return eventType, nil
+}
+
+// ListEventTypes lists the latest version of every event type in the schema registry,
+// or every version of the event type with the given name
+func (b *Bus) ListEventTypes(name string) ([]models.EventType, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if name != "" {
+		versions, ok := b.registry.versions[name]
+		if !ok {
+			return []models.EventType{}, models.EventTypeNotFoundError{Name: name}
+		}
+		return append([]models.EventType{}, versions...), nil
+	}
+	eventTypes := make([]models.EventType, 0, len(b.registry.versions))
+	for _, versions := range b.registry.versions {
+		eventTypes = append(eventTypes, versions[len(versions)-1])
+	}
+	sort.Slice(eventTypes, func(i, j int) bool {
+		return eventTypes[i].Name < eventTypes[j].Name
+	})
+	return eventTypes, nil
+}
+
+// GetEventType gets a version of an event type, zero version meaning the latest one
+func (b *Bus) GetEventType(name string, version int) (models.EventType, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	eventType, ok := b.registry.get(name, version)
+	if !ok {
+		return models.EventType{}, models.EventTypeNotFoundError{Name: name, Version: version}
+	}
+	return eventType, nil
+}
+
+// GetEventTypeBySchemaID gets the event type version events tagged with the schema ID were written with
+func (b *Bus) GetEventTypeBySchemaID(schemaID string) (models.EventType, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	eventType, ok := b.registry.byID[schemaID]
+	if !ok {
+		return models.EventType{}, models.EventTypeNotFoundError{ID: schemaID}
+	}
+	return eventType, nil
+}
+
+// validateEventType validates the JSON event against a version of an event type and gives back its schema ID
+func (b *Bus) validateEventType(name string, version int, body json.RawMessage) (string, error) {
+	eventType, ok := b.registry.get(name, version)
+	if !ok {
+		return "", models.EventTypeNotFoundError{Name: name, Version: version}
+	}
+	violations, err := b.registry.schemas[eventType.ID].Validate(body)
+	if err != nil {
+		logging.Logger.Debug("could not validate event", zap.Error(err))
+		return "", models.InvalidJSONError{}
+	}
+	if len(violations) > 0 {
+		return "", models.EventValidationError{Violations: violations}
+	}
+	return eventType.ID, nil
+}
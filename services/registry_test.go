@@ -0,0 +1,255 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
+)
+
+const (
+	userSchemaV1 = `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"], "additionalProperties": false}`
+	userSchemaV2 = `{"type": "object", "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}, "required": ["name"]}`
+)
+
+func (s *busSuite) Test_Bus_RegisterEventType_Success() {
+	eventType, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+
+	s.Require().NoError(err)
+	s.NotEmpty(eventType.ID)
+	s.Equal("user_created", eventType.Name)
+	s.Equal(1, eventType.Version)
+	s.Equal("backward", eventType.Compatibility)
+	s.JSONEq(userSchemaV1, string(eventType.Schema))
+	s.Equal([]models.EventType{eventType}, s.bus.registry.versions["user_created"])
+}
+
+func (s *busSuite) Test_Bus_RegisterEventType_NewVersion() {
+	v1, err := s.bus.RegisterEventType(testCtx, "user_created", "forward", json.RawMessage(userSchemaV2))
+	s.Require().NoError(err)
+
+	v2, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+
+	s.Require().NoError(err)
+	s.NotEqual(v1.ID, v2.ID)
+	s.Equal(2, v2.Version)
+	s.Equal("forward", v2.Compatibility)
+	s.Equal([]models.EventType{v1, v2}, s.bus.registry.versions["user_created"])
+}
+
+func (s *busSuite) Test_Bus_RegisterEventType_SameSchema() {
+	v1, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+
+	eventType, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"],"additionalProperties":false}`))
+
+	s.Require().NoError(err)
+	s.Equal(v1, eventType)
+	s.Len(s.bus.registry.versions["user_created"], 1)
+}
+
+func (s *busSuite) Test_Bus_RegisterEventType_IncompatibleSchemaError() {
+	_, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+	expected := models.IncompatibleSchemaError{
+		Compatibility: "backward",
+		Version:       1,
+		Violations:    []schema.Violation{{Path: "age", Message: "the new version requires the property"}},
+	}
+
+	eventType, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(`{"type": "object", "required": ["name", "age"]}`))
+
+	s.Equal(expected, err)
+	s.Empty(eventType)
+	s.Len(s.bus.registry.versions["user_created"], 1)
+}
+
+func (s *busSuite) Test_Bus_RegisterEventType_ConstrainedPropertyError() {
+	_, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(`{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`))
+	s.Require().NoError(err)
+	expected := models.IncompatibleSchemaError{
+		Compatibility: "backward",
+		Version:       1,
+		Violations:    []schema.Violation{{Path: "age", Message: "the new version restricts the type to integer"}},
+	}
+
+	eventType, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV2))
+
+	s.Equal(expected, err)
+	s.Empty(eventType)
+	s.Len(s.bus.registry.versions["user_created"], 1)
+}
+
+func (s *busSuite) Test_Bus_RegisterEventType_NoCompatibility() {
+	_, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+
+	eventType, err := s.bus.RegisterEventType(testCtx, "user_created", "none", json.RawMessage(`{"type": "string"}`))
+
+	s.Require().NoError(err)
+	s.Equal(2, eventType.Version)
+	s.Equal("none", eventType.Compatibility)
+}
+
+func (s *busSuite) Test_Bus_RegisterEventType_Errors() {
+	testCases := []struct {
+		name          string
+		eventType     string
+		compatibility string
+		schema        string
+		err           error
+	}{
+		{
+			name:   "MissingName",
+			schema: userSchemaV1,
+			err:    models.Error{Message: "event type name is required"},
+		},
+		{
+			name:          "UnsupportedCompatibility",
+			eventType:     "user_created",
+			compatibility: "transitive",
+			schema:        userSchemaV1,
+			err:           models.UnsupportedCompatibilityError{},
+		},
+		{
+			name:      "MissingSchema",
+			eventType: "user_created",
+			err:       models.InvalidSchemaError{Reason: "schema is required"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			eventType, err := s.bus.RegisterEventType(testCtx, testCase.eventType, testCase.compatibility, json.RawMessage(testCase.schema))
+
+			s.Equal(testCase.err, err)
+			s.Empty(eventType)
+		})
+	}
+	eventType, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(`{"type": "unknown"}`))
+	s.IsType(models.InvalidSchemaError{}, err)
+	s.Empty(eventType)
+	s.Empty(s.bus.registry.versions)
+}
+
+func (s *busSuite) Test_Bus_ListEventTypes_Latest() {
+	_, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+	userCreated, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV2))
+	s.Require().NoError(err)
+	orderPlaced, err := s.bus.RegisterEventType(testCtx, "order_placed", "", json.RawMessage(`{"type": "object"}`))
+	s.Require().NoError(err)
+
+	eventTypes, err := s.bus.ListEventTypes("")
+
+	s.Require().NoError(err)
+	s.Equal([]models.EventType{orderPlaced, userCreated}, eventTypes)
+}
+
+func (s *busSuite) Test_Bus_ListEventTypes_Versions() {
+	v1, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+	v2, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV2))
+	s.Require().NoError(err)
+
+	eventTypes, err := s.bus.ListEventTypes("user_created")
+
+	s.Require().NoError(err)
+	s.Equal([]models.EventType{v1, v2}, eventTypes)
+}
+
+func (s *busSuite) Test_Bus_ListEventTypes_EventTypeNotFoundError() {
+	eventTypes, err := s.bus.ListEventTypes("user_created")
+
+	s.EqualError(err, "event type 'user_created' not found")
+	s.Empty(eventTypes)
+}
+
+func (s *busSuite) Test_Bus_GetEventType_Success() {
+	v1, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+	v2, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV2))
+	s.Require().NoError(err)
+
+	latest, err := s.bus.GetEventType("user_created", 0)
+	s.Require().NoError(err)
+	s.Equal(v2, latest)
+
+	first, err := s.bus.GetEventType("user_created", 1)
+	s.Require().NoError(err)
+	s.Equal(v1, first)
+
+	byID, err := s.bus.GetEventTypeBySchemaID(v1.ID)
+	s.Require().NoError(err)
+	s.Equal(v1, byID)
+}
+
+func (s *busSuite) Test_Bus_GetEventType_EventTypeNotFoundError() {
+	_, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+
+	eventType, err := s.bus.GetEventType("user_created", 2)
+	s.EqualError(err, "event type 'user_created' version 2 not found")
+	s.Empty(eventType)
+
+	eventType, err = s.bus.GetEventTypeBySchemaID("schema-id")
+	s.EqualError(err, "event type with schema id 'schema-id' not found")
+	s.Empty(eventType)
+}
+
+func (s *busSuite) Test_Bus_Init_EventTypes() {
+	v1, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+	v2, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV2))
+	s.Require().NoError(err)
+	s.bus.registry = newRegistry()
+
+	err = s.bus.Init()
+
+	s.Require().NoError(err)
+	s.Equal([]models.EventType{v1, v2}, s.bus.registry.versions["user_created"])
+	s.Equal(v1, s.bus.registry.byID[v1.ID])
+	s.Contains(s.bus.registry.schemas, v2.ID)
+}
+
+func (s *busSuite) Test_Bus_WriteTypedEvent_Success() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	v1, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV1))
+	s.Require().NoError(err)
+	v2, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV2))
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.bus.WriteTypedEvent(testCtx, testClient, streamName, "user_created", 0, json.RawMessage(`{"name": "steve", "age": 30}`)))
+	s.Require().NoError(s.bus.WriteTypedEvent(testCtx, testClient, streamName, "user_created", 1, json.RawMessage(`{"name": "john"}`)))
+
+	events := s.fetchEvents()
+	s.Require().Len(events, 2)
+	schemaIDs := []string{events[0].SchemaID, events[1].SchemaID}
+	s.ElementsMatch([]string{v1.ID, v2.ID}, schemaIDs)
+}
+
+func (s *busSuite) Test_Bus_WriteTypedEvent_EventValidationError() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	_, err := s.bus.RegisterEventType(testCtx, "user_created", "", json.RawMessage(userSchemaV2))
+	s.Require().NoError(err)
+	expected := models.EventValidationError{
+		Violations: []schema.Violation{{Path: "age", Message: "Invalid type. Expected: integer, given: string"}},
+	}
+
+	err = s.bus.WriteTypedEvent(testCtx, testClient, streamName, "user_created", 0, json.RawMessage(`{"name": "steve", "age": "30"}`))
+
+	s.Equal(expected, err)
+	s.Empty(s.fetchEvents())
+}
+
+func (s *busSuite) Test_Bus_WriteTypedEvent_EventTypeNotFoundError() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+
+	err := s.bus.WriteTypedEvent(testCtx, testClient, streamName, "user_created", 0, json.RawMessage(`{}`))
+
+	s.Equal(models.EventTypeNotFoundError{Name: "user_created"}, err)
+	s.Empty(s.fetchEvents())
+}
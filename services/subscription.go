@@ -0,0 +1,96 @@
+package services
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/models"
+)
+
+// subscriptionBuffer is the number of events buffered for a subscriber before it is considered lagging
+const subscriptionBuffer = 256
+
+// Subscription represents a live feed of the events written to a stream
+type Subscription struct {
+	streamID string
+	events   chan models.Event
+	err      error
+}
+
+// Events gets the channel receiving every event written to the stream after subscribing.
+// The channel is closed once the subscription ends
+func (s *Subscription) Events() <-chan models.Event {
+	return s.events
+}
+
+// Err gets the reason the subscription ended, nil in case of unsubscribing.
+// To be called only after the events channel is closed
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Subscribe subscribes to all the events written to a stream from now on
+func (b *Bus) Subscribe(streamName string) (*Subscription, error) {
+	logger := logging.Logger
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stream, err := b.streamLookup(streamName)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		streamID: stream.ID,
+		events:   make(chan models.Event, subscriptionBuffer),
+	}
+	if b.subscriptions[stream.ID] == nil {
+		b.subscriptions[stream.ID] = map[*Subscription]struct{}{}
+	}
+	b.subscriptions[stream.ID][sub] = struct{}{}
+	logger.Debug("subscribed to stream", zap.String("stream_id", stream.ID))
+	return sub, nil
+}
+
+// Unsubscribe ends the subscription
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.endSubscription(sub, nil)
+}
+
+// publish delivers the event to the stream subscribers, subscribers which cannot keep up are dropped.
+// To be called while holding the lock
+func (b *Bus) publish(evt models.Event) {
+	for sub := range b.subscriptions[evt.StreamID] {
+		select {
+		case sub.events <- evt:
+		default:
+			logging.Logger.Debug("dropping lagging subscriber", zap.String("stream_id", evt.StreamID))
+			b.endSubscription(sub, models.SubscriptionLagError{})
+		}
+	}
+}
+
+// endSubscriptions ends all the subscriptions of a stream. To be called while holding the lock
+func (b *Bus) endSubscriptions(streamID string, err error) {
+	for sub := range b.subscriptions[streamID] {
+		b.endSubscription(sub, err)
+	}
+}
+
+func (b *Bus) endSubscription(sub *Subscription, err error) {
+	subs, ok := b.subscriptions[sub.streamID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subscriptions, sub.streamID)
+	}
+	sub.err = err
+	close(sub.events)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/go-web-dev/event-bus/models"
+)
+
+func (s *busSuite) Test_Bus_Subscribe_Success() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	sub, err := s.bus.Subscribe(streamName)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": "v"}`)))
+
+	evt := <-sub.Events()
+	s.Equal("stream-id", evt.StreamID)
+	s.JSONEq(`{"k": "v"}`, string(evt.Body))
+}
+
+func (s *busSuite) Test_Bus_Subscribe_StreamNotFoundError() {
+	sub, err := s.bus.Subscribe("stream-name")
+
+	s.EqualError(err, "stream 'stream-name' not found")
+	s.Nil(sub)
+}
+
+func (s *busSuite) Test_Bus_Unsubscribe() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	sub, err := s.bus.Subscribe(streamName)
+	s.Require().NoError(err)
+
+	s.bus.Unsubscribe(sub)
+	s.bus.Unsubscribe(sub)
+
+	_, ok := <-sub.Events()
+	s.False(ok)
+	s.NoError(sub.Err())
+	s.Empty(s.bus.subscriptions)
+}
+
+func (s *busSuite) Test_Bus_Subscribe_DeleteStream() {
+	streamName := "stream-name"
+	stream := models.Stream{ID: "stream-id", Name: streamName}
+	s.setStreams(stream)
+	s.bus.streams[streamName] = stream
+	sub, err := s.bus.Subscribe(streamName)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.bus.DeleteStream(testCtx, testClient, streamName))
+
+	_, ok := <-sub.Events()
+	s.False(ok)
+	s.Equal(models.StreamNotFoundError{Name: streamName}, sub.Err())
+}
+
+func (s *busSuite) Test_Bus_Subscribe_LagError() {
+	streamName := "stream-name"
+	s.bus.streams[streamName] = models.Stream{ID: "stream-id"}
+	sub, err := s.bus.Subscribe(streamName)
+	s.Require().NoError(err)
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		s.Require().NoError(s.bus.WriteEvent(testCtx, testClient, streamName, json.RawMessage(`{"k": "v"}`)))
+	}
+
+	count := 0
+	for range sub.Events() {
+		count++
+	}
+	s.Equal(subscriptionBuffer, count)
+	s.Equal(models.SubscriptionLagError{}, sub.Err())
+}
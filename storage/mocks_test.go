@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type cfgMock struct {
+	mock.Mock
+}
+
+func (m *cfgMock) GetStorageDir() string {
+	return m.Called().String(0)
+}
+
+func (m *cfgMock) GetStorageInMemory() bool {
+	return m.Called().Bool(0)
+}
+
+func (m *cfgMock) GetStorageSyncWrites() bool {
+	return m.Called().Bool(0)
+}
+
+func (m *cfgMock) GetStorageValueLogFileSize() int64 {
+	return m.Called().Get(0).(int64)
+}
+
+func (m *cfgMock) GetStorageCompression() string {
+	return m.Called().String(0)
+}
+
+func (m *cfgMock) GetStorageEncryptionKeyPath() string {
+	return m.Called().String(0)
+}
+
+func (m *cfgMock) GetStorageEncryptionKey() string {
+	return m.Called().String(0)
+}
+
+func (m *cfgMock) GetStorageEncryptionKeyRotation() time.Duration {
+	return m.Called().Get(0).(time.Duration)
+}
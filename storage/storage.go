@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/options"
+	"github.com/dgraph-io/badger/v2/pb"
+
+	"github.com/go-web-dev/event-bus/config"
+)
+
+// encryptedIndexCacheSize is the index cache size recommended by Badger when encryption is enabled
+const encryptedIndexCacheSize = 100 << 20
+
+// MaxPendingWrites is the number of pending writes Badger allows while loading a backup
+const MaxPendingWrites = 256
+
+// maxBackupListSize bounds the size of a single list of entries in a backup, so a corrupted size is not allocated
+const maxBackupListSize = 1 << 30
+
+var compressionTypes = map[string]options.CompressionType{
+	config.CompressionNone:   options.None,
+	config.CompressionSnappy: options.Snappy,
+	config.CompressionZSTD:   options.ZSTD,
+}
+
+// ErrEncryptionKeyMismatch is returned when the configured encryption key cannot decrypt the stored data
+var ErrEncryptionKeyMismatch = errors.New("encryption key does not match the stored data")
+
+// ErrEmptyEncryptionKey is returned when rotating to an empty encryption key without asking to decrypt
+var ErrEmptyEncryptionKey = errors.New("new encryption key is required, unless decrypting")
+
+// ConfigManager represents the configuration needed to open the storage
+type ConfigManager interface {
+	GetStorageDir() string
+	GetStorageInMemory() bool
+	GetStorageSyncWrites() bool
+	GetStorageValueLogFileSize() int64
+	GetStorageCompression() string
+	GetStorageEncryptionKeyPath() string
+	GetStorageEncryptionKey() string
+	GetStorageEncryptionKeyRotation() time.Duration
+}
+
+// Settings represents the Badger database settings
+type Settings struct {
+	Dir                   string
+	InMemory              bool
+	SyncWrites            bool
+	ValueLogFileSize      int64
+	Compression           string
+	EncryptionKey         []byte
+	EncryptionKeyRotation time.Duration
+}
+
+// NewSettings creates storage settings out of the application configuration
+func NewSettings(cfg ConfigManager) (Settings, error) {
+	key, err := LoadEncryptionKey(cfg.GetStorageEncryptionKey(), cfg.GetStorageEncryptionKeyPath())
+	if err != nil {
+		return Settings{}, err
+	}
+	settings := Settings{
+		Dir:                   cfg.GetStorageDir(),
+		InMemory:              cfg.GetStorageInMemory(),
+		SyncWrites:            cfg.GetStorageSyncWrites(),
+		ValueLogFileSize:      cfg.GetStorageValueLogFileSize(),
+		Compression:           cfg.GetStorageCompression(),
+		EncryptionKey:         key,
+		EncryptionKeyRotation: cfg.GetStorageEncryptionKeyRotation(),
+	}
+	return settings, nil
+}
+
+// LoadEncryptionKey loads the encryption key either from its raw value or from a key file.
+// No key at all means the storage is not encrypted
+func LoadEncryptionKey(key, path string) ([]byte, error) {
+	if key == "" && path != "" {
+		bs, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read encryption key file: %s", err)
+		}
+		key = strings.TrimRight(string(bs), "\r\n")
+	}
+	if key == "" {
+		return nil, nil
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return []byte(key), nil
+	default:
+		return nil, fmt.Errorf("encryption key must be 16, 24 or 32 bytes long, got %d", len(key))
+	}
+}
+
+// Open opens the Badger database described by the given settings
+func Open(settings Settings) (*badger.DB, error) {
+	opts, err := Options(settings)
+	if err != nil {
+		return nil, err
+	}
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, keyMismatch(err)
+	}
+	return db, nil
+}
+
+// Options converts storage settings into Badger options
+func Options(settings Settings) (badger.Options, error) {
+	compression, ok := compressionTypes[settings.Compression]
+	if !ok {
+		return badger.Options{}, fmt.Errorf("unknown compression type: '%s'", settings.Compression)
+	}
+
+	dir := settings.Dir
+	if settings.InMemory {
+		dir = ""
+	}
+	opts := badger.DefaultOptions(dir).
+		WithInMemory(settings.InMemory).
+		WithSyncWrites(settings.SyncWrites).
+		WithValueLogFileSize(settings.ValueLogFileSize).
+		WithCompression(compression)
+	if len(settings.EncryptionKey) > 0 {
+		opts = opts.
+			WithEncryptionKey(settings.EncryptionKey).
+			WithIndexCacheSize(encryptedIndexCacheSize)
+		if settings.EncryptionKeyRotation > 0 {
+			opts = opts.WithEncryptionKeyRotationDuration(settings.EncryptionKeyRotation)
+		}
+	}
+	opts.Logger = nil
+	return opts, nil
+}
+
+// RotateKey re-encrypts the Badger key registry with a new encryption key.
+// The database must not be opened by anyone else while rotating.
+// Decrypting turns encryption off for newly written data, in which case no new key must be given,
+// an empty new key being rejected otherwise
+func RotateKey(settings Settings, newKey []byte, decrypt bool) error {
+	if settings.InMemory {
+		return errors.New("cannot rotate encryption key of an in-memory storage")
+	}
+	if decrypt && len(newKey) > 0 {
+		return errors.New("new encryption key must be empty when decrypting")
+	}
+	if !decrypt && len(newKey) == 0 {
+		return ErrEmptyEncryptionKey
+	}
+	opts := badger.KeyRegistryOptions{
+		Dir:                           settings.Dir,
+		ReadOnly:                      true,
+		EncryptionKey:                 settings.EncryptionKey,
+		EncryptionKeyRotationDuration: settings.EncryptionKeyRotation,
+	}
+	registry, err := badger.OpenKeyRegistry(opts)
+	if err != nil {
+		return keyMismatch(err)
+	}
+	opts.EncryptionKey = newKey
+	return badger.WriteKeyRegistry(registry, opts)
+}
+
+// Backup writes a portable copy of the entries stored since the given version, zero meaning all of them,
+// and returns the version to back up since in the next incremental backup.
+// The database must not be opened by anyone else while backing up
+func Backup(settings Settings, w io.Writer, since uint64) (uint64, error) {
+	if settings.InMemory {
+		return 0, errors.New("cannot back up an in-memory storage")
+	}
+	db, err := Open(settings)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	version, err := db.Backup(w, since)
+	if err != nil {
+		return 0, err
+	}
+	return NextBackupVersion(since, version), nil
+}
+
+// NextBackupVersion computes the version to back up since in the next incremental backup
+// out of the latest version Badger backed up, which is zero when there was nothing new to back up
+func NextBackupVersion(since, version uint64) uint64 {
+	if version < since {
+		return since
+	}
+	return version + 1
+}
+
+// ValidateBackup decodes the whole backup without loading it, the way Badger reads it when loading
+func ValidateBackup(r io.Reader) error {
+	br := bufio.NewReaderSize(r, 16<<10)
+	for {
+		var size uint64
+		err := binary.Read(br, binary.LittleEndian, &size)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if size > maxBackupListSize {
+			return fmt.Errorf("list of %d bytes exceeds the maximum size of %d bytes", size, maxBackupListSize)
+		}
+		buf := make([]byte, size)
+		_, err = io.ReadFull(br, buf)
+		if err != nil {
+			return err
+		}
+		var list pb.KVList
+		err = list.Unmarshal(buf)
+		if err != nil {
+			return err
+		}
+		for _, kv := range list.Kv {
+			if len(kv.Key) == 0 {
+				return errors.New("entry without key")
+			}
+		}
+	}
+}
+
+// Restore loads a backup into the database, dropping the stored data first unless the restore is incremental.
+// The backup is validated first, so that the stored data is left untouched when it cannot be decoded.
+// The database must not be opened by anyone else while restoring
+func Restore(settings Settings, r io.ReadSeeker, incremental bool) error {
+	if settings.InMemory {
+		return errors.New("cannot restore an in-memory storage")
+	}
+	err := ValidateBackup(r)
+	if err != nil {
+		return fmt.Errorf("invalid backup: %s", err)
+	}
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	db, err := Open(settings)
+	if err != nil {
+		return err
+	}
+	if !incremental {
+		err = db.DropAll()
+		if err != nil {
+			db.Close()
+			return err
+		}
+	}
+	err = db.Load(r, MaxPendingWrites)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// CollectGarbage rewrites the value log files holding at least the given ratio of discardable data,
+// until none is left to rewrite, and returns the number of rewritten files.
+// The database must not be opened by anyone else while collecting garbage
+func CollectGarbage(settings Settings, discardRatio float64) (int, error) {
+	if settings.InMemory {
+		return 0, errors.New("cannot collect garbage of an in-memory storage")
+	}
+	if discardRatio <= 0 || discardRatio >= 1 {
+		return 0, fmt.Errorf("discard ratio must be between 0 and 1, got %g", discardRatio)
+	}
+	db, err := Open(settings)
+	if err != nil {
+		return 0, err
+	}
+	rewritten := 0
+	for {
+		err = db.RunValueLogGC(discardRatio)
+		if err != nil {
+			break
+		}
+		rewritten++
+	}
+	if err != badger.ErrNoRewrite {
+		db.Close()
+		return rewritten, err
+	}
+	return rewritten, db.Close()
+}
+
+// keyMismatch translates Badger's encryption key mismatch into a meaningful error.
+// Badger formats its errors instead of wrapping them, hence the message lookup
+func keyMismatch(err error) error {
+	if strings.Contains(err.Error(), badger.ErrEncryptionKeyMismatch.Error()) {
+		return ErrEncryptionKeyMismatch
+	}
+	return err
+}
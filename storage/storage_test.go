@@ -0,0 +1,410 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/options"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/logging"
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+const (
+	testKey1 = "0123456789abcdef"
+	testKey2 = "fedcba9876543210fedcba9876543210"
+)
+
+type storageSuite struct {
+	testutils.Suite
+	settings Settings
+	dir      string
+}
+
+func (s *storageSuite) SetupSuite() {
+	logging.Logger = testutils.Logger(s.T(), nil)
+}
+
+func (s *storageSuite) SetupTest() {
+	s.settings = Settings{
+		Dir:              "badger",
+		SyncWrites:       true,
+		ValueLogFileSize: 1 << 20,
+		Compression:      "snappy",
+	}
+	dir, err := ioutil.TempDir("", "event-bus-storage")
+	s.Require().NoError(err)
+	s.dir = dir
+}
+
+func (s *storageSuite) TearDownTest() {
+	s.Require().NoError(os.RemoveAll(s.dir))
+}
+
+func (s *storageSuite) Test_NewSettings_Success() {
+	cfg := new(cfgMock)
+	cfg.On("GetStorageDir").Return("badger").Once()
+	cfg.On("GetStorageInMemory").Return(false).Once()
+	cfg.On("GetStorageSyncWrites").Return(true).Once()
+	cfg.On("GetStorageValueLogFileSize").Return(int64(1 << 20)).Once()
+	cfg.On("GetStorageCompression").Return("zstd").Once()
+	cfg.On("GetStorageEncryptionKey").Return(testKey1).Once()
+	cfg.On("GetStorageEncryptionKeyPath").Return("").Once()
+	cfg.On("GetStorageEncryptionKeyRotation").Return(time.Hour).Once()
+	expected := Settings{
+		Dir:                   "badger",
+		SyncWrites:            true,
+		ValueLogFileSize:      1 << 20,
+		Compression:           "zstd",
+		EncryptionKey:         []byte(testKey1),
+		EncryptionKeyRotation: time.Hour,
+	}
+
+	settings, err := NewSettings(cfg)
+
+	s.Require().NoError(err)
+	s.Equal(expected, settings)
+	cfg.AssertExpectations(s.T())
+}
+
+func (s *storageSuite) Test_NewSettings_EncryptionKeyError() {
+	cfg := new(cfgMock)
+	cfg.On("GetStorageEncryptionKey").Return("short").Once()
+	cfg.On("GetStorageEncryptionKeyPath").Return("").Once()
+
+	_, err := NewSettings(cfg)
+
+	s.EqualError(err, "encryption key must be 16, 24 or 32 bytes long, got 5")
+	cfg.AssertExpectations(s.T())
+}
+
+func (s *storageSuite) Test_LoadEncryptionKey() {
+	keyFile := filepath.Join(s.dir, "key")
+	s.Require().NoError(ioutil.WriteFile(keyFile, []byte(testKey2+"\n"), 0600))
+	testCases := []struct {
+		name     string
+		key      string
+		path     string
+		expected []byte
+		err      string
+	}{
+		{name: "NoKey", expected: nil},
+		{name: "RawKey", key: testKey1, expected: []byte(testKey1)},
+		{name: "RawKeyPrecedence", key: testKey1, path: keyFile, expected: []byte(testKey1)},
+		{name: "KeyFile", path: keyFile, expected: []byte(testKey2)},
+		{name: "InvalidLength", key: "abc", err: "encryption key must be 16, 24 or 32 bytes long, got 3"},
+		{
+			name: "KeyFileNotFound",
+			path: "does_not_exist",
+			err:  "could not read encryption key file: open does_not_exist: no such file or directory",
+		},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			key, err := LoadEncryptionKey(testCase.key, testCase.path)
+
+			if testCase.err != "" {
+				s.EqualError(err, testCase.err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(testCase.expected, key)
+		})
+	}
+}
+
+func (s *storageSuite) Test_Options_Success() {
+	opts, err := Options(s.settings)
+
+	s.Require().NoError(err)
+	s.Equal("badger", opts.Dir)
+	s.Equal("badger", opts.ValueDir)
+	s.False(opts.InMemory)
+	s.True(opts.SyncWrites)
+	s.Equal(int64(1<<20), opts.ValueLogFileSize)
+	s.Equal(options.Snappy, opts.Compression)
+	s.Nil(opts.Logger)
+	s.Empty(opts.EncryptionKey)
+	s.Zero(opts.IndexCacheSize)
+}
+
+func (s *storageSuite) Test_Options_Encryption() {
+	s.settings.EncryptionKey = []byte(testKey1)
+	s.settings.EncryptionKeyRotation = time.Hour
+
+	opts, err := Options(s.settings)
+
+	s.Require().NoError(err)
+	s.Equal([]byte(testKey1), opts.EncryptionKey)
+	s.Equal(time.Hour, opts.EncryptionKeyRotationDuration)
+	s.Equal(int64(encryptedIndexCacheSize), opts.IndexCacheSize)
+}
+
+func (s *storageSuite) Test_Options_InMemory() {
+	s.settings.InMemory = true
+
+	opts, err := Options(s.settings)
+
+	s.Require().NoError(err)
+	s.Empty(opts.Dir)
+	s.Empty(opts.ValueDir)
+	s.True(opts.InMemory)
+}
+
+func (s *storageSuite) Test_Options_CompressionError() {
+	s.settings.Compression = "lz4"
+
+	_, err := Options(s.settings)
+
+	s.EqualError(err, "unknown compression type: 'lz4'")
+}
+
+func (s *storageSuite) Test_Open_Success() {
+	s.settings.Dir = s.dir
+
+	db, err := Open(s.settings)
+
+	s.Require().NoError(err)
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) Test_Open_Encrypted() {
+	s.settings.Dir = s.dir
+	s.settings.EncryptionKey = []byte(testKey1)
+	s.write("key", "value")
+
+	db, err := Open(s.settings)
+
+	s.Require().NoError(err)
+	s.Equal("value", s.get(db, "key"))
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) Test_Open_EncryptionKeyMismatch() {
+	s.settings.Dir = s.dir
+	s.settings.EncryptionKey = []byte(testKey1)
+	s.write("key", "value")
+	testCases := []struct {
+		name string
+		key  []byte
+	}{
+		{name: "WrongKey", key: []byte(testKey2)},
+		{name: "NoKey", key: nil},
+	}
+
+	for _, testCase := range testCases {
+		s.Run(testCase.name, func() {
+			settings := s.settings
+			settings.EncryptionKey = testCase.key
+
+			db, err := Open(settings)
+
+			s.Equal(ErrEncryptionKeyMismatch, err)
+			s.Nil(db)
+		})
+	}
+}
+
+func (s *storageSuite) Test_RotateKey_Success() {
+	s.settings.Dir = s.dir
+	s.settings.EncryptionKey = []byte(testKey1)
+	s.write("key", "value")
+
+	err := RotateKey(s.settings, []byte(testKey2), false)
+
+	s.Require().NoError(err)
+	_, err = Open(s.settings)
+	s.Equal(ErrEncryptionKeyMismatch, err)
+	s.settings.EncryptionKey = []byte(testKey2)
+	db, err := Open(s.settings)
+	s.Require().NoError(err)
+	s.Equal("value", s.get(db, "key"))
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) Test_RotateKey_EncryptionKeyMismatch() {
+	s.settings.Dir = s.dir
+	s.settings.EncryptionKey = []byte(testKey1)
+	s.write("key", "value")
+	s.settings.EncryptionKey = []byte(testKey2)
+
+	err := RotateKey(s.settings, []byte(testKey1), false)
+
+	s.Equal(ErrEncryptionKeyMismatch, err)
+}
+
+func (s *storageSuite) Test_RotateKey_Decrypt() {
+	s.settings.Dir = s.dir
+	s.settings.EncryptionKey = []byte(testKey1)
+	s.write("key", "value")
+
+	err := RotateKey(s.settings, nil, true)
+
+	s.Require().NoError(err)
+	s.settings.EncryptionKey = nil
+	db, err := Open(s.settings)
+	s.Require().NoError(err)
+	s.Equal("value", s.get(db, "key"))
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) Test_RotateKey_EmptyKeyError() {
+	s.settings.Dir = s.dir
+	s.settings.EncryptionKey = []byte(testKey1)
+	s.write("key", "value")
+
+	err := RotateKey(s.settings, nil, false)
+
+	s.Equal(ErrEmptyEncryptionKey, err)
+	db, err := Open(s.settings)
+	s.Require().NoError(err)
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) Test_RotateKey_DecryptWithKeyError() {
+	s.settings.Dir = s.dir
+
+	err := RotateKey(s.settings, []byte(testKey2), true)
+
+	s.EqualError(err, "new encryption key must be empty when decrypting")
+}
+
+func (s *storageSuite) Test_RotateKey_InMemoryError() {
+	s.settings.InMemory = true
+
+	err := RotateKey(s.settings, []byte(testKey1), false)
+
+	s.EqualError(err, "cannot rotate encryption key of an in-memory storage")
+}
+
+func (s *storageSuite) Test_BackupRestore_Success() {
+	s.settings.Dir = s.dir
+	s.write("key1", "value1")
+	var full, incremental bytes.Buffer
+	version, err := Backup(s.settings, &full, 0)
+	s.Require().NoError(err)
+	s.write("key2", "value2")
+
+	_, err = Backup(s.settings, &incremental, version)
+
+	s.Require().NoError(err)
+	s.write("key3", "value3")
+	s.Require().NoError(Restore(s.settings, bytes.NewReader(full.Bytes()), false))
+	db, err := Open(s.settings)
+	s.Require().NoError(err)
+	s.Equal("value1", s.get(db, "key1"))
+	s.Equal("", s.get(db, "key3"))
+	s.Require().NoError(db.Close())
+	s.Require().NoError(Restore(s.settings, bytes.NewReader(incremental.Bytes()), true))
+	db, err = Open(s.settings)
+	s.Require().NoError(err)
+	s.Equal("value1", s.get(db, "key1"))
+	s.Equal("value2", s.get(db, "key2"))
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) Test_Restore_Error() {
+	s.settings.Dir = s.dir
+	s.write("key1", "value1")
+
+	err := Restore(s.settings, strings.NewReader("garbage"), false)
+
+	s.EqualError(err, "invalid backup: unexpected EOF")
+	db, err := Open(s.settings)
+	s.Require().NoError(err)
+	s.Equal("value1", s.get(db, "key1"))
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) Test_ValidateBackup() {
+	s.settings.Dir = s.dir
+	s.write("key1", "value1")
+	var backup bytes.Buffer
+	_, err := Backup(s.settings, &backup, 0)
+	s.Require().NoError(err)
+	oversized := make([]byte, 8)
+	binary.LittleEndian.PutUint64(oversized, 1<<40)
+
+	s.NoError(ValidateBackup(bytes.NewReader(backup.Bytes())))
+	s.NoError(ValidateBackup(strings.NewReader("")))
+	s.EqualError(ValidateBackup(bytes.NewReader(backup.Bytes()[:backup.Len()-1])), "unexpected EOF")
+	s.EqualError(ValidateBackup(bytes.NewReader(oversized)), "list of 1099511627776 bytes exceeds the maximum size of 1073741824 bytes")
+}
+
+func (s *storageSuite) Test_BackupRestore_InMemoryError() {
+	s.settings.InMemory = true
+
+	_, err := Backup(s.settings, ioutil.Discard, 0)
+	s.EqualError(err, "cannot back up an in-memory storage")
+	err = Restore(s.settings, strings.NewReader(""), false)
+	s.EqualError(err, "cannot restore an in-memory storage")
+}
+
+func (s *storageSuite) Test_CollectGarbage_Success() {
+	s.settings.Dir = s.dir
+	s.write("key", "value")
+
+	rewritten, err := CollectGarbage(s.settings, 0.5)
+
+	s.Require().NoError(err)
+	s.Zero(rewritten)
+	db, err := Open(s.settings)
+	s.Require().NoError(err)
+	s.Equal("value", s.get(db, "key"))
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) Test_CollectGarbage_Error() {
+	_, err := CollectGarbage(s.settings, 1)
+	s.EqualError(err, "discard ratio must be between 0 and 1, got 1")
+	s.settings.InMemory = true
+	_, err = CollectGarbage(s.settings, 0.5)
+	s.EqualError(err, "cannot collect garbage of an in-memory storage")
+}
+
+func (s *storageSuite) Test_Open_Error() {
+	s.settings.Compression = ""
+
+	db, err := Open(s.settings)
+
+	s.EqualError(err, "unknown compression type: ''")
+	s.Nil(db)
+}
+
+func (s *storageSuite) write(key, value string) {
+	db, err := Open(s.settings)
+	s.Require().NoError(err)
+	s.Require().NoError(db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), []byte(value))
+	}))
+	s.Require().NoError(db.Close())
+}
+
+func (s *storageSuite) get(db *badger.DB, key string) string {
+	var value []byte
+	s.Require().NoError(db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	}))
+	return string(value)
+}
+
+func Test_StorageSuite(t *testing.T) {
+	suite.Run(t, new(storageSuite))
+}
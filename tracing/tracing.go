@@ -0,0 +1,135 @@
+// Package tracing traces the Event Bus operations with OpenTelemetry
+// and carries the W3C trace context across requests and events
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/go-web-dev/event-bus"
+
+// Exporters
+const (
+	NoExporter     = "none"
+	StdoutExporter = "stdout"
+	OTLPExporter   = "otlp"
+	MemoryExporter = "memory"
+)
+
+var propagator = propagation.TraceContext{}
+
+func init() {
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Settings represents the tracing settings
+type Settings struct {
+	// Exporter is the name of the span exporter, no spans are recorded with none
+	Exporter string
+	// Endpoint is the address of the OTLP collector
+	Endpoint string
+	// Insecure disables TLS towards the OTLP collector
+	Insecure bool
+	// SampleRatio is the ratio of traces started by the Event Bus which are sampled,
+	// traces continued from a producer follow the producer sampling decision
+	SampleRatio float64
+	// ServiceName is the service name the spans are reported under
+	ServiceName string
+	// Memory holds the spans exported by the memory exporter, meant to be used for testing purposes.
+	// A new one is created when nil
+	Memory *tracetest.InMemoryExporter
+}
+
+// Init sets up the global tracer provider with the configured exporter.
+// The returned function flushes the pending spans and stops the exporter
+func Init(settings Settings) (func(context.Context) error, error) {
+	var opt sdktrace.TracerProviderOption
+	switch settings.Exporter {
+	case NoExporter, "":
+		return func(context.Context) error { return nil }, nil
+	case StdoutExporter:
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, err
+		}
+		opt = sdktrace.WithBatcher(exporter)
+	case OTLPExporter:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(settings.Endpoint)}
+		if settings.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(context.Background(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		opt = sdktrace.WithBatcher(exporter)
+	case MemoryExporter:
+		memory := settings.Memory
+		if memory == nil {
+			memory = tracetest.NewInMemoryExporter()
+		}
+		opt = sdktrace.WithSyncer(memory)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter '%s'", settings.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		opt,
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String(settings.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span in the context, if any
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// End records the error of the traced work, if any, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject returns the W3C trace context of the span in the context, nil when there is none
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns a context continuing the trace of the given W3C trace context
+func Extract(ctx context.Context, traceContext map[string]string) context.Context {
+	if len(traceContext) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier(traceContext))
+}
+
+// FromHeaders returns the W3C trace context of the HTTP headers, nil when there is none
+func FromHeaders(header http.Header) map[string]string {
+	ctx := propagator.Extract(context.Background(), propagation.HeaderCarrier(header))
+	return Inject(ctx)
+}
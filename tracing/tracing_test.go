@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID      = "00f067aa0ba902b7"
+	testTraceparent = "00-" + testTraceID + "-" + testSpanID + "-01"
+)
+
+type tracingSuite struct {
+	testutils.Suite
+	shutdown func(context.Context) error
+	memory   *tracetest.InMemoryExporter
+}
+
+func (s *tracingSuite) SetupTest() {
+	s.memory = tracetest.NewInMemoryExporter()
+	shutdown, err := Init(Settings{Exporter: MemoryExporter, SampleRatio: 1, Memory: s.memory})
+	s.Require().NoError(err)
+	s.shutdown = shutdown
+}
+
+func (s *tracingSuite) TearDownTest() {
+	s.Require().NoError(s.shutdown(context.Background()))
+}
+
+func (s *tracingSuite) Test_Init_NoExporter() {
+	shutdown, err := Init(Settings{Exporter: NoExporter})
+
+	s.Require().NoError(err)
+	s.NoError(shutdown(context.Background()))
+}
+
+func (s *tracingSuite) Test_Init_UnsupportedExporterError() {
+	shutdown, err := Init(Settings{Exporter: "jaeger"})
+
+	s.EqualError(err, "unsupported tracing exporter 'jaeger'")
+	s.Nil(shutdown)
+}
+
+func (s *tracingSuite) Test_Start_End() {
+	ctx, parent := Start(context.Background(), "parent", trace.SpanKindServer, attribute.String("k", "v"))
+	_, child := Start(ctx, "child", trace.SpanKindInternal)
+	End(child, errors.New("some error"))
+	End(parent, nil)
+
+	spans := s.memory.GetSpans()
+	s.Require().Len(spans, 2)
+	s.Equal("child", spans[0].Name)
+	s.Equal(parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	s.Equal(codes.Error, spans[0].Status.Code)
+	s.Equal("some error", spans[0].Status.Description)
+	s.Require().Len(spans[0].Events, 1)
+	s.Equal("exception", spans[0].Events[0].Name)
+	s.Equal("parent", spans[1].Name)
+	s.Equal(trace.SpanKindServer, spans[1].SpanKind)
+	s.Equal(codes.Unset, spans[1].Status.Code)
+	s.Equal([]attribute.KeyValue{attribute.String("k", "v")}, spans[1].Attributes)
+}
+
+func (s *tracingSuite) Test_Extract_Inject() {
+	ctx := Extract(context.Background(), map[string]string{"traceparent": testTraceparent})
+	ctx, span := Start(ctx, "op", trace.SpanKindServer)
+	defer span.End()
+
+	traceContext := Inject(ctx)
+
+	s.Equal(testTraceID, span.SpanContext().TraceID().String())
+	s.Equal("00-"+testTraceID+"-"+span.SpanContext().SpanID().String()+"-01", traceContext["traceparent"])
+}
+
+func (s *tracingSuite) Test_Inject_NoSpan() {
+	s.Nil(Inject(context.Background()))
+}
+
+func (s *tracingSuite) Test_Extract_Empty() {
+	ctx := context.Background()
+
+	s.Equal(ctx, Extract(ctx, nil))
+}
+
+func (s *tracingSuite) Test_FromHeaders() {
+	header := http.Header{}
+	header.Set("Traceparent", testTraceparent)
+	header.Set("Tracestate", "vendor=value")
+
+	traceContext := FromHeaders(header)
+
+	s.Equal(map[string]string{"traceparent": testTraceparent, "tracestate": "vendor=value"}, traceContext)
+	s.Nil(FromHeaders(http.Header{}))
+}
+
+func Test_TracingSuite(t *testing.T) {
+	suite.Run(t, new(tracingSuite))
+}
@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Wire encodings
+const (
+	JSONEncoding    = "json"
+	MsgPackEncoding = "msgpack"
+)
+
+// Codec represents a wire encoding of the Event Bus requests and responses
+type Codec interface {
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var codecs = map[string]Codec{
+	JSONEncoding:    jsonCodec{},
+	MsgPackEncoding: msgPackCodec{},
+}
+
+// GetCodec gets the codec of the given wire encoding
+func GetCodec(encoding string) (Codec, bool) {
+	codec, ok := codecs[encoding]
+	return codec, ok
+}
+
+// Encodings lists all the supported wire encodings
+func Encodings() []string {
+	encodings := make([]string, 0, len(codecs))
+	for encoding := range codecs {
+		encodings = append(encodings, encoding)
+	}
+	sort.Strings(encodings)
+	return encodings
+}
+
+// RawMessage is a raw encoded value, kept in the wire encoding it was read with
+// so that it is decoded later with the same codec
+type RawMessage []byte
+
+// UnmarshalJSON keeps a copy of the JSON value
+func (m *RawMessage) UnmarshalJSON(data []byte) error {
+	*m = append((*m)[0:0], data...)
+	return nil
+}
+
+// DecodeMsgpack keeps a copy of the MessagePack value
+func (m *RawMessage) DecodeMsgpack(dec *msgpack.Decoder) error {
+	raw, err := dec.DecodeRaw()
+	if err != nil {
+		return err
+	}
+	*m = RawMessage(raw)
+	return nil
+}
+
+// json.RawMessage values, such as event bodies and schemas, are sent as MessagePack maps and arrays
+// rather than as binary strings, so that MessagePack clients never deal with JSON
+func init() {
+	msgpack.Register(json.RawMessage{}, encodeRawJSON, decodeRawJSON)
+}
+
+func encodeRawJSON(enc *msgpack.Encoder, v reflect.Value) error {
+	raw := v.Bytes()
+	if len(raw) == 0 {
+		return enc.EncodeNil()
+	}
+	var value interface{}
+	err := jsonCodec{}.Unmarshal(raw, &value)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(value)
+}
+
+func decodeRawJSON(dec *msgpack.Decoder, v reflect.Value) error {
+	value, err := dec.DecodeInterface()
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		v.SetBytes(nil)
+		return nil
+	}
+	bs, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	v.SetBytes(bs)
+	return nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return JSONEncoding
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal keeps integers as integers when decoding into an interface,
+// so that they are not turned into floats when encoded with another codec
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	iface, ok := v.(*interface{})
+	if ok {
+		dec.UseNumber()
+	}
+	err := dec.Decode(v)
+	if err != nil {
+		return err
+	}
+	if ok {
+		*iface = normalizeNumbers(*iface)
+	}
+	return nil
+}
+
+type msgPackCodec struct{}
+
+func (msgPackCodec) Name() string {
+	return MsgPackEncoding
+}
+
+func (msgPackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgPackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+func normalizeNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeNumbers(item)
+		}
+	}
+	return v
+}
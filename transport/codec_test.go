@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+type codecSuite struct {
+	testutils.Suite
+}
+
+func (s *codecSuite) TestGetCodec() {
+	codec, ok := GetCodec("msgpack")
+
+	s.True(ok)
+	s.Equal(msgPackCodec{}, codec)
+	_, ok = GetCodec("xml")
+	s.False(ok)
+}
+
+func (s *codecSuite) TestEncodings() {
+	s.Equal([]string{"json", "msgpack"}, Encodings())
+}
+
+func (s *codecSuite) TestMsgPack_RawJSON() {
+	type body struct {
+		Event json.RawMessage `json:"event,omitempty"`
+		Data  []byte          `json:"data,omitempty"`
+	}
+	msg := body{Event: json.RawMessage(`{"stream_name":"s1","retries":3,"ratio":0.5,"tags":["a",1]}`), Data: []byte("hi")}
+	codec, _ := GetCodec(MsgPackEncoding)
+
+	packed, err := codec.Marshal(msg)
+
+	s.Require().NoError(err)
+	var decoded map[string]interface{}
+	s.Require().NoError(codec.Unmarshal(packed, &decoded))
+	s.EqualValues(3, decoded["event"].(map[string]interface{})["retries"])
+	s.Equal([]byte("hi"), decoded["data"])
+	var unpacked body
+	s.Require().NoError(codec.Unmarshal(packed, &unpacked))
+	s.JSONEq(string(msg.Event), string(unpacked.Event))
+	s.Equal(msg.Data, unpacked.Data)
+}
+
+func (s *codecSuite) TestMsgPack_RawJSON_Error() {
+	codec, _ := GetCodec(MsgPackEncoding)
+
+	_, err := codec.Marshal(struct {
+		Event json.RawMessage `json:"event"`
+	}{Event: json.RawMessage("{")})
+
+	s.Error(err)
+}
+
+func (s *codecSuite) TestRawMessage() {
+	var req struct {
+		Body RawMessage `json:"body"`
+	}
+	jsonCodec, _ := GetCodec(JSONEncoding)
+	msgPackCodec, _ := GetCodec(MsgPackEncoding)
+	packed, err := msgPackCodec.Marshal(map[string]interface{}{"body": map[string]interface{}{"name": "s1"}})
+	s.Require().NoError(err)
+
+	s.Require().NoError(msgPackCodec.Unmarshal(packed, &req))
+	var body map[string]interface{}
+	s.Require().NoError(msgPackCodec.Unmarshal(req.Body, &body))
+	s.Equal("s1", body["name"])
+	s.Require().NoError(jsonCodec.Unmarshal([]byte(`{"body":{"name":"s1"}}`), &req))
+	s.Equal(`{"name":"s1"}`, string(req.Body))
+}
+
+func Test_CodecSuite(t *testing.T) {
+	suite.Run(t, new(codecSuite))
+}
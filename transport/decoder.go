@@ -1,21 +1,21 @@
 package transport
 
 import (
-	"encoding/json"
 	"io"
+	"io/ioutil"
 	"reflect"
 	"strings"
 
 	"github.com/go-web-dev/event-bus/models"
 )
 
-// Decode decodes a certain reader into the specified type
-func Decode(r io.Reader, v interface{}) error {
-	err := json.NewDecoder(r).Decode(v)
+// Decode decodes a certain reader into the specified type with the codec of the connection it was read from
+func Decode(r io.Reader, codec Codec, v interface{}) error {
+	bs, err := ioutil.ReadAll(r)
 	if err != nil {
 		return err
 	}
-	return nil
+	return codec.Unmarshal(bs, v)
 }
 
 // DecodeFields interprets a given type via reflection to output required fields and field types
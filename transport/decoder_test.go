@@ -20,7 +20,7 @@ func Test_Decode_Success(t *testing.T) {
 	}
 	var p person
 
-	err := Decode(bytes.NewReader([]byte(`{"name": "steve"}`)), &p)
+	err := Decode(bytes.NewReader([]byte(`{"name": "steve"}`)), jsonCodec{}, &p)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, p)
@@ -29,12 +29,27 @@ func Test_Decode_Success(t *testing.T) {
 func Test_Decode_Error(t *testing.T) {
 	var p person
 
-	err := Decode(bytes.NewReader([]byte(`}`)), &p)
+	err := Decode(bytes.NewReader([]byte(`}`)), jsonCodec{}, &p)
 
 	assert.EqualError(t, err, "invalid character '}' looking for beginning of value")
 	assert.Empty(t, p)
 }
 
+func Test_Decode_MsgPack(t *testing.T) {
+	expected := person{
+		Name: "steve",
+		Age:  42,
+	}
+	packed, err := msgPackCodec{}.Marshal(expected)
+	assert.NoError(t, err)
+	var p person
+
+	err = Decode(bytes.NewReader(packed), msgPackCodec{}, &p)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, p)
+}
+
 func Test_DecodeFields(t *testing.T) {
 	expected := []models.RequiredField{
 		{
@@ -17,16 +17,66 @@ type Response struct {
 	Body      interface{} `json:"body,omitempty"`
 	Context   interface{} `json:"context,omitempty"`
 	Reason    string      `json:"reason,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
-// SendJSON is responsible for sending out JSON.
+// requestWriter tags every response written through it with the ID of the request it answers
+type requestWriter struct {
+	io.Writer
+	requestID string
+}
+
+// WithRequestID wraps the writer so that every response sent through it echoes the request ID,
+// letting clients match the responses of pipelined requests
+func WithRequestID(w io.Writer, requestID string) io.Writer {
+	if requestID == "" {
+		return w
+	}
+	return requestWriter{Writer: w, requestID: requestID}
+}
+
+// Unwrap gets the writer wrapped with the request ID
+func Unwrap(w io.Writer) io.Writer {
+	if rw, ok := w.(requestWriter); ok {
+		return rw.Writer
+	}
+	return w
+}
+
+// Encoder represents a writer with its own wire encoding, such as a connection which negotiated MessagePack
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// codecReader represents a writer answering requests read with another codec than JSON
+type codecReader interface {
+	Codec() Codec
+}
+
+// CodecOf gets the codec the requests answered through the writer are decoded with, JSON by default
+func CodecOf(w io.Writer) Codec {
+	if cr, ok := Unwrap(w).(codecReader); ok {
+		return cr.Codec()
+	}
+	return jsonCodec{}
+}
+
+// SendJSON is responsible for sending out the response, as JSON unless the writer has its own wire encoding.
 // To be used in successful cases only
 func SendJSON(w io.Writer, op string, body interface{}) {
 	logger := logging.Logger
 	res := toResponse(body, op)
-	err := json.NewEncoder(w).Encode(res)
+	if rw, ok := w.(requestWriter); ok {
+		res.RequestID = rw.requestID
+	}
+	var err error
+	if enc, ok := Unwrap(w).(Encoder); ok {
+		err = enc.Encode(res)
+	} else {
+		err = json.NewEncoder(w).Encode(res)
+	}
 	if err != nil {
-		logger.Error("could not encode json response", zap.Error(err))
+		logger.Error("could not encode response", zap.Error(err))
 	}
 }
 
@@ -43,10 +93,15 @@ func toResponse(any interface{}, op string) Response {
 	switch value := any.(type) {
 	case nil:
 		res.Status = true
-	case models.OperationRequestError:
+	case models.OperationRequestError, models.EventValidationError, models.IncompatibleSchemaError,
+		models.QuotaExceededError, models.RateLimitError:
 		res.Status = false
-		res.Reason = value.Error()
+		res.Reason = value.(error).Error()
 		res.Context = value
+	case models.UnhealthyError:
+		res.Status = false
+		res.Reason = value.Error()
+		res.Context = value.Health
 	case error:
 		res.Status = false
 		res.Reason = value.Error()
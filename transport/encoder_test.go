@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-web-dev/event-bus/logging"
 	"github.com/go-web-dev/event-bus/models"
+	"github.com/go-web-dev/event-bus/schema"
 	"github.com/go-web-dev/event-bus/testutils"
 )
 
@@ -46,10 +47,24 @@ func (s *encoderSuite) TestSendJSON_Error() {
 
 	SendJSON(rw, "great_op", make(chan int))
 
-	s.Equal(s.loggerEntry.Message, "could not encode json response")
+	s.Equal(s.loggerEntry.Message, "could not encode response")
 	s.Empty(s.ReadAll(rw))
 }
 
+func (s *encoderSuite) TestSendJSON_Encoder() {
+	enc := &encoderMock{ReadWriter: testutils.NewReadWriter()}
+
+	SendJSON(WithRequestID(enc, "r1"), "great_op", person{Name: "steve"})
+
+	s.Equal([]interface{}{Response{Operation: "great_op", Status: true, Body: person{Name: "steve"}, RequestID: "r1"}}, enc.encoded)
+	s.Empty(s.ReadAll(enc.ReadWriter))
+}
+
+func (s *encoderSuite) TestCodecOf() {
+	s.Equal(jsonCodec{}, CodecOf(testutils.NewReadWriter()))
+	s.Equal(msgPackCodec{}, CodecOf(WithRequestID(&encoderMock{codec: msgPackCodec{}}, "r1")))
+}
+
 func (s *encoderSuite) TestSendError() {
 	rw := testutils.NewReadWriter()
 	expected := `{"operation":"great_op","status":false, "reason": "some weird reason"}`
@@ -94,6 +109,117 @@ func (s *encoderSuite) TestSendError_WithContext() {
 	s.Empty(s.ReadAll(rw))
 }
 
+func (s *encoderSuite) TestSendError_RateLimited() {
+	rw := testutils.NewReadWriter()
+	expected := `{
+		"operation":"great_op",
+		"status":false,
+		"reason": "rate_limited",
+		"context": {"retry_after_ms": 250}
+	}`
+
+	SendError(rw, "great_op", models.RateLimitError{RetryAfterMS: 250})
+
+	s.JSONEq(expected, s.ReadAll(rw))
+}
+
+func (s *encoderSuite) TestSendError_Unhealthy() {
+	rw := testutils.NewReadWriter()
+	expected := `{
+		"operation":"health",
+		"status":false,
+		"reason": "service is unhealthy",
+		"context": {"status": "down", "components": {"storage": {"status": "down", "error": "DB Closed"}}}
+	}`
+	err := models.UnhealthyError{Health: models.Health{
+		Status: models.HealthDown,
+		Components: map[string]models.ComponentHealth{
+			"storage": {Status: models.HealthDown, Error: "DB Closed"},
+		},
+	}}
+
+	SendError(rw, "health", err)
+
+	s.JSONEq(expected, s.ReadAll(rw))
+}
+
+func (s *encoderSuite) TestSendError_EventValidation() {
+	rw := testutils.NewReadWriter()
+	expected := `{
+		"operation":"great_op",
+		"status":false,
+		"reason": "event does not match the stream schema",
+		"context": {"violations": [{"path": "name", "message": "name is required"}]}
+	}`
+	err := models.EventValidationError{
+		Violations: []schema.Violation{{Path: "name", Message: "name is required"}},
+	}
+
+	SendError(rw, "great_op", err)
+
+	s.JSONEq(expected, s.ReadAll(rw))
+}
+
+func (s *encoderSuite) TestSendError_IncompatibleSchema() {
+	rw := testutils.NewReadWriter()
+	expected := `{
+		"operation":"great_op",
+		"status":false,
+		"reason": "schema is not backward compatible with version 1",
+		"context": {
+			"compatibility": "backward",
+			"version": 1,
+			"violations": [{"path": "age", "message": "the new version requires the property"}]
+		}
+	}`
+	err := models.IncompatibleSchemaError{
+		Compatibility: "backward",
+		Version:       1,
+		Violations:    []schema.Violation{{Path: "age", Message: "the new version requires the property"}},
+	}
+
+	SendError(rw, "great_op", err)
+
+	s.JSONEq(expected, s.ReadAll(rw))
+}
+
+func (s *encoderSuite) TestSendError_QuotaExceeded() {
+	rw := testutils.NewReadWriter()
+	expected := `{
+		"operation":"great_op",
+		"status":false,
+		"reason": "stream 's1' quota exceeded: max_events is 10",
+		"context": {"stream": "s1", "quota": "max_events", "limit": 10}
+	}`
+	err := models.QuotaExceededError{Stream: "s1", Quota: "max_events", Limit: 10}
+
+	SendError(rw, "great_op", err)
+
+	s.JSONEq(expected, s.ReadAll(rw))
+}
+
+func (s *encoderSuite) TestSendJSON_WithRequestID() {
+	rw := testutils.NewReadWriter()
+	expected := `{"operation":"great_op","status":true,"request_id":"req-1"}`
+
+	SendJSON(WithRequestID(rw, "req-1"), "great_op", nil)
+
+	s.JSONEq(expected, s.ReadAll(rw))
+}
+
+func (s *encoderSuite) TestWithRequestID_Empty() {
+	rw := testutils.NewReadWriter()
+
+	s.Equal(rw, WithRequestID(rw, ""))
+}
+
+func (s *encoderSuite) TestUnwrap() {
+	rw := testutils.NewReadWriter()
+
+	s.Equal(rw, Unwrap(WithRequestID(rw, "req-1")))
+	s.Equal(rw, Unwrap(rw))
+}
+
 func Test_EncoderSuite(t *testing.T) {
 	suite.Run(t, new(encoderSuite))
 }
@@ -0,0 +1,20 @@
+package transport
+
+import (
+	"github.com/go-web-dev/event-bus/testutils"
+)
+
+type encoderMock struct {
+	*testutils.ReadWriter
+	codec   Codec
+	encoded []interface{}
+}
+
+func (m *encoderMock) Encode(v interface{}) error {
+	m.encoded = append(m.encoded, v)
+	return nil
+}
+
+func (m *encoderMock) Codec() Codec {
+	return m.codec
+}